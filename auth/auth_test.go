@@ -0,0 +1,91 @@
+// auth_test.go - Tests for API keys and access rules.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	a, err := New(Options{APIKeys: map[string]string{"k-ingest": "ingest", "k-ops": "ops"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := a.Authenticate("k-ingest")
+	if err != nil || p.Subject != "ingest" {
+		t.Fatalf("Authenticate(k-ingest) = %+v, %v", p, err)
+	}
+	if _, err := a.Authenticate("k-ingest2"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate with an unknown key = %v, want ErrInvalidAPIKey", err)
+	}
+	if _, err := a.Authenticate(""); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Authenticate(\"\") = %v, want ErrNoCredentials", err)
+	}
+	// Tokens are disabled, so a dotted credential is looked up as a key
+	if _, err := a.Authenticate("a.b.c"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate(a.b.c) = %v, want ErrInvalidAPIKey", err)
+	}
+
+	ctx := NewContext(context.Background(), p)
+	if got, ok := FromContext(ctx); !ok || got != p {
+		t.Fatalf("FromContext() = %+v, %v", got, ok)
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatal("FromContext() found a principal in an empty context")
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	a, err := New(Options{
+		APIKeys: map[string]string{"k": "ingest"},
+		Rules: []Rule{
+			{Subject: "ingest", Resources: []string{"orders", "audit.*"}, Permissions: []Permission{Produce}},
+			{Subject: "ops", Resources: []string{"*"}, Permissions: []Permission{Produce, Consume, Admin}},
+			{Subject: AnySubject, Resources: []string{"public"}, Permissions: []Permission{Consume}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingest, ops, guest := &Principal{Subject: "ingest"}, &Principal{Subject: "ops"}, &Principal{Subject: "guest"}
+	for _, tc := range []struct {
+		p        *Principal
+		perm     Permission
+		resource string
+		want     bool
+	}{
+		{ingest, Produce, "orders", true},
+		{ingest, Produce, "audit.eu", true},
+		{ingest, Produce, "audit", false},
+		{ingest, Consume, "orders", false},
+		{ingest, Admin, "orders", false},
+		{ingest, Consume, "public", true},
+		{guest, Consume, "public", true},
+		{guest, Produce, "public", false},
+		{ops, Admin, "*", true},
+		{ops, Consume, "orders:billing", true},
+		{ingest, Admin, "*", false},
+	} {
+		if got := a.Allowed(tc.p, tc.perm, tc.resource); got != tc.want {
+			t.Errorf("Allowed(%s, %s, %s) = %v, want %v", tc.p.Subject, tc.perm, tc.resource, got, tc.want)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(Options{}); !errors.Is(err, ErrNoMethods) {
+		t.Fatalf("New() without methods = %v, want ErrNoMethods", err)
+	}
+	keys := map[string]string{"k": "s"}
+	if _, err := New(Options{APIKeys: keys, Rules: []Rule{{Subject: "s", Resources: []string{"q"}, Permissions: []Permission{"drain"}}}}); !errors.Is(err, ErrInvalidPermission) {
+		t.Fatalf("New() with an unknown permission = %v, want ErrInvalidPermission", err)
+	}
+	if _, err := New(Options{APIKeys: keys, Rules: []Rule{{Subject: "s", Resources: []string{"[q"}, Permissions: []Permission{Produce}}}}); !errors.Is(err, ErrInvalidRule) {
+		t.Fatalf("New() with a malformed pattern = %v, want ErrInvalidRule", err)
+	}
+	if _, err := New(Options{APIKeys: keys, Rules: []Rule{{Resources: []string{"q"}}}}); !errors.Is(err, ErrInvalidRule) {
+		t.Fatalf("New() with a rule without subject = %v, want ErrInvalidRule", err)
+	}
+}
@@ -0,0 +1,307 @@
+// token.go - Verifying signed tokens.
+//
+// This file verifies the compact JWS tokens clients present as bearer
+// credentials. HMAC-signed tokens use the HS256 algorithm with a secret shared
+// with whoever issues them, and NewHMACToken issues one. JWTs from an identity
+// provider use RS256, RS384, RS512, ES256, ES384 or ES512 and are checked
+// against the public keys of a JWKS file; the key is chosen by the token's "kid"
+// header, or tried in turn if there is none. HS256 is never verified with a
+// JWKS key, nor an asymmetric algorithm with the secret. A valid token must
+// carry a "sub" claim, must not be expired or used before its "nbf" time (with
+// a little leeway for clock skew), and must match the configured issuer and
+// audience.
+
+package auth
+
+import (
+	"crypto"          // For hash functions
+	"crypto/ecdh"     // For validating EC public keys
+	"crypto/ecdsa"    // For ES* signatures
+	"crypto/elliptic" // For EC curves
+	"crypto/hmac"     // For HS256 signatures
+	"crypto/rsa"      // For RS* signatures
+	"crypto/sha256"   // For HS256 and ES256/RS256
+	_ "crypto/sha512" // Registers SHA-384 and SHA-512 for ES384/ES512 and RS384/RS512
+	"encoding/base64" // For token and key encoding
+	"encoding/json"   // For token headers, claims and JWKS files
+	"errors"          // For key set errors
+	"math/big"        // For key parameters and EC signatures
+	"os"              // For reading JWKS files
+	"strings"         // For splitting tokens
+	"time"            // For expiry
+)
+
+// clockSkew is how far token times may be off before a token is rejected.
+const clockSkew = 30 * time.Second
+
+// Errors returned when loading a key set.
+var (
+	ErrInvalidKeySet = errors.New("invalid JWKS: expected an object with a \"keys\" array")
+	ErrNoKeys        = errors.New("JWKS holds no usable signing keys")
+)
+
+// b64 is the unpadded base64url encoding tokens and JWKS files use.
+var b64 = base64.RawURLEncoding
+
+// Claims are the registered JWT claims the server reads.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"` // Unix seconds; zero never expires
+	NotBefore int64    `json:"nbf,omitempty"` // Unix seconds
+	IssuedAt  int64    `json:"iat,omitempty"` // Unix seconds
+}
+
+// Audience is the "aud" claim, which a token may give as a string or an array.
+type Audience []string
+
+// UnmarshalJSON accepts a single audience string or an array of them.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// NewHMACToken issues an HS256 token carrying claims, signed with secret.
+func NewHMACToken(secret []byte, claims Claims) (string, error) {
+	h, _ := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := b64.EncodeToString(h) + "." + b64.EncodeToString(c)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + b64.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifyToken checks the signature and claims of token.
+func (a *Authenticator) verifyToken(token string) (Claims, error) {
+	var claims Claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, ErrInvalidToken
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return claims, ErrInvalidToken
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	if !a.verifySignature(h, signingInput, sig) {
+		return claims, ErrInvalidToken
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil || claims.Subject == "" {
+		return claims, ErrInvalidToken
+	}
+
+	now := a.now()
+	if claims.ExpiresAt != 0 && now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) {
+		return claims, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return claims, ErrInvalidToken
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return claims, ErrInvalidToken
+	}
+	if a.audience != "" && !claims.Audience.contains(a.audience) {
+		return claims, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// contains reports whether the audience includes name.
+func (a Audience) contains(name string) bool {
+	for _, aud := range a {
+		if aud == name {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON token segment into v.
+func decodeSegment(segment string, v any) error {
+	data, err := b64.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature checks sig over signingInput with the key the header's algorithm calls for.
+func (a *Authenticator) verifySignature(h header, signingInput, sig []byte) bool {
+	if h.Alg == "HS256" {
+		if a.hmacSecret == nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, a.hmacSecret)
+		mac.Write(signingInput)
+		return hmac.Equal(sig, mac.Sum(nil))
+	}
+	hash, ok := algHashes[h.Alg]
+	if !ok || a.keySet == nil {
+		return false
+	}
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+	for _, k := range a.keySet.keys {
+		if h.Kid != "" && k.id != h.Kid {
+			continue
+		}
+		if k.verify(h.Alg, hash, digest, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// algHashes maps the asymmetric algorithms accepted to their hash functions.
+var algHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// KeySet holds the public keys of a JWKS file.
+type KeySet struct {
+	keys []jwk
+}
+
+// jwk is one public key of a key set.
+type jwk struct {
+	id  string           // "kid"
+	rsa *rsa.PublicKey   // Set for RSA keys
+	ec  *ecdsa.PublicKey // Set for EC keys
+}
+
+// verify checks an RS* or ES* signature over digest.
+func (k jwk) verify(alg string, hash crypto.Hash, digest, sig []byte) bool {
+	switch {
+	case alg[:2] == "RS" && k.rsa != nil:
+		return rsa.VerifyPKCS1v15(k.rsa, hash, digest, sig) == nil
+	case alg[:2] == "ES" && k.ec != nil:
+		// The curve must match the algorithm: ES256 with P-256, ES384 with P-384, ES512 with P-521
+		size := (k.ec.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size || ecHashes[k.ec.Curve] != hash {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k.ec, digest, r, s)
+	}
+	return false
+}
+
+// ecHashes maps each curve to the hash of its algorithm.
+var ecHashes = map[elliptic.Curve]crypto.Hash{
+	elliptic.P256(): crypto.SHA256,
+	elliptic.P384(): crypto.SHA384,
+	elliptic.P521(): crypto.SHA512,
+}
+
+// LoadKeySet reads a JWKS file.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JWKS document. RSA and EC signing keys are kept; keys for
+// other uses or of other types are skipped, and a key that cannot be parsed is an error.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Keys == nil {
+		return nil, ErrInvalidKeySet
+	}
+	set := &KeySet{}
+	for _, raw := range doc.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+		k := jwk{id: raw.Kid}
+		switch raw.Kty {
+		case "RSA":
+			n, errN := b64.DecodeString(raw.N)
+			e, errE := b64.DecodeString(raw.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, errors.New("invalid RSA key " + raw.Kid)
+			}
+			k.rsa = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			pub, err := parseECKey(raw.Crv, raw.X, raw.Y)
+			if err != nil {
+				return nil, errors.New("invalid EC key " + raw.Kid)
+			}
+			k.ec = pub
+		default:
+			continue
+		}
+		set.keys = append(set.keys, k)
+	}
+	if len(set.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return set, nil
+}
+
+// parseECKey builds an EC public key from its JWK parameters, checking that the
+// point is on the curve.
+func parseECKey(crv, xParam, yParam string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var check ecdh.Curve
+	switch crv {
+	case "P-256":
+		curve, check = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, check = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, check = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, errors.New("unsupported curve")
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	x, errX := b64.DecodeString(xParam)
+	y, errY := b64.DecodeString(yParam)
+	if errX != nil || errY != nil || len(x) != size || len(y) != size {
+		return nil, errors.New("invalid point")
+	}
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := check.NewPublicKey(point); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
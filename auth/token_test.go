@@ -0,0 +1,164 @@
+// token_test.go - Tests for verifying HMAC-signed tokens and JWTs.
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// encodeSegments base64url-encodes a token header and payload.
+func encodeSegments(h header, payload any) string {
+	hb, _ := json.Marshal(h)
+	pb, _ := json.Marshal(payload)
+	return b64.EncodeToString(hb) + "." + b64.EncodeToString(pb)
+}
+
+// signHS256 signs a token with an HMAC secret.
+func signHS256(secret []byte, h header, payload any) string {
+	input := encodeSegments(h, payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + b64.EncodeToString(mac.Sum(nil))
+}
+
+// signAsymmetric signs a token with SHA-256 and the given signer.
+func signAsymmetric(h header, payload any, sign func(digest []byte) []byte) string {
+	input := encodeSegments(h, payload)
+	digest := sha256.Sum256([]byte(input))
+	return input + "." + b64.EncodeToString(sign(digest[:]))
+}
+
+func TestHMACToken(t *testing.T) {
+	secret := []byte("shared secret")
+	a, err := New(Options{HMACSecret: secret, Issuer: "ci", Audience: "quickpulse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	a.now = func() time.Time { return now }
+	token := func(c Claims) string {
+		tok, err := NewHMACToken(secret, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	valid := Claims{Subject: "ingest", Issuer: "ci", Audience: Audience{"other", "quickpulse"}, ExpiresAt: now.Add(time.Minute).Unix()}
+
+	p, err := a.Authenticate(token(valid))
+	if err != nil || p.Subject != "ingest" {
+		t.Fatalf("Authenticate(valid) = %+v, %v", p, err)
+	}
+	expired := valid
+	expired.ExpiresAt = now.Add(-time.Minute).Unix()
+	if _, err := a.Authenticate(token(expired)); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("Authenticate(expired) = %v, want ErrTokenExpired", err)
+	}
+	skewed := valid
+	skewed.ExpiresAt = now.Add(-10 * time.Second).Unix()
+	if _, err := a.Authenticate(token(skewed)); err != nil {
+		t.Fatalf("Authenticate(expired within the clock skew) = %v", err)
+	}
+	for name, c := range map[string]Claims{
+		"early":          {Subject: "ingest", Issuer: "ci", Audience: Audience{"quickpulse"}, NotBefore: now.Add(time.Hour).Unix()},
+		"wrong issuer":   {Subject: "ingest", Issuer: "someone", Audience: Audience{"quickpulse"}},
+		"wrong audience": {Subject: "ingest", Issuer: "ci", Audience: Audience{"other"}},
+		"no subject":     {Issuer: "ci", Audience: Audience{"quickpulse"}},
+	} {
+		if _, err := a.Authenticate(token(c)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%s) = %v, want ErrInvalidToken", name, err)
+		}
+	}
+	forged, _ := NewHMACToken([]byte("guess"), valid)
+	if _, err := a.Authenticate(forged); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Authenticate(forged) = %v, want ErrInvalidToken", err)
+	}
+	// A single "aud" string is accepted as well as an array
+	single := signHS256(secret, header{Alg: "HS256"}, map[string]string{"sub": "ingest", "iss": "ci", "aud": "quickpulse"})
+	if _, err := a.Authenticate(single); err != nil {
+		t.Fatalf("Authenticate(string aud) = %v", err)
+	}
+	// Unsigned tokens are never accepted
+	unsigned := encodeSegments(header{Alg: "none"}, valid) + "."
+	if _, err := a.Authenticate(unsigned); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Authenticate(alg none) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64.EncodeToString(rsaKey.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))), "y": b64.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "skipped", "k": "c2VjcmV0"},
+		{"kty": "RSA", "kid": "encryption", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	keys, err := ParseKeySet(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.keys) != 2 {
+		t.Fatalf("ParseKeySet kept %d keys, want 2", len(keys.keys))
+	}
+	a, err := New(Options{KeySet: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := Claims{Subject: "svc", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	signRSA := func(digest []byte) []byte {
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		return sig
+	}
+	signEC := func(digest []byte) []byte {
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, digest)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	for name, tok := range map[string]string{
+		"RS256":             signAsymmetric(header{Alg: "RS256", Kid: "rsa-1"}, claims, signRSA),
+		"ES256":             signAsymmetric(header{Alg: "ES256", Kid: "ec-1"}, claims, signEC),
+		"ES256 without kid": signAsymmetric(header{Alg: "ES256"}, claims, signEC),
+	} {
+		if p, err := a.Authenticate(tok); err != nil || p.Subject != "svc" {
+			t.Errorf("Authenticate(%s) = %+v, %v", name, p, err)
+		}
+	}
+	for name, tok := range map[string]string{
+		"wrong kid":      signAsymmetric(header{Alg: "RS256", Kid: "ec-1"}, claims, signRSA),
+		"alg mismatch":   signAsymmetric(header{Alg: "ES256", Kid: "rsa-1"}, claims, signRSA),
+		"unknown alg":    signAsymmetric(header{Alg: "PS256", Kid: "rsa-1"}, claims, signRSA),
+		"HS256 disabled": signHS256(rsaKey.N.Bytes(), header{Alg: "HS256"}, claims),
+	} {
+		if _, err := a.Authenticate(tok); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%s) = %v, want ErrInvalidToken", name, err)
+		}
+	}
+
+	if _, err := ParseKeySet([]byte(`{"keys": []}`)); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("ParseKeySet(no keys) = %v, want ErrNoKeys", err)
+	}
+	if _, err := ParseKeySet([]byte(`[]`)); !errors.Is(err, ErrInvalidKeySet) {
+		t.Fatalf("ParseKeySet(array) = %v, want ErrInvalidKeySet", err)
+	}
+	if _, err := ParseKeySet([]byte(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AAAA", "y": "AAAA"}]}`)); err == nil {
+		t.Fatal("ParseKeySet accepted a point off the curve")
+	}
+}
@@ -0,0 +1,45 @@
+// client.go - TLS configuration for clients.
+//
+// This file builds the TLS config clients such as the perf clients dial the
+// server with: which CAs to trust, which name to verify and, for servers that
+// require mutual TLS, the client certificate to present.
+
+package certs
+
+import "crypto/tls" // For client configs
+
+// ClientOptions configures a client's TLS connections.
+type ClientOptions struct {
+	CAFile             string // PEM CA certificates to verify the server with; empty uses the system roots
+	CertFile           string // PEM client certificate for mutual TLS; set with KeyFile
+	KeyFile            string // PEM private key of the client certificate
+	ServerName         string // Name to verify instead of the dialed host
+	InsecureSkipVerify bool   // Accept any server certificate (testing only)
+}
+
+// ClientConfig builds a client TLS config from opts.
+func ClientConfig(opts ClientOptions) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.CAFile != "" {
+		pool, err := LoadCertPool(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, ErrIncompleteKeyPair
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
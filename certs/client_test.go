@@ -0,0 +1,32 @@
+// client_test.go - Tests for client TLS configs.
+
+package certs
+
+import (
+	"crypto/x509"
+	"errors"
+	"testing"
+)
+
+func TestClientConfig(t *testing.T) {
+	ca := newTestCA(t, "CA")
+	cert, key := ca.issue(t, 1, x509.ExtKeyUsageClientAuth)
+	paths := writeFiles(t, t.TempDir(), "ca.pem", string(ca.pem), "client.pem", string(cert), "client-key.pem", string(key), "empty.pem", "")
+
+	cfg, err := ClientConfig(ClientOptions{CAFile: paths[0], CertFile: paths[1], KeyFile: paths[2], ServerName: "mq.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 || cfg.ServerName != "mq.internal" {
+		t.Fatalf("ClientConfig() = %+v", cfg)
+	}
+	if cfg, err := ClientConfig(ClientOptions{}); err != nil || cfg.RootCAs != nil || cfg.Certificates != nil {
+		t.Fatalf("ClientConfig() with no files = %+v, %v; want the system roots", cfg, err)
+	}
+	if _, err := ClientConfig(ClientOptions{CertFile: paths[1]}); !errors.Is(err, ErrIncompleteKeyPair) {
+		t.Fatalf("ClientConfig() without a key = %v, want ErrIncompleteKeyPair", err)
+	}
+	if _, err := ClientConfig(ClientOptions{CAFile: paths[3]}); !errors.Is(err, ErrNoCertificates) {
+		t.Fatalf("ClientConfig() with an empty CA file = %v, want ErrNoCertificates", err)
+	}
+}
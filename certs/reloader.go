@@ -0,0 +1,209 @@
+// reloader.go - Server certificates that follow their files.
+//
+// This file defines Reloader, which serves the TLS certificate and key from a
+// pair of PEM files, and optionally verifies client certificates against a CA
+// file (mutual TLS). The files are checked for changes periodically and on
+// demand, so rotated certificates take effect for new connections without a
+// restart; connections already open keep the certificate they started with. A
+// rotation that leaves the files unreadable or mismatched, for instance while
+// the certificate has been replaced but the key not yet, keeps the previous
+// certificate until the files are consistent again.
+
+package certs
+
+import (
+	"crypto/tls"  // For certificates and server configs
+	"crypto/x509" // For verifying client certificates
+	"errors"      // For certificate errors
+	"log/slog"    // For reporting failed reloads
+	"os"          // For reading and watching the files
+	"sync"        // For guarding the loaded certificates
+	"time"        // For the check interval
+)
+
+// DefaultCheckInterval is how often the server checks its certificate files for changes.
+const DefaultCheckInterval = 10 * time.Second
+
+// Errors returned when loading certificates.
+var (
+	ErrNoCertificates    = errors.New("file holds no PEM certificates")
+	ErrNoClientCert      = errors.New("client did not present a certificate")
+	ErrIncompleteKeyPair = errors.New("set both the certificate and key files, or neither")
+)
+
+// fileStamp identifies a version of a file by its size and modification time.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Reloader serves a certificate from files and reloads it when they change.
+type Reloader struct {
+	certFile     string // PEM certificate chain
+	keyFile      string // PEM private key
+	clientCAFile string // PEM CA certificates for client verification; empty disables mTLS
+
+	mu        sync.RWMutex     // Guards the fields below
+	cert      *tls.Certificate // Certificate served to new connections
+	clientCAs *x509.CertPool   // CAs client certificates must chain to
+	stamps    []fileStamp      // Versions of the files cert and clientCAs were loaded from
+
+	stop      chan struct{} // Closed by Close to stop watching
+	closeOnce sync.Once     // Guards closing stop
+}
+
+// NewReloader loads the certificate and key files, and the client CA file if it
+// is set, and checks them for changes every interval; zero disables the checks,
+// so only Reload picks up new files. It fails if the files cannot be loaded.
+func NewReloader(certFile, keyFile, clientCAFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, stop: make(chan struct{})}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go r.watch(interval)
+	}
+	return r, nil
+}
+
+// files lists the files the reloader loads.
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// Reload loads the files again. On failure the previous certificates stay in use.
+func (r *Reloader) Reload() error {
+	stamps, err := stat(r.files())
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		if clientCAs, err = LoadCertPool(r.clientCAFile); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.stamps = &cert, clientCAs, stamps
+	r.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any file differs from the version last loaded.
+func (r *Reloader) changed() bool {
+	stamps, err := stat(r.files())
+	if err != nil {
+		// A file being replaced may be missing for a moment; the load would fail anyway
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range stamps {
+		if stamps[i] != r.stamps[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// watch reloads the files whenever they change, until Close is called.
+func (r *Reloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		if !r.changed() {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			slog.Warn("TLS certificate reload failed, keeping the previous certificate", "err", err)
+			continue
+		}
+		slog.Info("TLS certificate reloaded", "cert", r.certFile)
+	}
+}
+
+// Close stops checking the files for changes.
+func (r *Reloader) Close() {
+	r.closeOnce.Do(func() { close(r.stop) })
+}
+
+// ServerConfig returns a TLS config for servers that presents the current
+// certificate and, if a client CA file is set, requires client certificates
+// signed by one of its CAs. It stays current as the files are reloaded, and can
+// be cloned, for instance by gRPC and net/http.
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+	if r.clientCAFile != "" {
+		// Verification is done here rather than through ClientCAs, which would
+		// pin the CAs loaded when the config was built
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = r.verifyClient
+	}
+	return cfg
+}
+
+// verifyClient checks that the client's certificate chains to a current client CA.
+func (r *Reloader) verifyClient(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return ErrNoClientCert
+	}
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// LoadCertPool reads a file of PEM certificates into a pool.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, ErrNoCertificates
+	}
+	return pool, nil
+}
+
+// stat returns the current version of every file.
+func stat(files []string) ([]fileStamp, error) {
+	stamps := make([]fileStamp, len(files))
+	for i, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps[i] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,230 @@
+// reloader_test.go - Tests for reloading server certificates and mutual TLS.
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority generated for a test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a certificate for localhost with the given serial number and usage,
+// returning its certificate and key as PEM.
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFiles writes name=content pairs into dir and returns the paths.
+func writeFiles(t *testing.T, dir string, files ...string) []string {
+	t.Helper()
+	var paths []string
+	for i := 0; i < len(files); i += 2 {
+		path := filepath.Join(dir, files[i])
+		if err := os.WriteFile(path, []byte(files[i+1]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// serve accepts TLS connections with cfg until the test ends, completing each handshake.
+func serve(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// serverSerial connects to addr and returns the serial number of the server's certificate.
+func serverSerial(t *testing.T, addr string, cfg *tls.Config) (int64, error) {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	// Under TLS 1.3 a rejected client certificate surfaces on the first read
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !isTimeoutOrEOF(err) {
+		return 0, err
+	}
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func isTimeoutOrEOF(err error) bool {
+	var ne net.Error
+	return errors.Is(err, io.EOF) || (errors.As(err, &ne) && ne.Timeout())
+}
+
+func TestReloaderMutualTLS(t *testing.T) {
+	serverCA, clientCA, otherCA := newTestCA(t, "server CA"), newTestCA(t, "client CA"), newTestCA(t, "other CA")
+	dir := t.TempDir()
+	serverCert, serverKey := serverCA.issue(t, 10, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := clientCA.issue(t, 20, x509.ExtKeyUsageClientAuth)
+	strangerCert, strangerKey := otherCA.issue(t, 30, x509.ExtKeyUsageClientAuth)
+	paths := writeFiles(t, dir,
+		"server.pem", string(serverCert), "server-key.pem", string(serverKey),
+		"client-ca.pem", string(clientCA.pem), "server-ca.pem", string(serverCA.pem),
+		"client.pem", string(clientCert), "client-key.pem", string(clientKey),
+		"stranger.pem", string(strangerCert), "stranger-key.pem", string(strangerKey))
+
+	r, err := NewReloader(paths[0], paths[1], paths[2], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	addr := serve(t, r.ServerConfig())
+
+	dial := func(certFile, keyFile string) error {
+		cfg, err := ClientConfig(ClientOptions{CAFile: paths[3], CertFile: certFile, KeyFile: keyFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = serverSerial(t, addr, cfg)
+		return err
+	}
+	if err := dial(paths[4], paths[5]); err != nil {
+		t.Fatalf("client with a trusted certificate: %v", err)
+	}
+	if err := dial("", ""); err == nil {
+		t.Fatal("client without a certificate was accepted")
+	}
+	if err := dial(paths[6], paths[7]); err == nil {
+		t.Fatal("client with a certificate from another CA was accepted")
+	}
+
+	if _, err := NewReloader(paths[0], paths[1], paths[0]+".missing", 0); err == nil {
+		t.Fatal("NewReloader with a missing client CA file succeeded")
+	}
+	if _, err := NewReloader(paths[0], paths[5], "", 0); err == nil {
+		t.Fatal("NewReloader with a mismatched key succeeded")
+	}
+}
+
+func TestReloaderRotation(t *testing.T) {
+	ca := newTestCA(t, "server CA")
+	dir := t.TempDir()
+	cert, key := ca.issue(t, 1, x509.ExtKeyUsageServerAuth)
+	paths := writeFiles(t, dir, "server.pem", string(cert), "server-key.pem", string(key), "ca.pem", string(ca.pem))
+
+	r, err := NewReloader(paths[0], paths[1], "", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	addr := serve(t, r.ServerConfig())
+	client, err := ClientConfig(ClientOptions{CAFile: paths[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial, err := serverSerial(t, addr, client); err != nil || serial != 1 {
+		t.Fatalf("serial = %d, %v; want 1", serial, err)
+	}
+
+	// A half-finished rotation keeps the old certificate
+	cert, key = ca.issue(t, 2, x509.ExtKeyUsageServerAuth)
+	writeFiles(t, dir, "server.pem", string(cert))
+	time.Sleep(50 * time.Millisecond)
+	if serial, err := serverSerial(t, addr, client); err != nil || serial != 1 {
+		t.Fatalf("serial during rotation = %d, %v; want 1", serial, err)
+	}
+
+	// Once the key follows, new connections get the new certificate
+	writeFiles(t, dir, "server-key.pem", string(key))
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		serial, err := serverSerial(t, addr, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if serial == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
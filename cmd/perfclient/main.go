@@ -1,8 +1,9 @@
 // Package main provides a command-line tool for running performance tests
 // against WebSocket, gRPC, and gRPC streaming servers. It allows configuration
-// of concurrency, in-flight requests, message count, test duration, payload, and
-// server address via command-line flags. The actual test logic is implemented in
-// the quickpulse/perfclient package.
+// of concurrency, in-flight requests, message count, test duration, payload,
+// server address, TLS (including a client certificate for mutual TLS) and the
+// credential to authenticate with via command-line flags. The actual test logic is implemented in the
+// quickpulse/perfclient package.
 package main
 
 import (
@@ -10,6 +11,7 @@ import (
 	"fmt"    // For formatted I/O
 	"os"     // For OS-level functions like exiting the program
 
+	"quickpulse/certs"      // For the client TLS config
 	"quickpulse/perfclient" // Import the perfclient package containing test runners
 )
 
@@ -25,8 +27,27 @@ func main() {
 	duration := flag.Int("duration", 5, "Test duration in seconds")
 	payload := flag.String("payload", "aGVsbG8gd29ybGQ=", "Base64-encoded payload")
 	address := flag.String("address", "localhost:50051", "gRPC server address")
+	useTLS := flag.Bool("tls", false, "Connect over TLS")
+	var tlsOpts certs.ClientOptions
+	flag.StringVar(&tlsOpts.CAFile, "tls-ca", "", "CA certificates to verify the server with (PEM; default: system roots)")
+	flag.StringVar(&tlsOpts.CertFile, "tls-cert", "", "Client certificate for mutual TLS (PEM)")
+	flag.StringVar(&tlsOpts.KeyFile, "tls-key", "", "Client private key for mutual TLS (PEM)")
+	flag.StringVar(&tlsOpts.ServerName, "tls-server-name", "", "Server name to verify instead of the host dialed")
+	flag.BoolVar(&tlsOpts.InsecureSkipVerify, "tls-insecure-skip-verify", false, "Accept any server certificate (testing only)")
+	token := flag.String("token", os.Getenv("QUICKPULSE_TOKEN"), "API key or token to authenticate with (default: $QUICKPULSE_TOKEN)")
 	flag.Parse() // Parse the command-line flags
 
+	// Any TLS flag implies -tls
+	if *useTLS || tlsOpts != (certs.ClientOptions{}) {
+		tlsCfg, err := certs.ClientConfig(tlsOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid TLS settings: %v\n", err)
+			os.Exit(1)
+		}
+		perfclient.UseTLS(tlsCfg)
+	}
+	perfclient.UseCredential(*token)
+
 	// Select the test mode and run the corresponding performance test
 	switch *mode {
 	case "ws":
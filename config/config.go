@@ -0,0 +1,552 @@
+// config.go - Server configuration.
+//
+// This file defines Config, which describes everything the server needs at
+// startup: its listeners, the queues it creates, gRPC limits, TLS, client
+// authentication, producer rate limits, metrics, the memory budget, durability,
+// snapshots, shutdown deadlines and the log level. Default returns the built-in
+// settings, Validate reports every invalid value at once, and String renders the
+// effective configuration for the startup log. Loading it from a file, the
+// environment and command-line flags is in load.go, and applying a changed file
+// to a running server is in reload.go.
+
+package config
+
+import (
+	"bytes"    // For trimming the HMAC secret
+	"errors"   // For joining validation errors
+	"log/slog" // For log levels
+	"os"       // For reading the HMAC secret
+	"path"     // For checking origin patterns
+	"strconv"  // For naming unnamed queues in errors
+	"strings"  // For checking the metrics path
+	"time"     // For deadlines and intervals
+
+	"quickpulse/auth"      // Authentication methods and access rules
+	"quickpulse/certs"     // Certificate reload interval
+	"quickpulse/mq"        // Queue and write-ahead log settings
+	"quickpulse/ratelimit" // Producer rate limits
+
+	"gopkg.in/yaml.v3" // For rendering the effective configuration
+)
+
+// Built-in defaults.
+const (
+	DefaultWebSocketAddr         = ":8081"
+	DefaultGRPCAddr              = ":50051"
+	DefaultMetricsAddr           = ":8080"
+	DefaultMetricsPath           = "/metrics"
+	DefaultQueueCapacity         = 1000000          // Capacity of the default queue
+	DefaultMaxConcurrentStreams  = 1000000          // Maximum concurrent gRPC streams
+	DefaultMaxReceiveMessageSize = 1024             // Maximum size of received gRPC messages (1KB)
+	DefaultWriteBufferSize       = 32 * 1024        // gRPC write buffer size (32KB)
+	DefaultReadBufferSize        = 32 * 1024        // gRPC read buffer size (32KB)
+	DefaultDrainTimeout          = 30 * time.Second // How long consumers may empty the queues on shutdown
+	DefaultGracePeriod           = 5 * time.Second  // How long listeners may take to close client connections
+	DefaultLogLevel              = "info"
+	DefaultWsMaxMessageSize      = 1 << 20 // Largest WebSocket message a client may send (1MB)
+)
+
+// Config is the complete server configuration.
+type Config struct {
+	Listeners  Listeners   `yaml:"listeners"`
+	Metrics    Metrics     `yaml:"metrics"`
+	TLS        TLS         `yaml:"tls"`
+	Auth       Auth        `yaml:"auth"`
+	RateLimits []RateLimit `yaml:"rate_limits"`
+	Limits     Limits      `yaml:"limits"`
+	Queues     []Queue     `yaml:"queues"`
+	Memory     Memory      `yaml:"memory"`
+	Durability Durability  `yaml:"durability"`
+	Snapshot   Snapshot    `yaml:"snapshot"`
+	Shutdown   Shutdown    `yaml:"shutdown"`
+	Log        Log         `yaml:"log"`
+}
+
+// Listeners configures the client-facing servers; each can be enabled on its own.
+type Listeners struct {
+	WebSocket WebSocketListener `yaml:"websocket"`
+	GRPC      GRPCListener      `yaml:"grpc"`
+}
+
+// WebSocketListener configures the WebSocket endpoints and their handshake policy.
+type WebSocketListener struct {
+	Enabled             bool     `yaml:"enabled"`
+	Addr                string   `yaml:"addr"`
+	AllowedOrigins      []string `yaml:"allowed_origins"`        // Exact or wildcard ("https://*.example.com"); "*" allows any, empty only the same origin
+	Subprotocols        []string `yaml:"subprotocols"`           // Supported subprotocols, in order of preference
+	MaxMessageSize      int64    `yaml:"max_message_size"`       // Largest frame or message a client may send; zero is unlimited
+	MaxConnectionsPerIP int      `yaml:"max_connections_per_ip"` // Zero is unlimited
+	ReadBufferSize      int      `yaml:"read_buffer_size"`       // Zero selects 4KB
+	WriteBufferSize     int      `yaml:"write_buffer_size"`      // Zero selects 4KB
+}
+
+// GRPCListener configures the gRPC server. Unary serves the unary RPCs and
+// Streaming serves StreamMessages; with both, one service serves them all.
+type GRPCListener struct {
+	Unary     bool   `yaml:"unary"`
+	Streaming bool   `yaml:"streaming"`
+	Addr      string `yaml:"addr"`
+}
+
+// Enabled reports whether the gRPC server runs at all.
+func (g GRPCListener) Enabled() bool {
+	return g.Unary || g.Streaming
+}
+
+// Metrics configures the HTTP server for Prometheus metrics, which also serves
+// the admin endpoints.
+type Metrics struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+	Path    string `yaml:"path"`
+}
+
+// TLS configures the certificate every listener serves. Both files must be set
+// to enable TLS; with neither, the listeners use plaintext. With ClientCAFile,
+// clients must present a certificate signed by one of its CAs (mutual TLS). The
+// files are reloaded when they change.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ClientCAFile   string        `yaml:"client_ca_file"`  // Empty accepts clients without certificates
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often the files are checked; zero only reloads on SIGHUP
+}
+
+// Enabled reports whether the listeners serve TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Auth configures client authentication. It is enabled by setting at least one
+// method: API keys, a file holding the secret HMAC-signed tokens are verified
+// with, or a JWKS file holding the public keys JWTs are verified with. Once
+// enabled, every client must authenticate, and may only do what Rules grant.
+type Auth struct {
+	APIKeys        []APIKey   `yaml:"api_keys"`
+	HMACSecretFile string     `yaml:"hmac_secret_file"`
+	JWKSFile       string     `yaml:"jwks_file"`
+	Issuer         string     `yaml:"issuer"`   // Required token "iss" claim; empty accepts any
+	Audience       string     `yaml:"audience"` // Required token "aud" claim; empty accepts any
+	Rules          []AuthRule `yaml:"rules"`
+}
+
+// APIKey is a static credential identifying a subject.
+type APIKey struct {
+	Subject string `yaml:"subject"`
+	Key     string `yaml:"key"`
+}
+
+// MarshalYAML hides the key, so the effective configuration can be logged.
+func (k APIKey) MarshalYAML() (any, error) {
+	return struct {
+		Subject string `yaml:"subject"`
+		Key     string `yaml:"key"`
+	}{k.Subject, "<redacted>"}, nil
+}
+
+// AuthRule grants a subject permissions on the queues, topics and logs whose
+// names match one of its patterns; the fields mirror auth.Rule.
+type AuthRule struct {
+	Subject     string   `yaml:"subject"`     // Subject, or "*" for every authenticated client
+	Resources   []string `yaml:"resources"`   // Name patterns, such as "orders", "audit.*" or "*"
+	Permissions []string `yaml:"permissions"` // produce, consume or admin
+}
+
+// Rule returns the access rule.
+func (r AuthRule) Rule() auth.Rule {
+	rule := auth.Rule{Subject: r.Subject, Resources: r.Resources}
+	for _, p := range r.Permissions {
+		rule.Permissions = append(rule.Permissions, auth.Permission(p))
+	}
+	return rule
+}
+
+// Enabled reports whether clients must authenticate.
+func (a Auth) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.HMACSecretFile != "" || a.JWKSFile != ""
+}
+
+// Authenticator reads the secret and key files and returns the authenticator the
+// listeners enforce.
+func (a Auth) Authenticator() (*auth.Authenticator, error) {
+	opts := auth.Options{APIKeys: make(map[string]string), Issuer: a.Issuer, Audience: a.Audience}
+	for _, k := range a.APIKeys {
+		opts.APIKeys[k.Key] = k.Subject
+	}
+	if a.HMACSecretFile != "" {
+		secret, err := os.ReadFile(a.HMACSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		// Secret files usually end in a newline that is not part of the secret
+		opts.HMACSecret = bytes.TrimSpace(secret)
+		if len(opts.HMACSecret) == 0 {
+			return nil, errors.New(a.HMACSecretFile + ": HMAC secret is empty")
+		}
+	}
+	if a.JWKSFile != "" {
+		keys, err := auth.LoadKeySet(a.JWKSFile)
+		if err != nil {
+			return nil, errors.New(a.JWKSFile + ": " + err.Error())
+		}
+		opts.KeySet = keys
+	}
+	for _, r := range a.Rules {
+		opts.Rules = append(opts.Rules, r.Rule())
+	}
+	return auth.New(opts)
+}
+
+// RateLimit limits the message and byte rates of producers sharing a key value;
+// the fields mirror ratelimit.Rule.
+type RateLimit struct {
+	Key               string  `yaml:"key"`                           // client, subject or queue
+	Match             string  `yaml:"match,omitempty"`               // Pattern the key value must match; empty matches all
+	MessagesPerSecond float64 `yaml:"messages_per_second,omitempty"` // Zero is unlimited
+	BytesPerSecond    float64 `yaml:"bytes_per_second,omitempty"`    // Zero is unlimited
+	MessageBurst      float64 `yaml:"message_burst,omitempty"`       // Zero selects one second's worth
+	ByteBurst         float64 `yaml:"byte_burst,omitempty"`          // Zero selects one second's worth
+}
+
+// Rule returns the rate limit rule.
+func (r RateLimit) Rule() ratelimit.Rule {
+	return ratelimit.Rule{
+		Key:               ratelimit.Key(r.Key),
+		Match:             r.Match,
+		MessagesPerSecond: r.MessagesPerSecond,
+		BytesPerSecond:    r.BytesPerSecond,
+		MessageBurst:      r.MessageBurst,
+		ByteBurst:         r.ByteBurst,
+	}
+}
+
+// Limits tunes the gRPC server.
+type Limits struct {
+	MaxConcurrentStreams  int `yaml:"max_concurrent_streams"`
+	MaxReceiveMessageSize int `yaml:"max_receive_message_size"`
+	WriteBufferSize       int `yaml:"write_buffer_size"` // Zero keeps gRPC's default
+	ReadBufferSize        int `yaml:"read_buffer_size"`  // Zero keeps gRPC's default
+}
+
+// Queue defines a named queue created at startup; the fields mirror mq.QueueConfig.
+type Queue struct {
+	Name                string        `yaml:"name"`
+	Capacity            uint64        `yaml:"capacity"`
+	MaxBytes            uint64        `yaml:"max_bytes,omitempty"`
+	VisibilityTimeout   time.Duration `yaml:"visibility_timeout,omitempty"`
+	MaxAge              time.Duration `yaml:"max_age,omitempty"`
+	Overflow            string        `yaml:"overflow,omitempty"`
+	BlockTimeout        time.Duration `yaml:"block_timeout,omitempty"`
+	PriorityLevels      int           `yaml:"priority_levels,omitempty"`
+	PriorityMode        string        `yaml:"priority_mode,omitempty"`
+	Partitions          int           `yaml:"partitions,omitempty"`
+	Durable             bool          `yaml:"durable,omitempty"`
+	MaxDeliveryAttempts int           `yaml:"max_delivery_attempts,omitempty"`
+	DeadLetterQueue     string        `yaml:"dead_letter_queue,omitempty"`
+}
+
+// QueueConfig returns the registry configuration of the queue.
+func (q Queue) QueueConfig() mq.QueueConfig {
+	return mq.QueueConfig{
+		Capacity:            q.Capacity,
+		MaxBytes:            q.MaxBytes,
+		VisibilityTimeout:   q.VisibilityTimeout,
+		MaxAge:              q.MaxAge,
+		Overflow:            mq.OverflowPolicy(q.Overflow),
+		BlockTimeout:        q.BlockTimeout,
+		PriorityLevels:      q.PriorityLevels,
+		PriorityMode:        mq.PriorityMode(q.PriorityMode),
+		Partitions:          q.Partitions,
+		Durable:             q.Durable,
+		MaxDeliveryAttempts: q.MaxDeliveryAttempts,
+		DeadLetterQueue:     q.DeadLetterQueue,
+	}
+}
+
+// Memory configures the memory budget shared by all queues; there is none unless
+// BudgetBytes is set.
+type Memory struct {
+	BudgetBytes int64  `yaml:"budget_bytes"` // Payload bytes all queues may hold together
+	Policy      string `yaml:"policy"`       // reject or evict; empty selects reject
+}
+
+// Enabled reports whether the queues share a memory budget.
+func (m Memory) Enabled() bool {
+	return m.BudgetBytes > 0
+}
+
+// Budget returns the memory budget, reporting its use to observer (which may be nil).
+func (m Memory) Budget(observer mq.BudgetObserver) (*mq.MemoryBudget, error) {
+	return mq.NewMemoryBudget(m.BudgetBytes, mq.BudgetPolicy(m.Policy), observer)
+}
+
+// Durability configures durable queues; they are disabled unless DataDir is set.
+type Durability struct {
+	DataDir         string        `yaml:"data_dir"`
+	WALFsync        string        `yaml:"wal_fsync"`         // Empty selects mq.FsyncInterval
+	WALSyncInterval time.Duration `yaml:"wal_sync_interval"` // Zero selects mq.DefaultSyncInterval
+	WALSegmentBytes int64         `yaml:"wal_segment_bytes"` // Zero selects mq.DefaultWALSegmentSize
+}
+
+// Enabled reports whether durable queues are enabled.
+func (d Durability) Enabled() bool {
+	return d.DataDir != ""
+}
+
+// Options returns the registry's durability settings.
+func (d Durability) Options() mq.Durability {
+	return mq.Durability{
+		Dir: d.DataDir,
+		WALOptions: mq.WALOptions{
+			Fsync:        mq.FsyncPolicy(d.WALFsync),
+			SyncInterval: d.WALSyncInterval,
+			SegmentSize:  d.WALSegmentBytes,
+		},
+	}
+}
+
+// Snapshot configures snapshots; they are disabled unless File is set.
+type Snapshot struct {
+	File    string `yaml:"file"`
+	Restore bool   `yaml:"restore"` // Restore File at startup, before the listeners open
+}
+
+// Shutdown configures the graceful shutdown on SIGINT and SIGTERM.
+type Shutdown struct {
+	DrainTimeout time.Duration `yaml:"drain_timeout"` // How long consumers may empty the queues
+	GracePeriod  time.Duration `yaml:"grace_period"`  // How long listeners may take to close client connections
+}
+
+// Log configures the server log.
+type Log struct {
+	Level string `yaml:"level"` // debug, info, warn or error
+}
+
+// SlogLevel returns the level as an slog.Level, or slog.LevelInfo if it is not valid.
+func (l Log) SlogLevel() slog.Level {
+	level, _ := parseLevel(l.Level)
+	return level
+}
+
+// parseLevel parses a log level name, ignoring case.
+func parseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, errors.New("must be debug, info, warn or error")
+}
+
+// Default returns the built-in configuration: every listener disabled, metrics on
+// DefaultMetricsAddr and a default queue of DefaultQueueCapacity.
+func Default() *Config {
+	return &Config{
+		Listeners: Listeners{
+			WebSocket: WebSocketListener{Addr: DefaultWebSocketAddr, MaxMessageSize: DefaultWsMaxMessageSize},
+			GRPC:      GRPCListener{Addr: DefaultGRPCAddr},
+		},
+		Metrics: Metrics{Enabled: true, Addr: DefaultMetricsAddr, Path: DefaultMetricsPath},
+		TLS:     TLS{ReloadInterval: certs.DefaultCheckInterval},
+		Limits: Limits{
+			MaxConcurrentStreams:  DefaultMaxConcurrentStreams,
+			MaxReceiveMessageSize: DefaultMaxReceiveMessageSize,
+			WriteBufferSize:       DefaultWriteBufferSize,
+			ReadBufferSize:        DefaultReadBufferSize,
+		},
+		Queues:   []Queue{{Name: mq.DefaultQueueName, Capacity: DefaultQueueCapacity}},
+		Shutdown: Shutdown{DrainTimeout: DefaultDrainTimeout, GracePeriod: DefaultGracePeriod},
+		Log:      Log{Level: DefaultLogLevel},
+	}
+}
+
+// Error describes one invalid configuration value.
+type Error struct {
+	Setting string // Where the value is set: a dotted config path, an environment variable or a flag
+	Reason  string // What is wrong with it
+}
+
+func (e *Error) Error() string {
+	return e.Setting + ": " + e.Reason
+}
+
+// Validate checks every value and returns all problems joined into one error,
+// each an *Error, or nil if the configuration is usable.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(setting, reason string) {
+		errs = append(errs, &Error{Setting: setting, Reason: reason})
+	}
+
+	if !c.Listeners.WebSocket.Enabled && !c.Listeners.GRPC.Enabled() {
+		invalid("listeners", "enable at least one of websocket.enabled, grpc.unary and grpc.streaming")
+	}
+	if c.Listeners.WebSocket.Enabled && c.Listeners.WebSocket.Addr == "" {
+		invalid("listeners.websocket.addr", "must be set")
+	}
+	for i, origin := range c.Listeners.WebSocket.AllowedOrigins {
+		if _, err := path.Match(origin, ""); err != nil || (origin != "*" && !strings.Contains(origin, "://")) {
+			invalid("listeners.websocket.allowed_origins["+strconv.Itoa(i)+"]", "must be \"*\" or an origin such as https://app.example.com or https://*.example.com")
+		}
+	}
+	for i, protocol := range c.Listeners.WebSocket.Subprotocols {
+		if protocol == "" || strings.ContainsAny(protocol, " ,") {
+			invalid("listeners.websocket.subprotocols["+strconv.Itoa(i)+"]", "must be a token without spaces or commas")
+		}
+	}
+	for _, n := range []struct {
+		setting string
+		value   int64
+	}{
+		{"listeners.websocket.max_message_size", c.Listeners.WebSocket.MaxMessageSize},
+		{"listeners.websocket.max_connections_per_ip", int64(c.Listeners.WebSocket.MaxConnectionsPerIP)},
+		{"listeners.websocket.read_buffer_size", int64(c.Listeners.WebSocket.ReadBufferSize)},
+		{"listeners.websocket.write_buffer_size", int64(c.Listeners.WebSocket.WriteBufferSize)},
+	} {
+		if n.value < 0 {
+			invalid(n.setting, "must not be negative")
+		}
+	}
+	if c.Listeners.GRPC.Enabled() && c.Listeners.GRPC.Addr == "" {
+		invalid("listeners.grpc.addr", "must be set")
+	}
+	if c.Metrics.Enabled {
+		if c.Metrics.Addr == "" {
+			invalid("metrics.addr", "must be set")
+		}
+		if !strings.HasPrefix(c.Metrics.Path, "/") {
+			invalid("metrics.path", "must start with /")
+		}
+	}
+	used := make(map[string]string)
+	for _, l := range []struct {
+		setting, addr string
+		enabled       bool
+	}{
+		{"listeners.websocket.addr", c.Listeners.WebSocket.Addr, c.Listeners.WebSocket.Enabled},
+		{"listeners.grpc.addr", c.Listeners.GRPC.Addr, c.Listeners.GRPC.Enabled()},
+		{"metrics.addr", c.Metrics.Addr, c.Metrics.Enabled},
+	} {
+		if !l.enabled || l.addr == "" {
+			continue
+		}
+		if other, ok := used[l.addr]; ok {
+			invalid(l.setting, "address "+l.addr+" is already used by "+other)
+		}
+		used[l.addr] = l.setting
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		invalid("tls", "set both cert_file and key_file, or neither")
+	}
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		invalid("tls.client_ca_file", "needs cert_file and key_file")
+	}
+	if c.TLS.ReloadInterval < 0 {
+		invalid("tls.reload_interval", "must not be negative")
+	}
+
+	keys := make(map[string]bool)
+	for i, k := range c.Auth.APIKeys {
+		setting := "auth.api_keys[" + strconv.Itoa(i) + "]"
+		if k.Subject == "" || k.Key == "" {
+			invalid(setting, "needs a subject and a key")
+		}
+		if keys[k.Key] {
+			invalid(setting, "key is used more than once")
+		}
+		keys[k.Key] = true
+	}
+	for i, r := range c.Auth.Rules {
+		if err := r.Rule().Validate(); err != nil {
+			invalid("auth.rules["+strconv.Itoa(i)+"]", err.Error())
+		}
+	}
+	if !c.Auth.Enabled() && (len(c.Auth.Rules) > 0 || c.Auth.Issuer != "" || c.Auth.Audience != "") {
+		invalid("auth", "set api_keys, hmac_secret_file or jwks_file to enable authentication")
+	}
+	if c.Auth.HMACSecretFile == "" && c.Auth.JWKSFile == "" && (c.Auth.Issuer != "" || c.Auth.Audience != "") {
+		invalid("auth", "issuer and audience only apply to tokens: set hmac_secret_file or jwks_file")
+	}
+
+	for i, r := range c.RateLimits {
+		if err := r.Rule().Validate(); err != nil {
+			invalid("rate_limits["+strconv.Itoa(i)+"]", err.Error())
+		}
+	}
+
+	if c.Limits.MaxConcurrentStreams <= 0 {
+		invalid("limits.max_concurrent_streams", "must be greater than zero")
+	}
+	if c.Limits.MaxReceiveMessageSize <= 0 {
+		invalid("limits.max_receive_message_size", "must be greater than zero")
+	}
+	if c.Limits.WriteBufferSize < 0 {
+		invalid("limits.write_buffer_size", "must not be negative")
+	}
+	if c.Limits.ReadBufferSize < 0 {
+		invalid("limits.read_buffer_size", "must not be negative")
+	}
+
+	names := make(map[string]bool)
+	for i, q := range c.Queues {
+		setting := "queues[" + strconv.Itoa(i) + "]"
+		if q.Name != "" {
+			setting = "queues." + q.Name
+		}
+		if names[q.Name] {
+			invalid(setting, "queue is defined more than once")
+		}
+		names[q.Name] = true
+		if err := q.QueueConfig().Validate(q.Name); err != nil {
+			invalid(setting, err.Error())
+		}
+		if q.Durable && !c.Durability.Enabled() {
+			invalid(setting, "durable queues need durability.data_dir")
+		}
+	}
+
+	if c.Memory.BudgetBytes < 0 {
+		invalid("memory.budget_bytes", "must not be negative")
+	}
+	if _, err := mq.ParseBudgetPolicy(c.Memory.Policy); err != nil {
+		invalid("memory.policy", err.Error())
+	}
+
+	if _, err := mq.ParseFsyncPolicy(c.Durability.WALFsync); err != nil {
+		invalid("durability.wal_fsync", err.Error())
+	}
+	if c.Durability.WALSyncInterval < 0 {
+		invalid("durability.wal_sync_interval", "must not be negative")
+	}
+	if c.Durability.WALSegmentBytes < 0 {
+		invalid("durability.wal_segment_bytes", "must not be negative")
+	}
+	if c.Snapshot.Restore && c.Snapshot.File == "" {
+		invalid("snapshot.restore", "needs snapshot.file")
+	}
+	if c.Shutdown.DrainTimeout < 0 {
+		invalid("shutdown.drain_timeout", "must not be negative")
+	}
+	if c.Shutdown.GracePeriod <= 0 {
+		invalid("shutdown.grace_period", "must be greater than zero")
+	}
+	if _, err := parseLevel(c.Log.Level); err != nil {
+		invalid("log.level", err.Error())
+	}
+	return errors.Join(errs...)
+}
+
+// String renders the configuration as YAML.
+func (c *Config) String() string {
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
@@ -0,0 +1,299 @@
+// config_test.go - Tests for loading and validating the configuration.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quickpulse/auth"
+	"quickpulse/mq"
+	"quickpulse/ratelimit"
+)
+
+// env returns a getenv function backed by vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+// writeFile writes a config file into a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(nil, env(map[string]string{"RPC_MODE": "1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Listeners.GRPC.Unary || cfg.Listeners.GRPC.Addr != DefaultGRPCAddr || cfg.Listeners.WebSocket.Enabled {
+		t.Fatalf("listeners = %+v", cfg.Listeners)
+	}
+	if len(cfg.Queues) != 1 || cfg.Queues[0].Name != mq.DefaultQueueName || cfg.Queues[0].Capacity != DefaultQueueCapacity {
+		t.Fatalf("queues = %+v", cfg.Queues)
+	}
+	if cfg.Limits.MaxReceiveMessageSize != DefaultMaxReceiveMessageSize || cfg.Metrics.Addr != DefaultMetricsAddr {
+		t.Fatalf("config = %+v", cfg)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	path := writeFile(t, "quickpulse.yaml", `
+listeners:
+  websocket:
+    enabled: true
+    addr: ":9001"
+  grpc:
+    unary: true
+    addr: ":9002"
+limits:
+  max_receive_message_size: 4096
+queues:
+  - name: orders
+    capacity: 100
+    visibility_timeout: 45s
+    overflow: block
+shutdown:
+  drain_timeout: 10s
+`)
+	vars := map[string]string{
+		ConfigFileEnv:            path,
+		"GRPC_ADDR":              ":9003",
+		"GRPC_MAX_RECV_MSG_SIZE": "8192",
+		"DRAIN_TIMEOUT_MS":       "2000",
+	}
+	cfg, err := Load([]string{"-grpc-addr", ":9004", "-default-queue-capacity", "50", "-metrics=false"}, env(vars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Listeners.WebSocket.Addr != ":9001" || !cfg.Listeners.WebSocket.Enabled {
+		t.Fatalf("websocket = %+v, want the file's settings", cfg.Listeners.WebSocket)
+	}
+	if cfg.Listeners.GRPC.Addr != ":9004" {
+		t.Fatalf("grpc addr = %q, want the flag to win", cfg.Listeners.GRPC.Addr)
+	}
+	if cfg.Limits.MaxReceiveMessageSize != 8192 || cfg.Shutdown.DrainTimeout != 2*time.Second {
+		t.Fatalf("limits = %+v, shutdown = %+v, want the environment to win", cfg.Limits, cfg.Shutdown)
+	}
+	// The file's queue list replaces the defaults, but the default queue is kept
+	if len(cfg.Queues) != 2 || cfg.Queues[0].Name != "orders" || cfg.Queues[1].Name != mq.DefaultQueueName {
+		t.Fatalf("queues = %+v", cfg.Queues)
+	}
+	orders := cfg.Queues[0].QueueConfig()
+	if orders.Capacity != 100 || orders.VisibilityTimeout != 45*time.Second || orders.Overflow != mq.OverflowBlock {
+		t.Fatalf("orders = %+v", orders)
+	}
+	if cfg.Metrics.Enabled {
+		t.Fatal("metrics enabled despite -metrics=false")
+	}
+	if cfg.Queues[1].Capacity != 50 {
+		t.Fatalf("default capacity = %d, want 50", cfg.Queues[1].Capacity)
+	}
+	if !strings.Contains(cfg.String(), "visibility_timeout: 45s") {
+		t.Fatalf("String() =\n%s", cfg)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := writeFile(t, "quickpulse.json", `{"listeners": {"grpc": {"streaming": true}}, "metrics": {"enabled": false}}`)
+	cfg, err := Load([]string{"-config", path, "-ws"}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Listeners.GRPC.Streaming || !cfg.Listeners.WebSocket.Enabled || cfg.Metrics.Enabled {
+		t.Fatalf("config = %+v", cfg)
+	}
+}
+
+func TestWebSocketPolicy(t *testing.T) {
+	vars := map[string]string{"WS_MODE": "1", "WS_ALLOWED_ORIGINS": "https://app.example.com, https://*.example.com", "WS_MAX_CONNECTIONS_PER_IP": "8"}
+	cfg, err := Load([]string{"-ws-subprotocols", "quickpulse.v1,quickpulse.json"}, env(vars))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := cfg.Listeners.WebSocket
+	if len(ws.AllowedOrigins) != 2 || ws.AllowedOrigins[1] != "https://*.example.com" || len(ws.Subprotocols) != 2 {
+		t.Fatalf("websocket = %+v", ws)
+	}
+	if ws.MaxConnectionsPerIP != 8 || ws.MaxMessageSize != DefaultWsMaxMessageSize {
+		t.Fatalf("websocket limits = %+v", ws)
+	}
+
+	cfg.Listeners.WebSocket.AllowedOrigins = []string{"example.com", "https://[.example.com"}
+	cfg.Listeners.WebSocket.Subprotocols = []string{"a b"}
+	cfg.Listeners.WebSocket.MaxMessageSize = -1
+	err = cfg.Validate()
+	for _, setting := range []string{"listeners.websocket.allowed_origins[0]", "listeners.websocket.allowed_origins[1]", "listeners.websocket.subprotocols[0]", "listeners.websocket.max_message_size"} {
+		if err == nil || !strings.Contains(err.Error(), setting+":") {
+			t.Errorf("Validate() does not report %s:\n%v", setting, err)
+		}
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	path := writeFile(t, "quickpulse.yaml", `
+listeners:
+  grpc:
+    unary: true
+rate_limits:
+  - { key: client, messages_per_second: 100 }
+  - { key: queue, match: "audit.*", bytes_per_second: 1048576, byte_burst: 4194304 }
+`)
+	cfg, err := Load([]string{"-config", path}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.RateLimits) != 2 {
+		t.Fatalf("rate_limits = %+v", cfg.RateLimits)
+	}
+	if rule := cfg.RateLimits[1].Rule(); rule.Key != ratelimit.KeyQueue || rule.Match != "audit.*" || rule.ByteBurst != 4194304 {
+		t.Fatalf("Rule() = %+v", rule)
+	}
+
+	cfg.RateLimits = append(cfg.RateLimits, RateLimit{Key: "tenant", MessagesPerSecond: 1}, RateLimit{Key: "queue"})
+	err = cfg.Validate()
+	for _, setting := range []string{"rate_limits[2]", "rate_limits[3]"} {
+		if err == nil || !strings.Contains(err.Error(), setting+":") {
+			t.Errorf("Validate() does not report %s:\n%v", setting, err)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	path := writeFile(t, "quickpulse.yaml", `
+listeners:
+  grpc:
+    unary: true
+queues:
+  - { name: orders, capacity: 1000, max_bytes: 65536 }
+memory: { budget_bytes: 1048576, policy: evict }
+`)
+	cfg, err := Load([]string{"-config", path}, env(map[string]string{"DEFAULT_QUEUE_MAX_BYTES": "4096", "MEMORY_BUDGET_POLICY": "reject"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := cfg.Queues[0].QueueConfig(); cfg.Queues[0].Name != "orders" || q.MaxBytes != 65536 {
+		t.Fatalf("queues[0] = %+v", cfg.Queues[0])
+	}
+	if q := cfg.Queues[1]; q.Name != "default" || q.Capacity != DefaultQueueCapacity || q.MaxBytes != 4096 {
+		t.Fatalf("default queue = %+v", q)
+	}
+	budget, err := cfg.Memory.Budget(nil)
+	if err != nil || budget.Limit() != 1048576 || budget.Policy() != mq.BudgetReject {
+		t.Fatalf("Budget() = %+v, %v", budget, err)
+	}
+
+	cfg.Memory = Memory{BudgetBytes: -1, Policy: "spill"}
+	err = cfg.Validate()
+	for _, setting := range []string{"memory.budget_bytes", "memory.policy"} {
+		if err == nil || !strings.Contains(err.Error(), setting+":") {
+			t.Errorf("Validate() does not report %s:\n%v", setting, err)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	path := writeFile(t, "typo.yaml", "listeners:\n  grpc:\n    unray: true\n")
+	if _, err := Load([]string{"-config", path}, env(nil)); err == nil || !strings.Contains(err.Error(), "unray") {
+		t.Fatalf("Load with an unknown key = %v", err)
+	}
+	if _, err := Load(nil, env(map[string]string{"WS_MODE": "yes please"})); err == nil || !strings.Contains(err.Error(), "WS_MODE") {
+		t.Fatalf("Load with a bad boolean = %v", err)
+	}
+	if _, err := Load([]string{"-grpc-max-recv-msg-size", "big"}, env(map[string]string{"RPC_MODE": "1"})); err == nil || !strings.Contains(err.Error(), "-grpc-max-recv-msg-size") {
+		t.Fatalf("Load with a bad flag value = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Listeners.GRPC.Unary = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	cfg.Listeners.WebSocket.Enabled = true
+	cfg.Listeners.WebSocket.Addr = DefaultGRPCAddr
+	cfg.Limits.MaxReceiveMessageSize = 0
+	cfg.TLS.CertFile = "server.pem"
+	cfg.Queues = append(cfg.Queues, Queue{Name: "orders", Capacity: 10, Overflow: "sideways"}, Queue{Name: "jobs", Capacity: 10, Durable: true})
+	cfg.Snapshot.Restore = true
+	cfg.Log.Level = "verbose"
+	err := cfg.Validate()
+	want := []string{"listeners.grpc.addr", "limits.max_receive_message_size", "tls", "queues.orders", "queues.jobs", "snapshot.restore", "log.level"}
+	for _, setting := range want {
+		if !strings.Contains(err.Error(), setting+":") {
+			t.Errorf("Validate() does not report %s:\n%v", setting, err)
+		}
+	}
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Validate() = %T, want *Error", err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	secret := writeFile(t, "hmac.key", "s3cret\n")
+	path := writeFile(t, "quickpulse.yaml", `
+listeners:
+  grpc:
+    unary: true
+auth:
+  api_keys:
+    - subject: ingest
+      key: k-ingest
+  rules:
+    - subject: ingest
+      resources: ["orders", "audit.*"]
+      permissions: [produce]
+`)
+	cfg, err := Load([]string{"-config", path, "-auth-hmac-secret-file", secret, "-auth-issuer", "ci"}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Auth.Enabled() || cfg.Auth.Issuer != "ci" {
+		t.Fatalf("auth = %+v", cfg.Auth)
+	}
+	if out := cfg.String(); strings.Contains(out, "k-ingest") || !strings.Contains(out, "<redacted>") {
+		t.Fatalf("String() shows the API key:\n%s", out)
+	}
+	a, err := cfg.Auth.Authenticator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := a.Authenticate("k-ingest")
+	if err != nil || !a.Allowed(p, auth.Produce, "audit.eu") || a.Allowed(p, auth.Consume, "orders") {
+		t.Fatalf("API key principal = %+v, %v", p, err)
+	}
+	token, _ := auth.NewHMACToken([]byte("s3cret"), auth.Claims{Subject: "svc", Issuer: "ci"})
+	if p, err := a.Authenticate(token); err != nil || p.Subject != "svc" {
+		t.Fatalf("Authenticate(token) = %+v, %v; want the trailing newline ignored", p, err)
+	}
+
+	cfg = Default()
+	cfg.Listeners.GRPC.Unary = true
+	cfg.Auth = Auth{
+		APIKeys:  []APIKey{{Subject: "a", Key: "same"}, {Subject: "b", Key: "same"}},
+		Audience: "quickpulse",
+		Rules:    []AuthRule{{Subject: "a", Resources: []string{"["}, Permissions: []string{"produce"}}, {Subject: "b", Resources: []string{"*"}, Permissions: []string{"write"}}},
+	}
+	err = cfg.Validate()
+	for _, setting := range []string{"auth.api_keys[1]", "auth.rules[0]", "auth.rules[1]", "auth"} {
+		if err == nil || !strings.Contains(err.Error(), setting+":") {
+			t.Errorf("Validate() does not report %s:\n%v", setting, err)
+		}
+	}
+	cfg.Auth = Auth{Rules: []AuthRule{{Subject: "a", Resources: []string{"*"}, Permissions: []string{"admin"}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() accepted rules without an authentication method")
+	}
+}
@@ -0,0 +1,246 @@
+// load.go - Loading the configuration from a file, the environment and flags.
+//
+// This file builds the effective configuration in layers: the built-in defaults,
+// then a YAML or JSON config file (JSON is valid YAML, so one strict decoder reads
+// both), then environment variables, then command-line flags. Every setting that
+// can be overridden is listed once in settings, with its environment variable and
+// flag, so the two always agree. The environment variables keep the names the
+// server has always used (WS_MODE, RPC_MODE, DATA_DIR and so on).
+
+package config
+
+import (
+	"bytes"   // For decoding the config file
+	"errors"  // For joining load errors
+	"flag"    // For command-line flags
+	"io"      // For recognizing an empty config file
+	"os"      // For reading the config file
+	"strconv" // For parsing environment variables and flags
+	"strings" // For parsing lists
+	"time"    // For millisecond settings
+
+	"quickpulse/mq" // For the default queue name
+
+	"gopkg.in/yaml.v3" // For decoding the config file
+)
+
+// ConfigFileEnv names the environment variable holding the config file path,
+// used when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// setting binds one configuration value to an environment variable and a flag.
+type setting struct {
+	env   string                          // Environment variable name
+	flag  string                          // Flag name, without the dash
+	usage string                          // Flag help text
+	bool  bool                            // Whether the flag can be given without a value
+	set   func(c *Config, v string) error // Parses v into c; returns a reason on failure
+}
+
+// settings lists every value that can be set from the environment or a flag.
+var settings = []setting{
+	boolSetting("WS_MODE", "ws", "enable the WebSocket listener", func(c *Config) *bool { return &c.Listeners.WebSocket.Enabled }),
+	stringSetting("WS_ADDR", "ws-addr", "WebSocket listen address", func(c *Config) *string { return &c.Listeners.WebSocket.Addr }),
+	listSetting("WS_ALLOWED_ORIGINS", "ws-allowed-origins", "comma-separated origins browsers may open WebSockets from (* allows any)", func(c *Config) *[]string { return &c.Listeners.WebSocket.AllowedOrigins }),
+	listSetting("WS_SUBPROTOCOLS", "ws-subprotocols", "comma-separated WebSocket subprotocols, in order of preference", func(c *Config) *[]string { return &c.Listeners.WebSocket.Subprotocols }),
+	int64Setting("WS_MAX_MESSAGE_SIZE", "ws-max-message-size", "largest WebSocket message a client may send, in bytes (0 is unlimited)", func(c *Config) *int64 { return &c.Listeners.WebSocket.MaxMessageSize }),
+	intSetting("WS_MAX_CONNECTIONS_PER_IP", "ws-max-connections-per-ip", "open WebSocket connections per client IP (0 is unlimited)", func(c *Config) *int { return &c.Listeners.WebSocket.MaxConnectionsPerIP }),
+	intSetting("WS_READ_BUFFER_SIZE", "ws-read-buffer-size", "WebSocket read buffer size in bytes", func(c *Config) *int { return &c.Listeners.WebSocket.ReadBufferSize }),
+	intSetting("WS_WRITE_BUFFER_SIZE", "ws-write-buffer-size", "WebSocket write buffer size in bytes", func(c *Config) *int { return &c.Listeners.WebSocket.WriteBufferSize }),
+	boolSetting("RPC_MODE", "grpc-unary", "serve the unary gRPC RPCs", func(c *Config) *bool { return &c.Listeners.GRPC.Unary }),
+	boolSetting("RPC_STREAM_MODE", "grpc-stream", "serve the StreamMessages gRPC RPC", func(c *Config) *bool { return &c.Listeners.GRPC.Streaming }),
+	stringSetting("GRPC_ADDR", "grpc-addr", "gRPC listen address", func(c *Config) *string { return &c.Listeners.GRPC.Addr }),
+	boolSetting("METRICS_ENABLED", "metrics", "serve Prometheus metrics and the admin endpoints", func(c *Config) *bool { return &c.Metrics.Enabled }),
+	stringSetting("METRICS_ADDR", "metrics-addr", "metrics listen address", func(c *Config) *string { return &c.Metrics.Addr }),
+	stringSetting("METRICS_PATH", "metrics-path", "path of the Prometheus metrics", func(c *Config) *string { return &c.Metrics.Path }),
+	stringSetting("TLS_CERT_FILE", "tls-cert", "TLS certificate file (PEM)", func(c *Config) *string { return &c.TLS.CertFile }),
+	stringSetting("TLS_KEY_FILE", "tls-key", "TLS private key file (PEM)", func(c *Config) *string { return &c.TLS.KeyFile }),
+	stringSetting("TLS_CLIENT_CA_FILE", "tls-client-ca", "CA certificates client certificates must be signed by (PEM; enables mutual TLS)", func(c *Config) *string { return &c.TLS.ClientCAFile }),
+	millisSetting("TLS_RELOAD_INTERVAL_MS", "tls-reload-interval-ms", "how often the TLS files are checked for changes, in milliseconds (0 disables)", func(c *Config) *time.Duration { return &c.TLS.ReloadInterval }),
+	stringSetting("AUTH_HMAC_SECRET_FILE", "auth-hmac-secret-file", "file holding the secret HMAC-signed tokens are verified with (enables authentication)", func(c *Config) *string { return &c.Auth.HMACSecretFile }),
+	stringSetting("AUTH_JWKS_FILE", "auth-jwks-file", "JWKS file holding the public keys JWTs are verified with (enables authentication)", func(c *Config) *string { return &c.Auth.JWKSFile }),
+	stringSetting("AUTH_ISSUER", "auth-issuer", "required \"iss\" claim of tokens", func(c *Config) *string { return &c.Auth.Issuer }),
+	stringSetting("AUTH_AUDIENCE", "auth-audience", "required \"aud\" claim of tokens", func(c *Config) *string { return &c.Auth.Audience }),
+	intSetting("GRPC_MAX_CONCURRENT_STREAMS", "grpc-max-concurrent-streams", "maximum concurrent gRPC streams", func(c *Config) *int { return &c.Limits.MaxConcurrentStreams }),
+	intSetting("GRPC_MAX_RECV_MSG_SIZE", "grpc-max-recv-msg-size", "maximum size of a received gRPC message in bytes", func(c *Config) *int { return &c.Limits.MaxReceiveMessageSize }),
+	intSetting("GRPC_WRITE_BUFFER_SIZE", "grpc-write-buffer-size", "gRPC write buffer size in bytes", func(c *Config) *int { return &c.Limits.WriteBufferSize }),
+	intSetting("GRPC_READ_BUFFER_SIZE", "grpc-read-buffer-size", "gRPC read buffer size in bytes", func(c *Config) *int { return &c.Limits.ReadBufferSize }),
+	defaultQueueSetting("DEFAULT_QUEUE_CAPACITY", "default-queue-capacity", "capacity of the default queue", func(q *Queue) *uint64 { return &q.Capacity }),
+	defaultQueueSetting("DEFAULT_QUEUE_MAX_BYTES", "default-queue-max-bytes", "payload bytes the default queue can hold (0 is unlimited)", func(q *Queue) *uint64 { return &q.MaxBytes }),
+	int64Setting("MEMORY_BUDGET_BYTES", "memory-budget-bytes", "payload bytes all queues may hold together (0 is unlimited)", func(c *Config) *int64 { return &c.Memory.BudgetBytes }),
+	stringSetting("MEMORY_BUDGET_POLICY", "memory-budget-policy", "what a produce beyond the memory budget does: reject or evict", func(c *Config) *string { return &c.Memory.Policy }),
+	stringSetting("DATA_DIR", "data-dir", "directory of the durable queue logs (enables durable queues)", func(c *Config) *string { return &c.Durability.DataDir }),
+	stringSetting("WAL_FSYNC", "wal-fsync", "when log writes are synced: always, interval or never", func(c *Config) *string { return &c.Durability.WALFsync }),
+	millisSetting("WAL_SYNC_INTERVAL_MS", "wal-sync-interval-ms", "sync period of the interval fsync policy in milliseconds", func(c *Config) *time.Duration { return &c.Durability.WALSyncInterval }),
+	int64Setting("WAL_SEGMENT_BYTES", "wal-segment-bytes", "size at which a log segment is rolled over", func(c *Config) *int64 { return &c.Durability.WALSegmentBytes }),
+	stringSetting("SNAPSHOT_FILE", "snapshot-file", "file snapshots are written to and restored from", func(c *Config) *string { return &c.Snapshot.File }),
+	boolSetting("RESTORE_SNAPSHOT", "restore-snapshot", "restore the snapshot file at startup", func(c *Config) *bool { return &c.Snapshot.Restore }),
+	millisSetting("DRAIN_TIMEOUT_MS", "drain-timeout-ms", "how long consumers may drain the queues on shutdown, in milliseconds", func(c *Config) *time.Duration { return &c.Shutdown.DrainTimeout }),
+	millisSetting("GRACE_PERIOD_MS", "grace-period-ms", "how long listeners may take to close connections on shutdown, in milliseconds", func(c *Config) *time.Duration { return &c.Shutdown.GracePeriod }),
+	stringSetting("LOG_LEVEL", "log-level", "log level: debug, info, warn or error", func(c *Config) *string { return &c.Log.Level }),
+}
+
+func boolSetting(env, flag, usage string, field func(*Config) *bool) setting {
+	return setting{env: env, flag: flag, usage: usage, bool: true, set: func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("not a boolean: " + strconv.Quote(v))
+		}
+		*field(c) = b
+		return nil
+	}}
+}
+
+func stringSetting(env, flag, usage string, field func(*Config) *string) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}}
+}
+
+// listSetting parses a comma-separated list; an empty value clears the list.
+func listSetting(env, flag, usage string, field func(*Config) *[]string) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(c) = list
+		return nil
+	}}
+}
+
+func intSetting(env, flag, usage string, field func(*Config) *int) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("not an integer: " + strconv.Quote(v))
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+func int64Setting(env, flag, usage string, field func(*Config) *int64) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.New("not an integer: " + strconv.Quote(v))
+		}
+		*field(c) = n
+		return nil
+	}}
+}
+
+// defaultQueueSetting parses a non-negative integer setting of the default queue.
+func defaultQueueSetting(env, flag, usage string, field func(*Queue) *uint64) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return errors.New("not a non-negative integer: " + strconv.Quote(v))
+		}
+		*field(c.defaultQueue()) = n
+		return nil
+	}}
+}
+
+func millisSetting(env, flag, usage string, field func(*Config) *time.Duration) setting {
+	return setting{env: env, flag: flag, usage: usage, set: func(c *Config, v string) error {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.New("not a number of milliseconds: " + strconv.Quote(v))
+		}
+		*field(c) = time.Duration(ms) * time.Millisecond
+		return nil
+	}}
+}
+
+// Load builds the effective configuration for a server started with the
+// command-line arguments args (without the program name), reading environment
+// variables through getenv. The config file is named by the -config flag or the
+// CONFIG_FILE environment variable; without one the defaults are used. Later
+// layers override earlier ones: file, then environment, then flags. The result
+// is validated, and every problem found is reported in the returned error.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	fs := flag.NewFlagSet("quickpulse", flag.ContinueOnError)
+	path := fs.String("config", getenv(ConfigFileEnv), "YAML or JSON config file (overrides "+ConfigFileEnv+")")
+	// Flags are applied after the file and the environment, so only record them while parsing
+	type flagValue struct {
+		s     setting
+		value string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		s := s
+		record := func(v string) error {
+			flagValues = append(flagValues, flagValue{s, v})
+			return nil
+		}
+		if s.bool {
+			fs.BoolFunc(s.flag, s.usage+" (overrides "+s.env+")", record)
+		} else {
+			fs.Func(s.flag, s.usage+" (overrides "+s.env+")", record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+	var errs []error
+	for _, s := range settings {
+		if v := getenv(s.env); v != "" {
+			if err := s.set(cfg, v); err != nil {
+				errs = append(errs, &Error{Setting: s.env, Reason: err.Error()})
+			}
+		}
+	}
+	for _, fv := range flagValues {
+		if err := fv.s.set(cfg, fv.value); err != nil {
+			errs = append(errs, &Error{Setting: "-" + fv.s.flag, Reason: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile decodes the config file at path over c. Unknown keys are errors, so
+// typos do not silently fall back to defaults. The default queue is always kept,
+// even if the file's queue list leaves it out.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && err != io.EOF {
+		return &Error{Setting: path, Reason: err.Error()}
+	}
+	c.defaultQueue()
+	return nil
+}
+
+// defaultQueue returns the definition of the default queue, adding one with
+// DefaultQueueCapacity if there is none.
+func (c *Config) defaultQueue() *Queue {
+	for i := range c.Queues {
+		if c.Queues[i].Name == mq.DefaultQueueName {
+			return &c.Queues[i]
+		}
+	}
+	c.Queues = append(c.Queues, Queue{Name: mq.DefaultQueueName, Capacity: DefaultQueueCapacity})
+	return &c.Queues[len(c.Queues)-1]
+}
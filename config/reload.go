@@ -0,0 +1,160 @@
+// reload.go - Applying a changed configuration to a running server.
+//
+// This file defines Reloader, which reloads the configuration (on SIGHUP or from
+// the admin endpoint) and applies what can change without a restart: new queues
+// are created, existing ones take new maximum ages, byte limits and overflow
+// policies, and the rate limits, log level and shutdown deadlines change.
+// Everything else (listeners, metrics, TLS, authentication, gRPC limits, the
+// memory budget, durability, snapshots, and the settings that decide how a
+// queue is built) is fixed at startup; changes to it are rejected and listed in
+// the report, and the server keeps running with its current settings.
+
+package config
+
+import (
+	"errors"   // For recognizing existing queues
+	"log/slog" // For changing the log level
+	"reflect"  // For comparing sections that hold lists
+	"strconv"  // For reporting rule counts
+	"sync"     // For serializing reloads
+
+	"quickpulse/mq"        // Queue registry
+	"quickpulse/ratelimit" // Producer rate limits
+)
+
+// ReloadReport lists what a reload changed and what it could not apply. Each
+// entry names the setting, as a dotted config path, followed by what happened.
+type ReloadReport struct {
+	Applied  []string `json:"applied"`
+	Rejected []string `json:"rejected"`
+}
+
+func (r *ReloadReport) apply(setting, what string) {
+	r.Applied = append(r.Applied, setting+": "+what)
+}
+
+func (r *ReloadReport) reject(setting, reason string) {
+	r.Rejected = append(r.Rejected, setting+": "+reason)
+}
+
+// Reloader reloads the configuration of a running server and applies the safe changes.
+type Reloader struct {
+	mu       sync.Mutex              // Serializes reloads
+	current  *Config                 // Configuration in effect; replaced, never modified
+	registry *mq.Registry            // Registry the queue definitions are applied to
+	limiter  *ratelimit.Limiter      // Limiter the rate limits are applied to
+	load     func() (*Config, error) // Loads the configuration again, as at startup
+}
+
+// NewReloader creates a Reloader for a server started with cfg, serving registry
+// and limiting producers with limiter. load builds the configuration again, from
+// the same file, environment and flags as at startup.
+func NewReloader(cfg *Config, registry *mq.Registry, limiter *ratelimit.Limiter, load func() (*Config, error)) *Reloader {
+	return &Reloader{current: cfg, registry: registry, limiter: limiter, load: load}
+}
+
+// Current returns the configuration in effect: the startup configuration with
+// every change applied by a reload since. The caller must not modify it.
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload loads the configuration and applies the changes that are safe while the
+// server runs, reporting each change as applied or rejected. If the configuration
+// cannot be loaded or is invalid, nothing is applied and the error is returned.
+func (r *Reloader) Reload() (ReloadReport, error) {
+	report := ReloadReport{Applied: []string{}, Rejected: []string{}}
+	next, err := r.load()
+	if err != nil {
+		return report, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur := r.current
+	applied := *cur
+
+	applied.Queues = r.reloadQueues(cur.Queues, next.Queues, &report)
+	if !reflect.DeepEqual(next.RateLimits, cur.RateLimits) {
+		var rules []ratelimit.Rule
+		for _, rl := range next.RateLimits {
+			rules = append(rules, rl.Rule())
+		}
+		if err := r.limiter.SetRules(rules); err != nil {
+			report.reject("rate_limits", err.Error())
+		} else {
+			applied.RateLimits = next.RateLimits
+			report.apply("rate_limits", strconv.Itoa(len(rules))+" rules")
+		}
+	}
+	if next.Log != cur.Log {
+		slog.SetLogLoggerLevel(next.Log.SlogLevel())
+		applied.Log = next.Log
+		report.apply("log.level", next.Log.Level)
+	}
+	if next.Shutdown != cur.Shutdown {
+		applied.Shutdown = next.Shutdown
+		report.apply("shutdown", "drain timeout "+next.Shutdown.DrainTimeout.String()+", grace period "+next.Shutdown.GracePeriod.String())
+	}
+	for _, section := range []struct {
+		setting string
+		changed bool
+	}{
+		{"listeners", !reflect.DeepEqual(next.Listeners, cur.Listeners)},
+		{"metrics", next.Metrics != cur.Metrics},
+		{"tls", next.TLS != cur.TLS},
+		{"auth", !reflect.DeepEqual(next.Auth, cur.Auth)},
+		{"limits", next.Limits != cur.Limits},
+		{"memory", next.Memory != cur.Memory},
+		{"durability", next.Durability != cur.Durability},
+		{"snapshot", next.Snapshot != cur.Snapshot},
+	} {
+		if section.changed {
+			report.reject(section.setting, "requires a restart")
+		}
+	}
+	r.current = &applied
+	return report, nil
+}
+
+// reloadQueues creates the queues next adds and reconfigures the ones it changes,
+// and returns the queue definitions now in effect. Queues left out of next are
+// kept; they can only be deleted through the API.
+func (r *Reloader) reloadQueues(cur, next []Queue, report *ReloadReport) []Queue {
+	old := make(map[string]Queue, len(cur))
+	for _, q := range cur {
+		old[q.Name] = q
+	}
+	var queues []Queue
+	for _, q := range next {
+		setting := "queues." + q.Name
+		prev, existed := old[q.Name]
+		delete(old, q.Name)
+		if existed && prev == q {
+			queues = append(queues, q)
+			continue
+		}
+		_, err := r.registry.Create(q.Name, q.QueueConfig())
+		what := "created"
+		if errors.Is(err, mq.ErrQueueExists) {
+			err, what = r.registry.Reconfigure(q.Name, q.QueueConfig()), "updated"
+		}
+		if err != nil {
+			report.reject(setting, err.Error())
+			if existed {
+				queues = append(queues, prev)
+			}
+			continue
+		}
+		report.apply(setting, what)
+		queues = append(queues, q)
+	}
+	for _, q := range cur {
+		if _, removed := old[q.Name]; removed {
+			report.reject("queues."+q.Name, "removing a queue requires the DeleteQueue RPC")
+			queues = append(queues, q)
+		}
+	}
+	return queues
+}
@@ -0,0 +1,172 @@
+// reload_test.go - Tests for reloading the configuration of a running server.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"quickpulse/mq"
+	"quickpulse/ratelimit"
+)
+
+func TestReload(t *testing.T) {
+	path := writeFile(t, "quickpulse.yaml", `
+listeners:
+  grpc:
+    unary: true
+queues:
+  - name: orders
+    capacity: 10
+`)
+	load := func() (*Config, error) { return Load([]string{"-config", path}, env(nil)) }
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := mq.NewRegistry(nil)
+	for _, q := range cfg.Queues {
+		if _, err := registry.Create(q.Name, q.QueueConfig()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	limiter, err := ratelimit.New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := NewReloader(cfg, registry, limiter, load)
+	defer slog.SetLogLoggerLevel(slog.LevelInfo)
+
+	rewrite := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rewrite(`
+listeners:
+  grpc:
+    unary: true
+    addr: ":9002"
+queues:
+  - name: orders
+    capacity: 20
+  - name: default
+    capacity: 1000000
+    overflow: drop_oldest
+    max_age: 1m
+    max_bytes: 65536
+  - name: audit
+    capacity: 5
+memory:
+  budget_bytes: 1048576
+shutdown:
+  drain_timeout: 3s
+log:
+  level: debug
+`)
+	report, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantApplied := []string{"queues.default: updated", "queues.audit: created", "log.level: debug", "shutdown: drain timeout 3s, grace period 5s"}
+	if strings.Join(report.Applied, "\n") != strings.Join(wantApplied, "\n") {
+		t.Fatalf("Applied = %q, want %q", report.Applied, wantApplied)
+	}
+	wantRejected := []string{"queues.orders: " + mq.ErrUnsafeChange.Error(), "listeners: requires a restart", "memory: requires a restart"}
+	if strings.Join(report.Rejected, "\n") != strings.Join(wantRejected, "\n") {
+		t.Fatalf("Rejected = %q, want %q", report.Rejected, wantRejected)
+	}
+
+	if _, err := registry.Get("audit"); err != nil {
+		t.Fatalf("audit queue not created: %v", err)
+	}
+	def, _ := registry.Get(mq.DefaultQueueName)
+	if c := def.Config(); c.Overflow != mq.OverflowDropOldest || c.MaxAge != time.Minute || c.MaxBytes != 65536 {
+		t.Fatalf("default queue config = %+v", c)
+	}
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("log level not lowered to debug")
+	}
+	// Rejected changes stay out of the configuration in effect
+	cur := reloader.Current()
+	if cur.Shutdown.DrainTimeout != 3*time.Second || cur.Listeners.GRPC.Addr != DefaultGRPCAddr {
+		t.Fatalf("Current() = %+v", cur)
+	}
+	if cur.Queues[0].Name != "orders" || cur.Queues[0].Capacity != 10 {
+		t.Fatalf("Current().Queues = %+v", cur.Queues)
+	}
+
+	// A queue left out of the file is kept; an invalid file changes nothing
+	rewrite("listeners:\n  grpc:\n    unary: true\n    addr: \":9002\"\nqueues: []\nlog:\n  level: debug\nshutdown:\n  drain_timeout: 3s\n")
+	report, err = reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rejected) != 3 || !strings.HasPrefix(report.Rejected[0], "queues.orders: removing") {
+		t.Fatalf("Rejected = %q", report.Rejected)
+	}
+	rewrite("log:\n  level: loud\n")
+	var cfgErr *Error
+	if _, err := reloader.Reload(); !errors.As(err, &cfgErr) {
+		t.Fatalf("Reload of an invalid file = %v, want *Error", err)
+	}
+	if reloader.Current().Log.Level != "debug" {
+		t.Fatalf("invalid reload changed the log level to %q", reloader.Current().Log.Level)
+	}
+}
+
+func TestReloadRateLimits(t *testing.T) {
+	path := writeFile(t, "quickpulse.yaml", "listeners:\n  grpc:\n    unary: true\n")
+	load := func() (*Config, error) { return Load([]string{"-config", path}, env(nil)) }
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := ratelimit.New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader := NewReloader(cfg, mq.NewRegistry(nil), limiter, load)
+	req := ratelimit.Request{Client: "10.0.0.1", Queue: "orders"}
+	if err := limiter.Allow(req); err != nil {
+		t.Fatalf("no rate limits at startup: %v", err)
+	}
+
+	// Adding a limit takes effect without a restart
+	if err := os.WriteFile(path, []byte("listeners:\n  grpc:\n    unary: true\nrate_limits:\n  - key: client\n    messages_per_second: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := reloader.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(report.Applied, "\n") != "rate_limits: 1 rules" || len(report.Rejected) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if err := limiter.Allow(req); err != nil {
+		t.Fatalf("first message under the new limit: %v", err)
+	}
+	if err := limiter.Allow(req); !errors.Is(err, ratelimit.ErrRateLimited) {
+		t.Fatalf("second message under the new limit = %v, want ErrRateLimited", err)
+	}
+	if len(reloader.Current().RateLimits) != 1 {
+		t.Fatalf("Current().RateLimits = %+v", reloader.Current().RateLimits)
+	}
+
+	// Removing it lifts the limit
+	if err := os.WriteFile(path, []byte("listeners:\n  grpc:\n    unary: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := reloader.Reload(); err != nil || strings.Join(report.Applied, "\n") != "rate_limits: 0 rules" {
+		t.Fatalf("Reload() = %+v, %v", report, err)
+	}
+	if err := limiter.Allow(req); err != nil {
+		t.Fatalf("after removing the limit: %v", err)
+	}
+}
@@ -1,151 +1,436 @@
 // main.go - Entry point for the QuickPulse server application.
 //
-// This server can operate in one of three modes, controlled by environment variables:
+// This server has three modes, each enabled on its own:
 //   - WebSocket mode (WS_MODE=1): Starts a WebSocket server for publishing and consuming messages.
 //   - gRPC unary mode (RPC_MODE=1): Starts a gRPC server supporting unary RPCs.
 //   - gRPC streaming mode (RPC_STREAM_MODE=1): Starts a gRPC server supporting streaming RPCs.
 //
 // The server also exposes Prometheus metrics on :8080/metrics for monitoring.
-// Only one mode can be active at a time.
+// Any combination of modes can run at once against the same queues; with both gRPC
+// modes enabled one gRPC service serves unary RPCs and StreamMessages on :50051,
+// and at least one mode must be enabled. Clients address named queues from a shared
+// registry; a queue named "default" is always created at startup, along with any
+// other queues the configuration defines. Durable queues are replayed from their
+// write-ahead logs at startup, and a configured snapshot file is written on
+// shutdown and through the admin endpoints, and can be restored before the
+// listeners open.
+//
+// The configuration (package config) comes from a YAML or JSON file named by
+// -config or CONFIG_FILE, overridden by environment variables such as WS_MODE and
+// DATA_DIR, overridden in turn by command-line flags; it is validated and logged
+// at startup.
+//
+// With a TLS certificate and key configured, every listener serves TLS, and with a
+// client CA file clients must present a certificate it signed (mutual TLS). The
+// certificate files are reloaded when they change, and on SIGHUP.
+//
+// With authentication configured, clients present an API key, an HMAC-signed token
+// or a JWT on every gRPC call and WebSocket handshake, and the admin endpoints
+// need the same; access rules grant each subject the produce, consume and admin
+// permissions on the queues, topics and logs it may use. Rate limits cap the
+// messages and bytes per second producers may send, per client, subject or queue.
+// Queues can be bounded by the payload bytes they hold, and a memory budget
+// bounds the bytes held by all of them together.
+//
+// On SIGHUP, or a POST to /admin/reload, the configuration is loaded again and the
+// changes that are safe while running are applied: new queues, queue maximum ages,
+// byte limits and overflow policies, the rate limits, the log level and the
+// shutdown deadlines. Other changes are rejected and logged, and need a restart.
+//
+// On SIGINT or SIGTERM the server shuts down gracefully: it stops taking new
+// messages, gives consumers up to the drain timeout (default 30s) to empty the
+// queues, closes client connections, stops the metrics server and, if a snapshot
+// file is configured, snapshots whatever messages are left.
 
 package main
 
 import (
+	"context" // For shutdown deadlines
+	"crypto/tls" // TLS for the listeners
+	"errors"  // For recognizing a closed HTTP server
+	"flag"    // For recognizing -h
 	"log"   // Logging for server events and errors
+	"log/slog" // For the configured log level
 	"net"   // Networking primitives for TCP listeners
 	"net/http" // HTTP server for Prometheus metrics and WebSocket endpoints
 	"os"    // For reading environment variables and exiting
-	"strconv" // For converting environment variables to integers
+	"os/signal" // For shutting down on SIGINT and SIGTERM
+	"sync"    // For stopping the listeners together
+	"syscall" // For SIGTERM and SIGHUP
 
+	"quickpulse/auth"       // Client authentication
+	"quickpulse/certs"      // Reloading TLS certificates
+	"quickpulse/config"     // Server configuration
 	"quickpulse/mq"         // Message queue implementation
 	"quickpulse/mqmetrics"  // Instrumented queue and Prometheus metrics
 	"quickpulse/proto"      // gRPC protobuf definitions (used for server registration)
+	"quickpulse/ratelimit"  // Rate limits on producers
 	"quickpulse/server"     // WebSocket and gRPC server implementations
 
 	"github.com/prometheus/client_golang/prometheus/promhttp" // Prometheus HTTP handler
 	"google.golang.org/grpc"          // gRPC server
+	"google.golang.org/grpc/credentials" // TLS for the gRPC server
 	"google.golang.org/grpc/reflection" // gRPC server reflection for debugging
 )
 
-// gRPC tuning constants for server configuration
-const (
-	MaxConcurrentStreams  = uint32(1000000) // Maximum concurrent gRPC streams
-	MaxReceiveMessageSize = 1024            // Maximum size of received gRPC messages (1KB)
-	WriteBufferSize       = 32 * 1024       // gRPC write buffer size (32KB)
-	ReadBufferSize        = 32 * 1024       // gRPC read buffer size (32KB)
-)
-
 func main() {
-	// Parse mode flags from environment variables (default to 0 if not set or invalid)
-	wsMode, _ := strconv.Atoi(os.Getenv("WS_MODE"))
-	rpcMode, _ := strconv.Atoi(os.Getenv("RPC_MODE"))
-	rpcStreamMode, _ := strconv.Atoi(os.Getenv("RPC_STREAM_MODE"))
+	// Load the configuration: file, then environment, then flags
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	log.Printf("Effective configuration:\n%s", cfg)
+	slog.SetLogLoggerLevel(cfg.Log.SlogLevel())
 
-	// Ensure exactly one mode is enabled
-	modeCount := 0
-	if wsMode == 1 {
-		modeCount++
+	// Initialize the queue registry; every named queue gets its own instrumented queue and Prometheus metrics
+	registry := mq.NewRegistry(func(name string, q mq.Queue) mq.Queue {
+		return mqmetrics.NewInstrumentedQueue(q, mqmetrics.NewPrometheusMetrics(name))
+	})
+	// Every log exports the lag of its consumer groups
+	registry.ObserveLogs(func(name string) mq.LogObserver {
+		return mqmetrics.NewPrometheusLogMetrics(name)
+	})
+	// A memory budget bounds the payload bytes of every queue together, so it is set before any are created
+	if cfg.Memory.Enabled() {
+		budget, err := cfg.Memory.Budget(mqmetrics.NewPrometheusMemoryMetrics())
+		if err != nil {
+			log.Fatalf("invalid memory budget: %v", err)
+		}
+		registry.SetMemoryBudget(budget)
+		log.Printf("Memory budget of %d bytes across all queues (policy %s)", budget.Limit(), budget.Policy())
+	}
+	// Durable queues are opt-in: they need a data directory, and recover their messages from it
+	if cfg.Durability.Enabled() {
+		durability := cfg.Durability.Options()
+		durability.RefillFailed = func(queue string, remaining int, err error) {
+			log.Printf("Could not put %d recovered messages back in durable queue %q: %v", remaining, queue, err)
+		}
+		if err := registry.EnableDurability(durability); err != nil {
+			log.Fatalf("failed to enable durable queues: %v", err)
+		}
+		recovered, err := registry.Recover()
+		if err != nil {
+			log.Fatalf("failed to recover durable queues: %v", err)
+		}
+		for _, rq := range recovered {
+			log.Printf("Recovered durable queue %q with %d messages and %d scheduled messages (%d damaged log segments)", rq.Name, rq.Messages, rq.Scheduled, rq.Damaged)
+		}
 	}
-	if rpcMode == 1 {
-		modeCount++
+	// Create the configured queues; durable ones may already have been recovered
+	for _, q := range cfg.Queues {
+		if _, err := registry.Create(q.Name, q.QueueConfig()); err != nil && !errors.Is(err, mq.ErrQueueExists) {
+			log.Fatalf("failed to create queue %q: %v", q.Name, err)
+		}
 	}
-	if rpcStreamMode == 1 {
-		modeCount++
+	// Rate limits: every producer is checked against the same limiter, whatever its transport;
+	// it is created without rules too, so a reload can add them
+	var rules []ratelimit.Rule
+	for _, r := range cfg.RateLimits {
+		rules = append(rules, r.Rule())
 	}
-	if modeCount != 1 {
-		log.Fatal("Exactly one of WS_MODE, RPC_MODE, or RPC_STREAM_MODE must be set to 1.")
+	limiter, err := ratelimit.New(rules, mqmetrics.NewPrometheusRateLimitMetrics())
+	if err != nil {
+		log.Fatalf("invalid rate limits: %v", err)
 	}
+	if len(rules) > 0 {
+		log.Printf("Rate limiting producers with %d rules", len(rules))
+	}
+	// Reloads read the same file, environment and flags as startup
+	reloader := config.NewReloader(cfg, registry, limiter, func() (*config.Config, error) {
+		return config.Load(os.Args[1:], os.Getenv)
+	})
 
-	// Initialize Prometheus metrics and instrumented message queue
-	metrics := mqmetrics.NewPrometheusMetrics()
-	queue := mq.NewMessageQueue(1000000)
-	instrumentedQueue := mqmetrics.NewInstrumentedQueue(queue, metrics)
-
-	// Start Prometheus metrics HTTP server in a separate goroutine
-	go func() {
-		http.Handle("/metrics", promhttp.Handler())
-		log.Println("Prometheus metrics server listening on :8080/metrics")
-		if err := http.ListenAndServe(":8080", nil); err != nil {
-			log.Fatalf("metrics server error: %v", err)
+	// Snapshots: reload the last one before clients can connect, and take a new one on shutdown
+	if snapshotFile := cfg.Snapshot.File; snapshotFile != "" {
+		registry.SetSnapshotFile(snapshotFile)
+		if cfg.Snapshot.Restore {
+			info, err := registry.LoadSnapshot()
+			if err != nil {
+				log.Fatalf("failed to restore snapshot %s: %v", snapshotFile, err)
+			}
+			log.Printf("Restored %d queues with %d messages from %s", info.Queues, info.Messages, snapshotFile)
 		}
-	}()
+	}
 
-	// WebSocket server mode
-	if wsMode == 1 {
-		// Create a new WebSocket server with the instrumented queue
-		wsServer := server.NewWsServer(instrumentedQueue)
-		// Register HTTP handlers for publish and consume endpoints
-		http.HandleFunc("/ws/publish", wsServer.PublishHandler)
-		http.HandleFunc("/ws/consume", wsServer.ConsumeHandler)
-		log.Println("WebSocket server listening on :8081 (endpoints: /ws/publish, /ws/consume)")
-		// Start the HTTP server for WebSocket endpoints
-		if err := http.ListenAndServe(":8081", nil); err != nil {
-			log.Fatalf("WebSocket server error: %v", err)
+	// TLS: one certificate, reloaded as its files change, serves every listener
+	var certReloader *certs.Reloader
+	var serverTLS *tls.Config
+	if cfg.TLS.Enabled() {
+		certReloader, err = certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.ReloadInterval)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		serverTLS = certReloader.ServerConfig()
+		if cfg.TLS.ClientCAFile != "" {
+			log.Printf("Mutual TLS enabled: clients need a certificate signed by a CA in %s", cfg.TLS.ClientCAFile)
 		}
-		return
 	}
 
-	// gRPC unary server mode
-	if rpcMode == 1 {
-		// Listen on TCP port 50051 for gRPC connections
-		lis, err := net.Listen("tcp", ":50051")
+	// Authentication: once enabled, every listener and the admin endpoints require credentials
+	var authn *auth.Authenticator
+	if cfg.Auth.Enabled() {
+		authn, err = cfg.Auth.Authenticator()
 		if err != nil {
-			log.Fatalf("failed to listen: %v", err)
+			log.Fatalf("failed to set up authentication: %v", err)
 		}
+		log.Printf("Authentication enabled with %d access rules", len(cfg.Auth.Rules))
+	}
 
-		// Configure gRPC server options for performance tuning
-		var serverOpts []grpc.ServerOption
-		serverOpts = append(serverOpts, grpc.MaxConcurrentStreams(MaxConcurrentStreams))
-		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(MaxReceiveMessageSize))
-		if WriteBufferSize > 0 {
-			serverOpts = append(serverOpts, grpc.WriteBufferSize(WriteBufferSize))
-		}
-		if ReadBufferSize > 0 {
-			serverOpts = append(serverOpts, grpc.ReadBufferSize(ReadBufferSize))
-		}
-		// Create the gRPC server with the configured options
-		grpcSrv := grpc.NewServer(serverOpts...)
-		// Register the MessageQueue service with a unary handler
-		proto.RegisterMessageQueueServer(grpcSrv, server.NewGrpcUnaryServer(instrumentedQueue))
-		// Enable server reflection for debugging with tools like grpcurl
-		reflection.Register(grpcSrv)
+	// Start Prometheus metrics HTTP server in a separate goroutine; it also serves the admin endpoints
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled {
+		adminServer := server.NewAdminServer(registry, reloader)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/admin/snapshot", server.RequirePermission(authn, auth.Admin, server.RegistryResource, adminServer.SnapshotHandler))
+		mux.HandleFunc("/admin/restore", server.RequirePermission(authn, auth.Admin, server.RegistryResource, adminServer.RestoreHandler))
+		mux.HandleFunc("/admin/reload", server.RequirePermission(authn, auth.Admin, server.RegistryResource, adminServer.ReloadHandler))
+		mux.Handle(cfg.Metrics.Path, promhttp.Handler())
+		metricsSrv = &http.Server{Addr: cfg.Metrics.Addr, Handler: mux}
+		go func() {
+			log.Printf("Prometheus metrics server listening on %s%s", cfg.Metrics.Addr, cfg.Metrics.Path)
+			if err := listenAndServe(metricsSrv, serverTLS); err != nil {
+				log.Fatalf("metrics server error: %v", err)
+			}
+		}()
+	}
 
-		log.Println("gRPC server (unary) listening on :50051")
-		// Start serving gRPC requests
-		if err := grpcSrv.Serve(lis); err != nil {
-			log.Fatalf("failed to serve: %v", err)
-		}
+	var listeners []listener
+
+	// WebSocket listener
+	if cfg.Listeners.WebSocket.Enabled {
+		// Create a new WebSocket server backed by the queue registry, with the configured handshake policy
+		wsCfg := cfg.Listeners.WebSocket
+		wsServer := server.NewWsServer(registry, limiter, server.WsOptions{
+			AllowedOrigins:      wsCfg.AllowedOrigins,
+			Subprotocols:        wsCfg.Subprotocols,
+			MaxMessageSize:      wsCfg.MaxMessageSize,
+			MaxConnectionsPerIP: wsCfg.MaxConnectionsPerIP,
+			ReadBufferSize:      wsCfg.ReadBufferSize,
+			WriteBufferSize:     wsCfg.WriteBufferSize,
+			Observer:            mqmetrics.NewPrometheusWsMetrics(),
+		})
+		// Register HTTP handlers for publish and consume endpoints (default queue and named queues);
+		// with authentication the handshake is refused unless the client may use the queue or topic
+		publish := server.RequirePermission(authn, auth.Produce, server.QueueResource, wsServer.PublishHandler)
+		consume := server.RequirePermission(authn, auth.Consume, server.QueueResource, wsServer.ConsumeHandler)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ws/publish", publish)
+		mux.HandleFunc("/ws/publish/{queue}", publish)
+		mux.HandleFunc("/ws/consume", consume)
+		mux.HandleFunc("/ws/consume/{queue}", consume)
+		mux.HandleFunc("/ws/topics/{topic}/publish", server.RequirePermission(authn, auth.Produce, server.TopicResource, wsServer.TopicPublishHandler))
+		mux.HandleFunc("/ws/topics/{topic}/subscribe", server.RequireSubscribePermission(authn, wsServer.SubscribeHandler))
+		log.Printf("WebSocket server listening on %s (endpoints: /ws/publish[/{queue}], /ws/consume[/{queue}], /ws/topics/{topic}/publish, /ws/topics/{topic}/subscribe)", cfg.Listeners.WebSocket.Addr)
+		// The HTTP server for WebSocket endpoints
+		wsHTTPSrv := &http.Server{Addr: cfg.Listeners.WebSocket.Addr, Handler: mux}
+		listeners = append(listeners, listener{
+			name:  "WebSocket server",
+			serve: func() error { return listenAndServe(wsHTTPSrv, serverTLS) },
+			stop: func(ctx context.Context) {
+				// Stop accepting connections, then close the upgraded ones, which Shutdown leaves alone
+				if err := wsHTTPSrv.Shutdown(ctx); err != nil {
+					log.Printf("WebSocket server shutdown error: %v", err)
+				}
+				if err := wsServer.Shutdown(ctx); err != nil {
+					log.Printf("WebSocket connections did not close in time: %v", err)
+				}
+			},
+		})
 	}
 
-	// gRPC streaming server mode
-	if rpcStreamMode == 1 {
-		// Listen on TCP port 50051 for gRPC connections
-		lis, err := net.Listen("tcp", ":50051")
+	// gRPC listener: RPC_MODE serves the unary RPCs and RPC_STREAM_MODE serves StreamMessages;
+	// with both set one combined service serves them all
+	if grpcCfg := cfg.Listeners.GRPC; grpcCfg.Enabled() {
+		// Listen for gRPC connections
+		lis, err := net.Listen("tcp", grpcCfg.Addr)
 		if err != nil {
 			log.Fatalf("failed to listen: %v", err)
 		}
 
 		// Configure gRPC server options for performance tuning
+		limits := cfg.Limits
 		var serverOpts []grpc.ServerOption
-		serverOpts = append(serverOpts, grpc.MaxConcurrentStreams(MaxConcurrentStreams))
-		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(MaxReceiveMessageSize))
-		if WriteBufferSize > 0 {
-			serverOpts = append(serverOpts, grpc.WriteBufferSize(WriteBufferSize))
+		serverOpts = append(serverOpts, grpc.MaxConcurrentStreams(uint32(limits.MaxConcurrentStreams)))
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(limits.MaxReceiveMessageSize))
+		if limits.WriteBufferSize > 0 {
+			serverOpts = append(serverOpts, grpc.WriteBufferSize(limits.WriteBufferSize))
 		}
-		if ReadBufferSize > 0 {
-			serverOpts = append(serverOpts, grpc.ReadBufferSize(ReadBufferSize))
+		if limits.ReadBufferSize > 0 {
+			serverOpts = append(serverOpts, grpc.ReadBufferSize(limits.ReadBufferSize))
 		}
+		if serverTLS != nil {
+			serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+		}
+		if authn != nil {
+			serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(server.UnaryAuthInterceptor(authn)))
+			serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(server.StreamAuthInterceptor(authn)))
+		}
+		// Failures are reported in the API version each call asks for, once it is authenticated
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(server.UnaryVersionInterceptor()))
+		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(server.StreamVersionInterceptor()))
 		// Create the gRPC server with the configured options
 		grpcSrv := grpc.NewServer(serverOpts...)
-		// Register the MessageQueue service with a streaming handler
-		proto.RegisterMessageQueueServer(grpcSrv, server.NewGrpcStreamServer(instrumentedQueue))
+		// Register the MessageQueue service with the handlers for the enabled modes
+		var service proto.MessageQueueServer
+		closeStreams := func() {}
+		kind := "unary"
+		switch {
+		case grpcCfg.Unary && grpcCfg.Streaming:
+			combined := server.NewGrpcServer(registry, limiter)
+			service, closeStreams, kind = combined, combined.Shutdown, "unary and streaming"
+		case grpcCfg.Streaming:
+			streamServer := server.NewGrpcStreamServer(registry, limiter)
+			service, closeStreams, kind = streamServer, streamServer.Shutdown, "streaming"
+		default:
+			service = server.NewGrpcUnaryServer(registry, limiter)
+		}
+		proto.RegisterMessageQueueServer(grpcSrv, service)
 		// Enable server reflection for debugging with tools like grpcurl
 		reflection.Register(grpcSrv)
 
-		log.Println("gRPC server (streaming) listening on :50051")
-		// Start serving gRPC requests
-		if err := grpcSrv.Serve(lis); err != nil {
-			log.Fatalf("failed to serve: %v", err)
+		log.Printf("gRPC server (%s) listening on %s", kind, grpcCfg.Addr)
+		listeners = append(listeners, listener{
+			name:  "gRPC server",
+			serve: func() error { return grpcSrv.Serve(lis) },
+			stop: func(ctx context.Context) {
+				// Open streams end after their current reply; GracefulStop would wait for clients to close them
+				closeStreams()
+				gracefulStop(ctx, grpcSrv)
+			},
+		})
+	}
+
+	// Reload the configuration and the TLS certificate on SIGHUP
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		for range hangups {
+			log.Printf("Received SIGHUP, reloading the configuration")
+			logReload(reloader.Reload())
+			if certReloader != nil {
+				if err := certReloader.Reload(); err != nil {
+					log.Printf("TLS certificate not reloaded: %v", err)
+				}
+			}
 		}
+	}()
+
+	// Shut down gracefully on SIGINT or SIGTERM, with the deadlines in effect at the time
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %v, shutting down", sig)
+		shutdown(registry, reloader.Current().Shutdown, listeners, metricsSrv)
+		close(stopped)
+	}()
+
+	// Start serving requests on every listener; each returns once shutdown stops it
+	for _, l := range listeners {
+		go func(l listener) {
+			if err := l.serve(); err != nil {
+				log.Fatalf("%s failed to serve: %v", l.name, err)
+			}
+		}(l)
+	}
+	<-stopped
+}
+
+// listener is a client-facing server run by main.
+type listener struct {
+	name  string                    // For logging
+	serve func() error              // Blocks serving clients until the listener is stopped
+	stop  func(ctx context.Context) // Closes client connections gracefully within ctx's deadline
+}
+
+// listenAndServe serves srv over TLS with tlsCfg, or plaintext if it is nil,
+// until srv is shut down.
+func listenAndServe(srv *http.Server, tlsCfg *tls.Config) error {
+	var err error
+	if tlsCfg != nil {
+		// The certificate comes from tlsCfg, so no files are named here
+		srv.TLSConfig = tlsCfg
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// logReload logs the outcome of a configuration reload.
+func logReload(report config.ReloadReport, err error) {
+	if err != nil {
+		log.Printf("Configuration not reloaded:\n%v", err)
+		return
+	}
+	for _, change := range report.Applied {
+		log.Printf("Reload applied %s", change)
+	}
+	for _, change := range report.Rejected {
+		log.Printf("Reload rejected %s", change)
+	}
+	if len(report.Applied)+len(report.Rejected) == 0 {
+		log.Printf("Reload found no changes")
+	}
+}
+
+// shutdown stops the server gracefully. Producers are refused at once, while
+// consumers get up to the drain timeout to empty the queues; then the listeners
+// close their client connections and the metrics server (if any) stops, all within
+// the grace period. Whatever messages are left are snapshotted if a snapshot file
+// is configured, and the logs of durable queues are closed.
+func shutdown(registry *mq.Registry, deadlines config.Shutdown, listeners []listener, metricsSrv *http.Server) {
+	drainCtx, cancel := context.WithTimeout(context.Background(), deadlines.DrainTimeout)
+	if err := registry.Drain(drainCtx); err != nil {
+		log.Printf("Queues not drained within %v, stopping anyway", deadlines.DrainTimeout)
+	}
+	cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadlines.GracePeriod)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l listener) {
+			defer wg.Done()
+			l.stop(ctx)
+		}(l)
+	}
+	wg.Wait()
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			log.Printf("metrics server shutdown error: %v", err)
+		}
+	}
+
+	if info, err := registry.SaveSnapshot(); err == nil {
+		log.Printf("Wrote %d queues with %d messages to the snapshot file", info.Queues, info.Messages)
+	} else if !errors.Is(err, mq.ErrSnapshotsDisabled) {
+		log.Printf("failed to write snapshot: %v", err)
+	}
+	if err := registry.Close(); err != nil {
+		log.Printf("failed to close durable queues: %v", err)
+	}
+}
+
+// gracefulStop stops grpcSrv gracefully, letting in-flight RPCs finish, and cuts
+// off whatever is still running when ctx is done.
+func gracefulStop(ctx context.Context, grpcSrv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		grpcSrv.Stop()
 	}
 }
@@ -0,0 +1,108 @@
+// deadletter.go - Administration of dead-letter queues.
+//
+// This file provides the operations operators use on the dead-letter queue
+// linked to a named queue: inspecting its messages without consuming them,
+// requeueing them to the queue they came from, and purging them.
+
+package mq
+
+import "errors" // For dead-letter errors
+
+// ErrNoDeadLetterQueue is returned when a queue has no linked dead-letter queue.
+var ErrNoDeadLetterQueue = errors.New("queue has no dead-letter queue")
+
+// Peeker is implemented by queues that can list their messages without removing them.
+type Peeker interface {
+	Peek(limit int) []*Message // Up to limit of the oldest messages (all if limit is zero)
+}
+
+// DeadLetterQueue returns the linked dead-letter queue, or nil if there is none.
+func (nq *NamedQueue) DeadLetterQueue() *NamedQueue {
+	return nq.deadLetter
+}
+
+// Peek returns up to limit of the oldest messages in the queue without removing
+// them (all of them if limit is zero).
+func (nq *NamedQueue) Peek(limit int) []*Message {
+	if p, ok := nq.base.(Peeker); ok {
+		return p.Peek(limit)
+	}
+	return nil
+}
+
+// deadLetterQueue returns the dead-letter queue linked to the named queue.
+func (r *Registry) deadLetterQueue(name string) (*NamedQueue, error) {
+	nq, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if nq.deadLetter == nil {
+		return nil, ErrNoDeadLetterQueue
+	}
+	return nq.deadLetter, nil
+}
+
+// InspectDeadLetters returns up to limit of the oldest messages in the dead-letter
+// queue of the named queue (all of them if limit is zero) without removing them.
+func (r *Registry) InspectDeadLetters(name string, limit int) ([]*Message, error) {
+	dlq, err := r.deadLetterQueue(name)
+	if err != nil {
+		return nil, err
+	}
+	return dlq.Peek(limit), nil
+}
+
+// RequeueDeadLetters moves up to limit messages (all of them if limit is zero) from
+// the dead-letter queue of the named queue back to the queues they were dead-lettered
+// from, with a clean delivery history. Messages whose original queue no longer exists
+// go to the named queue. It returns how many messages were moved; if a target queue
+// is full the message is put back on the dead-letter queue and the error is returned.
+func (r *Registry) RequeueDeadLetters(name string, limit int) (int, error) {
+	dlq, err := r.deadLetterQueue(name)
+	if err != nil {
+		return 0, err
+	}
+	// Bound the work by the current length, so messages dead-lettered meanwhile are left alone
+	n := int(dlq.Len())
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	moved := 0
+	for ; moved < n; moved++ {
+		msg, err := dlq.Dequeue()
+		if err != nil {
+			break // Drained by someone else
+		}
+		target, err := r.Get(name)
+		if dl := msg.GetDeadLetter(); dl != nil {
+			if origin, originErr := r.Get(dl.Queue); originErr == nil {
+				target, err = origin, nil
+			}
+		}
+		if err == nil {
+			err = target.Enqueue(msg.requeued())
+		}
+		if err != nil {
+			_ = dlq.Enqueue(msg) // Keep the message dead-lettered
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// PurgeDeadLetters discards every message currently in the dead-letter queue of
+// the named queue and returns how many were discarded.
+func (r *Registry) PurgeDeadLetters(name string) (int, error) {
+	dlq, err := r.deadLetterQueue(name)
+	if err != nil {
+		return 0, err
+	}
+	n := int(dlq.Len())
+	purged := 0
+	for ; purged < n; purged++ {
+		if _, err := dlq.Dequeue(); err != nil {
+			break
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,118 @@
+// deadletter_test.go - Tests for dead-letter queues.
+
+package mq
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countingQueue counts dead-lettered messages reported to it.
+type countingQueue struct {
+	Queue
+	deadLettered map[string]int
+}
+
+func (c *countingQueue) ObserveDeadLetter(reason string) {
+	c.deadLettered[reason]++
+}
+
+func TestDeadLetterAfterMaxAttempts(t *testing.T) {
+	observed := &countingQueue{deadLettered: map[string]int{}}
+	r := NewRegistry(func(name string, q Queue) Queue {
+		if name != "jobs" {
+			return q
+		}
+		observed.Queue = q
+		return observed
+	})
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 4, MaxDeliveryAttempts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlq := jobs.DeadLetterQueue()
+	if dlq == nil || dlq.Name() != "jobs"+DeadLetterSuffix {
+		t.Fatalf("DeadLetterQueue() = %v", dlq)
+	}
+	if err := jobs.Enqueue(textMessage("poison")); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		lease, err := jobs.Leases().Dequeue(0)
+		if err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+		if err := jobs.Leases().Nack(lease.Message.GetID()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if jobs.Len() != 0 || dlq.Len() != 1 {
+		t.Fatalf("after max attempts: jobs Len = %d, dlq Len = %d", jobs.Len(), dlq.Len())
+	}
+	if observed.deadLettered[ReasonNack] != 1 {
+		t.Fatalf("observed dead letters = %v", observed.deadLettered)
+	}
+
+	msgs, err := r.InspectDeadLetters("jobs", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || dlq.Len() != 1 {
+		t.Fatalf("InspectDeadLetters returned %d messages, dlq Len = %d", len(msgs), dlq.Len())
+	}
+	dl := msgs[0].GetDeadLetter()
+	if dl == nil || dl.Queue != "jobs" || dl.Reason != ReasonMaxAttempts || len(msgs[0].GetAttempts()) != 2 {
+		t.Fatalf("dead letter = %+v, attempts = %+v", dl, msgs[0].GetAttempts())
+	}
+
+	if err := r.Delete(dlq.Name()); !errors.Is(err, ErrQueueInUse) {
+		t.Fatalf("Delete of linked dlq = %v, want ErrQueueInUse", err)
+	}
+	if _, err := r.InspectDeadLetters(dlq.Name(), 0); !errors.Is(err, ErrNoDeadLetterQueue) {
+		t.Fatalf("InspectDeadLetters on a dlq = %v, want ErrNoDeadLetterQueue", err)
+	}
+
+	// Requeued messages start over with a clean history
+	if n, err := r.RequeueDeadLetters("jobs", 0); err != nil || n != 1 {
+		t.Fatalf("RequeueDeadLetters = %d, %v", n, err)
+	}
+	msg, err := jobs.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetDeliveryAttempt() != 1 || msg.GetDeadLetter() != nil {
+		t.Fatalf("requeued message attempt %d, dead letter %+v", msg.GetDeliveryAttempt(), msg.GetDeadLetter())
+	}
+
+	if err := dlq.Enqueue(textMessage("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dlq.Enqueue(textMessage("b")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.PurgeDeadLetters("jobs"); err != nil || n != 2 || dlq.Len() != 0 {
+		t.Fatalf("PurgeDeadLetters = %d, %v (dlq Len %d)", n, err, dlq.Len())
+	}
+}
+
+func TestDeadLetterQueueRemovedWhenCreateFails(t *testing.T) {
+	dir := t.TempDir()
+	r := durableRegistry(t, dir)
+	defer r.Close()
+	// A file where the queue's log directory belongs makes building the queue fail
+	if err := os.WriteFile(filepath.Join(dir, "jobs"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Create("jobs", QueueConfig{Capacity: 8, Durable: true, MaxDeliveryAttempts: 3}); err == nil {
+		t.Fatal("Create succeeded without a log directory")
+	}
+	if _, err := r.Get("jobs.dlq"); !errors.Is(err, ErrQueueNotFound) {
+		t.Fatalf("Get(jobs.dlq) after the failed Create = %v, want ErrQueueNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "jobs.dlq")); !os.IsNotExist(err) {
+		t.Fatalf("log of the dead-letter queue left behind: %v", err)
+	}
+}
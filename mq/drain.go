@@ -0,0 +1,72 @@
+// drain.go - Draining the registry for a graceful shutdown.
+//
+// This file lets a server stop taking new messages while its consumers empty the
+// queues: once StopProducers is called, Producing reports false and servers turn
+// producers away with ErrDraining, and Drain waits until every queue is empty and
+// has no messages in flight, or until the caller's deadline passes.
+
+package mq
+
+import (
+	"context" // For the drain deadline
+	"errors"  // For the draining error
+	"time"    // For polling the queues
+)
+
+// ErrDraining is returned to producers once the registry has stopped taking new messages.
+var ErrDraining = errors.New("server is shutting down")
+
+// drainPollInterval is how often Drain checks whether the queues are empty.
+const drainPollInterval = 50 * time.Millisecond
+
+// StopProducers makes the registry refuse new messages from now on. Consumers,
+// acks and nacks are unaffected. It cannot be undone.
+func (r *Registry) StopProducers() {
+	r.draining.Store(true)
+}
+
+// Producing reports whether the registry still takes new messages, that is,
+// whether StopProducers has not been called.
+func (r *Registry) Producing() bool {
+	return !r.draining.Load()
+}
+
+// Drain stops producers and waits until every queue is empty and has no leased
+// messages awaiting ack, or until ctx is done, in which case it returns ctx.Err().
+// Delayed messages that are not yet due are not waited for, and neither are
+// dead-letter queues, which nobody is expected to consume.
+func (r *Registry) Drain(ctx context.Context) error {
+	r.StopProducers()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for !r.drained() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// drained reports whether every queue other than the dead-letter queues is empty
+// and has nothing in flight.
+func (r *Registry) drained() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deadLetters := make(map[*NamedQueue]bool)
+	for _, nq := range r.queues {
+		if nq.deadLetter != nil {
+			deadLetters[nq.deadLetter] = true
+		}
+	}
+	for _, nq := range r.queues {
+		if deadLetters[nq] {
+			continue
+		}
+		if nq.Len() > 0 || nq.leases.InFlight() > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// drain_test.go - Tests for draining the registry on shutdown.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryDrain(t *testing.T) {
+	r := NewRegistry(nil)
+	orders, err := r.Create("orders", QueueConfig{Capacity: 4, MaxDeliveryAttempts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Producing() {
+		t.Fatal("new registry is not producing")
+	}
+	if err := orders.Enqueue(NewMessage(NewID(), []byte("o1"))); err != nil {
+		t.Fatal(err)
+	}
+	// A dead-lettered message does not hold up the drain
+	dlq, err := r.Get("orders" + DeadLetterSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dlq.Enqueue(NewMessage(NewID(), []byte("dead"))); err != nil {
+		t.Fatal(err)
+	}
+
+	drain := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		return r.Drain(ctx)
+	}
+	if err := drain(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain with a ready message = %v, want DeadlineExceeded", err)
+	}
+	if r.Producing() {
+		t.Fatal("Producing() = true after Drain")
+	}
+
+	// A leased message must be settled before the queue counts as drained
+	lease, err := orders.Leases().Dequeue(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := drain(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain with a message in flight = %v, want DeadlineExceeded", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Drain(context.Background()) }()
+	if err := orders.Leases().Ack(lease.Message.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Drain after ack = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Drain did not return once the queue was empty")
+	}
+}
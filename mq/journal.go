@@ -0,0 +1,553 @@
+// journal.go - Durable queues backed by a write-ahead log.
+//
+// This file makes named queues durable. A durable queue keeps a write-ahead log
+// (wal.go) in its own directory below the registry's data directory, next to a
+// file holding its configuration. Every message enqueued is appended to the log
+// before it becomes visible to consumers, and a record is appended whenever a
+// message leaves the queue for good: when a plain consume dequeues it, when a
+// leased message is acked or dead-lettered, and when it expires. A leased message
+// that has not been acked is still live, so messages in flight when the server
+// stops are delivered again after it restarts.
+//
+// Delayed messages are journaled with their due time when they are scheduled, and
+// again as an ordinary enqueue when they come due.
+//
+// On startup Recover replays each log and refills the queue with every message
+// that was enqueued and never removed, in the order of its last enqueue, and
+// re-arms the scheduler with every delayed message that had not come due yet.
+// Segments holding only messages that are gone are deleted from the front of the log.
+
+package mq
+
+import (
+	"context"         // For refilling a queue that overflows on recovery
+	"encoding/binary" // For encoding records
+	"encoding/json"   // For the queue configuration file
+	"errors"          // For durability errors
+	"os"              // For queue directories
+	"path/filepath"   // For queue paths
+	"sort"            // For replaying messages in order
+	"sync"            // For guarding live message tracking
+	"time"            // For message timestamps
+)
+
+// Durability errors.
+var (
+	ErrDurabilityDisabled = errors.New("durable queues are not enabled on this server")
+	ErrInvalidDurability  = errors.New("durable queues cannot be partitioned or use a drop overflow policy")
+	ErrCorruptRecord      = errors.New("corrupt write-ahead log record")
+)
+
+// queueConfigFile is the name of the file holding a durable queue's configuration.
+const queueConfigFile = "queue.json"
+
+// Journal record types.
+const (
+	recordEnqueue  byte = 1 // A message entered the queue; carries the whole message
+	recordDequeue  byte = 2 // A message was consumed without a lease, or expired
+	recordAck      byte = 3 // A leased message was acked or dead-lettered
+	recordSchedule byte = 4 // A delayed message was scheduled; carries its due time and the whole message
+)
+
+// Durability configures where and how durable queues keep their write-ahead logs.
+type Durability struct {
+	Dir string // Data directory; each durable queue gets a subdirectory
+	WALOptions
+	// RefillFailed, if set, is called when messages recovered from a queue's log
+	// cannot all be put back in the queue; remaining is how many were not. They stay
+	// in the log and are recovered again on the next start.
+	RefillFailed func(queue string, remaining int, err error)
+}
+
+// RecoveredQueue reports what Recover found for one durable queue.
+type RecoveredQueue struct {
+	Name      string // Name of the queue
+	Messages  int    // Messages put back in the queue
+	Scheduled int    // Delayed messages put back in the scheduler
+	Damaged   int    // Log segments that ended in a torn or corrupt record
+}
+
+// journal is the write-ahead log of one durable queue, together with the segment
+// each live message was last enqueued in, which decides when segments can go.
+type journal struct {
+	wal    *wal
+	mu     sync.Mutex        // Guards live and counts
+	live   map[string]uint64 // Segment of the last enqueue record of each message still in the queue
+	counts map[uint64]int    // Live messages by segment
+}
+
+// journaledQueue appends an enqueue record for every message before handing it to
+// the underlying queue.
+type journaledQueue struct {
+	storage
+	journal *journal
+}
+
+// Enqueue journals msg and adds it to the queue.
+func (q *journaledQueue) Enqueue(msg *Message) error {
+	existed, err := q.journal.enqueued(msg)
+	if err != nil {
+		return err
+	}
+	if err := q.storage.Enqueue(msg); err != nil {
+		q.journal.rejected(msg, existed)
+		return err
+	}
+	return nil
+}
+
+// EnqueueContext journals msg and adds it to the queue, waiting for space until ctx is done.
+func (q *journaledQueue) EnqueueContext(ctx context.Context, msg *Message) error {
+	existed, err := q.journal.enqueued(msg)
+	if err != nil {
+		return err
+	}
+	if err := q.storage.EnqueueContext(ctx, msg); err != nil {
+		q.journal.rejected(msg, existed)
+		return err
+	}
+	return nil
+}
+
+// enqueued appends the enqueue record of msg and reports whether the message was
+// already live, as it is when a leased message is redelivered or a delayed
+// message comes due.
+func (j *journal) enqueued(msg *Message) (bool, error) {
+	return j.track(msg, encodeMessage(msg))
+}
+
+// scheduled appends the schedule record of msg, due at at, and reports whether the
+// message was already live.
+func (j *journal) scheduled(msg *Message, at time.Time) (bool, error) {
+	return j.track(msg, encodeSchedule(msg, at))
+}
+
+// track appends record, which carries msg, and makes it the record that keeps msg
+// live. It reports whether the message was already live.
+func (j *journal) track(msg *Message, record []byte) (bool, error) {
+	seg, err := j.wal.append(record)
+	if err != nil {
+		return false, err
+	}
+	j.mu.Lock()
+	old, existed := j.live[msg.id]
+	if existed {
+		j.counts[old]--
+	}
+	j.live[msg.id] = seg
+	j.counts[seg]++
+	j.mu.Unlock()
+	if existed {
+		j.compact()
+	}
+	return existed, nil
+}
+
+// rejected undoes the enqueue record of a message the queue did not accept. A
+// message that was live before is left live: it is still in flight.
+func (j *journal) rejected(msg *Message, existed bool) {
+	if !existed {
+		j.removed(msg.id, recordDequeue)
+	}
+}
+
+// removed appends a record of the given type for a message that left the queue
+// for good and deletes segments that no longer hold live messages.
+func (j *journal) removed(id string, kind byte) {
+	j.mu.Lock()
+	seg, ok := j.live[id]
+	if !ok {
+		j.mu.Unlock()
+		return
+	}
+	delete(j.live, id)
+	j.counts[seg]--
+	j.mu.Unlock()
+	// A failed write leaves the message to be delivered again after a restart
+	_, _ = j.wal.append(appendString([]byte{kind}, id))
+	j.compact()
+}
+
+// compact deletes segments without live messages from the front of the log.
+func (j *journal) compact() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_ = j.wal.removeSegments(func(seq uint64) bool {
+		if j.counts[seq] > 0 {
+			return false
+		}
+		delete(j.counts, seq)
+		return true
+	})
+}
+
+// close syncs and closes the log.
+func (j *journal) close() error {
+	return j.wal.close()
+}
+
+// journaledMessage is a message recovered from a log.
+type journaledMessage struct {
+	msg *Message  // The message
+	at  time.Time // When a delayed message is due; zero for a message that was in the queue
+}
+
+// openJournal opens the log in dir and returns the messages it holds, in the order
+// they should be put back in the queue or scheduler.
+func openJournal(dir string, opts WALOptions) (*journal, []journaledMessage, walReplay, error) {
+	j := &journal{live: make(map[string]uint64), counts: make(map[uint64]int)}
+	msgs := make(map[string]journaledMessage)
+	order := make(map[string]int)
+	n := 0
+	w, stats, err := openWAL(dir, opts, func(seg uint64, record []byte) {
+		if len(record) == 0 {
+			return
+		}
+		switch record[0] {
+		case recordEnqueue, recordSchedule:
+			d := decoder{buf: record[1:]}
+			var at time.Time
+			if record[0] == recordSchedule {
+				at = d.time()
+			}
+			if d.err != nil {
+				return
+			}
+			msg, err := decodeMessage(d.buf)
+			if err != nil {
+				return
+			}
+			if old, ok := j.live[msg.id]; ok {
+				j.counts[old]--
+			}
+			msgs[msg.id] = journaledMessage{msg: msg, at: at}
+			order[msg.id] = n
+			n++
+			j.live[msg.id] = seg
+			j.counts[seg]++
+		case recordDequeue, recordAck:
+			d := decoder{buf: record[1:]}
+			id := d.string()
+			if seg, ok := j.live[id]; ok && d.err == nil {
+				delete(j.live, id)
+				delete(msgs, id)
+				j.counts[seg]--
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, stats, err
+	}
+	j.wal = w
+	out := make([]journaledMessage, 0, len(msgs))
+	for _, jm := range msgs {
+		out = append(out, jm)
+	}
+	sort.Slice(out, func(a, b int) bool { return order[out[a].msg.id] < order[out[b].msg.id] })
+	j.compact()
+	return j, out, stats, nil
+}
+
+// encodeMessage returns the enqueue record of msg.
+func encodeMessage(msg *Message) []byte {
+	return appendMessage([]byte{recordEnqueue}, msg)
+}
+
+// encodeSchedule returns the schedule record of msg, due at at.
+func encodeSchedule(msg *Message, at time.Time) []byte {
+	return appendMessage(appendTime([]byte{recordSchedule}, at), msg)
+}
+
+// appendMessage appends every field of msg.
+func appendMessage(b []byte, msg *Message) []byte {
+	b = appendString(b, msg.id)
+	b = appendString(b, string(msg.payload))
+	b = binary.AppendUvarint(b, uint64(len(msg.headers)))
+	for k, v := range msg.headers {
+		b = appendString(b, k)
+		b = appendString(b, v)
+	}
+	b = appendString(b, msg.contentType)
+	b = binary.AppendUvarint(b, uint64(msg.priority))
+	b = appendString(b, msg.key)
+	b = appendTime(b, msg.timestamp)
+	b = appendTime(b, msg.expiresAt)
+	b = binary.AppendUvarint(b, uint64(len(msg.attempts)))
+	for _, a := range msg.attempts {
+		b = appendTime(b, a.DeliveredAt)
+		b = appendString(b, a.Reason)
+	}
+	if dl := msg.deadLetter; dl != nil {
+		b = append(b, 1)
+		b = appendString(b, dl.Queue)
+		b = appendString(b, dl.Reason)
+		b = appendTime(b, dl.At)
+	} else {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// decodeMessage parses a message written by appendMessage.
+func decodeMessage(data []byte) (*Message, error) {
+	d := decoder{buf: data}
+	msg := &Message{id: d.string(), payload: []byte(d.string())}
+	if n := d.uvarint(); n > 0 && d.err == nil {
+		msg.headers = make(map[string]string, min(n, uint64(len(d.buf))))
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			k := d.string()
+			msg.headers[k] = d.string()
+		}
+	}
+	msg.contentType = d.string()
+	msg.priority = int(d.uvarint())
+	msg.key = d.string()
+	msg.timestamp = d.time()
+	msg.expiresAt = d.time()
+	for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+		at := d.time()
+		msg.attempts = append(msg.attempts, Attempt{DeliveredAt: at, Reason: d.string()})
+	}
+	if d.byte() == 1 {
+		msg.deadLetter = &DeadLetter{Queue: d.string(), Reason: d.string(), At: d.time()}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return msg, nil
+}
+
+// appendString appends a length-prefixed string.
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendTime appends a time as Unix nanoseconds, with 0 for the zero time.
+func appendTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(b, 0)
+	}
+	return binary.AppendVarint(b, t.UnixNano())
+}
+
+// decoder reads the fields written by encodeMessage. The first failure is kept in
+// err and turns every later read into a no-op.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = ErrCorruptRecord
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(len(d.buf)) {
+		d.err = ErrCorruptRecord
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func (d *decoder) time() time.Time {
+	if d.err != nil {
+		return time.Time{}
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = ErrCorruptRecord
+		return time.Time{}
+	}
+	d.buf = d.buf[n:]
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v)
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) == 0 {
+		d.err = ErrCorruptRecord
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+// EnableDurability lets queues be created with QueueConfig.Durable, keeping their
+// logs below d.Dir. It must be called before queues are created.
+func (r *Registry) EnableDurability(d Durability) error {
+	policy, err := ParseFsyncPolicy(string(d.Fsync))
+	if err != nil {
+		return err
+	}
+	d.Fsync = policy
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return err
+	}
+	r.durability = &d
+	return nil
+}
+
+// queueDir returns the directory of the durable queue called name.
+func (r *Registry) queueDir(name string) string {
+	return filepath.Join(r.durability.Dir, name)
+}
+
+// journal opens the log of a durable queue and saves the queue's configuration
+// next to it. It returns the messages the log holds, which restore puts back once
+// the queue is built. The caller holds r.mu and has validated cfg.
+func (r *Registry) journal(name string, cfg QueueConfig) (*journal, []journaledMessage, RecoveredQueue, error) {
+	report := RecoveredQueue{Name: name}
+	dir := r.queueDir(name)
+	j, msgs, stats, err := openJournal(dir, r.durability.WALOptions)
+	if err != nil {
+		return nil, nil, report, err
+	}
+	if err := saveQueueConfig(dir, cfg); err != nil {
+		j.close()
+		return nil, nil, report, err
+	}
+	report.Damaged = stats.damaged
+	return j, msgs, report, nil
+}
+
+// restore puts the messages recovered from the log of a durable queue back: ready
+// messages into the queue, in order, and delayed ones into its scheduler.
+func (r *Registry) restore(nq *NamedQueue, msgs []journaledMessage) {
+	var backlog []*Message
+	for _, jm := range msgs {
+		if !jm.at.IsZero() {
+			if err := nq.scheduler.Schedule(jm.msg, jm.at); err != nil {
+				r.refillFailed(nq, 1, err)
+				continue
+			}
+			nq.recovered.Scheduled++
+			continue
+		}
+		nq.recovered.Messages++
+		if backlog != nil {
+			backlog = append(backlog, jm.msg)
+		} else if err := nq.base.Enqueue(jm.msg); err != nil {
+			// Messages that were in flight can make the backlog exceed the capacity;
+			// the rest goes in as consumers make room
+			backlog = append(backlog, jm.msg)
+		}
+	}
+	if backlog != nil {
+		go r.refill(nq, backlog)
+	}
+}
+
+// saveQueueConfig atomically replaces the configuration file in a durable queue's directory.
+func saveQueueConfig(dir string, cfg QueueConfig) error {
+	data, _ := json.MarshalIndent(cfg, "", "  ")
+	tmp := filepath.Join(dir, queueConfigFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, queueConfigFile))
+}
+
+// refill enqueues msgs into a recovered queue in order, waiting for room for each,
+// until the queue is deleted or the registry closed. Other failures are reported
+// to Durability.RefillFailed.
+func (r *Registry) refill(nq *NamedQueue, msgs []*Message) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-nq.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for i, msg := range msgs {
+		if err := nq.base.EnqueueContext(ctx, msg); err != nil {
+			if ctx.Err() == nil {
+				r.refillFailed(nq, len(msgs)-i, err)
+			}
+			return
+		}
+	}
+}
+
+// refillFailed reports messages recovered for nq that could not be put back.
+func (r *Registry) refillFailed(nq *NamedQueue, remaining int, err error) {
+	if r.durability.RefillFailed != nil {
+		r.durability.RefillFailed(nq.name, remaining, err)
+	}
+}
+
+// Recover recreates every durable queue found in the data directory, with the
+// configuration it was created with and the messages its log still holds, and
+// reports what it found. Queues that already exist are left alone. It must be
+// called after EnableDurability and before clients connect.
+func (r *Registry) Recover() ([]RecoveredQueue, error) {
+	if r.durability == nil {
+		return nil, ErrDurabilityDisabled
+	}
+	entries, err := os.ReadDir(r.durability.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var reports []RecoveredQueue
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		data, err := os.ReadFile(filepath.Join(r.queueDir(name), queueConfigFile))
+		if err != nil {
+			continue // Not a queue directory
+		}
+		var cfg QueueConfig
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return reports, err
+		}
+		cfg.Durable = true
+		nq, _, err := r.recreate(name, cfg)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, nq.recovered)
+	}
+	return reports, nil
+}
+
+// Close syncs and closes the logs of all durable queues. The registry must not be
+// used afterwards.
+func (r *Registry) Close() error {
+	r.cancel()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var firstErr error
+	for _, nq := range r.queues {
+		if nq.journal != nil {
+			if err := nq.journal.close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,241 @@
+// journal_test.go - Tests for durable queues and crash recovery.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// durableRegistry returns a registry keeping durable queues in dir.
+func durableRegistry(t *testing.T, dir string) *Registry {
+	t.Helper()
+	r := NewRegistry(nil)
+	if err := r.EnableDurability(Durability{Dir: dir, WALOptions: WALOptions{Fsync: FsyncAlways, SegmentSize: 256}}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// restart closes r and recovers its durable queues into a new registry.
+func restart(t *testing.T, r *Registry, dir string) (*Registry, []RecoveredQueue) {
+	t.Helper()
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r = durableRegistry(t, dir)
+	reports, err := r.Recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, reports
+}
+
+func TestDurableQueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	r := durableRegistry(t, dir)
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 8, Durable: true, MaxDeliveryAttempts: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"consumed", "acked", "in-flight", "waiting"} {
+		msg := textMessage(s)
+		msg.SetHeader("step", s)
+		if err := jobs.Enqueue(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := jobs.Dequeue(); err != nil {
+		t.Fatal(err)
+	}
+	acked, err := jobs.Leases().Dequeue(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Leases().Ack(acked.Message.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jobs.Leases().Dequeue(0); err != nil {
+		t.Fatal(err)
+	}
+
+	r, reports := restart(t, r, dir)
+	var report RecoveredQueue
+	for _, rq := range reports {
+		if rq.Name == "jobs" {
+			report = rq
+		}
+	}
+	if report.Messages != 2 || report.Damaged != 0 {
+		t.Fatalf("recovered %+v, want 2 messages", report)
+	}
+	jobs, err = r.Get("jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jobs.Durable() || jobs.Config().MaxDeliveryAttempts != 3 || jobs.DeadLetterQueue() == nil {
+		t.Fatalf("recovered queue lost its configuration: %+v", jobs.Config())
+	}
+	// The unacked message is delivered again, ahead of the one that was waiting behind it
+	for _, want := range []string{"in-flight", "waiting"} {
+		msg, err := jobs.Dequeue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.GetPayload()) != want || msg.GetHeaders()["step"] != want {
+			t.Fatalf("Dequeue = %q %v, want %q", msg.GetPayload(), msg.GetHeaders(), want)
+		}
+	}
+	if _, err := jobs.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue = %v, want ErrEmpty", err)
+	}
+
+	// Everything was consumed, so nothing comes back after another restart
+	r, _ = restart(t, r, dir)
+	defer r.Close()
+	jobs, err = r.Get("jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs.Len() != 0 {
+		t.Fatalf("Len after second restart = %d, want 0", jobs.Len())
+	}
+}
+
+func TestDurableQueueRecoversFromTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	r := durableRegistry(t, dir)
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 8, Durable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"a", "b"} {
+		if err := jobs.Enqueue(textMessage(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash half way through journaling a third message
+	segments, err := listSegments(filepath.Join(dir, "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(segmentPath(filepath.Join(dir, "jobs"), segments[len(segments)-1]), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := encodeMessage(textMessage("c"))
+	if _, err := f.Write(append([]byte{byte(len(frame)), 0, 0, 0, 0, 0, 0, 0}, frame[:len(frame)/2]...)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r = durableRegistry(t, dir)
+	defer r.Close()
+	reports, err := r.Recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].Messages != 2 || reports[0].Damaged != 1 {
+		t.Fatalf("Recover() = %+v, want 2 messages and 1 damaged segment", reports)
+	}
+}
+
+func TestDurableQueueCompactsLog(t *testing.T) {
+	dir := t.TempDir()
+	r := durableRegistry(t, dir)
+	defer r.Close()
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 64, Durable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if err := jobs.Enqueue(textMessage("payload")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := jobs.Dequeue(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	segments, err := listSegments(filepath.Join(dir, "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) > 2 {
+		t.Fatalf("%d segments left after consuming everything", len(segments))
+	}
+}
+
+func TestDurableQueueValidation(t *testing.T) {
+	if _, err := NewRegistry(nil).Create("jobs", QueueConfig{Capacity: 4, Durable: true}); !errors.Is(err, ErrDurabilityDisabled) {
+		t.Fatalf("Create without durability = %v, want ErrDurabilityDisabled", err)
+	}
+	r := durableRegistry(t, t.TempDir())
+	defer r.Close()
+	if _, err := r.Create("keyed", QueueConfig{Capacity: 4, Durable: true, Partitions: 2}); !errors.Is(err, ErrInvalidDurability) {
+		t.Fatalf("Create partitioned = %v, want ErrInvalidDurability", err)
+	}
+	if _, err := r.Create("lossy", QueueConfig{Capacity: 4, Durable: true, Overflow: OverflowDropOldest}); !errors.Is(err, ErrInvalidDurability) {
+		t.Fatalf("Create with drop_oldest = %v, want ErrInvalidDurability", err)
+	}
+	if _, err := r.Create("jobs", QueueConfig{Capacity: 4, Durable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete("jobs"); err != nil {
+		t.Fatal(err)
+	}
+	if reports, err := r.Recover(); err != nil || len(reports) != 0 {
+		t.Fatalf("Recover after Delete = %+v, %v", reports, err)
+	}
+}
+
+func TestDurableQueueKeepsScheduledMessages(t *testing.T) {
+	dir := t.TempDir()
+	r := durableRegistry(t, dir)
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 8, Durable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Scheduler().Schedule(textMessage("soon"), time.Now().Add(20*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Scheduler().Schedule(textMessage("later"), time.Now().Add(300*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	// "soon" comes due and is consumed before the crash; "later" is still held
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if msg, err := jobs.DequeueContext(ctx); err != nil || string(msg.GetPayload()) != "soon" {
+		t.Fatalf("DequeueContext = %v, %v", msg, err)
+	}
+
+	r, reports := restart(t, r, dir)
+	if len(reports) != 1 || reports[0].Messages != 0 || reports[0].Scheduled != 1 {
+		t.Fatalf("Recover() = %+v, want 1 scheduled message", reports)
+	}
+	jobs, err = r.Get("jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobs.Len() != 0 || jobs.Scheduler().Len() != 1 {
+		t.Fatalf("after restart Len = %d, scheduled = %d", jobs.Len(), jobs.Scheduler().Len())
+	}
+	msg, err := jobs.DequeueContext(ctx)
+	if err != nil || string(msg.GetPayload()) != "later" {
+		t.Fatalf("re-armed message: %v, %v", msg, err)
+	}
+
+	// Consumed once due, it does not come back after another restart
+	r, reports = restart(t, r, dir)
+	defer r.Close()
+	if len(reports) != 1 || reports[0].Messages != 0 || reports[0].Scheduled != 0 {
+		t.Fatalf("second Recover() = %+v, want nothing", reports)
+	}
+}
@@ -0,0 +1,283 @@
+// lease.go - Lease-based (at-least-once) consumption.
+//
+// This file defines Leases, which hands out messages from a queue under a
+// visibility timeout. A leased message stays in flight until the consumer acks
+// it, which deletes it for good, or nacks it or lets the lease expire, which
+// puts it back on the queue with the failed attempt recorded in its history.
+// Once a message has failed a queue's maximum number of delivery attempts it is
+// moved to the queue's dead-letter queue instead.
+
+package mq
+
+import (
+	"context" // For blocking lease operations
+	"errors"  // For lease errors
+	"sort"    // For listing in-flight messages in lease order
+	"sync"    // For guarding the in-flight table
+	"time"    // For visibility timeouts
+)
+
+// DefaultVisibilityTimeout is how long a leased message stays in flight when
+// neither the queue nor the consumer chooses a timeout.
+const DefaultVisibilityTimeout = 30 * time.Second
+
+// redeliveryRetry is how long to wait before retrying a redelivery that found the queue full.
+const redeliveryRetry = 100 * time.Millisecond
+
+// ReasonMaxAttempts is the dead-letter reason for messages that failed too many deliveries.
+const ReasonMaxAttempts = "max delivery attempts exceeded"
+
+// Reasons recorded in a message's attempt history.
+const (
+	ReasonNack         = "nack"
+	ReasonLeaseExpired = "lease_expired"
+)
+
+// ErrLeaseNotFound is returned by Ack and Nack when the message is not in flight,
+// either because it was never leased, was already acked or nacked, or its lease expired.
+var ErrLeaseNotFound = errors.New("no in-flight message with that id")
+
+// Lease is a message handed to a consumer, together with when its lease expires.
+type Lease struct {
+	Message  *Message  // Leased message
+	Deadline time.Time // When the message is redelivered unless acked first
+}
+
+// leaseEntry tracks one in-flight message.
+type leaseEntry struct {
+	msg         *Message    // The leased message
+	deliveredAt time.Time   // When the lease was taken
+	timer       *time.Timer // Fires when the lease expires
+	reason      string      // Set once the delivery has failed and only the requeue is pending
+}
+
+// DeadLetterObserver is implemented by queue wrappers (such as an instrumented
+// queue) that want to count messages moved to a dead-letter queue. reason is the
+// failure of the message's last delivery attempt.
+type DeadLetterObserver interface {
+	ObserveDeadLetter(reason string)
+}
+
+// leaseTracker is implemented by queues that need to know which of their messages
+// are in flight and put failed messages back themselves (PartitionedQueue, which
+// keeps the messages of a key in order).
+type leaseTracker interface {
+	leased(msg *Message)        // msg was leased
+	settled(msg *Message)       // The lease on msg ended: it was acked, requeued or dead-lettered
+	requeue(msg *Message) error // Put msg back for redelivery
+}
+
+// Leases hands out messages from a queue under a visibility timeout and
+// redelivers those that are not acked in time. It is safe for concurrent use.
+type Leases struct {
+	queue       Queue                  // Queue messages are leased from and redelivered to
+	timeout     time.Duration          // Default visibility timeout
+	source      string                 // Name of the queue, recorded on dead-lettered messages
+	maxAttempts int                    // Failed deliveries before dead-lettering (0 disables)
+	deadLetter  Queue                  // Where messages go after maxAttempts failures
+	mu          sync.Mutex             // Guards leases and closed
+	leases      map[string]*leaseEntry // In-flight messages by message ID
+	closed      bool                   // Set by Close; no further redeliveries happen
+	onSettle    func(id string)        // Called when a message leaves the queue for good (acked or dead-lettered); set before use
+	tracker     leaseTracker           // Told about leases and requeues messages, or nil; set before use
+}
+
+// NewLeases creates a lease tracker for q. A timeout of zero selects DefaultVisibilityTimeout.
+func NewLeases(q Queue, timeout time.Duration) *Leases {
+	if timeout <= 0 {
+		timeout = DefaultVisibilityTimeout
+	}
+	return &Leases{
+		queue:   q,
+		timeout: timeout,
+		leases:  make(map[string]*leaseEntry),
+	}
+}
+
+// setDeadLetter makes messages of the queue named source move to dlq once they
+// have failed maxAttempts deliveries. It must be called before the tracker is used.
+func (l *Leases) setDeadLetter(source string, maxAttempts int, dlq Queue) {
+	l.source = source
+	l.maxAttempts = maxAttempts
+	l.deadLetter = dlq
+}
+
+// Timeout returns the default visibility timeout.
+func (l *Leases) Timeout() time.Duration {
+	return l.timeout
+}
+
+// Dequeue removes the next message from the queue and leases it for visibility
+// (the default timeout if zero). Returns ErrEmpty if the queue is empty.
+func (l *Leases) Dequeue(visibility time.Duration) (*Lease, error) {
+	msg, err := l.queue.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+	return l.Hold(msg, visibility), nil
+}
+
+// DequeueContext is like Dequeue but waits for a message until ctx is done.
+func (l *Leases) DequeueContext(ctx context.Context, visibility time.Duration) (*Lease, error) {
+	msg, err := l.queue.DequeueContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.Hold(msg, visibility), nil
+}
+
+// Hold leases msg, which the caller has already taken from the queue (for example
+// through a PartitionConsumer), for visibility (the default timeout if zero): it
+// records msg as in flight and arms its expiry timer.
+func (l *Leases) Hold(msg *Message, visibility time.Duration) *Lease {
+	if visibility <= 0 {
+		visibility = l.timeout
+	}
+	now := time.Now()
+	e := &leaseEntry{msg: msg, deliveredAt: now}
+	id := msg.GetID()
+	l.mu.Lock()
+	// The timer is created under the lock, so expire always sees e.timer set
+	if !l.closed {
+		l.leases[id] = e
+		e.timer = time.AfterFunc(visibility, func() { l.expire(id, e) })
+		if l.tracker != nil {
+			l.tracker.leased(msg)
+		}
+	}
+	l.mu.Unlock()
+	return &Lease{Message: msg, Deadline: now.Add(visibility)}
+}
+
+// Ack acknowledges successful processing of an in-flight message, deleting it.
+func (l *Leases) Ack(id string) error {
+	l.mu.Lock()
+	e, ok := l.leases[id]
+	if !ok {
+		l.mu.Unlock()
+		return ErrLeaseNotFound
+	}
+	delete(l.leases, id)
+	e.timer.Stop()
+	l.mu.Unlock()
+	if l.onSettle != nil {
+		l.onSettle(id)
+	}
+	if l.tracker != nil {
+		l.tracker.settled(e.msg)
+	}
+	return nil
+}
+
+// Nack reports failed processing of an in-flight message, which is redelivered at once.
+func (l *Leases) Nack(id string) error {
+	l.mu.Lock()
+	e, ok := l.leases[id]
+	if ok {
+		delete(l.leases, id)
+		e.timer.Stop()
+	}
+	l.mu.Unlock()
+	if !ok {
+		return ErrLeaseNotFound
+	}
+	l.redeliver(id, e, ReasonNack)
+	return nil
+}
+
+// expire is called by the lease timer and redelivers the message if it is still in flight.
+func (l *Leases) expire(id string, e *leaseEntry) {
+	l.mu.Lock()
+	if l.leases[id] != e {
+		// Acked, nacked or closed in the meantime
+		l.mu.Unlock()
+		return
+	}
+	delete(l.leases, id)
+	l.mu.Unlock()
+	reason := e.reason
+	if reason == "" {
+		reason = ReasonLeaseExpired
+	}
+	l.redeliver(id, e, reason)
+}
+
+// redeliver puts the message back on the queue with the failed attempt recorded,
+// or on the dead-letter queue once it has failed too many deliveries. If the target
+// queue is full the message stays in flight and the requeue is retried shortly.
+func (l *Leases) redeliver(id string, e *leaseEntry, reason string) {
+	if e.reason == "" {
+		// First failure of this delivery: record the attempt once, however many requeue retries follow
+		e.msg = e.msg.redelivery(Attempt{DeliveredAt: e.deliveredAt, Reason: reason})
+		e.reason = reason
+	}
+	if l.deadLetter != nil && len(e.msg.GetAttempts()) >= l.maxAttempts {
+		dead := e.msg.deadLettered(DeadLetter{Queue: l.source, Reason: ReasonMaxAttempts, At: time.Now()})
+		if err := l.deadLetter.Enqueue(dead); err == nil {
+			if l.onSettle != nil {
+				l.onSettle(id)
+			}
+			if l.tracker != nil {
+				l.tracker.settled(e.msg)
+			}
+			if o, ok := l.queue.(DeadLetterObserver); ok {
+				o.ObserveDeadLetter(e.reason)
+			}
+			return
+		}
+	} else if err := l.requeue(e.msg); err == nil {
+		if l.tracker != nil {
+			l.tracker.settled(e.msg)
+		}
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.leases[id] = e
+	e.timer = time.AfterFunc(redeliveryRetry, func() { l.expire(id, e) })
+}
+
+// requeue puts a failed message back on the queue for redelivery.
+func (l *Leases) requeue(msg *Message) error {
+	if l.tracker != nil {
+		return l.tracker.requeue(msg)
+	}
+	return l.queue.Enqueue(msg)
+}
+
+// InFlight returns the number of messages currently leased and not yet acked.
+func (l *Leases) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.leases)
+}
+
+// leased returns the messages currently in flight, oldest lease first.
+func (l *Leases) leased() []*Message {
+	l.mu.Lock()
+	entries := make([]*leaseEntry, 0, len(l.leases))
+	for _, e := range l.leases {
+		entries = append(entries, e)
+	}
+	l.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].deliveredAt.Before(entries[j].deliveredAt) })
+	msgs := make([]*Message, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.msg
+	}
+	return msgs
+}
+
+// Close stops all lease timers and forgets the in-flight messages.
+func (l *Leases) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for id, e := range l.leases {
+		e.timer.Stop()
+		delete(l.leases, id)
+	}
+}
@@ -0,0 +1,100 @@
+// lease_test.go - Tests for lease-based consumption.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLeasesAckNack(t *testing.T) {
+	q := NewMessageQueue(4)
+	l := NewLeases(q, time.Minute)
+	if err := q.Enqueue(textMessage("job")); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := l.Dequeue(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := first.Message.GetDeliveryAttempt(); got != 1 {
+		t.Fatalf("first delivery attempt = %d, want 1", got)
+	}
+	if l.InFlight() != 1 || q.Len() != 0 {
+		t.Fatalf("InFlight = %d, Len = %d after lease", l.InFlight(), q.Len())
+	}
+
+	// A nack puts the message straight back with the failure recorded
+	id := first.Message.GetID()
+	if err := l.Nack(id); err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.Dequeue(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Message.GetID() != id || second.Message.GetDeliveryAttempt() != 2 {
+		t.Fatalf("redelivery = %s attempt %d", second.Message.GetID(), second.Message.GetDeliveryAttempt())
+	}
+	if a := second.Message.GetAttempts(); len(a) != 1 || a[0].Reason != ReasonNack {
+		t.Fatalf("attempt history = %+v", a)
+	}
+	if first.Message.GetDeliveryAttempt() != 1 {
+		t.Fatal("redelivery modified the message held by the first consumer")
+	}
+
+	// An ack deletes it for good
+	if err := l.Ack(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Ack(id); !errors.Is(err, ErrLeaseNotFound) {
+		t.Fatalf("second Ack = %v, want ErrLeaseNotFound", err)
+	}
+	if err := l.Nack("unknown"); !errors.Is(err, ErrLeaseNotFound) {
+		t.Fatalf("Nack of unknown id = %v, want ErrLeaseNotFound", err)
+	}
+	if l.InFlight() != 0 || q.Len() != 0 {
+		t.Fatalf("InFlight = %d, Len = %d after ack", l.InFlight(), q.Len())
+	}
+}
+
+func TestLeasesExpiryRedelivers(t *testing.T) {
+	q := NewMessageQueue(1)
+	l := NewLeases(q, time.Minute)
+	if err := q.Enqueue(textMessage("job")); err != nil {
+		t.Fatal(err)
+	}
+	lease, err := l.Dequeue(20 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fill the queue so the first requeue attempt finds no room
+	if err := q.Enqueue(textMessage("blocker")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := q.Dequeue(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	again, err := l.DequeueContext(ctx, 0)
+	if err != nil {
+		t.Fatalf("expired lease was not redelivered: %v", err)
+	}
+	if again.Message.GetID() != lease.Message.GetID() {
+		t.Fatalf("redelivered %s, want %s", again.Message.GetID(), lease.Message.GetID())
+	}
+	// Requeue retries must not be recorded as extra attempts
+	if a := again.Message.GetAttempts(); len(a) != 1 || a[0].Reason != ReasonLeaseExpired {
+		t.Fatalf("attempt history = %+v", a)
+	}
+	l.Close()
+	if l.InFlight() != 0 {
+		t.Fatalf("InFlight = %d after Close", l.InFlight())
+	}
+}
@@ -0,0 +1,347 @@
+// log.go - Log-structured stream with consumer groups.
+//
+// This file defines Log, an append-only sequence of messages stored in fixed-size
+// segments, in which every message gets a monotonically increasing offset. Reading
+// does not remove anything: each consumer group keeps its own position, so several
+// groups read the same stream independently, while members of one group share its
+// position and so split the group's messages between them. Groups commit the
+// offset up to which they have processed the stream; the distance from there to
+// the end of the log is the group's lag. Messages handed out but not committed are
+// handed out again once the group is rewound to its committed offset, so a member
+// that restarts without committing does not lose them. Old segments are discarded
+// once the log holds more than its retention.
+
+package mq
+
+import (
+	"context" // For blocking fetches
+	"errors"  // For log errors
+	"io"      // For closing log observers
+	"sort"    // For listing logs and groups in a stable order
+	"sync"    // For guarding segments and groups
+)
+
+// Defaults for LogConfig fields left zero.
+const (
+	DefaultSegmentSize  = 4096    // Messages per segment
+	DefaultLogRetention = 1000000 // Messages retained
+)
+
+// MaxFetch is the largest number of messages one Fetch hands out, which bounds how
+// long it holds the log's lock.
+const MaxFetch = 1000
+
+// Log errors.
+var (
+	ErrLogExists     = errors.New("log already exists")
+	ErrLogNotFound   = errors.New("log not found")
+	ErrInvalidOffset = errors.New("offset is beyond the end of the log")
+)
+
+// LogObserver is implemented by metrics collectors that want to track how far
+// each consumer group of a log lags behind its end. It is called whenever the
+// lag of a group changes.
+type LogObserver interface {
+	ObserveLag(group string, lag uint64)
+}
+
+// LogConfig describes how a log is built.
+type LogConfig struct {
+	SegmentSize int    // Messages per segment (zero selects DefaultSegmentSize)
+	Retention   uint64 // Minimum number of most recent messages retained (zero selects DefaultLogRetention)
+}
+
+// Record is a message read from a log, together with its offset.
+type Record struct {
+	Offset  uint64   // Position of the message in the log
+	Message *Message // The message
+}
+
+// logSegment holds consecutive messages starting at offset base.
+type logSegment struct {
+	base    uint64     // Offset of the first message
+	records []*Message // Messages in offset order; full at cap(records)
+}
+
+// consumerGroup is the read state of one consumer group.
+type consumerGroup struct {
+	committed uint64 // Offset of the next message the group has not finished processing
+	position  uint64 // Offset of the next message handed to a member
+}
+
+// GroupInfo is a point-in-time summary of a consumer group.
+type GroupInfo struct {
+	Name      string // Name of the group
+	Committed uint64 // Committed offset: the group has processed everything before it
+	Position  uint64 // Offset of the next message handed to a member
+	Lag       uint64 // Messages between the committed offset and the end of the log
+}
+
+// LogInfo is a point-in-time summary of a log.
+type LogInfo struct {
+	Name   string      // Registry name of the log
+	Start  uint64      // Offset of the oldest retained message
+	End    uint64      // Offset the next appended message will get
+	Groups []GroupInfo // Consumer groups, sorted by name
+}
+
+// Log is an append-only, segmented stream of messages read by consumer groups.
+// It is safe for concurrent use.
+type Log struct {
+	name        string                    // Registry name of the log
+	segmentSize int                       // Messages per segment
+	retention   uint64                    // Minimum number of messages retained
+	observer    LogObserver               // Notified of lag changes, or nil
+	appended    notifier                  // Wakes members parked in FetchContext
+	mu          sync.Mutex                // Guards the fields below
+	segments    []*logSegment             // Retained segments, oldest first
+	end         uint64                    // Offset of the next appended message
+	groups      map[string]*consumerGroup // Read state by group name
+}
+
+// NewLog creates an empty log. observer may be nil.
+func NewLog(name string, cfg LogConfig, observer LogObserver) *Log {
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = DefaultSegmentSize
+	}
+	if cfg.Retention == 0 {
+		cfg.Retention = DefaultLogRetention
+	}
+	return &Log{
+		name:        name,
+		segmentSize: cfg.SegmentSize,
+		retention:   cfg.Retention,
+		observer:    observer,
+		groups:      make(map[string]*consumerGroup),
+	}
+}
+
+// Name returns the name the log is registered under.
+func (l *Log) Name() string {
+	return l.name
+}
+
+// start returns the offset of the oldest retained message. The caller holds l.mu.
+func (l *Log) start() uint64 {
+	if len(l.segments) == 0 {
+		return l.end
+	}
+	return l.segments[0].base
+}
+
+// Append adds msg to the end of the log and returns its offset.
+func (l *Log) Append(msg *Message) uint64 {
+	l.mu.Lock()
+	n := len(l.segments)
+	if n == 0 || len(l.segments[n-1].records) == l.segmentSize {
+		l.segments = append(l.segments, &logSegment{base: l.end, records: make([]*Message, 0, l.segmentSize)})
+		// Drop whole segments the retention no longer needs, counting the message being appended
+		for len(l.segments) > 1 && l.end+1-l.segments[1].base >= l.retention {
+			l.segments[0] = nil
+			l.segments = l.segments[1:]
+		}
+		n = len(l.segments)
+	}
+	seg := l.segments[n-1]
+	seg.records = append(seg.records, msg)
+	offset := l.end
+	l.end++
+	for name, g := range l.groups {
+		l.observe(name, g)
+	}
+	l.mu.Unlock()
+	l.appended.broadcast()
+	return offset
+}
+
+// group returns the read state of the named group, creating it at the start of
+// the log if needed. The caller holds l.mu.
+func (l *Log) group(name string) *consumerGroup {
+	g := l.groups[name]
+	if g == nil {
+		start := l.start()
+		g = &consumerGroup{committed: start, position: start}
+		l.groups[name] = g
+		l.observe(name, g)
+	}
+	return g
+}
+
+// lag returns how many messages lie between the group's committed offset and the
+// end of the log, not counting messages that are no longer retained. The caller holds l.mu.
+func (l *Log) lag(g *consumerGroup) uint64 {
+	from := g.committed
+	if start := l.start(); from < start {
+		from = start
+	}
+	return l.end - from
+}
+
+// observe reports the lag of a group. The caller holds l.mu.
+func (l *Log) observe(name string, g *consumerGroup) {
+	if l.observer != nil {
+		l.observer.ObserveLag(name, l.lag(g))
+	}
+}
+
+// Fetch hands up to max messages (at least one, at most MaxFetch) from the group's
+// position to a member of the group and advances the position past them. A new
+// group starts at the oldest retained message. It returns no records if the group
+// has read everything.
+func (l *Log) Fetch(group string, max int) []Record {
+	if max < 1 {
+		max = 1
+	} else if max > MaxFetch {
+		max = MaxFetch
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	g := l.group(group)
+	if start := l.start(); g.position < start {
+		g.position = start // The group fell behind the retention
+	}
+	var out []Record
+	for len(out) < max && g.position < l.end {
+		i := sort.Search(len(l.segments), func(i int) bool { return l.segments[i].base > g.position }) - 1
+		seg := l.segments[i]
+		for j := int(g.position - seg.base); j < len(seg.records) && len(out) < max; j++ {
+			out = append(out, Record{Offset: g.position, Message: seg.records[j]})
+			g.position++
+		}
+	}
+	return out
+}
+
+// FetchContext is like Fetch but waits until at least one message is available
+// or ctx is done, in which case it returns ctx.Err().
+func (l *Log) FetchContext(ctx context.Context, group string, max int) ([]Record, error) {
+	for {
+		if out := l.Fetch(group, max); len(out) > 0 {
+			return out, nil
+		}
+		ch := l.appended.prepare()
+		// Re-check after registering so a message appended in between is not missed
+		if out := l.Fetch(group, max); len(out) > 0 {
+			l.appended.done()
+			return out, nil
+		}
+		select {
+		case <-ch:
+			l.appended.done()
+		case <-ctx.Done():
+			l.appended.done()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Commit records that the group has processed every message before offset. Members
+// that fetch afterwards never get those messages again. Returns ErrInvalidOffset
+// if offset is past the end of the log.
+func (l *Log) Commit(group string, offset uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if offset > l.end {
+		return ErrInvalidOffset
+	}
+	g := l.group(group)
+	g.committed = offset
+	if g.position < offset {
+		g.position = offset
+	}
+	l.observe(group, g)
+	return nil
+}
+
+// Rewind moves the group's position back to its committed offset, so the messages
+// handed out but not committed are fetched again, and returns the new position.
+// Members call it when they restart or the group's members change.
+func (l *Log) Rewind(group string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	g := l.group(group)
+	g.position = g.committed
+	return g.position
+}
+
+// Info returns a summary of the log and its consumer groups.
+func (l *Log) Info() LogInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	info := LogInfo{Name: l.name, Start: l.start(), End: l.end, Groups: make([]GroupInfo, 0, len(l.groups))}
+	for name, g := range l.groups {
+		info.Groups = append(info.Groups, GroupInfo{Name: name, Committed: g.committed, Position: g.position, Lag: l.lag(g)})
+	}
+	sort.Slice(info.Groups, func(i, j int) bool { return info.Groups[i].Name < info.Groups[j].Name })
+	return info
+}
+
+// CreateLog builds a new log with the given configuration and registers it under
+// name. Logs have their own namespace, separate from queues. Returns ErrLogExists
+// if a log with that name is already registered.
+func (r *Registry) CreateLog(name string, cfg LogConfig) (*Log, error) {
+	if !ValidQueueName(name) {
+		return nil, ErrInvalidQueueName
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.logs[name]; ok {
+		return nil, ErrLogExists
+	}
+	var observer LogObserver
+	if r.observeLog != nil {
+		observer = r.observeLog(name)
+	}
+	l := NewLog(name, cfg, observer)
+	r.logs[name] = l
+	return l, nil
+}
+
+// GetLog returns the log registered under name, or ErrLogNotFound.
+func (r *Registry) GetLog(name string) (*Log, error) {
+	r.mu.RLock()
+	l, ok := r.logs[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrLogNotFound
+	}
+	return l, nil
+}
+
+// DeleteLog unregisters the named log and discards its messages and groups.
+// If the log's observer implements io.Closer it is closed.
+func (r *Registry) DeleteLog(name string) error {
+	r.mu.Lock()
+	l, ok := r.logs[name]
+	delete(r.logs, name)
+	r.mu.Unlock()
+	if !ok {
+		return ErrLogNotFound
+	}
+	if c, ok := l.observer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Logs returns a summary of every registered log, sorted by name.
+func (r *Registry) Logs() []LogInfo {
+	r.mu.RLock()
+	logs := make([]*Log, 0, len(r.logs))
+	for _, l := range r.logs {
+		logs = append(logs, l)
+	}
+	r.mu.RUnlock()
+	infos := make([]LogInfo, 0, len(logs))
+	for _, l := range logs {
+		infos = append(infos, l.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// ObserveLogs sets the function that creates the observer of each new log, for
+// example to export consumer group lag. It must be called before logs are created.
+func (r *Registry) ObserveLogs(fn func(name string) LogObserver) {
+	r.observeLog = fn
+}
@@ -0,0 +1,112 @@
+// log_test.go - Tests for logs and consumer groups.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// lagObserver records the last lag reported for each group.
+type lagObserver map[string]uint64
+
+func (o lagObserver) ObserveLag(group string, lag uint64) {
+	o[group] = lag
+}
+
+func TestLogConsumerGroups(t *testing.T) {
+	lags := lagObserver{}
+	l := NewLog("events", LogConfig{SegmentSize: 2}, lags)
+	for i, s := range []string{"e0", "e1", "e2", "e3", "e4"} {
+		if off := l.Append(textMessage(s)); off != uint64(i) {
+			t.Fatalf("Append(%q) offset = %d, want %d", s, off, i)
+		}
+	}
+
+	// Members of one group share its position; another group reads everything again
+	first := l.Fetch("billing", 3)
+	second := l.Fetch("billing", 3)
+	if len(first) != 3 || len(second) != 2 || first[2].Offset != 2 || second[0].Offset != 3 {
+		t.Fatalf("billing fetched %v then %v", first, second)
+	}
+	if got := l.Fetch("billing", 3); len(got) != 0 {
+		t.Fatalf("billing fetched %d records past the end", len(got))
+	}
+	audit := l.Fetch("audit", 10)
+	if len(audit) != 5 || string(audit[4].Message.GetPayload()) != "e4" {
+		t.Fatalf("audit fetched %v", audit)
+	}
+
+	if err := l.Commit("billing", 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Commit("billing", 6); !errors.Is(err, ErrInvalidOffset) {
+		t.Fatalf("Commit past the end = %v, want ErrInvalidOffset", err)
+	}
+	if lags["billing"] != 1 || lags["audit"] != 5 {
+		t.Fatalf("lags = %v", lags)
+	}
+	l.Append(textMessage("e5"))
+	if lags["billing"] != 2 {
+		t.Fatalf("billing lag after append = %d, want 2", lags["billing"])
+	}
+	info := l.Info()
+	if info.End != 6 || len(info.Groups) != 2 || info.Groups[1].Name != "billing" || info.Groups[1].Committed != 4 {
+		t.Fatalf("Info() = %+v", info)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		l.Append(textMessage("e6"))
+	}()
+	got, err := l.FetchContext(ctx, "billing", 10)
+	if err != nil || len(got) != 1 || got[0].Offset != 5 {
+		t.Fatalf("FetchContext = %v, %v", got, err)
+	}
+}
+
+func TestLogRetention(t *testing.T) {
+	l := NewLog("events", LogConfig{SegmentSize: 2, Retention: 3}, nil)
+	for i := 0; i < 10; i++ {
+		l.Append(textMessage("e"))
+	}
+	info := l.Info()
+	// Whole segments are dropped once the rest still covers the retention
+	if info.Start != 6 || info.End != 10 {
+		t.Fatalf("retained offsets [%d, %d), want [6, 10)", info.Start, info.End)
+	}
+	// A group behind the retention resumes at the oldest retained message
+	if got := l.Fetch("late", 1); len(got) != 1 || got[0].Offset != 6 {
+		t.Fatalf("late group fetched %v", got)
+	}
+}
+
+func TestLogRewindRedeliversUncommitted(t *testing.T) {
+	l := NewLog("events", LogConfig{}, nil)
+	for i := 0; i < MaxFetch+5; i++ {
+		l.Append(textMessage("e"))
+	}
+	// One Fetch never hands out more than MaxFetch records
+	if got := l.Fetch("billing", MaxFetch+5); len(got) != MaxFetch {
+		t.Fatalf("Fetch returned %d records, want %d", len(got), MaxFetch)
+	}
+	if err := l.Commit("billing", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The member reconnects without committing the rest: it gets them again
+	if pos := l.Rewind("billing"); pos != 2 {
+		t.Fatalf("Rewind = %d, want 2", pos)
+	}
+	got := l.Fetch("billing", 3)
+	if len(got) != 3 || got[0].Offset != 2 || got[2].Offset != 4 {
+		t.Fatalf("after Rewind fetched %v, want offsets 2-4", got)
+	}
+	if info := l.Info(); info.Groups[0].Position != 5 || info.Groups[0].Committed != 2 {
+		t.Fatalf("group = %+v", info.Groups[0])
+	}
+}
@@ -0,0 +1,207 @@
+// memory.go - Byte limits on queues and a memory budget shared by all queues.
+//
+// This file bounds queues by the payload bytes they hold as well as by their
+// message count. A queue with a byte limit (QueueConfig.MaxBytes) treats a
+// message that would take it past the limit like one that finds it full, so its
+// overflow policy decides what happens; a message larger than the limit itself
+// can never fit and fails with ErrTooLarge. A MemoryBudget bounds the payload
+// bytes held by every queue of a registry together. A message that would exceed
+// it is either rejected with ErrMemoryBudget whatever the queue's policy
+// (BudgetReject), or handled by the queue's overflow policy as if the queue were
+// full (BudgetEvict), so queues that drop their oldest messages make room by
+// evicting them. Only messages waiting in a queue count: leased and scheduled
+// messages do not.
+
+package mq
+
+import (
+	"errors"      // For memory errors
+	"sync/atomic" // For the byte counts
+)
+
+// Errors returned when a message does not fit in memory.
+var (
+	ErrTooLarge     = errors.New("message is larger than the queue's byte limit or the memory budget")
+	ErrMemoryBudget = errors.New("memory budget exhausted")
+)
+
+// BudgetPolicy selects what happens to a message that would exceed the memory budget.
+type BudgetPolicy string
+
+// Supported budget policies. The zero value behaves like BudgetReject.
+const (
+	BudgetReject BudgetPolicy = "reject" // Fail with ErrMemoryBudget
+	BudgetEvict  BudgetPolicy = "evict"  // Apply the queue's overflow policy, as if the queue were full
+)
+
+// Errors returned by NewMemoryBudget.
+var (
+	ErrInvalidBudget       = errors.New("memory budget must be greater than zero")
+	ErrInvalidBudgetPolicy = errors.New("invalid memory budget policy")
+)
+
+// ParseBudgetPolicy returns the budget policy with the given name. The empty name
+// selects BudgetReject.
+func ParseBudgetPolicy(name string) (BudgetPolicy, error) {
+	switch p := BudgetPolicy(name); p {
+	case "":
+		return BudgetReject, nil
+	case BudgetReject, BudgetEvict:
+		return p, nil
+	}
+	return "", ErrInvalidBudgetPolicy
+}
+
+// Sizer is implemented by queues that report the payload bytes they hold, such
+// as every queue a Registry builds.
+type Sizer interface {
+	Bytes() uint64 // Payload bytes currently held
+}
+
+// BudgetObserver is implemented by metrics collectors that track how much of a
+// memory budget is in use. It is called with the bytes in use after every change.
+type BudgetObserver interface {
+	ObserveBudgetUsed(bytes int64)
+}
+
+// MemoryBudget bounds the payload bytes held by all the queues of a registry.
+// It is safe for concurrent use.
+type MemoryBudget struct {
+	limit    int64          // Payload bytes the queues may hold together
+	policy   BudgetPolicy   // What happens to a message that would exceed the limit
+	observer BudgetObserver // Notified of every change, or nil
+	used     atomic.Int64   // Payload bytes held
+	freed    notifier       // Wakes producers waiting for the budget
+}
+
+// NewMemoryBudget creates a budget of limit bytes, which must be greater than
+// zero, applying policy to messages that would exceed it. observer may be nil.
+func NewMemoryBudget(limit int64, policy BudgetPolicy, observer BudgetObserver) (*MemoryBudget, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidBudget
+	}
+	policy, err := ParseBudgetPolicy(string(policy))
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryBudget{limit: limit, policy: policy, observer: observer}, nil
+}
+
+// Limit returns the number of bytes the budget allows.
+func (b *MemoryBudget) Limit() int64 {
+	return b.limit
+}
+
+// Used returns the number of bytes currently held against the budget.
+func (b *MemoryBudget) Used() int64 {
+	return b.used.Load()
+}
+
+// Policy returns what happens to a message that would exceed the budget.
+func (b *MemoryBudget) Policy() BudgetPolicy {
+	return b.policy
+}
+
+// reserve takes n bytes from the budget, returning false if there are too few left.
+func (b *MemoryBudget) reserve(n int64) bool {
+	used := b.used.Add(n)
+	if used > b.limit {
+		b.used.Add(-n)
+		return false
+	}
+	b.observe(used)
+	return true
+}
+
+// release gives n bytes back to the budget.
+func (b *MemoryBudget) release(n int64) {
+	b.observe(b.used.Add(-n))
+	b.freed.broadcast()
+}
+
+// prepare registers the caller as waiting for bytes to be released, like
+// notifier.prepare. A nil budget returns a nil channel, which never fires.
+func (b *MemoryBudget) prepare() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+	return b.freed.prepare()
+}
+
+// done unregisters a waiter registered with prepare.
+func (b *MemoryBudget) done() {
+	if b != nil {
+		b.freed.done()
+	}
+}
+
+// observe reports the bytes in use.
+func (b *MemoryBudget) observe(used int64) {
+	if b.observer != nil {
+		b.observer.ObserveBudgetUsed(used)
+	}
+}
+
+// SetMemoryBudget makes every queue of the registry hold its messages against b.
+// It must be called before queues are created.
+func (r *Registry) SetMemoryBudget(b *MemoryBudget) {
+	r.budget = b
+}
+
+// byteCount tracks the payload bytes held by one queue against its byte limit
+// and the memory budget.
+type byteCount struct {
+	limit  atomic.Int64                 // Byte limit of the queue; zero is unlimited
+	used   atomic.Int64                 // Payload bytes held
+	budget atomic.Pointer[MemoryBudget] // Shared budget, or nil
+}
+
+// reserve accounts for a message of n bytes entering the queue. It returns
+// ErrTooLarge if the message can never fit, ErrFull if the queue has no room,
+// and, if the budget has no room, ErrMemoryBudget or ErrFull depending on the
+// budget's policy.
+func (c *byteCount) reserve(n int64) error {
+	limit, budget := c.limit.Load(), c.budget.Load()
+	if (limit > 0 && n > limit) || (budget != nil && n > budget.limit) {
+		return ErrTooLarge
+	}
+	if used := c.used.Add(n); limit > 0 && used > limit {
+		c.used.Add(-n)
+		return ErrFull
+	}
+	if budget != nil && !budget.reserve(n) {
+		c.used.Add(-n)
+		if budget.policy == BudgetEvict {
+			return ErrFull
+		}
+		return ErrMemoryBudget
+	}
+	return nil
+}
+
+// release accounts for a message of n bytes leaving the queue.
+func (c *byteCount) release(n int64) {
+	c.used.Add(-n)
+	if budget := c.budget.Load(); budget != nil {
+		budget.release(n)
+	}
+}
+
+// join starts holding the queue's messages against b. It is called before the queue is used.
+func (c *byteCount) join(b *MemoryBudget) {
+	if b != nil {
+		c.budget.Store(b)
+	}
+}
+
+// leave gives the bytes of a deleted queue back to its budget.
+func (c *byteCount) leave() {
+	if budget := c.budget.Swap(nil); budget != nil {
+		budget.release(c.used.Load())
+	}
+}
+
+// waitable reports whether a producer refused with err may wait for room.
+func waitable(err error) bool {
+	return err == ErrFull || err == ErrMemoryBudget
+}
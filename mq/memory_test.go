@@ -0,0 +1,156 @@
+// memory_test.go - Tests for byte limits and the memory budget.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// payload returns a message with a payload of n bytes.
+func payload(n int) *Message {
+	return NewMessage(NewID(), []byte(strings.Repeat("x", n)))
+}
+
+// usedObserver records the last budget use reported.
+type usedObserver struct{ used atomic.Int64 }
+
+func (o *usedObserver) ObserveBudgetUsed(bytes int64) { o.used.Store(bytes) }
+
+func TestMessageQueueMaxBytes(t *testing.T) {
+	q := NewMessageQueue(100)
+	q.SetMaxBytes(10)
+	if err := q.Enqueue(payload(6)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(payload(6)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Enqueue past the byte limit = %v, want ErrFull", err)
+	}
+	if err := q.Enqueue(payload(11)); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Enqueue of a message over the byte limit = %v, want ErrTooLarge", err)
+	}
+	if q.Bytes() != 6 || q.Len() != 1 {
+		t.Fatalf("Bytes() = %d, Len() = %d; want 6, 1", q.Bytes(), q.Len())
+	}
+	if _, err := q.Dequeue(); err != nil || q.Bytes() != 0 {
+		t.Fatalf("Dequeue = %v, Bytes() = %d", err, q.Bytes())
+	}
+
+	// Dropping the oldest messages makes room by bytes as well as by count
+	q.SetOverflow(OverflowDropOldest, 0)
+	for _, n := range []int{4, 4, 4} {
+		if err := q.Enqueue(payload(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if q.Bytes() != 8 || q.Len() != 2 {
+		t.Fatalf("after evicting: Bytes() = %d, Len() = %d; want 8, 2", q.Bytes(), q.Len())
+	}
+	if err := q.Enqueue(payload(11)); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("drop-oldest Enqueue of a message over the limit = %v, want ErrTooLarge", err)
+	}
+
+	// A producer blocked by bytes wakes up once a consumer makes room
+	q.SetOverflow(OverflowReject, 0)
+	done := make(chan error)
+	go func() { done <- q.EnqueueContext(context.Background(), payload(6)) }()
+	time.Sleep(20 * time.Millisecond)
+	q.Dequeue()
+	if err := <-done; err != nil || q.Bytes() != 10 {
+		t.Fatalf("EnqueueContext = %v, Bytes() = %d", err, q.Bytes())
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	obs := &usedObserver{}
+	budget, err := NewMemoryBudget(20, BudgetReject, obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry(nil)
+	r.SetMemoryBudget(budget)
+	orders, _ := r.Create("orders", QueueConfig{Capacity: 100, Overflow: OverflowDropOldest})
+	audit, _ := r.Create("audit", QueueConfig{Capacity: 100, PriorityLevels: 3})
+	if err := orders.Enqueue(payload(12)); err != nil {
+		t.Fatal(err)
+	}
+	if err := audit.Enqueue(payload(8)); err != nil {
+		t.Fatal(err)
+	}
+	// Rejecting ignores the queue's overflow policy
+	if err := orders.Enqueue(payload(1)); !errors.Is(err, ErrMemoryBudget) {
+		t.Fatalf("Enqueue over the budget = %v, want ErrMemoryBudget", err)
+	}
+	if err := audit.Enqueue(payload(21)); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Enqueue of a message over the budget = %v, want ErrTooLarge", err)
+	}
+	if budget.Used() != 20 || obs.used.Load() != 20 {
+		t.Fatalf("Used() = %d, observed %d; want 20", budget.Used(), obs.used.Load())
+	}
+	if infos := r.List(); infos[0].Name != "audit" || infos[0].Bytes != 8 || infos[1].Bytes != 12 {
+		t.Fatalf("List() = %+v", infos)
+	}
+
+	// A producer waiting for the budget wakes up when another queue frees some
+	done := make(chan error)
+	go func() { done <- audit.EnqueueContext(context.Background(), payload(5)) }()
+	time.Sleep(20 * time.Millisecond)
+	if _, err := orders.Dequeue(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil || budget.Used() != 13 {
+		t.Fatalf("EnqueueContext = %v, Used() = %d; want 13", err, budget.Used())
+	}
+
+	// Deleting a queue gives its bytes back
+	if err := r.Delete("audit"); err != nil {
+		t.Fatal(err)
+	}
+	if budget.Used() != 0 || obs.used.Load() != 0 {
+		t.Fatalf("after delete: Used() = %d, observed %d", budget.Used(), obs.used.Load())
+	}
+}
+
+func TestMemoryBudgetEvict(t *testing.T) {
+	budget, _ := NewMemoryBudget(10, BudgetEvict, nil)
+	r := NewRegistry(nil)
+	r.SetMemoryBudget(budget)
+	events, _ := r.Create("events", QueueConfig{Capacity: 100, Overflow: OverflowDropOldest})
+	orders, _ := r.Create("orders", QueueConfig{Capacity: 100})
+	for i := 0; i < 2; i++ {
+		if err := events.Enqueue(payload(4)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A queue that rejects treats the exhausted budget as a full queue
+	if err := orders.Enqueue(payload(4)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Enqueue over the budget = %v, want ErrFull", err)
+	}
+	// A queue that drops its oldest messages evicts them to make room
+	if err := events.Enqueue(payload(4)); err != nil {
+		t.Fatalf("drop-oldest Enqueue over the budget = %v", err)
+	}
+	if events.Len() != 2 || budget.Used() != 8 {
+		t.Fatalf("Len() = %d, Used() = %d; want 2, 8", events.Len(), budget.Used())
+	}
+	if err := orders.Enqueue(payload(2)); err != nil {
+		t.Fatal(err)
+	}
+	// It gives up once it has nothing left to evict
+	events.Dequeue()
+	events.Dequeue()
+	if err := events.Enqueue(payload(9)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Enqueue with the budget held by another queue = %v, want ErrFull", err)
+	}
+
+	if _, err := NewMemoryBudget(0, BudgetReject, nil); !errors.Is(err, ErrInvalidBudget) {
+		t.Fatalf("NewMemoryBudget(0) = %v", err)
+	}
+	if _, err := NewMemoryBudget(1, "spill", nil); !errors.Is(err, ErrInvalidBudgetPolicy) {
+		t.Fatalf("NewMemoryBudget with an unknown policy = %v", err)
+	}
+}
@@ -1,22 +1,59 @@
 // message.go - Defines the Message type used in the message queue system.
 //
 // This file provides the Message struct, which encapsulates a message's unique
-// identifier and its payload, along with methods for creating and accessing messages.
+// identifier and its payload together with producer-supplied headers, a content
+// type, a priority, a partition key, the time the message was accepted, an
+// optional expiry time, the history of failed deliveries and, for dead-lettered
+// messages, why they were dead-lettered, along with methods for creating and
+// accessing messages. It also provides NewID for server-assigned message IDs.
 
 package mq
 
-// Message represents a message in the queue, consisting of an ID and a payload.
+import (
+	"crypto/rand"  // For the per-process ID prefix
+	"encoding/hex" // For encoding the ID prefix
+	"strconv"      // For formatting the ID counter
+	"sync/atomic"  // For the ID counter
+	"time"         // For message timestamps
+)
+
+// Message represents a message in the queue: an ID and a payload plus envelope metadata.
+//
+// A Message is built by the producer side before it is enqueued and must not be
+// modified afterwards; consumers only read it.
 type Message struct {
-	id      string // Unique identifier for the message
-	payload []byte // Message payload (arbitrary binary data)
+	id          string            // Unique identifier for the message
+	payload     []byte            // Message payload (arbitrary binary data)
+	headers     map[string]string // Producer-supplied headers/attributes
+	contentType string            // MIME type of the payload, if known
+	priority    int               // Priority level for priority queues (0 is the lowest)
+	key         string            // Partition key for partitioned queues ("" if none)
+	timestamp   time.Time         // When the message was created for enqueueing
+	expiresAt   time.Time         // When the message expires (zero means never)
+	attempts    []Attempt         // Failed deliveries of this message, oldest first
+	deadLetter  *DeadLetter       // Set on messages moved to a dead-letter queue
+}
+
+// Attempt records one failed delivery of a message.
+type Attempt struct {
+	DeliveredAt time.Time // When the message was handed to a consumer
+	Reason      string    // Why the delivery failed, e.g. "nack" or "lease_expired"
 }
 
-// NewMessage creates a new Message with the given id and payload.
+// DeadLetter describes why a message was moved to a dead-letter queue.
+type DeadLetter struct {
+	Queue  string    // Queue the message was dead-lettered from
+	Reason string    // Why it was dead-lettered
+	At     time.Time // When it was dead-lettered
+}
+
+// NewMessage creates a new Message with the given id and payload, timestamped now.
 // Returns a pointer to the created Message.
 func NewMessage(id string, payload []byte) *Message {
 	return &Message{
-		id:      id,
-		payload: payload,
+		id:        id,
+		payload:   payload,
+		timestamp: time.Now(),
 	}
 }
 
@@ -28,4 +65,164 @@ func (m *Message) GetID() string {
 // GetPayload returns the payload of the message as a byte slice.
 func (m *Message) GetPayload() []byte {
 	return m.payload
-}
\ No newline at end of file
+}
+
+// GetHeaders returns the producer-supplied headers. The map must not be modified.
+func (m *Message) GetHeaders() map[string]string {
+	return m.headers
+}
+
+// GetHeader returns the value of a single header, or "" if it is not set.
+func (m *Message) GetHeader(key string) string {
+	return m.headers[key]
+}
+
+// SetHeaders replaces the message headers with a copy of headers.
+func (m *Message) SetHeaders(headers map[string]string) {
+	if len(headers) == 0 {
+		m.headers = nil
+		return
+	}
+	m.headers = make(map[string]string, len(headers))
+	for k, v := range headers {
+		m.headers[k] = v
+	}
+}
+
+// SetHeader sets a single header on the message.
+func (m *Message) SetHeader(key, value string) {
+	if m.headers == nil {
+		m.headers = make(map[string]string)
+	}
+	m.headers[key] = value
+}
+
+// GetContentType returns the MIME type of the payload, or "" if unspecified.
+func (m *Message) GetContentType() string {
+	return m.contentType
+}
+
+// SetContentType sets the MIME type of the payload.
+func (m *Message) SetContentType(contentType string) {
+	m.contentType = contentType
+}
+
+// GetPriority returns the priority of the message (0, the lowest, unless set).
+func (m *Message) GetPriority() int {
+	return m.priority
+}
+
+// SetPriority sets the priority of the message. Priority queues deliver messages
+// of a higher priority first; other queues ignore it. Negative values are treated as 0.
+func (m *Message) SetPriority(priority int) {
+	if priority < 0 {
+		priority = 0
+	}
+	m.priority = priority
+}
+
+// GetKey returns the partition key of the message, or "" if it has none.
+func (m *Message) GetKey() string {
+	return m.key
+}
+
+// SetKey sets the partition key of the message. Partitioned queues deliver all
+// messages with the same key through one partition, in order; other queues ignore it.
+func (m *Message) SetKey(key string) {
+	m.key = key
+}
+
+// GetTimestamp returns the time the message was created for enqueueing.
+func (m *Message) GetTimestamp() time.Time {
+	return m.timestamp
+}
+
+// SetTTL makes the message expire ttl after its timestamp. A ttl of zero or less
+// clears the expiry.
+func (m *Message) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		m.expiresAt = time.Time{}
+		return
+	}
+	m.expiresAt = m.timestamp.Add(ttl)
+}
+
+// GetExpiresAt returns when the message expires, or the zero time if it never does.
+func (m *Message) GetExpiresAt() time.Time {
+	return m.expiresAt
+}
+
+// expired reports whether the message has outlived its own TTL or, if maxAge is
+// positive, the queue's maximum age.
+func (m *Message) expired(now time.Time, maxAge time.Duration) bool {
+	if !m.expiresAt.IsZero() && !now.Before(m.expiresAt) {
+		return true
+	}
+	return maxAge > 0 && now.Sub(m.timestamp) >= maxAge
+}
+
+// GetAttempts returns the failed deliveries of the message, oldest first.
+// The slice must not be modified.
+func (m *Message) GetAttempts() []Attempt {
+	return m.attempts
+}
+
+// GetDeliveryAttempt returns which delivery of the message this is: 1 for the
+// first delivery, incremented each time the message is redelivered.
+func (m *Message) GetDeliveryAttempt() int {
+	return len(m.attempts) + 1
+}
+
+// redelivery returns a copy of the message with a failed attempt appended to its
+// history. Copying keeps the original immutable for consumers that still hold it.
+func (m *Message) redelivery(a Attempt) *Message {
+	c := *m
+	c.attempts = append(m.attempts[:len(m.attempts):len(m.attempts)], a)
+	return &c
+}
+
+// GetDeadLetter returns why the message was dead-lettered, or nil if it was not.
+func (m *Message) GetDeadLetter() *DeadLetter {
+	return m.deadLetter
+}
+
+// deadLettered returns a copy of the message marked as dead-lettered.
+func (m *Message) deadLettered(dl DeadLetter) *Message {
+	c := *m
+	c.deadLetter = &dl
+	return &c
+}
+
+// requeued returns a copy of a dead-lettered message with a clean delivery history,
+// ready to go back to its original queue.
+func (m *Message) requeued() *Message {
+	c := *m
+	c.attempts = nil
+	c.deadLetter = nil
+	return &c
+}
+
+var (
+	idPrefix  = newIDPrefix() // Random per-process prefix, so IDs stay unique across restarts
+	idCounter uint64          // Monotonic per-process counter (accessed atomically)
+)
+
+// newIDPrefix returns 8 random bytes as hex.
+func newIDPrefix() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock just in case
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// NewID returns a new unique message ID of the form "<process prefix>-<counter>".
+// It is cheap enough to call on every enqueue.
+func NewID() string {
+	n := atomic.AddUint64(&idCounter, 1)
+	buf := make([]byte, 0, len(idPrefix)+1+13)
+	buf = append(buf, idPrefix...)
+	buf = append(buf, '-')
+	return string(strconv.AppendUint(buf, n, 36))
+}
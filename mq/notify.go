@@ -0,0 +1,60 @@
+// notify.go - Lightweight wake-up primitive for goroutines parked on a queue.
+//
+// This file defines notifier, which lets blocking queue operations sleep until
+// the queue changes state instead of busy-polling. The fast path for the side
+// that changes state is a single atomic load when nobody is waiting.
+
+package mq
+
+import (
+	"sync"        // For guarding the wake-up channel
+	"sync/atomic" // For the lock-free waiter count
+)
+
+// notifier parks goroutines until broadcast is called.
+//
+// Waiters must follow the prepare / re-check / wait / done protocol:
+//
+//	ch := n.prepare()
+//	if conditionNowTrue() { n.done(); ... }
+//	<-ch
+//	n.done()
+//
+// Because the waiter count is raised before the condition is re-checked, a
+// state change that happens after the re-check is guaranteed to see the waiter
+// and close the channel, so no wake-up is ever lost.
+type notifier struct {
+	waiters int64         // Number of goroutines between prepare and done (accessed atomically)
+	mu      sync.Mutex    // Guards ch
+	ch      chan struct{} // Closed on broadcast; nil when nobody holds it
+}
+
+// prepare registers the caller as a waiter and returns the channel to wait on.
+func (n *notifier) prepare() <-chan struct{} {
+	atomic.AddInt64(&n.waiters, 1)
+	n.mu.Lock()
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	ch := n.ch
+	n.mu.Unlock()
+	return ch
+}
+
+// done unregisters a waiter previously registered with prepare.
+func (n *notifier) done() {
+	atomic.AddInt64(&n.waiters, -1)
+}
+
+// broadcast wakes every goroutine currently waiting. It is cheap when there are no waiters.
+func (n *notifier) broadcast() {
+	if atomic.LoadInt64(&n.waiters) == 0 {
+		return
+	}
+	n.mu.Lock()
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+	}
+	n.mu.Unlock()
+}
@@ -0,0 +1,120 @@
+// overflow.go - Overflow policies for full queues.
+//
+// This file defines what Enqueue does when a MessageQueue has no free slot:
+// reject the message, block the producer for a bounded time, evict the oldest
+// message to make room, or silently discard the new message. The same policies
+// apply when the queue's byte limit, or a memory budget that evicts, has no room
+// for the message. Messages evicted or discarded this way are reported to a
+// DropObserver with the policy as reason.
+
+package mq
+
+import (
+	"context" // For bounding blocked enqueues
+	"errors"  // For overflow errors
+	"time"    // For block timeouts
+)
+
+// OverflowPolicy selects what Enqueue does when the queue is full.
+type OverflowPolicy string
+
+// Supported overflow policies. The zero value behaves like OverflowReject.
+const (
+	OverflowReject     OverflowPolicy = "reject"      // Fail with ErrFull
+	OverflowBlock      OverflowPolicy = "block"       // Wait for space, failing with ErrFull after the block timeout
+	OverflowDropOldest OverflowPolicy = "drop_oldest" // Discard the oldest message to make room
+	OverflowDropNewest OverflowPolicy = "drop_newest" // Discard the new message and report success
+)
+
+// DefaultBlockTimeout is how long Enqueue waits for space under OverflowBlock
+// when the queue sets no timeout of its own.
+const DefaultBlockTimeout = time.Second
+
+// ErrInvalidOverflowPolicy is returned for an overflow policy name that is not supported.
+var ErrInvalidOverflowPolicy = errors.New("invalid overflow policy")
+
+// DropObserver is implemented by queue wrappers (such as an instrumented queue)
+// that want to count messages discarded by an overflow policy. reason is the
+// policy that discarded the message (OverflowDropOldest or OverflowDropNewest).
+type DropObserver interface {
+	ObserveDropped(reason string)
+}
+
+// ParseOverflowPolicy returns the overflow policy with the given name. The empty
+// name selects OverflowReject.
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(name); p {
+	case "":
+		return OverflowReject, nil
+	case OverflowReject, OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+		return p, nil
+	}
+	return "", ErrInvalidOverflowPolicy
+}
+
+// overflow is the overflow behaviour of a MessageQueue.
+type overflow struct {
+	policy  OverflowPolicy // What to do when the queue is full
+	timeout time.Duration  // How long OverflowBlock waits for space
+}
+
+// SetOverflow sets what Enqueue does when the queue is full. timeout bounds the
+// wait under OverflowBlock (zero selects DefaultBlockTimeout) and is otherwise
+// ignored. It is safe to call while the queue is in use.
+func (q *MessageQueue) SetOverflow(policy OverflowPolicy, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultBlockTimeout
+	}
+	q.overflow.Store(&overflow{policy: policy, timeout: timeout})
+}
+
+// Overflow returns the queue's overflow policy and block timeout.
+func (q *MessageQueue) Overflow() (OverflowPolicy, time.Duration) {
+	o := q.overflow.Load()
+	return o.policy, o.timeout
+}
+
+// enqueueFull applies the overflow policy to msg after a plain enqueue found the queue full.
+func (q *MessageQueue) enqueueFull(msg *Message) error {
+	o := q.overflow.Load()
+	switch o.policy {
+	case OverflowBlock:
+		ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+		defer cancel()
+		err := q.EnqueueContext(ctx, msg)
+		if err != nil && err == ctx.Err() {
+			return ErrFull // Still full after the block timeout
+		}
+		return err
+	case OverflowDropOldest:
+		for {
+			if err := q.enqueue(msg); err != ErrFull {
+				return err
+			}
+			if _, err := q.dequeue(nil); err == nil {
+				q.drop(OverflowDropOldest)
+			} else if q.Len() == 0 {
+				// Nothing left to evict: other queues hold the memory budget
+				return q.enqueue(msg)
+			}
+			// Otherwise a consumer freed a slot in between; retry
+		}
+	case OverflowDropNewest:
+		q.drop(OverflowDropNewest)
+		return nil
+	}
+	return ErrFull
+}
+
+// observe sets the callbacks for expired and dropped messages. It must be called before the queue is used.
+func (q *MessageQueue) observe(onExpire func(*Message), onDrop func(reason string)) {
+	q.onExpire = onExpire
+	q.onDrop = onDrop
+}
+
+// drop reports a message discarded by the overflow policy.
+func (q *MessageQueue) drop(reason OverflowPolicy) {
+	if q.onDrop != nil {
+		q.onDrop(string(reason))
+	}
+}
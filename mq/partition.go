@@ -0,0 +1,411 @@
+// partition.go - Keyed partitions with per-key ordering.
+//
+// This file defines PartitionedQueue, which keeps one lock-free MessageQueue ring
+// per partition and a shared count bounding the total number of messages (see
+// rings.go). A producer-supplied key (Message.SetKey) is hashed to a partition,
+// so every message with the same key lands in the same FIFO ring; messages
+// without a key are spread round-robin. Consumers join the queue as members and
+// the queue assigns each partition to at most one member, so the messages of a
+// key are processed by one consumer at a time and in order. Partitions are
+// rebalanced whenever a member joins or leaves, moving as few partitions as
+// possible. Partitions without an owner (all of them while nobody has joined)
+// are served to plain Dequeue callers.
+// A leased message that is nacked or whose lease expires goes back to the head of
+// its partition, ahead of the later messages of its key. A partition that changes
+// owner while leases on its messages are outstanding is fenced: nobody consumes
+// it until those leases are acked, redelivered or dead-lettered, so the new owner
+// never overlaps with the previous one.
+
+package mq
+
+import (
+	"context"     // For cancelling blocking operations
+	"errors"      // For partition errors
+	"hash/fnv"    // For hashing keys to partitions
+	"sort"        // For stable assignment listings
+	"sync"        // For guarding membership
+	"sync/atomic" // For lock-free reads of the assignment
+)
+
+// MaxPartitions is the largest number of partitions a PartitionedQueue supports.
+const MaxPartitions = 256
+
+// Partition errors.
+var (
+	ErrInvalidPartitions = errors.New("invalid partition configuration: use up to 256 partitions, not combined with priority levels")
+	ErrNotPartitioned    = errors.New("queue is not partitioned")
+)
+
+// DequeueObserver is implemented by queue wrappers (such as an instrumented queue)
+// that want to count messages taken by partition consumers, which bypass the
+// wrapper's own Dequeue.
+type DequeueObserver interface {
+	ObserveDequeued()
+}
+
+// PartitionAssignment lists the partitions owned by one consumer.
+type PartitionAssignment struct {
+	Consumer   string // ID the consumer joined with
+	Partitions []int  // Owned partitions, ascending
+}
+
+// PartitionedQueue is a bounded queue of *Message values split into partitions by
+// message key. It implements Queue and supports the same expiry and overflow
+// settings as MessageQueue; OverflowDropOldest discards the oldest message of the
+// first non-empty partition.
+type PartitionedQueue struct {
+	ringSet                                          // One ring per partition
+	spread      uint64                               // Round-robin partition for keyless messages (accessed atomically)
+	scan        uint64                               // Round-robin start of plain Dequeue (accessed atomically)
+	onDequeue   func()                               // Called for each message taken by a consumer; set before use
+	mu          sync.Mutex                           // Guards members, rebalancing and setting fenced
+	members     []*PartitionConsumer                 // Joined consumers, in join order
+	owners      atomic.Pointer[[]*PartitionConsumer] // Owner of each partition (nil if unowned); replaced on rebalance
+	inflight    []int64                              // Leased messages not yet settled, per partition (accessed atomically)
+	fenced      []atomic.Bool                        // Set while a partition waits for the leases of its previous owner
+	retryMu     sync.Mutex                           // Guards redelivered
+	redelivered [][]*Message                         // Messages put back at the head of each partition, in redelivery order
+	retries     int64                                // Messages in redelivered (accessed atomically)
+}
+
+// PartitionConsumer is a member of a PartitionedQueue. It only receives messages
+// from the partitions currently assigned to it.
+type PartitionConsumer struct {
+	queue      *PartitionedQueue
+	id         string                // ID the consumer joined with
+	partitions atomic.Pointer[[]int] // Owned partitions; nil after Leave
+	turn       uint64                // Round-robin start over the owned partitions (accessed atomically)
+}
+
+// NewPartitionedQueue creates a PartitionedQueue holding at most capacity messages
+// over the given number of partitions. It panics if capacity is zero or partitions
+// is not between 1 and MaxPartitions.
+func NewPartitionedQueue(capacity uint64, partitions int) *PartitionedQueue {
+	if partitions < 1 || partitions > MaxPartitions {
+		panic("mq: PartitionedQueue partitions out of range")
+	}
+	q := &PartitionedQueue{
+		inflight:    make([]int64, partitions),
+		fenced:      make([]atomic.Bool, partitions),
+		redelivered: make([][]*Message, partitions),
+	}
+	q.init(capacity, partitions)
+	return q
+}
+
+// Partitions returns the number of partitions.
+func (q *PartitionedQueue) Partitions() int {
+	return len(q.rings)
+}
+
+// Partition returns the partition for a key. The empty key has no fixed partition
+// and returns -1.
+func (q *PartitionedQueue) Partition(key string) int {
+	if key == "" {
+		return -1
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(q.rings)))
+}
+
+// ring returns the ring msg belongs to.
+func (q *PartitionedQueue) ring(msg *Message) *MessageQueue {
+	p := q.Partition(msg.GetKey())
+	if p < 0 {
+		p = int((atomic.AddUint64(&q.spread, 1) - 1) % uint64(len(q.rings)))
+	}
+	return q.rings[p]
+}
+
+// Enqueue adds a message to the partition of its key. If the queue is full it
+// applies the queue's overflow policy, which by default rejects the message with ErrFull.
+func (q *PartitionedQueue) Enqueue(msg *Message) error {
+	return q.add(q.ring(msg), msg)
+}
+
+// EnqueueContext adds a message to the partition of its key, parking the caller
+// while the queue is full whatever its overflow policy. It returns ctx.Err() if
+// ctx is done before room frees up.
+func (q *PartitionedQueue) EnqueueContext(ctx context.Context, msg *Message) error {
+	return q.putContext(ctx, q.ring(msg), msg)
+}
+
+// Dequeue removes and returns the next message from a partition no consumer owns,
+// discarding expired messages. Returns nil and ErrEmpty if those partitions are
+// empty or every partition is owned.
+func (q *PartitionedQueue) Dequeue() (*Message, error) {
+	var owners []*PartitionConsumer
+	if p := q.owners.Load(); p != nil {
+		owners = *p
+	}
+	n := uint64(len(q.rings))
+	start := atomic.AddUint64(&q.scan, 1) - 1
+	for i := uint64(0); i < n; i++ {
+		p := (start + i) % n
+		if (owners != nil && owners[p] != nil) || q.fenced[p].Load() {
+			continue
+		}
+		if msg, err := q.takeFrom(int(p)); err == nil {
+			return msg, nil
+		}
+	}
+	return nil, ErrEmpty
+}
+
+// takeFrom removes the next message from partition p, serving messages put back
+// at its head first, and returns ErrEmpty if there is none.
+func (q *PartitionedQueue) takeFrom(p int) (*Message, error) {
+	for atomic.LoadInt64(&q.retries) > 0 {
+		q.retryMu.Lock()
+		head := q.redelivered[p]
+		if len(head) == 0 {
+			q.retryMu.Unlock()
+			break
+		}
+		msg := head[0]
+		head[0] = nil // Avoid memory leak
+		q.redelivered[p] = head[1:]
+		atomic.AddInt64(&q.retries, -1)
+		q.retryMu.Unlock()
+		q.bytes.release(int64(len(msg.payload)))
+		if q.rings[p].expire(msg) {
+			continue // The ring's expiry callback gave back its room
+		}
+		q.release()
+		return msg, nil
+	}
+	return q.take(q.rings[p])
+}
+
+// requeue puts msg, a message taken from the queue whose delivery failed, back at
+// the head of its key's partition, so it is served before the messages that came
+// after it. Keyless messages go to the tail of a partition like new ones. It
+// returns ErrFull if the queue has no room (see ringSet.put), whatever its
+// overflow policy.
+func (q *PartitionedQueue) requeue(msg *Message) error {
+	p := q.Partition(msg.GetKey())
+	if p < 0 {
+		return q.put(q.ring(msg), msg)
+	}
+	if uint64(atomic.AddInt64(&q.size, 1)) > q.capacity {
+		q.release()
+		return ErrFull
+	}
+	if err := q.bytes.reserve(int64(len(msg.payload))); err != nil {
+		q.release()
+		return err
+	}
+	q.retryMu.Lock()
+	q.redelivered[p] = append(q.redelivered[p], msg)
+	atomic.AddInt64(&q.retries, 1)
+	q.retryMu.Unlock()
+	q.notEmpty.broadcast()
+	return nil
+}
+
+// leased records that msg is in flight under a lease.
+func (q *PartitionedQueue) leased(msg *Message) {
+	if p := q.Partition(msg.GetKey()); p >= 0 {
+		atomic.AddInt64(&q.inflight[p], 1)
+	}
+}
+
+// settled records that the lease on msg ended because it was acked, requeued or
+// dead-lettered. The last lease to end on a fenced partition lifts the fence.
+func (q *PartitionedQueue) settled(msg *Message) {
+	p := q.Partition(msg.GetKey())
+	if p < 0 || atomic.AddInt64(&q.inflight[p], -1) > 0 {
+		return
+	}
+	// Under q.mu, so a rebalance cannot fence the partition after this check
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if atomic.LoadInt64(&q.inflight[p]) == 0 && q.fenced[p].Swap(false) {
+		q.notEmpty.broadcast()
+	}
+}
+
+// DequeueContext is like Dequeue but parks the caller until a message is available
+// in an unowned partition. It returns ctx.Err() if ctx is done before one arrives.
+func (q *PartitionedQueue) DequeueContext(ctx context.Context) (*Message, error) {
+	return q.takeContext(ctx, q.Dequeue)
+}
+
+// Peek returns up to limit messages without removing them (all of them if limit
+// is zero), partition by partition and in delivery order within a partition.
+func (q *PartitionedQueue) Peek(limit int) []*Message {
+	var out []*Message
+	for i := 0; i < len(q.rings) && (limit <= 0 || len(out) < limit); i++ {
+		rest := 0
+		if limit > 0 {
+			rest = limit - len(out)
+		}
+		q.retryMu.Lock()
+		head := q.redelivered[i]
+		if rest > 0 && len(head) > rest {
+			head = head[:rest]
+		}
+		out = append(out, head...)
+		q.retryMu.Unlock()
+		if limit > 0 {
+			if rest -= len(head); rest == 0 {
+				continue
+			}
+		}
+		out = append(out, q.rings[i].Peek(rest)...)
+	}
+	return out
+}
+
+// Join adds a consumer to the queue and rebalances the partitions. id identifies
+// the consumer in assignment listings and need not be unique. The consumer must
+// call Leave when it goes away so its partitions are handed to the others.
+func (q *PartitionedQueue) Join(id string) *PartitionConsumer {
+	c := &PartitionConsumer{queue: q, id: id}
+	c.partitions.Store(&[]int{})
+	q.mu.Lock()
+	q.members = append(q.members, c)
+	q.rebalance()
+	q.mu.Unlock()
+	return c
+}
+
+// rebalance spreads the partitions evenly over the members, in join order, while
+// keeping as many partitions as possible with their current owner, and fences
+// the partitions that change owner while leases on them are outstanding. The
+// caller holds q.mu.
+func (q *PartitionedQueue) rebalance() {
+	n := len(q.rings)
+	owners := make([]*PartitionConsumer, n)
+	if len(q.members) > 0 {
+		target := make(map[*PartitionConsumer]int, len(q.members))
+		for i, m := range q.members {
+			target[m] = n / len(q.members)
+			if i < n%len(q.members) {
+				target[m]++
+			}
+		}
+		// Keep partitions with members that still have room for them
+		if old := q.owners.Load(); old != nil {
+			for p, owner := range *old {
+				if owner != nil && target[owner] > 0 {
+					owners[p] = owner
+					target[owner]--
+				}
+			}
+		}
+		// Hand the rest to members below their share
+		m := 0
+		for p := range owners {
+			if owners[p] != nil {
+				continue
+			}
+			for target[q.members[m]] == 0 {
+				m++
+			}
+			owners[p] = q.members[m]
+			target[q.members[m]]--
+		}
+	}
+	// Fence partitions changing hands while leases on them are outstanding
+	if old := q.owners.Load(); old != nil {
+		for p, owner := range *old {
+			if owner != owners[p] && atomic.LoadInt64(&q.inflight[p]) > 0 {
+				q.fenced[p].Store(true)
+			}
+		}
+	}
+	assigned := make(map[*PartitionConsumer][]int, len(q.members))
+	for p, owner := range owners {
+		if owner != nil {
+			assigned[owner] = append(assigned[owner], p)
+		}
+	}
+	for _, m := range q.members {
+		partitions := assigned[m]
+		m.partitions.Store(&partitions)
+	}
+	q.owners.Store(&owners)
+	// Wake parked consumers so they pick up their new partitions
+	q.notEmpty.broadcast()
+}
+
+// Assignments returns the partitions owned by each member, in join order.
+func (q *PartitionedQueue) Assignments() []PartitionAssignment {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]PartitionAssignment, 0, len(q.members))
+	for _, m := range q.members {
+		out = append(out, PartitionAssignment{Consumer: m.id, Partitions: m.Partitions()})
+	}
+	return out
+}
+
+// ID returns the ID the consumer joined with.
+func (c *PartitionConsumer) ID() string {
+	return c.id
+}
+
+// Partitions returns the partitions currently assigned to the consumer, ascending.
+func (c *PartitionConsumer) Partitions() []int {
+	p := c.partitions.Load()
+	if p == nil {
+		return nil
+	}
+	out := append([]int(nil), *p...)
+	sort.Ints(out)
+	return out
+}
+
+// Dequeue removes and returns the next message from one of the consumer's
+// partitions, discarding expired messages. Returns ErrEmpty if they are empty,
+// fenced or the consumer owns none, and ErrClosed after Leave.
+func (c *PartitionConsumer) Dequeue() (*Message, error) {
+	p := c.partitions.Load()
+	if p == nil {
+		return nil, ErrClosed
+	}
+	partitions := *p
+	n := uint64(len(partitions))
+	if n == 0 {
+		return nil, ErrEmpty
+	}
+	start := atomic.AddUint64(&c.turn, 1) - 1
+	for i := uint64(0); i < n; i++ {
+		p := partitions[(start+i)%n]
+		if c.queue.fenced[p].Load() {
+			continue
+		}
+		if msg, err := c.queue.takeFrom(p); err == nil {
+			if c.queue.onDequeue != nil {
+				c.queue.onDequeue()
+			}
+			return msg, nil
+		}
+	}
+	return nil, ErrEmpty
+}
+
+// DequeueContext is like Dequeue but parks the caller until one of its partitions
+// has a message. It returns ctx.Err() if ctx is done before one arrives, and
+// ErrClosed if the consumer leaves meanwhile.
+func (c *PartitionConsumer) DequeueContext(ctx context.Context) (*Message, error) {
+	return c.queue.takeContext(ctx, c.Dequeue)
+}
+
+// Leave removes the consumer from the queue and hands its partitions to the
+// remaining members. Calling it more than once has no effect.
+func (c *PartitionConsumer) Leave() {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, m := range q.members {
+		if m == c {
+			q.members = append(q.members[:i], q.members[i+1:]...)
+			c.partitions.Store(nil)
+			q.rebalance()
+			return
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// partition_test.go - Tests for PartitionedQueue.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// keyedMessage returns a text message with the given partition key.
+func keyedMessage(s, key string) *Message {
+	msg := textMessage(s)
+	msg.SetKey(key)
+	return msg
+}
+
+func TestPartitionedQueueKeyOrdering(t *testing.T) {
+	q := NewPartitionedQueue(100, 4)
+	keys := []string{"alice", "bob", "carol", "dave", "erin"}
+	for i := 0; i < 10; i++ {
+		for _, key := range keys {
+			if err := q.Enqueue(keyedMessage(string(rune('0'+i)), key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	a, b := q.Join("a"), q.Join("b")
+	if pa, pb := a.Partitions(), b.Partitions(); len(pa) != 2 || len(pb) != 2 {
+		t.Fatalf("assignment a=%v b=%v, want two partitions each", pa, pb)
+	}
+	// Every partition is owned, so plain Dequeue gets nothing
+	if _, err := q.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue with all partitions owned = %v, want ErrEmpty", err)
+	}
+
+	seen := make(map[string]*PartitionConsumer)
+	last := make(map[string]byte)
+	for _, c := range []*PartitionConsumer{a, b} {
+		for {
+			msg, err := c.Dequeue()
+			if errors.Is(err, ErrEmpty) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := msg.GetKey()
+			if owner := seen[key]; owner != nil && owner != c {
+				t.Fatalf("key %q delivered to two consumers", key)
+			}
+			seen[key] = c
+			if p := msg.GetPayload()[0]; last[key] != 0 && p < last[key] {
+				t.Fatalf("key %q out of order: %c after %c", key, p, last[key])
+			} else {
+				last[key] = p
+			}
+		}
+	}
+	if len(seen) != len(keys) || q.Len() != 0 {
+		t.Fatalf("consumed keys %d of %d, %d messages left", len(seen), len(keys), q.Len())
+	}
+}
+
+func TestPartitionedQueueRebalance(t *testing.T) {
+	q := NewPartitionedQueue(10, 6)
+	a := q.Join("a")
+	if len(a.Partitions()) != 6 {
+		t.Fatalf("sole member owns %v", a.Partitions())
+	}
+	b := q.Join("b")
+	c := q.Join("c")
+	owned := make(map[int]bool)
+	for _, m := range []*PartitionConsumer{a, b, c} {
+		if len(m.Partitions()) != 2 {
+			t.Fatalf("%s owns %v, want two partitions", m.ID(), m.Partitions())
+		}
+		for _, p := range m.Partitions() {
+			if owned[p] {
+				t.Fatalf("partition %d owned twice", p)
+			}
+			owned[p] = true
+		}
+	}
+	// A leaving member's partitions move to the others; theirs stay put
+	before := append(a.Partitions(), c.Partitions()...)
+	b.Leave()
+	b.Leave()
+	if _, err := b.Dequeue(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Dequeue after Leave = %v, want ErrClosed", err)
+	}
+	after := make(map[int]bool)
+	for _, p := range append(a.Partitions(), c.Partitions()...) {
+		after[p] = true
+	}
+	for _, p := range before {
+		if !after[p] {
+			t.Fatalf("partition %d moved although its owner stayed", p)
+		}
+	}
+	if len(after) != 6 || len(q.Assignments()) != 2 {
+		t.Fatalf("after leave: %v", q.Assignments())
+	}
+
+	// A consumer parked on its partitions wakes up when it is handed a partition
+	// holding a message
+	msg := keyedMessage("m", "k")
+	owner := a
+	for _, p := range c.Partitions() {
+		if p == q.Partition("k") {
+			owner = c
+		}
+	}
+	other := a
+	if owner == a {
+		other = c
+	}
+	if err := q.Enqueue(msg); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan *Message)
+	go func() {
+		m, _ := other.DequeueContext(ctx)
+		got <- m
+	}()
+	owner.Leave()
+	if m := <-got; m != msg {
+		t.Fatalf("DequeueContext after rebalance = %v", m)
+	}
+	other.Leave()
+	if err := q.Enqueue(keyedMessage("unowned", "k")); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := q.Dequeue(); err != nil || string(m.GetPayload()) != "unowned" {
+		t.Fatalf("Dequeue once nobody owns the partitions = %v, %v", m, err)
+	}
+}
+
+// payloads dequeues every message c can take now and returns their payloads.
+func payloads(t *testing.T, c *PartitionConsumer) []string {
+	t.Helper()
+	var out []string
+	for {
+		msg, err := c.Dequeue()
+		if errors.Is(err, ErrEmpty) {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, string(msg.GetPayload()))
+	}
+}
+
+func TestPartitionedQueueRedeliversToHead(t *testing.T) {
+	q := NewPartitionedQueue(10, 2)
+	leases := NewLeases(q, time.Minute)
+	leases.tracker = q
+	for _, s := range []string{"k1", "k2", "k3"} {
+		if err := q.Enqueue(keyedMessage(s, "k")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := q.Join("a")
+	msg, err := c.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leases.Hold(msg, 0)
+	if err := leases.Nack(msg.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 3 || len(q.Peek(0)) != 3 || string(q.Peek(1)[0].GetPayload()) != "k1" {
+		t.Fatalf("Len = %d, Peek = %v after Nack", q.Len(), q.Peek(0))
+	}
+	if got := payloads(t, c); len(got) != 3 || got[0] != "k1" || got[1] != "k2" || got[2] != "k3" {
+		t.Fatalf("after Nack consumed %v, want [k1 k2 k3]", got)
+	}
+}
+
+func TestPartitionedQueueFencesReassignedPartition(t *testing.T) {
+	q := NewPartitionedQueue(10, 1)
+	leases := NewLeases(q, time.Minute)
+	leases.tracker = q
+	for _, s := range []string{"k1", "k2"} {
+		if err := q.Enqueue(keyedMessage(s, "k")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := q.Join("old")
+	next := q.Join("next")
+	msg, err := old.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leases.Hold(msg, 0)
+
+	// The partition moves to next while old still holds k1: next must wait
+	old.Leave()
+	if got := next.Partitions(); len(got) != 1 {
+		t.Fatalf("next owns %v after old left", got)
+	}
+	if _, err := next.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue from a fenced partition = %v, want ErrEmpty", err)
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("plain Dequeue from a fenced partition = %v, want ErrEmpty", err)
+	}
+
+	// Once the lease ends the fence lifts and k1 comes first
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan string, 1)
+	go func() {
+		msg, err := next.DequeueContext(ctx)
+		if err != nil {
+			got <- err.Error()
+			return
+		}
+		got <- string(msg.GetPayload())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := leases.Nack(msg.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if s := <-got; s != "k1" {
+		t.Fatalf("after the lease ended next got %q, want k1", s)
+	}
+	if got := payloads(t, next); len(got) != 1 || got[0] != "k2" {
+		t.Fatalf("then consumed %v, want [k2]", got)
+	}
+}
@@ -0,0 +1,162 @@
+// priority.go - Priority queue with a fixed number of priority levels.
+//
+// This file defines PriorityQueue, which keeps one lock-free MessageQueue ring
+// per priority level and a shared count bounding the total number of messages
+// (see rings.go). Consumers either always take from the highest non-empty level
+// (strict mode) or follow a weighted round-robin over the levels (weighted mode),
+// in which each level is preferred twice as often as the one below it, so low
+// priorities make progress under sustained high-priority load. Within a level
+// messages stay FIFO.
+
+package mq
+
+import (
+	"context"     // For cancelling blocking operations
+	"errors"      // For priority errors
+	"sync/atomic" // For the round-robin position
+)
+
+// PriorityMode selects how a PriorityQueue chooses the level to dequeue from.
+type PriorityMode string
+
+// Supported priority modes. The zero value behaves like PriorityStrict.
+const (
+	PriorityStrict   PriorityMode = "strict"   // Always the highest non-empty level
+	PriorityWeighted PriorityMode = "weighted" // Weighted round-robin, level i weighted 2^i
+)
+
+// MaxPriorityLevels is the largest number of levels a PriorityQueue supports.
+const MaxPriorityLevels = 10
+
+// ErrInvalidPriority is returned for an unsupported number of priority levels or priority mode.
+var ErrInvalidPriority = errors.New("invalid priority configuration")
+
+// ParsePriorityMode returns the priority mode with the given name. The empty name
+// selects PriorityStrict.
+func ParsePriorityMode(name string) (PriorityMode, error) {
+	switch m := PriorityMode(name); m {
+	case "":
+		return PriorityStrict, nil
+	case PriorityStrict, PriorityWeighted:
+		return m, nil
+	}
+	return "", ErrInvalidPriority
+}
+
+// PriorityQueue is a bounded queue of *Message values ordered by message priority
+// (see Message.SetPriority), from 0 (lowest) to the number of levels minus one.
+// Higher priorities are clamped to the top level. It implements Queue and supports
+// the same expiry and overflow settings as MessageQueue; OverflowDropOldest
+// discards the oldest message of the lowest non-empty level.
+type PriorityQueue struct {
+	ringSet               // One ring per priority level, lowest first
+	mode     PriorityMode // How the next level is chosen
+	schedule []int        // Weighted mode: the preferred level for each round-robin turn
+	turn     uint64       // Weighted mode: next round-robin turn (accessed atomically)
+}
+
+// NewPriorityQueue creates a PriorityQueue holding at most capacity messages over
+// the given number of levels. It panics if capacity is zero or levels is not
+// between 1 and MaxPriorityLevels.
+func NewPriorityQueue(capacity uint64, levels int, mode PriorityMode) *PriorityQueue {
+	if levels < 1 || levels > MaxPriorityLevels {
+		panic("mq: PriorityQueue levels out of range")
+	}
+	q := &PriorityQueue{mode: mode}
+	q.init(capacity, levels)
+	if mode == PriorityWeighted {
+		q.schedule = weightedSchedule(levels)
+	}
+	return q
+}
+
+// weightedSchedule spreads the round-robin turns over the levels in proportion to
+// their weights (2^level) using smooth weighted round-robin, so that a level's
+// turns are interleaved with the others rather than bunched together.
+func weightedSchedule(levels int) []int {
+	total := 1<<levels - 1
+	current := make([]int, levels)
+	schedule := make([]int, 0, total)
+	for len(schedule) < total {
+		best := 0
+		for i := range current {
+			current[i] += 1 << i
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		schedule = append(schedule, best)
+	}
+	return schedule
+}
+
+// Levels returns the number of priority levels.
+func (q *PriorityQueue) Levels() int {
+	return len(q.rings)
+}
+
+// Mode returns how the queue chooses the level to dequeue from.
+func (q *PriorityQueue) Mode() PriorityMode {
+	return q.mode
+}
+
+// level returns the ring for the priority of msg.
+func (q *PriorityQueue) level(msg *Message) *MessageQueue {
+	p := msg.GetPriority()
+	if p >= len(q.rings) {
+		p = len(q.rings) - 1
+	}
+	return q.rings[p]
+}
+
+// Enqueue adds a message at its priority level. If the queue is full it applies
+// the queue's overflow policy, which by default rejects the message with ErrFull.
+func (q *PriorityQueue) Enqueue(msg *Message) error {
+	return q.add(q.level(msg), msg)
+}
+
+// Dequeue removes and returns the next message by priority, discarding expired
+// messages. Returns nil and ErrEmpty if every level is empty.
+func (q *PriorityQueue) Dequeue() (*Message, error) {
+	if q.schedule != nil {
+		turn := atomic.AddUint64(&q.turn, 1) - 1
+		if msg, err := q.take(q.rings[q.schedule[turn%uint64(len(q.schedule))]]); err == nil {
+			return msg, nil
+		}
+	}
+	// Strict order: highest level first
+	for i := len(q.rings) - 1; i >= 0; i-- {
+		if msg, err := q.take(q.rings[i]); err == nil {
+			return msg, nil
+		}
+	}
+	return nil, ErrEmpty
+}
+
+// EnqueueContext adds a message at its priority level, parking the caller while
+// the queue is full whatever its overflow policy. It returns ctx.Err() if ctx is
+// done before room frees up.
+func (q *PriorityQueue) EnqueueContext(ctx context.Context, msg *Message) error {
+	return q.putContext(ctx, q.level(msg), msg)
+}
+
+// DequeueContext removes and returns the next message by priority, parking the
+// caller while the queue is empty. It returns ctx.Err() if ctx is done before a message arrives.
+func (q *PriorityQueue) DequeueContext(ctx context.Context) (*Message, error) {
+	return q.takeContext(ctx, q.Dequeue)
+}
+
+// Peek returns up to limit messages without removing them (all of them if limit
+// is zero), highest level first and oldest first within a level.
+func (q *PriorityQueue) Peek(limit int) []*Message {
+	var out []*Message
+	for i := len(q.rings) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		rest := 0
+		if limit > 0 {
+			rest = limit - len(out)
+		}
+		out = append(out, q.rings[i].Peek(rest)...)
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+// priority_test.go - Tests for PriorityQueue.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// priorityMessage returns a text message with the given priority.
+func priorityMessage(s string, priority int) *Message {
+	msg := textMessage(s)
+	msg.SetPriority(priority)
+	return msg
+}
+
+func TestPriorityQueueStrict(t *testing.T) {
+	q := NewPriorityQueue(4, 3, PriorityStrict)
+	for _, msg := range []*Message{
+		priorityMessage("bulk1", 0),
+		priorityMessage("normal", 1),
+		priorityMessage("bulk2", 0),
+		priorityMessage("cancel", 7), // Clamped to the top level
+	} {
+		if err := q.Enqueue(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Enqueue(priorityMessage("overflow", 2)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Enqueue beyond capacity = %v, want ErrFull", err)
+	}
+	if q.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", q.Len())
+	}
+	for _, want := range []string{"cancel", "normal", "bulk1", "bulk2"} {
+		msg, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("waiting for %q: %v", want, err)
+		}
+		if got := string(msg.GetPayload()); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue on empty queue = %v, want ErrEmpty", err)
+	}
+}
+
+func TestPriorityQueueWeightedDoesNotStarve(t *testing.T) {
+	q := NewPriorityQueue(64, 2, PriorityWeighted)
+	for i := 0; i < 30; i++ {
+		if err := q.Enqueue(priorityMessage("high", 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Enqueue(priorityMessage("low", 0)); err != nil {
+		t.Fatal(err)
+	}
+	// Level 1 is weighted twice level 0, so the low message is reached within one round
+	for i := 0; i < 3; i++ {
+		msg, err := q.Dequeue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.GetPayload()) == "low" {
+			return
+		}
+	}
+	t.Fatal("low priority message not delivered within one weighted round")
+}
+
+func TestPriorityQueueBlockingAndDropOldest(t *testing.T) {
+	q := NewPriorityQueue(2, 2, PriorityStrict)
+	var dropped []string
+	q.observe(nil, func(reason string) { dropped = append(dropped, reason) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var got *Message
+	go func() {
+		defer wg.Done()
+		got, _ = q.DequeueContext(ctx)
+	}()
+	if err := q.Enqueue(priorityMessage("wake", 1)); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if got == nil || string(got.GetPayload()) != "wake" {
+		t.Fatalf("DequeueContext = %v", got)
+	}
+
+	q.SetOverflow(OverflowDropOldest, 0)
+	for _, msg := range []*Message{priorityMessage("low", 0), priorityMessage("high", 1), priorityMessage("urgent", 1)} {
+		if err := q.Enqueue(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(dropped) != 1 || dropped[0] != string(OverflowDropOldest) {
+		t.Fatalf("dropped = %v", dropped)
+	}
+	var payloads []string
+	for _, msg := range q.Peek(0) {
+		payloads = append(payloads, string(msg.GetPayload()))
+	}
+	if len(payloads) != 2 || payloads[0] != "high" || payloads[1] != "urgent" {
+		t.Fatalf("after drop_oldest queue holds %v, want the low priority message dropped", payloads)
+	}
+}
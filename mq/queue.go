@@ -1,87 +1,349 @@
 // queue.go - High-performance, lock-free message queue implementation.
 //
 // This file defines the Queue interface and provides a MessageQueue implementation
-// using a fixed-size ring buffer and atomic operations for ultra-low latency and
-// minimal locking. The queue is designed for concurrent producers and consumers.
+// using a fixed-size ring buffer with per-slot sequence numbers (a bounded
+// multi-producer/multi-consumer queue). Producers and consumers claim positions
+// with a CAS on tail/head, but a slot only becomes visible to the other side once
+// its sequence number has been published, so a consumer can never observe a
+// half-written slot and a lapped producer can never overwrite an unread one.
+// Blocking variants park the caller on a notifier until the queue changes state.
+// Expired messages (past their TTL or the queue's maximum age) are discarded by
+// Dequeue instead of being returned, and Sweep reaps them from the head of the queue.
+// What Enqueue does when the queue is full is set by its overflow policy (overflow.go).
+// Besides its slots, a queue can be bounded by the payload bytes it holds and by
+// a memory budget shared with other queues (memory.go).
 
 package mq
 
 import (
-	"errors"   // For error handling
-	"log"      // For logging errors
-	"sync/atomic" // For atomic operations on queue pointers
+	"context"     // For cancelling blocking operations
+	"errors"      // For error handling
+	"sync/atomic" // For atomic operations on queue pointers and slot sequences
+	"time"        // For message expiry
+)
+
+// Errors returned by non-blocking queue operations.
+var (
+	ErrFull   = errors.New("queue is full")   // Returned by Enqueue when there is no free slot
+	ErrEmpty  = errors.New("queue is empty")  // Returned by Dequeue when there is no message
+	ErrClosed = errors.New("queue is closed") // Returned by operations on a queue that has been shut down
 )
 
 // Queue defines the interface for a message queue supporting basic operations.
 type Queue interface {
-	Enqueue(msg []byte) error   // Add a message to the queue
-	Dequeue() ([]byte, error)   // Remove and return the next message
-	Len() uint64                // Get the current number of messages in the queue
+	Enqueue(msg *Message) error                             // Add a message to the queue
+	Dequeue() (*Message, error)                             // Remove and return the next message
+	EnqueueContext(ctx context.Context, msg *Message) error // Add a message, waiting for space until ctx is done
+	DequeueContext(ctx context.Context) (*Message, error)   // Remove the next message, waiting for one until ctx is done
+	Len() uint64                                            // Get the current number of messages in the queue
 }
 
-// MessageQueue is a high-performance, ultra low latency queue for binary messages.
-// It uses a fixed-size ring buffer and atomic operations for minimal locking.
+// slot is a single cell of the ring buffer.
+//
+// seq encodes the state of the slot relative to a queue position pos that maps to it:
+//   - seq == 2*pos:       the slot is free and may be written by the producer that claims pos
+//   - seq == 2*pos+1:     the slot holds the message for pos and may be read by the consumer that claims pos
+//   - seq == 2*(pos+cap): the consumer of pos has released the slot for the next lap
+//
+// Positions are doubled so that "full for pos" and "free for the next lap" never
+// collide, which keeps the scheme correct even for a capacity of one.
+type slot struct {
+	seq uint64                  // Sequence number guarding msg (accessed atomically)
+	msg atomic.Pointer[Message] // Stored message, only valid while seq == 2*pos+1
+}
+
+// ExpiryObserver is implemented by queue wrappers (such as an instrumented queue)
+// that want to count messages discarded because they expired.
+type ExpiryObserver interface {
+	ObserveExpired()
+}
+
+// MessageQueue is a high-performance, ultra low latency queue of *Message values.
+// It uses a fixed-size ring buffer of sequenced slots and atomic operations, so no
+// locks are taken on either the enqueue or the dequeue path.
 type MessageQueue struct {
-	buffer     [][]byte // The ring buffer holding messages
-	capacity   uint64   // Maximum number of messages the queue can hold
-	head       uint64   // Next position to read (consumer index)
-	tail       uint64   // Next position to write (producer index)
-	_          [56]byte // Padding to avoid false sharing (cache line alignment)
+	head     uint64                   // Next position to read (consumer index)
+	_        [56]byte                 // Padding so head and tail live on different cache lines
+	tail     uint64                   // Next position to write (producer index)
+	_        [56]byte                 // Padding to avoid false sharing with the fields below
+	slots    []slot                   // The ring buffer holding messages
+	capacity uint64                   // Maximum number of messages the queue can hold
+	notEmpty notifier                 // Wakes consumers parked in DequeueContext
+	notFull  notifier                 // Wakes producers parked in EnqueueContext
+	maxAge   int64                    // Maximum message age in nanoseconds, 0 for none (accessed atomically)
+	onExpire func(*Message)           // Called for each expired message discarded; set before use
+	overflow atomic.Pointer[overflow] // What Enqueue does when the queue is full
+	onDrop   func(reason string)      // Called for each message discarded by the overflow policy; set before use
+	bytes    *byteCount               // Payload bytes held; shared by the rings of a ringSet
 }
 
 // NewMessageQueue creates a new MessageQueue with the given capacity.
+// It panics if capacity is zero.
 func NewMessageQueue(capacity uint64) *MessageQueue {
-	return &MessageQueue{
-		buffer:   make([][]byte, capacity),
+	if capacity == 0 {
+		panic("mq: MessageQueue capacity must be greater than zero")
+	}
+	q := &MessageQueue{
+		slots:    make([]slot, capacity),
 		capacity: capacity,
+		bytes:    new(byteCount),
 	}
+	q.SetOverflow(OverflowReject, 0)
+	// Slot i starts out free for position i.
+	for i := range q.slots {
+		q.slots[i].seq = 2 * uint64(i)
+	}
+	return q
+}
+
+// Enqueue adds a message to the queue. If the queue is full it applies the
+// queue's overflow policy, which by default rejects the message with ErrFull.
+func (q *MessageQueue) Enqueue(msg *Message) error {
+	if err := q.enqueue(msg); err != ErrFull {
+		return err
+	}
+	return q.enqueueFull(msg)
 }
 
-// Enqueue adds a binary message to the queue.
-// Returns an error if the queue is full.
-// Uses atomic operations to ensure thread safety for concurrent producers.
-func (q *MessageQueue) Enqueue(msg []byte) error {
+// enqueue adds a message to the queue, returning ErrFull if there is no free slot
+// or the byte limits refuse it (see byteCount.reserve for their errors).
+// The message is written before the slot's sequence number is published, so
+// concurrent consumers only ever see fully written slots.
+func (q *MessageQueue) enqueue(msg *Message) error {
+	size := int64(len(msg.payload))
+	if err := q.bytes.reserve(size); err != nil {
+		return err
+	}
 	for {
-		head := atomic.LoadUint64(&q.head)
-		tail := atomic.LoadUint64(&q.tail)
-		// Check if the queue is full
-		if (tail-head) >= q.capacity {
-			log.Println("ERROR: MessageQueue capacity breached. Cannot enqueue new message.")
-			return errors.New("queue is full")
+		pos := atomic.LoadUint64(&q.tail)
+		s := &q.slots[pos%q.capacity]
+		seq := atomic.LoadUint64(&s.seq)
+		switch diff := int64(seq - 2*pos); {
+		case diff == 0:
+			// The slot is free for this lap; try to claim the position
+			if atomic.CompareAndSwapUint64(&q.tail, pos, pos+1) {
+				s.msg.Store(msg)
+				atomic.StoreUint64(&s.seq, 2*pos+1) // Publish the message to consumers
+				q.notEmpty.broadcast()
+				return nil
+			}
+			// If CAS fails, another producer won the race; retry
+		case diff < 0:
+			// The slot still holds a message from the previous lap: the queue is full
+			q.bytes.release(size)
+			return ErrFull
 		}
-		pos := tail % q.capacity
-		// Atomically claim the next slot for writing
-		if atomic.CompareAndSwapUint64(&q.tail, tail, tail+1) {
-			q.buffer[pos] = msg
-			return nil
+		// diff > 0: tail moved on since we loaded it; retry with the new tail
+	}
+}
+
+// SetMaxAge sets the maximum age of messages in the queue, measured from their
+// timestamp; older messages are treated as expired. Zero disables the limit.
+// It is safe to call while the queue is in use.
+func (q *MessageQueue) SetMaxAge(d time.Duration) {
+	atomic.StoreInt64(&q.maxAge, int64(d))
+}
+
+// MaxAge returns the maximum age of messages in the queue (zero if unlimited).
+func (q *MessageQueue) MaxAge() time.Duration {
+	return time.Duration(atomic.LoadInt64(&q.maxAge))
+}
+
+// Dequeue removes and returns the next message from the queue, discarding any
+// expired messages in front of it. Returns nil and ErrEmpty if the queue is empty.
+func (q *MessageQueue) Dequeue() (*Message, error) {
+	for {
+		msg, err := q.dequeue(nil)
+		if err != nil {
+			return nil, err
+		}
+		if !q.expire(msg) {
+			return msg, nil
 		}
-		// If CAS fails, another producer won the race; retry
 	}
 }
 
-// Dequeue removes and returns the next binary message from the queue.
-// Returns nil and an error if the queue is empty.
-// Uses atomic operations to ensure thread safety for concurrent consumers.
-func (q *MessageQueue) Dequeue() ([]byte, error) {
+// expire reports whether msg has expired, counting it if so. The clock is only
+// read when the message or the queue has an expiry.
+func (q *MessageQueue) expire(msg *Message) bool {
+	maxAge := time.Duration(atomic.LoadInt64(&q.maxAge))
+	if maxAge <= 0 && msg.expiresAt.IsZero() {
+		return false
+	}
+	if !msg.expired(time.Now(), maxAge) {
+		return false
+	}
+	if q.onExpire != nil {
+		q.onExpire(msg)
+	}
+	return true
+}
+
+// Sweep discards expired messages from the head of the queue and returns how many
+// it discarded. Expired messages queued behind a live one are left for Dequeue to skip.
+func (q *MessageQueue) Sweep() int {
+	now := time.Now()
+	maxAge := time.Duration(atomic.LoadInt64(&q.maxAge))
+	expired := func(msg *Message) bool { return msg.expired(now, maxAge) }
+	n := 0
 	for {
-		head := atomic.LoadUint64(&q.head)
-		tail := atomic.LoadUint64(&q.tail)
-		// Check if the queue is empty
-		if head == tail {
-			return nil, errors.New("queue is empty")
+		msg, err := q.dequeue(expired)
+		if err != nil {
+			return n
 		}
-		pos := head % q.capacity
-		msg := q.buffer[pos]
-		// Atomically claim the next slot for reading
-		if atomic.CompareAndSwapUint64(&q.head, head, head+1) {
-			q.buffer[pos] = nil // Avoid memory leak by clearing the slot
+		if q.onExpire != nil {
+			q.onExpire(msg)
+		}
+		n++
+	}
+}
+
+// dequeue removes and returns the message at the head of the queue. If match is
+// not nil, the message is only removed if match returns true for it; otherwise
+// ErrEmpty is returned. The slot is handed back to producers only after the
+// message has been read out.
+func (q *MessageQueue) dequeue(match func(*Message) bool) (*Message, error) {
+	for {
+		pos := atomic.LoadUint64(&q.head)
+		s := &q.slots[pos%q.capacity]
+		seq := atomic.LoadUint64(&s.seq)
+		switch diff := int64(seq - (2*pos + 1)); {
+		case diff == 0:
+			if match != nil {
+				// The message stays in place until the CAS below, which fails if anyone else claims it
+				msg := s.msg.Load()
+				if msg == nil {
+					continue // Claimed by a consumer in the meantime; retry
+				}
+				if !match(msg) {
+					return nil, ErrEmpty
+				}
+			}
+			// The slot holds a published message; try to claim the position
+			if atomic.CompareAndSwapUint64(&q.head, pos, pos+1) {
+				msg := s.msg.Load()
+				s.msg.Store(nil)                               // Avoid memory leak by clearing the slot
+				atomic.StoreUint64(&s.seq, 2*(pos+q.capacity)) // Release the slot for the next lap
+				q.bytes.release(int64(len(msg.payload)))
+				q.notFull.broadcast()
+				return msg, nil
+			}
+			// If CAS fails, another consumer won the race; retry
+		case diff < 0:
+			// Nothing has been published at head yet: the queue is empty
+			return nil, ErrEmpty
+		}
+		// diff > 0: head moved on since we loaded it; retry with the new head
+	}
+}
+
+// EnqueueContext adds a message to the queue, parking the caller while the
+// queue or its memory budget is full whatever its overflow policy. It returns
+// ctx.Err() if ctx is done before room frees up, and ErrTooLarge at once for a
+// message that can never fit.
+func (q *MessageQueue) EnqueueContext(ctx context.Context, msg *Message) error {
+	for {
+		if err := q.enqueue(msg); !waitable(err) {
+			return err
+		}
+		budget := q.bytes.budget.Load()
+		ch, freed := q.notFull.prepare(), budget.prepare()
+		// Re-check after registering so room released in between is not missed
+		if err := q.enqueue(msg); !waitable(err) {
+			q.notFull.done()
+			budget.done()
+			return err
+		}
+		select {
+		case <-ch:
+		case <-freed:
+		case <-ctx.Done():
+			q.notFull.done()
+			budget.done()
+			return ctx.Err()
+		}
+		q.notFull.done()
+		budget.done()
+	}
+}
+
+// DequeueContext removes and returns the next message, parking the caller
+// while the queue is empty. It returns ctx.Err() if ctx is done before a message arrives.
+func (q *MessageQueue) DequeueContext(ctx context.Context) (*Message, error) {
+	for {
+		if msg, err := q.Dequeue(); err == nil {
+			return msg, nil
+		}
+		ch := q.notEmpty.prepare()
+		// Re-check after registering so a message published in between is not missed
+		if msg, err := q.Dequeue(); err == nil {
+			q.notEmpty.done()
 			return msg, nil
 		}
-		// If CAS fails, another consumer won the race; retry
+		select {
+		case <-ch:
+			q.notEmpty.done()
+		case <-ctx.Done():
+			q.notEmpty.done()
+			return nil, ctx.Err()
+		}
 	}
 }
 
+// Peek returns up to limit of the oldest messages in the queue without removing
+// them (all of them if limit is zero). Messages consumed while Peek runs are skipped,
+// so under concurrent use the result is a best-effort snapshot.
+func (q *MessageQueue) Peek(limit int) []*Message {
+	head := atomic.LoadUint64(&q.head)
+	tail := atomic.LoadUint64(&q.tail)
+	var out []*Message
+	for pos := head; pos < tail && (limit <= 0 || len(out) < limit); pos++ {
+		s := &q.slots[pos%q.capacity]
+		if atomic.LoadUint64(&s.seq) != 2*pos+1 {
+			continue // Not yet published, or already consumed
+		}
+		msg := s.msg.Load()
+		// Re-check the sequence so a message consumed (and possibly replaced) in between is not reported
+		if msg == nil || atomic.LoadUint64(&s.seq) != 2*pos+1 {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
 // Len returns the number of messages currently in the queue.
+// Under concurrent use the value is a snapshot and may be stale by the time it is read.
 func (q *MessageQueue) Len() uint64 {
-	return atomic.LoadUint64(&q.tail) - atomic.LoadUint64(&q.head)
-}
\ No newline at end of file
+	head := atomic.LoadUint64(&q.head)
+	tail := atomic.LoadUint64(&q.tail)
+	if tail <= head {
+		return 0
+	}
+	if n := tail - head; n < q.capacity {
+		return n
+	}
+	return q.capacity
+}
+
+// Cap returns the maximum number of messages the queue can hold.
+func (q *MessageQueue) Cap() uint64 {
+	return q.capacity
+}
+
+// SetMaxBytes bounds the payload bytes the queue holds. Zero removes the limit.
+// Lowering it below what the queue holds refuses new messages until consumers
+// make room. It is safe to call while the queue is in use.
+func (q *MessageQueue) SetMaxBytes(n uint64) {
+	q.bytes.limit.Store(int64(n))
+}
+
+// Bytes returns the payload bytes currently held by the queue.
+func (q *MessageQueue) Bytes() uint64 {
+	return uint64(max(q.bytes.used.Load(), 0))
+}
+
+// account returns the byte count of the queue.
+func (q *MessageQueue) account() *byteCount {
+	return q.bytes
+}
@@ -0,0 +1,458 @@
+// queue_test.go - Correctness and stress tests for MessageQueue.
+//
+// The stress tests hammer a small ring buffer from many producers and consumers
+// at once so that positions wrap around many times. Every payload carries its
+// producer, a per-producer sequence number and a checksum, which lets the tests
+// prove that no message is lost, duplicated or corrupted. Run them with -race:
+//
+//	go test -race ./mq/...
+
+package mq
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// encodePayload builds a 24-byte payload: producer, sequence and a checksum of both.
+func encodePayload(producer, seq uint64) []byte {
+	buf := make([]byte, 24)
+	binary.LittleEndian.PutUint64(buf[0:], producer)
+	binary.LittleEndian.PutUint64(buf[8:], seq)
+	binary.LittleEndian.PutUint64(buf[16:], checksum(producer, seq))
+	return buf
+}
+
+// decodePayload reverses encodePayload and reports whether the checksum matches.
+func decodePayload(buf []byte) (producer, seq uint64, ok bool) {
+	if len(buf) != 24 {
+		return 0, 0, false
+	}
+	producer = binary.LittleEndian.Uint64(buf[0:])
+	seq = binary.LittleEndian.Uint64(buf[8:])
+	return producer, seq, binary.LittleEndian.Uint64(buf[16:]) == checksum(producer, seq)
+}
+
+// textMessage wraps s in a Message with a fresh ID.
+func textMessage(s string) *Message {
+	return NewMessage(NewID(), []byte(s))
+}
+
+func checksum(producer, seq uint64) uint64 {
+	return (producer*0x9E3779B97F4A7C15 ^ seq) * 0xBF58476D1CE4E5B9
+}
+
+// TestMain silences the "capacity breached" log line, which the stress tests hit constantly.
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func TestMessageQueueFIFO(t *testing.T) {
+	q := NewMessageQueue(4)
+	for round := 0; round < 3; round++ { // Several rounds to exercise wrap-around
+		for i := 0; i < 4; i++ {
+			if err := q.Enqueue(NewMessage(NewID(), []byte{byte(round), byte(i)})); err != nil {
+				t.Fatalf("round %d: enqueue %d: %v", round, i, err)
+			}
+		}
+		if err := q.Enqueue(textMessage("overflow")); err == nil {
+			t.Fatalf("round %d: enqueue into a full queue succeeded", round)
+		}
+		if got := q.Len(); got != 4 {
+			t.Fatalf("round %d: Len() = %d, want 4", round, got)
+		}
+		for i := 0; i < 4; i++ {
+			msg, err := q.Dequeue()
+			if err != nil {
+				t.Fatalf("round %d: dequeue %d: %v", round, i, err)
+			}
+			if p := msg.GetPayload(); p[0] != byte(round) || p[1] != byte(i) {
+				t.Fatalf("round %d: dequeue %d returned %v", round, i, p)
+			}
+		}
+		if _, err := q.Dequeue(); err == nil {
+			t.Fatalf("round %d: dequeue from an empty queue succeeded", round)
+		}
+		if got := q.Len(); got != 0 {
+			t.Fatalf("round %d: Len() = %d, want 0", round, got)
+		}
+	}
+}
+
+func TestMessageQueueCarriesEnvelope(t *testing.T) {
+	q := NewMessageQueue(2)
+	in := textMessage("body")
+	in.SetHeaders(map[string]string{"trace": "abc"})
+	in.SetContentType("text/plain")
+	if err := q.Enqueue(in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := q.Dequeue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.GetID() != in.GetID() || out.GetHeader("trace") != "abc" ||
+		out.GetContentType() != "text/plain" || out.GetTimestamp().IsZero() {
+		t.Fatalf("envelope not preserved: %+v", out)
+	}
+	if a, b := NewID(), NewID(); a == b || a == "" {
+		t.Fatalf("NewID returned %q twice", a)
+	}
+}
+
+func TestMessageQueueExpiry(t *testing.T) {
+	q := NewMessageQueue(4)
+	expired := 0
+	q.onExpire = func(*Message) { expired++ }
+
+	short := textMessage("short")
+	short.SetTTL(time.Millisecond)
+	if err := q.Enqueue(short); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(textMessage("live")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	msg, err := q.Dequeue()
+	if err != nil || string(msg.GetPayload()) != "live" {
+		t.Fatalf("Dequeue = %v, %v; want the live message", msg, err)
+	}
+	if expired != 1 {
+		t.Fatalf("expired = %d, want 1", expired)
+	}
+
+	// Queue-level max age: the sweeper reaps old messages from the head
+	if err := q.Enqueue(textMessage("old")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := q.Enqueue(textMessage("new")); err != nil {
+		t.Fatal(err)
+	}
+	q.SetMaxAge(3 * time.Millisecond)
+	if n := q.Sweep(); n != 1 || q.Len() != 1 {
+		t.Fatalf("Sweep = %d, Len = %d; want 1, 1", n, q.Len())
+	}
+	q.SetMaxAge(0)
+	if n := q.Sweep(); n != 0 {
+		t.Fatalf("Sweep without max age = %d", n)
+	}
+	if msg, err := q.Dequeue(); err != nil || string(msg.GetPayload()) != "new" {
+		t.Fatalf("Dequeue = %v, %v; want the new message", msg, err)
+	}
+	if expired != 2 {
+		t.Fatalf("expired = %d, want 2", expired)
+	}
+}
+
+func TestMessageQueueOverflowPolicies(t *testing.T) {
+	fill := func(policy OverflowPolicy, timeout time.Duration) (*MessageQueue, map[string]int) {
+		q := NewMessageQueue(2)
+		q.SetOverflow(policy, timeout)
+		dropped := map[string]int{}
+		q.onDrop = func(reason string) { dropped[reason]++ }
+		for _, s := range []string{"a", "b"} {
+			if err := q.Enqueue(textMessage(s)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return q, dropped
+	}
+	payloads := func(q *MessageQueue) string {
+		var out string
+		for _, msg := range q.Peek(0) {
+			out += string(msg.GetPayload())
+		}
+		return out
+	}
+
+	q, dropped := fill(OverflowReject, 0)
+	if err := q.Enqueue(textMessage("c")); !errors.Is(err, ErrFull) || payloads(q) != "ab" || len(dropped) != 0 {
+		t.Fatalf("reject: err = %v, queue %q, dropped %v", err, payloads(q), dropped)
+	}
+
+	q, dropped = fill(OverflowDropOldest, 0)
+	if err := q.Enqueue(textMessage("c")); err != nil || payloads(q) != "bc" || dropped[string(OverflowDropOldest)] != 1 {
+		t.Fatalf("drop_oldest: err = %v, queue %q, dropped %v", err, payloads(q), dropped)
+	}
+
+	q, dropped = fill(OverflowDropNewest, 0)
+	if err := q.Enqueue(textMessage("c")); err != nil || payloads(q) != "ab" || dropped[string(OverflowDropNewest)] != 1 {
+		t.Fatalf("drop_newest: err = %v, queue %q, dropped %v", err, payloads(q), dropped)
+	}
+
+	q, _ = fill(OverflowBlock, 30*time.Millisecond)
+	start := time.Now()
+	if err := q.Enqueue(textMessage("c")); !errors.Is(err, ErrFull) || time.Since(start) < 30*time.Millisecond {
+		t.Fatalf("block: err = %v after %v, want ErrFull after the timeout", err, time.Since(start))
+	}
+	q.SetOverflow(OverflowBlock, 5*time.Second)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = q.Dequeue()
+	}()
+	if err := q.Enqueue(textMessage("c")); err != nil || payloads(q) != "bc" {
+		t.Fatalf("block: err = %v, queue %q once a slot freed up", err, payloads(q))
+	}
+}
+
+func TestMessageQueueZeroCapacityPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewMessageQueue(0) did not panic")
+		}
+	}()
+	NewMessageQueue(0)
+}
+
+func TestMessageQueueDequeueContextWaitsForMessage(t *testing.T) {
+	q := NewMessageQueue(2)
+	got := make(chan *Message, 1)
+	go func() {
+		msg, err := q.DequeueContext(context.Background())
+		if err != nil {
+			t.Errorf("DequeueContext: %v", err)
+		}
+		got <- msg
+	}()
+	time.Sleep(20 * time.Millisecond) // Give the consumer time to park
+	if err := q.Enqueue(textMessage("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-got:
+		if msg == nil || string(msg.GetPayload()) != "hello" {
+			t.Fatalf("DequeueContext returned %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("parked consumer was not woken by Enqueue")
+	}
+}
+
+func TestMessageQueueEnqueueContextWaitsForSpace(t *testing.T) {
+	q := NewMessageQueue(1)
+	if err := q.Enqueue(textMessage("a")); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- q.EnqueueContext(context.Background(), textMessage("b")) }()
+	time.Sleep(20 * time.Millisecond) // Give the producer time to park
+	if msg, err := q.Dequeue(); err != nil || string(msg.GetPayload()) != "a" {
+		t.Fatalf("Dequeue = %v, %v", msg, err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("EnqueueContext: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("parked producer was not woken by Dequeue")
+	}
+	if msg, err := q.Dequeue(); err != nil || string(msg.GetPayload()) != "b" {
+		t.Fatalf("Dequeue = %v, %v", msg, err)
+	}
+}
+
+func TestMessageQueueContextCancellation(t *testing.T) {
+	q := NewMessageQueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.DequeueContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("DequeueContext on empty queue = %v, want DeadlineExceeded", err)
+	}
+	if err := q.Enqueue(textMessage("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.EnqueueContext(ctx, textMessage("y")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EnqueueContext on full queue = %v, want DeadlineExceeded", err)
+	}
+	if _, err := q.Dequeue(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Dequeue on empty queue = %v, want ErrEmpty", err)
+	}
+}
+
+// TestMessageQueueBlockingStress moves messages through a tiny queue using only the
+// blocking calls, so every producer and consumer repeatedly parks and is woken.
+func TestMessageQueueBlockingStress(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 5000
+	q := NewMessageQueue(2)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var received atomic.Int64
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p uint64) {
+			defer wg.Done()
+			for seq := uint64(0); seq < perProducer; seq++ {
+				if err := q.EnqueueContext(ctx, NewMessage("", encodePayload(p, seq))); err != nil {
+					t.Errorf("EnqueueContext: %v", err)
+					return
+				}
+			}
+		}(uint64(p))
+	}
+	per := producers * perProducer / consumers
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				msg, err := q.DequeueContext(ctx)
+				if err != nil {
+					t.Errorf("DequeueContext: %v", err)
+					return
+				}
+				if _, _, ok := decodePayload(msg.GetPayload()); !ok {
+					t.Error("corrupted payload")
+				}
+				received.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := received.Load(); got != producers*perProducer {
+		t.Fatalf("received %d messages, want %d", got, producers*perProducer)
+	}
+}
+
+// runStress pushes perProducer messages from each producer through a queue of the
+// given capacity while consumers drain it, then checks every message arrived once,
+// intact, and in order with respect to its producer as seen by each consumer.
+func runStress(t *testing.T, capacity uint64, producers, consumers int, perProducer uint64) {
+	t.Helper()
+	q := NewMessageQueue(capacity)
+	total := uint64(producers) * perProducer
+
+	var consumed atomic.Uint64
+	seen := make([][]atomic.Uint32, producers) // seen[p][seq] counts deliveries
+	for p := range seen {
+		seen[p] = make([]atomic.Uint32, perProducer)
+	}
+	errs := make(chan string, 16)
+	report := func(msg string) {
+		select {
+		case errs <- msg:
+		default:
+		}
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p uint64) {
+			defer wg.Done()
+			for seq := uint64(0); seq < perProducer; {
+				if err := q.Enqueue(NewMessage("", encodePayload(p, seq))); err != nil {
+					runtime.Gosched() // Full: let consumers make progress
+					continue
+				}
+				seq++
+			}
+		}(uint64(p))
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := make([]int64, producers) // Last sequence seen from each producer by this consumer
+			for i := range last {
+				last[i] = -1
+			}
+			for consumed.Load() < total {
+				msg, err := q.Dequeue()
+				if err != nil {
+					runtime.Gosched() // Empty: let producers make progress
+					continue
+				}
+				p, seq, ok := decodePayload(msg.GetPayload())
+				if !ok || p >= uint64(producers) || seq >= perProducer {
+					report("corrupted payload")
+					consumed.Add(1)
+					continue
+				}
+				if int64(seq) <= last[p] {
+					report("out-of-order delivery within a producer")
+				}
+				last[p] = int64(seq)
+				if seen[p][seq].Add(1) > 1 {
+					report("duplicate delivery")
+				}
+				consumed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Error(e)
+	}
+	for p := range seen {
+		for seq := range seen[p] {
+			if n := seen[p][seq].Load(); n != 1 {
+				t.Fatalf("producer %d message %d delivered %d times", p, seq, n)
+			}
+		}
+	}
+	if got := q.Len(); got != 0 {
+		t.Fatalf("Len() = %d after draining, want 0", got)
+	}
+}
+
+func TestMessageQueueStressSPSC(t *testing.T) {
+	runStress(t, 8, 1, 1, 50000)
+}
+
+func TestMessageQueueStressMPSC(t *testing.T) {
+	runStress(t, 16, 8, 1, 10000)
+}
+
+func TestMessageQueueStressSPMC(t *testing.T) {
+	runStress(t, 16, 1, 8, 50000)
+}
+
+func TestMessageQueueStressMPMC(t *testing.T) {
+	runStress(t, 32, 8, 8, 10000)
+}
+
+func TestMessageQueueStressTinyCapacity(t *testing.T) {
+	// Capacity 1 forces every producer to wait for a consumer on every message.
+	runStress(t, 1, 4, 4, 5000)
+}
+
+func TestMessageQueueStressNonPowerOfTwo(t *testing.T) {
+	runStress(t, 7, 6, 5, 8000)
+}
+
+func BenchmarkMessageQueueEnqueueDequeue(b *testing.B) {
+	q := NewMessageQueue(1024)
+	msg := textMessage("payload")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for q.Enqueue(msg) != nil {
+				runtime.Gosched()
+			}
+			for {
+				if _, err := q.Dequeue(); err == nil {
+					break
+				}
+				runtime.Gosched()
+			}
+		}
+	})
+}
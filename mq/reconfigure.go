@@ -0,0 +1,52 @@
+// reconfigure.go - Changing the configuration of a live queue.
+//
+// This file lets a server apply a new configuration to a queue without
+// recreating it, as a config reload does. Only the settings the queue reads on
+// every operation can change: the maximum message age, the byte limit and the
+// overflow policy with its block timeout. Anything that decides how the queue was built, such as
+// its capacity, priority levels, partitions, durability or dead-letter queue,
+// is fixed for the queue's lifetime, and a change to it is refused with
+// ErrUnsafeChange.
+
+package mq
+
+import "errors" // For the reconfiguration error
+
+// ErrUnsafeChange is returned by Reconfigure for a change that requires recreating the queue.
+var ErrUnsafeChange = errors.New("change requires recreating the queue")
+
+// Reconfigure applies cfg to the existing queue called name. cfg is validated as
+// Create would, and may differ from the queue's configuration only in MaxAge,
+// MaxBytes, Overflow and BlockTimeout; any other difference returns ErrUnsafeChange and
+// leaves the queue untouched. Returns ErrQueueNotFound if there is no such queue.
+// A durable queue's saved configuration is updated, so the change survives a restart.
+func (r *Registry) Reconfigure(name string, cfg QueueConfig) error {
+	if err := r.validate(name, &cfg); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nq, ok := r.queues[name]
+	if !ok {
+		return ErrQueueNotFound
+	}
+	old := nq.Config()
+	fixed := cfg
+	fixed.MaxAge, fixed.MaxBytes, fixed.Overflow, fixed.BlockTimeout = old.MaxAge, old.MaxBytes, old.Overflow, old.BlockTimeout
+	if fixed != old {
+		return ErrUnsafeChange
+	}
+	if cfg == old {
+		return nil
+	}
+	if nq.journal != nil {
+		if err := saveQueueConfig(r.queueDir(name), cfg); err != nil {
+			return err
+		}
+	}
+	nq.base.SetMaxAge(cfg.MaxAge)
+	nq.base.SetMaxBytes(cfg.MaxBytes)
+	nq.base.SetOverflow(cfg.Overflow, cfg.BlockTimeout)
+	nq.config.Store(&cfg)
+	return nil
+}
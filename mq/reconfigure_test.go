@@ -0,0 +1,61 @@
+// reconfigure_test.go - Tests for reconfiguring live queues.
+
+package mq
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryReconfigure(t *testing.T) {
+	r := NewRegistry(nil)
+	orders, err := r.Create("orders", QueueConfig{Capacity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.Enqueue(NewMessage(NewID(), []byte("o1"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.Enqueue(NewMessage(NewID(), []byte("o2"))); !errors.Is(err, ErrFull) {
+		t.Fatalf("Enqueue on a full queue = %v, want ErrFull", err)
+	}
+
+	// The overflow policy and maximum age change in place
+	if err := r.Reconfigure("orders", QueueConfig{Capacity: 1, Overflow: OverflowDropOldest, MaxAge: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.Enqueue(NewMessage(NewID(), []byte("o2"))); err != nil {
+		t.Fatalf("Enqueue after switching to drop-oldest = %v", err)
+	}
+	if msg, err := orders.Dequeue(); err != nil || string(msg.GetPayload()) != "o2" {
+		t.Fatalf("Dequeue = %v, %v; want o2", msg, err)
+	}
+	cfg := orders.Config()
+	if cfg.Overflow != OverflowDropOldest || cfg.MaxAge != time.Hour {
+		t.Fatalf("Config() = %+v", cfg)
+	}
+	if infos := r.List(); infos[0].Overflow != OverflowDropOldest || infos[0].MaxAge != time.Hour {
+		t.Fatalf("List() = %+v", infos)
+	}
+
+	// Anything that decides how the queue was built is refused
+	for _, bad := range []QueueConfig{
+		{Capacity: 2, Overflow: OverflowDropOldest, MaxAge: time.Hour},
+		{Capacity: 1, Overflow: OverflowDropOldest, MaxAge: time.Hour, PriorityLevels: 3},
+		{Capacity: 1, Overflow: OverflowDropOldest, MaxAge: time.Hour, MaxDeliveryAttempts: 3},
+	} {
+		if err := r.Reconfigure("orders", bad); !errors.Is(err, ErrUnsafeChange) {
+			t.Errorf("Reconfigure(%+v) = %v, want ErrUnsafeChange", bad, err)
+		}
+	}
+	if orders.Config() != cfg {
+		t.Fatalf("refused change altered the config: %+v", orders.Config())
+	}
+	if err := r.Reconfigure("orders", QueueConfig{Capacity: 1, Overflow: "sideways"}); !errors.Is(err, ErrInvalidOverflowPolicy) {
+		t.Fatalf("Reconfigure with an unknown policy = %v, want ErrInvalidOverflowPolicy", err)
+	}
+	if err := r.Reconfigure("missing", QueueConfig{Capacity: 1}); !errors.Is(err, ErrQueueNotFound) {
+		t.Fatalf("Reconfigure of a missing queue = %v, want ErrQueueNotFound", err)
+	}
+}
@@ -0,0 +1,556 @@
+// registry.go - Named queue registry.
+//
+// This file defines Registry, which creates, looks up, lists and deletes
+// independent named queues. Each queue has its own capacity and is built
+// through an optional WrapFunc, which callers use to attach per-queue
+// instrumentation such as an InstrumentedQueue, and has its own Leases for
+// at-least-once consumption and a Scheduler for delayed delivery. A queue with
+// a maximum number of delivery attempts is linked to a dead-letter queue, which
+// the registry creates if needed. A background sweeper per queue reaps expired
+// messages. Depending on its configuration a queue is a plain FIFO MessageQueue,
+// a PriorityQueue or a PartitionedQueue, and durable queues are journaled to a
+// write-ahead log (journal.go). Queues may be bounded by payload bytes and share
+// a memory budget (memory.go).
+
+package mq
+
+import (
+	"context"     // For blocking dequeues and the registry's lifetime
+	"errors"      // For registry errors
+	"io"          // For closing wrapped queues on delete
+	"os"          // For removing the logs of deleted durable queues
+	"sort"        // For stable listing order
+	"strings"     // For recognizing subscription queues
+	"sync"        // For guarding the queue map
+	"sync/atomic" // For the draining flag and live configuration
+	"time"        // For visibility timeouts
+)
+
+// DefaultQueueName is the queue used when a client does not name one.
+const DefaultQueueName = "default"
+
+// MaxCapacity is the largest number of slots a queue can be created with. A queue
+// allocates all of its slots up front, and a priority or partitioned queue
+// allocates its capacity once per level or partition, so the bound applies to
+// capacity times levels or partitions. It keeps a single request from claiming
+// more memory than the server has.
+const MaxCapacity = 1 << 24
+
+// maxQueueNameLen bounds queue names so they stay usable as metric labels and URL segments.
+const maxQueueNameLen = 128
+
+// Errors returned by Registry operations.
+var (
+	ErrQueueExists       = errors.New("queue already exists")
+	ErrQueueNotFound     = errors.New("queue not found")
+	ErrInvalidQueueName  = errors.New("invalid queue name: use 1-128 characters from [A-Za-z0-9._-]")
+	ErrInvalidCapacity   = errors.New("queue capacity must be greater than zero and at most 16777216 slots over all priority levels or partitions")
+	ErrInvalidDeadLetter = errors.New("a queue cannot be its own dead-letter queue")
+	ErrQueueInUse        = errors.New("queue is the dead-letter queue of another queue")
+)
+
+// DeadLetterSuffix is appended to a queue's name to name its dead-letter queue
+// when the configuration does not name one.
+const DeadLetterSuffix = ".dlq"
+
+// QueueConfig describes how a named queue is built.
+type QueueConfig struct {
+	Capacity          uint64         // Maximum number of messages the queue can hold
+	MaxBytes          uint64         // Maximum payload bytes the queue can hold (zero is unlimited)
+	VisibilityTimeout time.Duration  // Default lease duration (zero selects DefaultVisibilityTimeout)
+	MaxAge            time.Duration  // Messages older than this expire (zero disables)
+	Overflow          OverflowPolicy // What Enqueue does when the queue is full (empty selects OverflowReject)
+	BlockTimeout      time.Duration  // How long OverflowBlock waits for space (zero selects DefaultBlockTimeout)
+	PriorityLevels    int            // Number of priority levels; 0 or 1 builds a plain FIFO queue
+	PriorityMode      PriorityMode   // How a priority queue picks the next level (empty selects PriorityStrict)
+	Partitions        int            // Number of keyed partitions; 0 or 1 builds an unpartitioned queue
+	Durable           bool           // Journal the queue to disk so its messages survive a restart
+
+	// MaxDeliveryAttempts is how many failed deliveries (nacks or expired leases)
+	// a message may have before it moves to the dead-letter queue. Zero disables
+	// dead-lettering, so failing messages are redelivered forever.
+	MaxDeliveryAttempts int
+	// DeadLetterQueue names the dead-letter queue; empty selects name+DeadLetterSuffix.
+	// It is created with the same capacity if it does not exist.
+	DeadLetterQueue string
+}
+
+// WrapFunc decorates a freshly built queue, for example to record metrics.
+// If the returned queue implements io.Closer it is closed when the queue is deleted.
+type WrapFunc func(name string, q Queue) Queue
+
+// storage is implemented by the queue types a Registry builds named queues on.
+type storage interface {
+	Queue
+	Peeker
+	SetMaxAge(d time.Duration)
+	SetOverflow(policy OverflowPolicy, timeout time.Duration)
+	SetMaxBytes(n uint64)
+	Bytes() uint64
+	Sweep() int
+	observe(onExpire func(*Message), onDrop func(reason string))
+	account() *byteCount
+}
+
+// NamedQueue is a queue registered under a name in a Registry.
+// It embeds the (possibly wrapped) queue, so it can be used wherever a Queue is expected.
+type NamedQueue struct {
+	Queue                                  // Underlying queue, as returned by the registry's WrapFunc
+	base       storage                     // Unwrapped queue, for operations outside the Queue interface
+	name       string                      // Registry name of the queue
+	config     atomic.Pointer[QueueConfig] // Current configuration; Reconfigure replaces it
+	leases     *Leases                     // In-flight messages of lease-based consumers
+	scheduler  *Scheduler                  // Delayed messages that are not yet ready
+	deadLetter *NamedQueue                 // Linked dead-letter queue, or nil
+	topic      string                      // Topic the queue subscribes to, if it is a subscription
+	journal    *journal                    // Write-ahead log of a durable queue, or nil
+	recovered  RecoveredQueue              // What was recovered from the log when the queue was built
+	stop       chan struct{}               // Closed on delete to stop the sweeper
+}
+
+// Name returns the name the queue is registered under.
+func (nq *NamedQueue) Name() string {
+	return nq.name
+}
+
+// Config returns the configuration of the queue: the one it was created with, as
+// changed by Reconfigure.
+func (nq *NamedQueue) Config() QueueConfig {
+	return *nq.config.Load()
+}
+
+// Leases returns the lease tracker used for at-least-once consumption from the queue.
+func (nq *NamedQueue) Leases() *Leases {
+	return nq.leases
+}
+
+// Scheduler returns the holding area for delayed messages of the queue.
+func (nq *NamedQueue) Scheduler() *Scheduler {
+	return nq.scheduler
+}
+
+// Dequeue removes and returns the next message for good, like the embedded
+// queue's Dequeue; on a durable queue the removal is journaled.
+func (nq *NamedQueue) Dequeue() (*Message, error) {
+	msg, err := nq.Queue.Dequeue()
+	if err == nil && nq.journal != nil {
+		nq.journal.removed(msg.id, recordDequeue)
+	}
+	return msg, err
+}
+
+// DequeueContext is like Dequeue but waits for a message until ctx is done.
+func (nq *NamedQueue) DequeueContext(ctx context.Context) (*Message, error) {
+	msg, err := nq.Queue.DequeueContext(ctx)
+	if err == nil && nq.journal != nil {
+		nq.journal.removed(msg.id, recordDequeue)
+	}
+	return msg, err
+}
+
+// Durable reports whether the queue is journaled to disk.
+func (nq *NamedQueue) Durable() bool {
+	return nq.journal != nil
+}
+
+// Join adds a consumer to a partitioned queue (see PartitionedQueue.Join).
+// Returns ErrNotPartitioned if the queue has no partitions.
+func (nq *NamedQueue) Join(consumer string) (*PartitionConsumer, error) {
+	pq, ok := nq.base.(*PartitionedQueue)
+	if !ok {
+		return nil, ErrNotPartitioned
+	}
+	return pq.Join(consumer), nil
+}
+
+// QueueInfo is a point-in-time summary of a named queue.
+type QueueInfo struct {
+	Name      string         // Registry name of the queue
+	Capacity  uint64         // Maximum number of messages the queue can hold
+	Len       uint64         // Number of messages in the queue when it was listed
+	MaxBytes  uint64         // Maximum payload bytes the queue can hold (zero if unlimited)
+	Bytes     uint64         // Payload bytes in the queue when it was listed
+	InFlight  uint64         // Number of leased messages awaiting ack when it was listed
+	Scheduled uint64         // Number of delayed messages not yet ready when it was listed
+	MaxAge    time.Duration  // Messages older than this expire (zero if unlimited)
+	Overflow  OverflowPolicy // What Enqueue does when the queue is full
+	Durable   bool           // Whether the queue is journaled to disk
+	// PriorityLevels and PriorityMode describe a priority queue (zero and empty for a FIFO queue).
+	PriorityLevels int
+	PriorityMode   PriorityMode
+	// Partitions and Assignments describe a partitioned queue (zero and nil otherwise).
+	Partitions  int
+	Assignments []PartitionAssignment
+
+	MaxDeliveryAttempts int    // Failed deliveries before dead-lettering (0 if disabled)
+	DeadLetterQueue     string // Name of the linked dead-letter queue, if any
+	Topic               string // Topic the queue subscribes to, if it is a subscription
+}
+
+// Registry manages a set of independent named queues, the topics built on them and logs.
+// It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex                      // Guards queues, topics and logs
+	queues     map[string]*NamedQueue            // Queues by name
+	topics     map[string]map[string]*NamedQueue // Subscription queues by topic and subscription name
+	logs       map[string]*Log                   // Logs by name
+	wrap       WrapFunc                          // Optional decorator applied to each new queue
+	observeLog func(name string) LogObserver     // Optional observer factory for each new log
+	durability *Durability                       // Where durable queues keep their logs, or nil if disabled
+	snapshots  string                            // File SaveSnapshot writes and LoadSnapshot reads ("" if none)
+	budget     *MemoryBudget                     // Memory budget shared by every queue, or nil
+	draining   atomic.Bool                       // Set once producers are stopped (drain.go)
+	ctx        context.Context                   // Done once the registry is closed, ending its background work
+	cancel     context.CancelFunc                // Cancels ctx
+}
+
+// NewRegistry creates an empty Registry. wrap may be nil.
+func NewRegistry(wrap WrapFunc) *Registry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Registry{
+		queues: make(map[string]*NamedQueue),
+		topics: make(map[string]map[string]*NamedQueue),
+		logs:   make(map[string]*Log),
+		wrap:   wrap,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// ValidQueueName reports whether name can be used for a queue.
+func ValidQueueName(name string) bool {
+	if name == "" || len(name) > maxQueueNameLen {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Create builds a new queue with the given configuration and registers it under name.
+// Returns ErrQueueExists if a queue with that name is already registered.
+// If cfg sets MaxDeliveryAttempts, the queue is linked to its dead-letter queue,
+// which is created first if it does not exist yet.
+func (r *Registry) Create(name string, cfg QueueConfig) (*NamedQueue, error) {
+	if !ValidQueueName(name) {
+		return nil, ErrInvalidQueueName
+	}
+	if err := r.validate(name, &cfg); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(name, cfg)
+}
+
+// validate checks cfg for a queue called name and fills in its defaults.
+func (r *Registry) validate(name string, cfg *QueueConfig) error {
+	if cfg.Durable && r.durability == nil {
+		return ErrDurabilityDisabled
+	}
+	return cfg.normalize(name)
+}
+
+// Validate checks cfg for a queue called name as Create would, so configuration
+// can be rejected before any queue is built. Whether durable queues are enabled
+// depends on the registry and is not checked.
+func (cfg QueueConfig) Validate(name string) error {
+	if !ValidQueueName(name) {
+		return ErrInvalidQueueName
+	}
+	return cfg.normalize(name)
+}
+
+// normalize checks cfg for a queue called name and fills in its defaults.
+func (cfg *QueueConfig) normalize(name string) error {
+	policy, err := ParseOverflowPolicy(string(cfg.Overflow))
+	if err != nil {
+		return err
+	}
+	cfg.Overflow = policy
+	if cfg.PriorityLevels < 0 || cfg.PriorityLevels > MaxPriorityLevels {
+		return ErrInvalidPriority
+	}
+	if cfg.Partitions < 0 || cfg.Partitions > MaxPartitions || (cfg.Partitions > 1 && cfg.PriorityLevels > 1) {
+		return ErrInvalidPartitions
+	}
+	if cfg.Partitions == 1 {
+		cfg.Partitions = 0
+	}
+	if cfg.Durable && (cfg.Partitions > 1 || cfg.Overflow == OverflowDropOldest || cfg.Overflow == OverflowDropNewest) {
+		return ErrInvalidDurability
+	}
+	if cfg.PriorityLevels > 1 {
+		if cfg.PriorityMode, err = ParsePriorityMode(string(cfg.PriorityMode)); err != nil {
+			return err
+		}
+	} else {
+		cfg.PriorityLevels, cfg.PriorityMode = 0, ""
+	}
+	rings := uint64(max(cfg.PriorityLevels, cfg.Partitions, 1))
+	if cfg.Capacity == 0 || cfg.Capacity > MaxCapacity/rings {
+		return ErrInvalidCapacity
+	}
+	if cfg.MaxDeliveryAttempts > 0 {
+		if cfg.DeadLetterQueue == "" {
+			cfg.DeadLetterQueue = name + DeadLetterSuffix
+		} else if !ValidQueueName(cfg.DeadLetterQueue) {
+			return ErrInvalidQueueName
+		}
+		if cfg.DeadLetterQueue == name {
+			return ErrInvalidDeadLetter
+		}
+	} else {
+		cfg.DeadLetterQueue = ""
+	}
+	return nil
+}
+
+// create builds and registers a queue, and its dead-letter queue if it needs one.
+// The caller holds r.mu and has validated cfg.
+func (r *Registry) create(name string, cfg QueueConfig) (*NamedQueue, error) {
+	if _, ok := r.queues[name]; ok {
+		return nil, ErrQueueExists
+	}
+	var dlq *NamedQueue
+	created := false
+	if cfg.DeadLetterQueue != "" {
+		dlq = r.queues[cfg.DeadLetterQueue]
+		if dlq == nil {
+			created = true
+			var err error
+			dlq, err = r.build(cfg.DeadLetterQueue, QueueConfig{
+				Capacity:          cfg.Capacity,
+				MaxBytes:          cfg.MaxBytes,
+				VisibilityTimeout: cfg.VisibilityTimeout,
+				MaxAge:            cfg.MaxAge,
+				Durable:           cfg.Durable,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	nq, err := r.build(name, cfg)
+	if err != nil {
+		if created {
+			// Do not leave behind a dead-letter queue created for a queue that failed
+			delete(r.queues, cfg.DeadLetterQueue)
+			r.discard(cfg.DeadLetterQueue, dlq)
+		}
+		return nil, err
+	}
+	if dlq != nil {
+		nq.deadLetter = dlq
+		nq.leases.setDeadLetter(name, cfg.MaxDeliveryAttempts, dlq)
+		nq.scheduler.setDeadLetter(name, dlq)
+	}
+	return nq, nil
+}
+
+// build creates and registers a queue, recovering the messages of a durable queue
+// from its log. The caller holds r.mu and has validated cfg.
+func (r *Registry) build(name string, cfg QueueConfig) (*NamedQueue, error) {
+	var base storage
+	if cfg.Partitions > 1 {
+		base = NewPartitionedQueue(cfg.Capacity, cfg.Partitions)
+	} else if cfg.PriorityLevels > 1 {
+		base = NewPriorityQueue(cfg.Capacity, cfg.PriorityLevels, cfg.PriorityMode)
+	} else {
+		base = NewMessageQueue(cfg.Capacity)
+	}
+	base.SetMaxAge(cfg.MaxAge)
+	base.SetOverflow(cfg.Overflow, cfg.BlockTimeout)
+	base.SetMaxBytes(cfg.MaxBytes)
+	base.account().join(r.budget)
+	var q Queue = base
+	var j *journal
+	var journaled []journaledMessage
+	recovered := RecoveredQueue{Name: name}
+	if cfg.Durable {
+		var err error
+		if j, journaled, recovered, err = r.journal(name, cfg); err != nil {
+			return nil, err
+		}
+		q = &journaledQueue{storage: base, journal: j}
+	}
+	if r.wrap != nil {
+		q = r.wrap(name, q)
+	}
+	var onExpire func(*Message)
+	var onDrop func(string)
+	o, observed := q.(ExpiryObserver)
+	if observed || j != nil {
+		onExpire = func(msg *Message) {
+			if j != nil {
+				j.removed(msg.id, recordDequeue)
+			}
+			if observed {
+				o.ObserveExpired()
+			}
+		}
+	}
+	if o, ok := q.(DropObserver); ok {
+		onDrop = o.ObserveDropped
+	}
+	base.observe(onExpire, onDrop)
+	if pq, ok := base.(*PartitionedQueue); ok {
+		if o, ok := q.(DequeueObserver); ok {
+			pq.onDequeue = o.ObserveDequeued
+		}
+	}
+	nq := &NamedQueue{
+		Queue:     q,
+		base:      base,
+		name:      name,
+		leases:    NewLeases(q, cfg.VisibilityTimeout),
+		scheduler: NewScheduler(q, int(cfg.Capacity)),
+		journal:   j,
+		recovered: recovered,
+		stop:      make(chan struct{}),
+	}
+	nq.config.Store(&cfg)
+	if j != nil {
+		nq.leases.onSettle = func(id string) { j.removed(id, recordAck) }
+		nq.scheduler.journal = j
+	}
+	if pq, ok := base.(*PartitionedQueue); ok {
+		nq.leases.tracker = pq
+	}
+	r.restore(nq, journaled)
+	r.queues[name] = nq
+	go sweep(base, nq.stop)
+	return nq, nil
+}
+
+// sweepInterval is how often the background sweeper reaps expired messages.
+const sweepInterval = time.Second
+
+// sweep periodically discards expired messages from the head of q until stop is closed.
+func sweep(q storage, stop <-chan struct{}) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			q.Sweep()
+		}
+	}
+}
+
+// recreate creates a queue from a saved name and configuration, subscribing it to
+// its topic if it is a subscription queue. A queue that already exists, for
+// instance because it was created as another queue's dead-letter queue, is
+// returned as is, and existed reports so.
+func (r *Registry) recreate(name string, cfg QueueConfig) (nq *NamedQueue, existed bool, err error) {
+	if topic, sub, ok := strings.Cut(name, SubscriptionSeparator); ok {
+		_, err = r.Get(name)
+		existed = err == nil
+		nq, err = r.Subscribe(topic, sub, cfg)
+		return nq, existed, err
+	}
+	nq, err = r.Create(name, cfg)
+	if errors.Is(err, ErrQueueExists) {
+		nq, err = r.Get(name)
+		return nq, true, err
+	}
+	return nq, false, err
+}
+
+// Get returns the queue registered under name, or ErrQueueNotFound.
+func (r *Registry) Get(name string) (*NamedQueue, error) {
+	r.mu.RLock()
+	nq, ok := r.queues[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrQueueNotFound
+	}
+	return nq, nil
+}
+
+// List returns a summary of every registered queue, sorted by name.
+func (r *Registry) List() []QueueInfo {
+	r.mu.RLock()
+	infos := make([]QueueInfo, 0, len(r.queues))
+	for _, nq := range r.queues {
+		var assignments []PartitionAssignment
+		if pq, ok := nq.base.(*PartitionedQueue); ok {
+			assignments = pq.Assignments()
+		}
+		cfg := nq.Config()
+		infos = append(infos, QueueInfo{
+			Name:                nq.name,
+			Capacity:            cfg.Capacity,
+			Len:                 nq.Len(),
+			MaxBytes:            cfg.MaxBytes,
+			Bytes:               nq.base.Bytes(),
+			InFlight:            uint64(nq.leases.InFlight()),
+			Scheduled:           uint64(nq.scheduler.Len()),
+			MaxAge:              cfg.MaxAge,
+			Overflow:            cfg.Overflow,
+			Durable:             cfg.Durable,
+			PriorityLevels:      cfg.PriorityLevels,
+			PriorityMode:        cfg.PriorityMode,
+			Partitions:          cfg.Partitions,
+			Assignments:         assignments,
+			MaxDeliveryAttempts: cfg.MaxDeliveryAttempts,
+			DeadLetterQueue:     cfg.DeadLetterQueue,
+			Topic:               nq.topic,
+		})
+	}
+	r.mu.RUnlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Delete unregisters the queue with the given name and discards its messages,
+// including any that are in flight or scheduled. Returns ErrQueueNotFound if no
+// such queue exists, and ErrQueueInUse if it is the dead-letter queue of another queue.
+// Deleting a subscription queue unsubscribes it from its topic.
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	nq, ok := r.queues[name]
+	if !ok {
+		r.mu.Unlock()
+		return ErrQueueNotFound
+	}
+	for _, other := range r.queues {
+		if other.deadLetter == nq {
+			r.mu.Unlock()
+			return ErrQueueInUse
+		}
+	}
+	delete(r.queues, name)
+	if nq.topic != "" {
+		r.unlink(nq)
+	}
+	r.mu.Unlock()
+	return r.discard(name, nq)
+}
+
+// discard closes a queue that has been removed from the registry, releasing its
+// share of the memory budget and deleting the log of a durable queue.
+func (r *Registry) discard(name string, nq *NamedQueue) error {
+	nq.leases.Close()
+	nq.scheduler.Close()
+	close(nq.stop)
+	nq.base.account().leave()
+	if nq.journal != nil {
+		nq.journal.close()
+		if err := os.RemoveAll(r.queueDir(name)); err != nil {
+			return err
+		}
+	}
+	if c, ok := nq.Queue.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// registry_test.go - Tests for the named queue Registry.
+
+package mq
+
+import (
+	"errors"
+	"testing"
+)
+
+// closingQueue records whether the registry closed it on delete.
+type closingQueue struct {
+	Queue
+	closed bool
+}
+
+func (c *closingQueue) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRegistryLifecycle(t *testing.T) {
+	wrapped := map[string]*closingQueue{}
+	r := NewRegistry(func(name string, q Queue) Queue {
+		cq := &closingQueue{Queue: q}
+		wrapped[name] = cq
+		return cq
+	})
+
+	orders, err := r.Create("orders", QueueConfig{Capacity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Create("orders", QueueConfig{Capacity: 2}); !errors.Is(err, ErrQueueExists) {
+		t.Fatalf("duplicate Create = %v, want ErrQueueExists", err)
+	}
+	if _, err := r.Create("bad name", QueueConfig{Capacity: 2}); !errors.Is(err, ErrInvalidQueueName) {
+		t.Fatalf("Create with invalid name = %v, want ErrInvalidQueueName", err)
+	}
+	if _, err := r.Create("empty", QueueConfig{}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("Create with zero capacity = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := r.Create("huge", QueueConfig{Capacity: 1 << 62}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("Create with capacity 1<<62 = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := r.Create("wide", QueueConfig{Capacity: MaxCapacity/MaxPartitions + 1, Partitions: MaxPartitions}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("Create with capacity times partitions above MaxCapacity = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := r.Create("tall", QueueConfig{Capacity: MaxCapacity / 2, PriorityLevels: 3}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("Create with capacity times levels above MaxCapacity = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := r.Subscribe("events", "huge", QueueConfig{Capacity: MaxCapacity + 1}); !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("Subscribe with capacity above MaxCapacity = %v, want ErrInvalidCapacity", err)
+	}
+	if _, err := r.Create("audit", QueueConfig{Capacity: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queues are independent
+	if err := orders.Enqueue(NewMessage(NewID(), []byte("o1"))); err != nil {
+		t.Fatal(err)
+	}
+	audit, err := r.Get("audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := audit.Dequeue(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("audit Dequeue = %v, want ErrEmpty", err)
+	}
+
+	infos := r.List()
+	if len(infos) != 2 || infos[0].Name != "audit" || infos[1].Name != "orders" {
+		t.Fatalf("List() = %+v", infos)
+	}
+	if infos[1].Capacity != 2 || infos[1].Len != 1 {
+		t.Fatalf("orders info = %+v", infos[1])
+	}
+
+	if err := r.Delete("orders"); err != nil {
+		t.Fatal(err)
+	}
+	if !wrapped["orders"].closed {
+		t.Fatal("Delete did not close the wrapped queue")
+	}
+	if _, err := r.Get("orders"); !errors.Is(err, ErrQueueNotFound) {
+		t.Fatalf("Get after Delete = %v, want ErrQueueNotFound", err)
+	}
+	if err := r.Delete("orders"); !errors.Is(err, ErrQueueNotFound) {
+		t.Fatalf("second Delete = %v, want ErrQueueNotFound", err)
+	}
+}
@@ -0,0 +1,256 @@
+// rings.go - Shared bookkeeping for queues built from several rings.
+//
+// This file defines ringSet, which PriorityQueue and PartitionedQueue embed. It
+// keeps one lock-free MessageQueue ring per level or partition, a shared count
+// bounding the total number of messages and a shared byte count, and implements
+// the parts both queue types have in common: the overflow policies, blocking,
+// expiry and sweeping.
+// The embedding queue decides which ring a message goes to and which ring the
+// next message is taken from.
+
+package mq
+
+import (
+	"context"     // For cancelling blocking operations
+	"errors"      // For telling an empty queue from other failures
+	"sync/atomic" // For the shared message count
+	"time"        // For message expiry and block timeouts
+)
+
+// ringSet is a set of rings sharing one capacity.
+type ringSet struct {
+	rings    []*MessageQueue          // The rings; OverflowDropOldest searches them in order
+	capacity uint64                   // Maximum number of messages across all rings
+	size     int64                    // Messages held or being enqueued (accessed atomically)
+	notEmpty notifier                 // Wakes consumers parked in DequeueContext
+	notFull  notifier                 // Wakes producers parked in EnqueueContext
+	overflow atomic.Pointer[overflow] // What Enqueue does when the set is full
+	onExpire func(*Message)           // Called for each expired message discarded; set before use
+	onDrop   func(reason string)      // Called for each message discarded by the overflow policy; set before use
+	bytes    byteCount                // Payload bytes held across all rings
+}
+
+// init creates n rings holding at most capacity messages between them.
+func (s *ringSet) init(capacity uint64, n int) {
+	s.rings = make([]*MessageQueue, n)
+	s.capacity = capacity
+	s.SetOverflow(OverflowReject, 0)
+	for i := range s.rings {
+		// Every ring can hold the whole capacity, so one level or partition can use all
+		// of it; the shared count enforces the total and QueueConfig bounds capacity
+		// times the number of rings by MaxCapacity
+		s.rings[i] = NewMessageQueue(capacity)
+		s.rings[i].onExpire = s.expired
+		s.rings[i].bytes = &s.bytes
+	}
+}
+
+// SetMaxAge sets the maximum age of messages in every ring. Zero disables the limit.
+func (s *ringSet) SetMaxAge(d time.Duration) {
+	for _, r := range s.rings {
+		r.SetMaxAge(d)
+	}
+}
+
+// SetOverflow sets what Enqueue does when the queue is full, like MessageQueue.SetOverflow.
+// OverflowDropOldest discards the oldest message of the first non-empty ring.
+func (s *ringSet) SetOverflow(policy OverflowPolicy, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultBlockTimeout
+	}
+	s.overflow.Store(&overflow{policy: policy, timeout: timeout})
+}
+
+// add puts msg on ring. If the set is full it applies the overflow policy, which
+// by default rejects the message with ErrFull.
+func (s *ringSet) add(ring *MessageQueue, msg *Message) error {
+	if err := s.put(ring, msg); err != ErrFull {
+		return err
+	}
+	o := s.overflow.Load()
+	switch o.policy {
+	case OverflowBlock:
+		ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+		defer cancel()
+		err := s.putContext(ctx, ring, msg)
+		if err != nil && err == ctx.Err() {
+			return ErrFull // Still full after the block timeout
+		}
+		return err
+	case OverflowDropOldest:
+		for {
+			if err := s.put(ring, msg); err != ErrFull {
+				return err
+			}
+			if !s.evict() && s.Len() == 0 {
+				// Nothing left to evict: other queues hold the memory budget
+				return s.put(ring, msg)
+			}
+		}
+	case OverflowDropNewest:
+		s.drop(OverflowDropNewest)
+		return nil
+	}
+	return ErrFull
+}
+
+// evict discards the oldest message of the first non-empty ring, reporting
+// whether there was one.
+func (s *ringSet) evict() bool {
+	for _, r := range s.rings {
+		if _, err := r.dequeue(nil); err == nil {
+			s.release()
+			s.drop(OverflowDropOldest)
+			return true
+		}
+	}
+	return false
+}
+
+// put reserves room in the shared count and adds msg to ring, returning ErrFull
+// if the set is full or the byte limits refuse it (see byteCount.reserve).
+func (s *ringSet) put(ring *MessageQueue, msg *Message) error {
+	if uint64(atomic.AddInt64(&s.size, 1)) > s.capacity {
+		s.release()
+		return ErrFull
+	}
+	// Only the byte limits can refuse it: a ring never holds more messages than the shared count allows
+	if err := ring.enqueue(msg); err != nil {
+		s.release()
+		return err
+	}
+	s.notEmpty.broadcast()
+	return nil
+}
+
+// putContext adds msg to ring, parking the caller while the set or its memory
+// budget is full whatever its overflow policy. It returns ctx.Err() if ctx is
+// done before room frees up, and ErrTooLarge at once for a message that can never fit.
+func (s *ringSet) putContext(ctx context.Context, ring *MessageQueue, msg *Message) error {
+	for {
+		if err := s.put(ring, msg); !waitable(err) {
+			return err
+		}
+		budget := s.bytes.budget.Load()
+		ch, freed := s.notFull.prepare(), budget.prepare()
+		// Re-check after registering so room released in between is not missed
+		if err := s.put(ring, msg); !waitable(err) {
+			s.notFull.done()
+			budget.done()
+			return err
+		}
+		select {
+		case <-ch:
+		case <-freed:
+		case <-ctx.Done():
+			s.notFull.done()
+			budget.done()
+			return ctx.Err()
+		}
+		s.notFull.done()
+		budget.done()
+	}
+}
+
+// take removes the next message from ring, discarding expired messages, and
+// returns ErrEmpty if there is none.
+func (s *ringSet) take(ring *MessageQueue) (*Message, error) {
+	msg, err := ring.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+	s.release()
+	return msg, nil
+}
+
+// takeContext calls next until it returns a message or an error other than
+// ErrEmpty, parking the caller while it reports ErrEmpty. It returns ctx.Err() if
+// ctx is done before a message arrives.
+func (s *ringSet) takeContext(ctx context.Context, next func() (*Message, error)) (*Message, error) {
+	for {
+		if msg, err := next(); !errors.Is(err, ErrEmpty) {
+			return msg, err
+		}
+		ch := s.notEmpty.prepare()
+		// Re-check after registering so a message published in between is not missed
+		if msg, err := next(); !errors.Is(err, ErrEmpty) {
+			s.notEmpty.done()
+			return msg, err
+		}
+		select {
+		case <-ch:
+			s.notEmpty.done()
+		case <-ctx.Done():
+			s.notEmpty.done()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release gives back the room of a message that left a ring.
+func (s *ringSet) release() {
+	atomic.AddInt64(&s.size, -1)
+	s.notFull.broadcast()
+}
+
+// expired is called by a ring for each expired message it discards.
+func (s *ringSet) expired(msg *Message) {
+	s.release()
+	if s.onExpire != nil {
+		s.onExpire(msg)
+	}
+}
+
+// drop reports a message discarded by the overflow policy.
+func (s *ringSet) drop(reason OverflowPolicy) {
+	if s.onDrop != nil {
+		s.onDrop(string(reason))
+	}
+}
+
+// Sweep discards expired messages from the head of every ring and returns how many it discarded.
+func (s *ringSet) Sweep() int {
+	n := 0
+	for _, r := range s.rings {
+		n += r.Sweep()
+	}
+	return n
+}
+
+// Len returns the number of messages currently in the queue.
+func (s *ringSet) Len() uint64 {
+	n := atomic.LoadInt64(&s.size)
+	if n <= 0 {
+		return 0
+	}
+	if uint64(n) > s.capacity {
+		return s.capacity
+	}
+	return uint64(n)
+}
+
+// Cap returns the maximum number of messages the queue can hold.
+func (s *ringSet) Cap() uint64 {
+	return s.capacity
+}
+
+// SetMaxBytes bounds the payload bytes held across all rings, like MessageQueue.SetMaxBytes.
+func (s *ringSet) SetMaxBytes(n uint64) {
+	s.bytes.limit.Store(int64(n))
+}
+
+// Bytes returns the payload bytes currently held across all rings.
+func (s *ringSet) Bytes() uint64 {
+	return uint64(max(s.bytes.used.Load(), 0))
+}
+
+// account returns the byte count shared by the rings.
+func (s *ringSet) account() *byteCount {
+	return &s.bytes
+}
+
+// observe sets the callbacks for expired and dropped messages. It must be called before the queue is used.
+func (s *ringSet) observe(onExpire func(*Message), onDrop func(reason string)) {
+	s.onExpire = onExpire
+	s.onDrop = onDrop
+}
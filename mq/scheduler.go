@@ -0,0 +1,260 @@
+// scheduler.go - Holding area for delayed messages.
+//
+// This file defines Scheduler, which keeps messages that must not be delivered
+// before a given time in a min-heap ordered by due time. A single timer is armed
+// for the earliest message; when it fires every due message is moved into the
+// ready queue, in due-time order and FIFO among messages due at the same time.
+// Due messages are taken off the heap under the lock and enqueued outside it, so
+// a full blocking queue never stalls Schedule. A message the queue can never
+// accept (one larger than its byte limit) goes to the dead-letter queue, or is
+// dropped if there is none, instead of holding up the messages behind it.
+
+package mq
+
+import (
+	"container/heap" // For the due-time ordered holding area
+	"errors"         // For telling retryable enqueue failures apart
+	"sort"           // For listing held messages in due-time order
+	"sync"           // For guarding the heap and timer
+	"time"           // For due times
+)
+
+// ScheduleObserver is implemented by queue wrappers (such as an instrumented queue)
+// that want to track how many messages are scheduled but not yet ready.
+type ScheduleObserver interface {
+	ObserveScheduled(n int)
+}
+
+// ReasonUndeliverable is the dead-letter and drop reason for scheduled messages
+// that came due but can never be enqueued.
+const ReasonUndeliverable = "undeliverable"
+
+// scheduledMessage is a message waiting in the holding area.
+type scheduledMessage struct {
+	at  time.Time // When the message becomes ready
+	seq uint64    // Arrival order, to keep messages due at the same time FIFO
+	msg *Message  // The held message
+}
+
+// scheduleHeap is a min-heap of scheduled messages by due time, then arrival order.
+type scheduleHeap []*scheduledMessage
+
+func (h scheduleHeap) Len() int { return len(h) }
+func (h scheduleHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h scheduleHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *scheduleHeap) Push(x any)   { *h = append(*h, x.(*scheduledMessage)) }
+func (h *scheduleHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil // Avoid memory leak
+	*h = old[:n-1]
+	return item
+}
+
+// Scheduler holds delayed messages until they are due and then enqueues them.
+// It is safe for concurrent use.
+type Scheduler struct {
+	queue      Queue        // Ready queue due messages are moved into
+	limit      int          // Maximum number of held messages
+	source     string       // Name of the queue, recorded on dead-lettered messages
+	deadLetter Queue        // Where undeliverable messages go, or nil to drop them
+	journal    *journal     // Write-ahead log of a durable queue, or nil; set before use
+	releasing  sync.Mutex   // Serializes release so due messages keep their order
+	mu         sync.Mutex   // Guards the fields below
+	held       scheduleHeap // Messages waiting to become ready
+	seq        uint64       // Next arrival number
+	timer      *time.Timer  // Fires when the earliest held message is due
+	closed     bool         // Set by Close; nothing more is accepted or released
+}
+
+// NewScheduler creates a scheduler that moves due messages into q and holds at
+// most limit messages at a time.
+func NewScheduler(q Queue, limit int) *Scheduler {
+	return &Scheduler{queue: q, limit: limit}
+}
+
+// setDeadLetter makes messages of the queue named source that can never be
+// enqueued move to dlq. It must be called before the scheduler is used.
+func (s *Scheduler) setDeadLetter(source string, dlq Queue) {
+	s.source = source
+	s.deadLetter = dlq
+}
+
+// Schedule holds msg until at and then enqueues it. A time that is not in the
+// future enqueues the message at once. On a durable queue the message is
+// journaled with its due time first. Returns ErrFull if the holding area is full
+// and ErrClosed if the scheduler has been closed.
+func (s *Scheduler) Schedule(msg *Message, at time.Time) error {
+	if !at.After(time.Now()) {
+		return s.queue.Enqueue(msg)
+	}
+	var existed bool
+	if s.journal != nil {
+		var err error
+		if existed, err = s.journal.scheduled(msg, at); err != nil {
+			return err
+		}
+	}
+	err := s.hold(msg, at)
+	if err != nil && s.journal != nil {
+		s.journal.rejected(msg, existed)
+	}
+	return err
+}
+
+// hold adds msg to the holding area until at.
+func (s *Scheduler) hold(msg *Message, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if len(s.held) >= s.limit {
+		return ErrFull
+	}
+	item := &scheduledMessage{at: at, seq: s.seq, msg: msg}
+	s.seq++
+	heap.Push(&s.held, item)
+	if s.held[0] == item {
+		// New earliest message: bring the timer forward
+		s.arm(time.Until(at))
+	}
+	s.observe()
+	return nil
+}
+
+// arm (re)starts the timer to fire after d. The caller holds s.mu.
+func (s *Scheduler) arm(d time.Duration) {
+	if s.timer == nil {
+		s.timer = time.AfterFunc(d, s.release)
+		return
+	}
+	s.timer.Reset(d)
+}
+
+// release moves every due message into the ready queue and re-arms the timer for
+// the next one. If the ready queue is full the rest go back to the heap and are
+// retried shortly; a message it can never accept is dead-lettered or dropped.
+func (s *Scheduler) release() {
+	s.releasing.Lock()
+	defer s.releasing.Unlock()
+	due := s.due()
+	for i, item := range due {
+		err := s.queue.Enqueue(item.msg)
+		if err == nil {
+			continue
+		}
+		if retryable(err) {
+			s.putBack(due[i:])
+			return
+		}
+		s.undeliverable(item.msg)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observe()
+}
+
+// due takes the messages that are due off the heap and re-arms the timer for
+// the next one.
+func (s *Scheduler) due() []*scheduledMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	var due []*scheduledMessage
+	now := time.Now()
+	for len(s.held) > 0 && !s.held[0].at.After(now) {
+		due = append(due, heap.Pop(&s.held).(*scheduledMessage))
+	}
+	if len(s.held) > 0 {
+		s.arm(time.Until(s.held[0].at))
+	}
+	return due
+}
+
+// putBack puts due messages the ready queue had no room for back on the heap and
+// retries them shortly.
+func (s *Scheduler) putBack(items []*scheduledMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for _, item := range items {
+		heap.Push(&s.held, item)
+	}
+	s.arm(redeliveryRetry)
+	s.observe()
+}
+
+// undeliverable moves a due message the ready queue can never accept to the
+// dead-letter queue, or drops it if there is none or it has no room.
+func (s *Scheduler) undeliverable(msg *Message) {
+	if s.deadLetter != nil {
+		dead := msg.deadLettered(DeadLetter{Queue: s.source, Reason: ReasonUndeliverable, At: time.Now()})
+		if s.deadLetter.Enqueue(dead) == nil {
+			if s.journal != nil {
+				s.journal.removed(msg.id, recordAck)
+			}
+			if o, ok := s.queue.(DeadLetterObserver); ok {
+				o.ObserveDeadLetter(ReasonUndeliverable)
+			}
+			return
+		}
+	}
+	if s.journal != nil {
+		s.journal.removed(msg.id, recordDequeue)
+	}
+	if o, ok := s.queue.(DropObserver); ok {
+		o.ObserveDropped(ReasonUndeliverable)
+	}
+}
+
+// retryable reports whether an enqueue failed only for lack of room, so it may
+// succeed later.
+func retryable(err error) bool {
+	return errors.Is(err, ErrFull) || errors.Is(err, ErrMemoryBudget) || errors.Is(err, ErrClosed)
+}
+
+// observe reports the number of held messages. The caller holds s.mu.
+func (s *Scheduler) observe() {
+	if o, ok := s.queue.(ScheduleObserver); ok {
+		o.ObserveScheduled(len(s.held))
+	}
+}
+
+// Len returns the number of messages scheduled but not yet ready.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.held)
+}
+
+// pending returns the held messages in the order they become due.
+func (s *Scheduler) pending() []*scheduledMessage {
+	s.mu.Lock()
+	held := make(scheduleHeap, len(s.held))
+	copy(held, s.held)
+	s.mu.Unlock()
+	sort.Slice(held, func(i, j int) bool { return held.Less(i, j) })
+	return held
+}
+
+// Close stops the timer and discards the held messages.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.held = nil
+}
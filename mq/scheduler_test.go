@@ -0,0 +1,128 @@
+// scheduler_test.go - Tests for delayed delivery.
+
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulerReleasesInDueOrder(t *testing.T) {
+	q := NewMessageQueue(4)
+	s := NewScheduler(q, 2)
+	now := time.Now()
+	if err := s.Schedule(textMessage("late"), now.Add(80*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(textMessage("early"), now.Add(40*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(textMessage("overflow"), now.Add(time.Hour)); !errors.Is(err, ErrFull) {
+		t.Fatalf("Schedule beyond the limit = %v, want ErrFull", err)
+	}
+	// A due time in the past skips the holding area
+	if err := s.Schedule(textMessage("now"), now.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2 || q.Len() != 1 {
+		t.Fatalf("Scheduler Len = %d, queue Len = %d", s.Len(), q.Len())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, want := range []string{"now", "early", "late"} {
+		msg, err := q.DequeueContext(ctx)
+		if err != nil {
+			t.Fatalf("waiting for %q: %v", want, err)
+		}
+		if got := string(msg.GetPayload()); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	if elapsed := time.Since(now); elapsed < 80*time.Millisecond {
+		t.Fatalf("delayed message delivered after %v, before it was due", elapsed)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("Scheduler Len = %d after release", s.Len())
+	}
+
+	s.Close()
+	if err := s.Schedule(textMessage("closed"), time.Now().Add(time.Hour)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Schedule after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestSchedulerDoesNotBlockOnFullQueue(t *testing.T) {
+	q := NewMessageQueue(1)
+	q.SetOverflow(OverflowBlock, 5*time.Second)
+	s := NewScheduler(q, 4)
+	defer s.Close()
+	if err := q.Enqueue(textMessage("filler")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(textMessage("due"), time.Now().Add(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond) // release is now waiting for room in q
+
+	start := time.Now()
+	if err := s.Schedule(textMessage("later"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Len(); n != 1 {
+		t.Fatalf("Scheduler Len = %d, want 1", n)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Schedule and Len took %v while the ready queue was full", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, want := range []string{"filler", "due"} {
+		msg, err := q.DequeueContext(ctx)
+		if err != nil {
+			t.Fatalf("waiting for %q: %v", want, err)
+		}
+		if got := string(msg.GetPayload()); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSchedulerDeadLettersUndeliverable(t *testing.T) {
+	q := NewMessageQueue(4)
+	q.SetMaxBytes(4)
+	dlq := NewMessageQueue(4)
+	s := NewScheduler(q, 4)
+	s.setDeadLetter("orders", dlq)
+	defer s.Close()
+	now := time.Now()
+	if err := s.Schedule(textMessage("too large"), now.Add(20*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedule(textMessage("ok"), now.Add(40*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg, err := q.DequeueContext(ctx)
+	if err != nil {
+		t.Fatalf("message behind an undeliverable one: %v", err)
+	}
+	if got := string(msg.GetPayload()); got != "ok" {
+		t.Fatalf("got %q, want %q", got, "ok")
+	}
+	dead, err := dlq.Dequeue()
+	if err != nil {
+		t.Fatalf("dead-letter queue: %v", err)
+	}
+	if got := string(dead.GetPayload()); got != "too large" {
+		t.Fatalf("dead-lettered %q, want %q", got, "too large")
+	}
+	if dl := dead.GetDeadLetter(); dl == nil || dl.Queue != "orders" || dl.Reason != ReasonUndeliverable {
+		t.Fatalf("dead letter = %+v", dl)
+	}
+}
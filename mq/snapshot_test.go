@@ -0,0 +1,152 @@
+// snapshot_test.go - Tests for queue snapshots.
+
+package mq
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	r := NewRegistry(nil)
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 8, MaxDeliveryAttempts: 2, PriorityLevels: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range []string{"leased", "low", "high"} {
+		msg := textMessage(s)
+		msg.SetHeader("n", s)
+		msg.SetPriority(i)
+		msg.SetTTL(time.Hour)
+		if err := jobs.Enqueue(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lease, err := jobs.Leases().Dequeue(0)
+	if err != nil || string(lease.Message.GetPayload()) != "high" {
+		t.Fatalf("Leases().Dequeue = %v, %v", lease, err)
+	}
+	due := time.Now().Add(time.Hour)
+	if err := jobs.Scheduler().Schedule(textMessage("later"), due); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Subscribe("events", "audit", QueueConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Publish("events", nil, textMessage("event"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	info, err := r.Snapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// jobs, jobs.dlq and the subscription queue
+	if info.Queues != 3 || info.Messages != 5 {
+		t.Fatalf("Snapshot() = %+v, want 3 queues and 5 messages", info)
+	}
+	if jobs.Len() != 2 || jobs.Leases().InFlight() != 1 {
+		t.Fatal("Snapshot consumed messages")
+	}
+
+	restored := NewRegistry(nil)
+	if info, err := restored.Restore(&buf); err != nil || info.Queues != 3 || info.Messages != 5 {
+		t.Fatalf("Restore() = %+v, %v", info, err)
+	}
+	jobs, err = restored.Get("jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := jobs.Config(); cfg.PriorityLevels != 3 || cfg.MaxDeliveryAttempts != 2 || jobs.DeadLetterQueue() == nil {
+		t.Fatalf("restored configuration = %+v", cfg)
+	}
+	if jobs.Scheduler().Len() != 1 {
+		t.Fatalf("restored %d scheduled messages, want 1", jobs.Scheduler().Len())
+	}
+	// The message that was in flight is delivered again, in priority order
+	for _, want := range []string{"high", "low", "leased"} {
+		msg, err := jobs.Dequeue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg.GetPayload()) != want || msg.GetHeader("n") != want || msg.GetExpiresAt().IsZero() {
+			t.Fatalf("Dequeue = %q %v expiring %v, want %q", msg.GetPayload(), msg.GetHeaders(), msg.GetExpiresAt(), want)
+		}
+	}
+	subs, err := restored.Subscriptions("events")
+	if err != nil || len(subs) != 1 || subs[0].Len() != 1 {
+		t.Fatalf("Subscriptions(events) = %v, %v", subs, err)
+	}
+}
+
+func TestRestoreTwice(t *testing.T) {
+	r := NewRegistry(nil)
+	jobs, err := r.Create("jobs", QueueConfig{Capacity: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Enqueue(textMessage("once")); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := r.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+
+	// An existing but empty queue takes the saved messages
+	restored := NewRegistry(nil)
+	if _, err := restored.Create("jobs", QueueConfig{Capacity: 8}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := restored.Restore(bytes.NewReader(snapshot)); err != nil || info.Messages != 1 {
+		t.Fatalf("first Restore() = %+v, %v", info, err)
+	}
+	// Restoring again would duplicate them
+	if _, err := restored.Restore(bytes.NewReader(snapshot)); !errors.Is(err, ErrQueueNotEmpty) {
+		t.Fatalf("second Restore() = %v, want ErrQueueNotEmpty", err)
+	}
+	if jobs, err := restored.Get("jobs"); err != nil || jobs.Len() != 1 {
+		t.Fatalf("after restoring twice jobs = %v, %v; want 1 message", jobs, err)
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	r := NewRegistry(nil)
+	if _, err := r.SaveSnapshot(); !errors.Is(err, ErrSnapshotsDisabled) {
+		t.Fatalf("SaveSnapshot without a file = %v, want ErrSnapshotsDisabled", err)
+	}
+	path := filepath.Join(t.TempDir(), "queues.snapshot")
+	r.SetSnapshotFile(path)
+	q, err := r.Create("jobs", QueueConfig{Capacity: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(textMessage("kept")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SaveSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewRegistry(nil)
+	restored.SetSnapshotFile(path)
+	if info, err := restored.LoadSnapshot(); err != nil || info.Messages != 1 {
+		t.Fatalf("LoadSnapshot() = %+v, %v", info, err)
+	}
+	if q, err := restored.Get("jobs"); err != nil || q.Len() != 1 {
+		t.Fatalf("restored queue = %v, %v", q, err)
+	}
+}
+
+func TestRestoreRejectsUnknownVersion(t *testing.T) {
+	_, err := NewRegistry(nil).Restore(strings.NewReader(`{"Version": 99, "Queues": []}`))
+	if !errors.Is(err, ErrSnapshotVersion) {
+		t.Fatalf("Restore = %v, want ErrSnapshotVersion", err)
+	}
+}
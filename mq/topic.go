@@ -0,0 +1,197 @@
+// topic.go - Publish/subscribe topics.
+//
+// This file adds topics to the Registry. A topic is a set of subscriptions, each
+// backed by its own named queue, and every message published to the topic is
+// delivered to all of them, so each subscriber sees the whole stream while
+// consumers of one subscription still compete for its messages. Subscription
+// queues support everything other named queues do (leases, dead-lettering,
+// delays); they are named "<topic>:<subscription>", which cannot clash with the
+// names of ordinary queues. A topic exists while it has at least one subscription.
+
+package mq
+
+import (
+	"errors" // For topic errors
+	"sort"   // For listing topics in a stable order
+	"time"   // For delayed publishes
+)
+
+// SubscriptionSeparator separates the topic from the subscription in the name of a
+// subscription queue. It is not allowed in ordinary queue names.
+const SubscriptionSeparator = ":"
+
+// DefaultSubscriptionCapacity is the capacity of a subscription queue when none is configured.
+const DefaultSubscriptionCapacity = 10000
+
+// Topic errors.
+var (
+	ErrTopicNotFound        = errors.New("topic not found")        // The topic has no subscriptions
+	ErrSubscriptionNotFound = errors.New("subscription not found") // The topic has no subscription with that name
+)
+
+// TopicInfo is a point-in-time summary of a topic.
+type TopicInfo struct {
+	Name          string   // Name of the topic
+	Subscriptions []string // Names of its subscriptions, sorted
+}
+
+// SubscriptionQueue returns the name of the queue backing a subscription.
+func SubscriptionQueue(topic, subscription string) string {
+	return topic + SubscriptionSeparator + subscription
+}
+
+// Topic returns the topic the queue subscribes to, or "" if it is not a subscription queue.
+func (nq *NamedQueue) Topic() string {
+	return nq.topic
+}
+
+// Subscribe attaches a subscription to a topic, creating the topic if needed, and
+// returns the queue every message published to the topic from now on is delivered
+// to. cfg configures the queue (a zero capacity selects DefaultSubscriptionCapacity).
+// Subscribing again under an existing name returns the existing queue unchanged.
+func (r *Registry) Subscribe(topic, subscription string, cfg QueueConfig) (*NamedQueue, error) {
+	if !ValidQueueName(topic) || !ValidQueueName(subscription) {
+		return nil, ErrInvalidQueueName
+	}
+	if cfg.Capacity == 0 {
+		cfg.Capacity = DefaultSubscriptionCapacity
+	}
+	name := SubscriptionQueue(topic, subscription)
+	if err := r.validate(name, &cfg); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if nq := r.topics[topic][subscription]; nq != nil {
+		return nq, nil
+	}
+	nq, err := r.create(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	nq.topic = topic
+	if r.topics[topic] == nil {
+		r.topics[topic] = make(map[string]*NamedQueue)
+	}
+	r.topics[topic][subscription] = nq
+	return nq, nil
+}
+
+// Unsubscribe detaches a subscription from its topic and deletes its queue, discarding
+// any messages it still holds. The topic goes away with its last subscription.
+func (r *Registry) Unsubscribe(topic, subscription string) error {
+	r.mu.RLock()
+	nq := r.topics[topic][subscription]
+	r.mu.RUnlock()
+	if nq == nil {
+		return ErrSubscriptionNotFound
+	}
+	if err := r.Delete(nq.name); err != nil {
+		if errors.Is(err, ErrQueueNotFound) {
+			return ErrSubscriptionNotFound // Unsubscribed concurrently
+		}
+		return err
+	}
+	return nil
+}
+
+// unlink removes a deleted subscription queue from its topic. The caller holds r.mu.
+func (r *Registry) unlink(nq *NamedQueue) {
+	subs := r.topics[nq.topic]
+	for name, sub := range subs {
+		if sub == nq {
+			delete(subs, name)
+		}
+	}
+	if len(subs) == 0 {
+		delete(r.topics, nq.topic)
+	}
+}
+
+// Subscriptions returns the queues of every subscription to topic, or ErrTopicNotFound.
+func (r *Registry) Subscriptions(topic string) ([]*NamedQueue, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subs, ok := r.topics[topic]
+	if !ok {
+		return nil, ErrTopicNotFound
+	}
+	queues := make([]*NamedQueue, 0, len(subs))
+	for _, nq := range subs {
+		queues = append(queues, nq)
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].name < queues[j].name })
+	return queues, nil
+}
+
+// Publication reports which subscriptions a published message reached.
+type Publication struct {
+	Delivered []string // Subscriptions that accepted the message, sorted
+	Failed    []string // Subscriptions that refused it, sorted
+}
+
+// Publish delivers msg to every subscription of topic, immediately or, if at is in
+// the future, through each subscription's scheduler. If subscriptions is not empty
+// only the subscriptions it names are delivered to, so a producer can retry the
+// ones that refused a message without duplicating it in the others; naming one
+// the topic does not have fails with ErrSubscriptionNotFound before anything is
+// delivered. A subscription that rejects the message (for example because it is
+// full) does not stop delivery to the others; the first such error is returned
+// and the Publication tells which subscriptions took the message.
+func (r *Registry) Publish(topic string, subscriptions []string, msg *Message, at time.Time) (Publication, error) {
+	var pub Publication
+	r.mu.RLock()
+	subs, ok := r.topics[topic]
+	if !ok {
+		r.mu.RUnlock()
+		return pub, ErrTopicNotFound
+	}
+	targets := subs
+	if len(subscriptions) > 0 {
+		targets = make(map[string]*NamedQueue, len(subscriptions))
+		for _, name := range subscriptions {
+			nq, ok := subs[name]
+			if !ok {
+				r.mu.RUnlock()
+				return pub, ErrSubscriptionNotFound
+			}
+			targets[name] = nq
+		}
+	}
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+	var firstErr error
+	for _, name := range names {
+		// Schedule enqueues at once when at is not in the future
+		if err := targets[name].scheduler.Schedule(msg, at); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			pub.Failed = append(pub.Failed, name)
+			continue
+		}
+		pub.Delivered = append(pub.Delivered, name)
+	}
+	return pub, firstErr
+}
+
+// Topics returns a summary of every topic, sorted by name.
+func (r *Registry) Topics() []TopicInfo {
+	r.mu.RLock()
+	infos := make([]TopicInfo, 0, len(r.topics))
+	for topic, subs := range r.topics {
+		info := TopicInfo{Name: topic, Subscriptions: make([]string, 0, len(subs))}
+		for name := range subs {
+			info.Subscriptions = append(info.Subscriptions, name)
+		}
+		sort.Strings(info.Subscriptions)
+		infos = append(infos, info)
+	}
+	r.mu.RUnlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
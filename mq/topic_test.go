@@ -0,0 +1,76 @@
+// topic_test.go - Tests for publish/subscribe topics.
+
+package mq
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTopicFanOut(t *testing.T) {
+	r := NewRegistry(nil)
+	if _, err := r.Publish("orders", nil, textMessage("lost"), time.Time{}); !errors.Is(err, ErrTopicNotFound) {
+		t.Fatalf("Publish without subscriptions = %v, want ErrTopicNotFound", err)
+	}
+	billing, err := r.Subscribe("orders", "billing", QueueConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipping, err := r.Subscribe("orders", "shipping", QueueConfig{Capacity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if billing.Name() != "orders:billing" || billing.Topic() != "orders" || billing.Config().Capacity != DefaultSubscriptionCapacity {
+		t.Fatalf("subscription queue %q, topic %q, config %+v", billing.Name(), billing.Topic(), billing.Config())
+	}
+	if again, err := r.Subscribe("orders", "billing", QueueConfig{}); err != nil || again != billing {
+		t.Fatalf("resubscribing = %v, %v; want the existing queue", again, err)
+	}
+
+	if pub, err := r.Publish("orders", nil, textMessage("o1"), time.Time{}); err != nil || len(pub.Delivered) != 2 {
+		t.Fatalf("Publish = %+v, %v", pub, err)
+	}
+	// shipping is full now, which must not keep the message from billing
+	pub, err := r.Publish("orders", nil, textMessage("o2"), time.Time{})
+	if !errors.Is(err, ErrFull) || !reflect.DeepEqual(pub.Delivered, []string{"billing"}) || !reflect.DeepEqual(pub.Failed, []string{"shipping"}) {
+		t.Fatalf("Publish to a full subscription = %+v, %v", pub, err)
+	}
+	if billing.Len() != 2 || shipping.Len() != 1 {
+		t.Fatalf("billing Len = %d, shipping Len = %d", billing.Len(), shipping.Len())
+	}
+	// Retrying only the failed subscription does not duplicate the message in billing
+	if _, err := shipping.Dequeue(); err != nil {
+		t.Fatal(err)
+	}
+	if pub, err := r.Publish("orders", pub.Failed, textMessage("o2"), time.Time{}); err != nil || !reflect.DeepEqual(pub.Delivered, []string{"shipping"}) {
+		t.Fatalf("retrying the failed subscriptions = %+v, %v", pub, err)
+	}
+	if billing.Len() != 2 || shipping.Len() != 1 {
+		t.Fatalf("after the retry billing Len = %d, shipping Len = %d", billing.Len(), shipping.Len())
+	}
+	if _, err := r.Publish("orders", []string{"billing", "audit"}, textMessage("o3"), time.Time{}); !errors.Is(err, ErrSubscriptionNotFound) || billing.Len() != 2 {
+		t.Fatalf("Publish to an unknown subscription = %v, billing Len = %d", err, billing.Len())
+	}
+
+	if topics := r.Topics(); len(topics) != 1 || len(topics[0].Subscriptions) != 2 {
+		t.Fatalf("Topics() = %+v", topics)
+	}
+	if err := r.Unsubscribe("orders", "shipping"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get(shipping.Name()); !errors.Is(err, ErrQueueNotFound) {
+		t.Fatalf("subscription queue still registered after Unsubscribe: %v", err)
+	}
+	// Deleting the last subscription queue removes the topic
+	if err := r.Delete(billing.Name()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Unsubscribe("orders", "billing"); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Fatalf("Unsubscribe after Delete = %v, want ErrSubscriptionNotFound", err)
+	}
+	if topics := r.Topics(); len(topics) != 0 {
+		t.Fatalf("Topics() = %+v after the last subscription went away", topics)
+	}
+}
@@ -0,0 +1,332 @@
+// wal.go - Segmented write-ahead log files.
+//
+// This file defines wal, an append-only log of opaque records stored in numbered
+// segment files in one directory. Each record is framed with its length and a
+// CRC-32C checksum, so replay can tell a complete record from a torn write left
+// behind by a crash: reading a segment stops at the first incomplete or corrupt
+// record, and the tail of the last segment is truncated there. After a restart
+// new records always go to a fresh segment, and a segment is rolled over once it
+// reaches the configured size. How often appended records are flushed to stable
+// storage is set by the fsync policy.
+
+package mq
+
+import (
+	"encoding/binary" // For record frames
+	"errors"          // For WAL errors
+	"fmt"             // For segment file names
+	"hash/crc32"      // For record checksums
+	"os"              // For segment files
+	"path/filepath"   // For segment paths
+	"sort"            // For ordering segments
+	"strconv"         // For parsing segment file names
+	"strings"         // For recognizing segment files
+	"sync"            // For guarding the active segment
+	"time"            // For the sync interval
+)
+
+// FsyncPolicy selects when records appended to a write-ahead log are synced to disk.
+type FsyncPolicy string
+
+// Supported fsync policies. The zero value behaves like FsyncInterval.
+const (
+	FsyncAlways   FsyncPolicy = "always"   // Sync after every record, before the operation returns
+	FsyncInterval FsyncPolicy = "interval" // Sync in the background every sync interval
+	FsyncNever    FsyncPolicy = "never"    // Leave flushing to the operating system
+)
+
+// Defaults for WALOptions fields left zero.
+const (
+	DefaultWALSegmentSize = 16 << 20               // Bytes per segment
+	DefaultSyncInterval   = 100 * time.Millisecond // Period of FsyncInterval
+)
+
+// ErrInvalidFsyncPolicy is returned for an unknown fsync policy name.
+var ErrInvalidFsyncPolicy = errors.New("invalid fsync policy: use always, interval or never")
+
+// ParseFsyncPolicy returns the fsync policy with the given name. The empty name
+// selects FsyncInterval.
+func ParseFsyncPolicy(name string) (FsyncPolicy, error) {
+	switch p := FsyncPolicy(name); p {
+	case "":
+		return FsyncInterval, nil
+	case FsyncAlways, FsyncInterval, FsyncNever:
+		return p, nil
+	}
+	return "", ErrInvalidFsyncPolicy
+}
+
+// WALOptions configures a write-ahead log.
+type WALOptions struct {
+	Fsync        FsyncPolicy   // When appended records are synced (empty selects FsyncInterval)
+	SyncInterval time.Duration // Period of FsyncInterval (zero selects DefaultSyncInterval)
+	SegmentSize  int64         // Size at which a segment is rolled over (zero selects DefaultWALSegmentSize)
+}
+
+// walSegmentExt is the file name extension of segment files.
+const walSegmentExt = ".wal"
+
+// walFrameHeader is the size of a record frame header: length and checksum.
+const walFrameHeader = 8
+
+// walChecksum is the CRC table used for record checksums.
+var walChecksum = crc32.MakeTable(crc32.Castagnoli)
+
+// walReplay summarizes what opening a write-ahead log found.
+type walReplay struct {
+	records int // Intact records replayed
+	damaged int // Segments that ended in a torn or corrupt record
+}
+
+// wal is a segmented write-ahead log. It is safe for concurrent use.
+type wal struct {
+	dir      string        // Directory holding the segment files
+	opts     WALOptions    // Options with defaults filled in
+	stop     chan struct{} // Closed by close to stop the background sync
+	done     chan struct{} // Closed when the background sync has stopped
+	mu       sync.Mutex    // Guards the fields below
+	segments []uint64      // Sequence numbers of the segment files, oldest first; the last is active
+	file     *os.File      // Active segment, or nil once closed
+	size     int64         // Bytes written to the active segment
+	dirty    bool          // Records appended since the last sync
+}
+
+// openWAL opens the write-ahead log in dir, creating the directory if needed. It
+// first passes every intact record to replay, oldest first, together with the
+// segment it is stored in, then starts a fresh segment for new records.
+func openWAL(dir string, opts WALOptions, replay func(segment uint64, record []byte)) (*wal, walReplay, error) {
+	var stats walReplay
+	if opts.Fsync == "" {
+		opts.Fsync = FsyncInterval
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = DefaultSyncInterval
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultWALSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, stats, err
+	}
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, stats, err
+	}
+	for i, seq := range segments {
+		n, size, intact, err := replaySegment(segmentPath(dir, seq), func(record []byte) { replay(seq, record) })
+		if err != nil {
+			return nil, stats, err
+		}
+		stats.records += n
+		if !intact {
+			stats.damaged++
+		}
+		// Cut a torn tail off the last segment so the log stays readable; records
+		// lost in the middle of an earlier segment cannot be recovered either way
+		if !intact && i == len(segments)-1 {
+			if err := os.Truncate(segmentPath(dir, seq), size); err != nil {
+				return nil, stats, err
+			}
+		}
+	}
+	w := &wal{dir: dir, opts: opts, segments: segments, stop: make(chan struct{}), done: make(chan struct{})}
+	next := uint64(1)
+	if len(segments) > 0 {
+		next = segments[len(segments)-1] + 1
+	}
+	if err := w.startSegment(next); err != nil {
+		return nil, stats, err
+	}
+	if opts.Fsync == FsyncInterval {
+		go w.syncLoop()
+	} else {
+		close(w.done)
+	}
+	return w, stats, nil
+}
+
+// listSegments returns the sequence numbers of the segment files in dir, ascending.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, walSegmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, walSegmentExt), 16, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, seq)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+// segmentPath returns the path of the segment with the given sequence number.
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%016x%s", seq, walSegmentExt))
+}
+
+// replaySegment passes the intact records of a segment file to fn. It returns how
+// many there were, the size of the intact prefix of the file and whether the whole
+// file was intact.
+func replaySegment(path string, fn func(record []byte)) (n int, size int64, intact bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	for len(data) > 0 {
+		record, rest, ok := nextRecord(data)
+		if !ok {
+			return n, size, false, nil
+		}
+		fn(record)
+		n++
+		size += int64(len(data) - len(rest))
+		data = rest
+	}
+	return n, size, true, nil
+}
+
+// nextRecord decodes the record frame at the start of data. It reports false if
+// the frame is incomplete or its checksum does not match.
+func nextRecord(data []byte) (record, rest []byte, ok bool) {
+	if len(data) < walFrameHeader {
+		return nil, nil, false
+	}
+	length := binary.LittleEndian.Uint32(data[0:4])
+	sum := binary.LittleEndian.Uint32(data[4:8])
+	if length == 0 || uint64(length) > uint64(len(data)-walFrameHeader) {
+		return nil, nil, false
+	}
+	record = data[walFrameHeader : walFrameHeader+int(length)]
+	if crc32.Checksum(record, walChecksum) != sum {
+		return nil, nil, false
+	}
+	return record, data[walFrameHeader+int(length):], true
+}
+
+// startSegment creates the segment with the given sequence number and makes it
+// the active one. The caller holds w.mu or has not shared w yet.
+func (w *wal) startSegment(seq uint64) error {
+	f, err := os.OpenFile(segmentPath(w.dir, seq), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	// Make the new file itself durable, not only its contents
+	if d, err := os.Open(w.dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	w.file = f
+	w.size = 0
+	w.segments = append(w.segments, seq)
+	return nil
+}
+
+// append writes a record to the active segment, rolling over to a new segment if
+// the active one is full, and returns the sequence number of the segment it went to.
+func (w *wal) append(record []byte) (uint64, error) {
+	frame := make([]byte, walFrameHeader, walFrameHeader+len(record))
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(len(record)))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.Checksum(record, walChecksum))
+	frame = append(frame, record...)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(frame)) > w.opts.SegmentSize {
+		if err := w.file.Sync(); err != nil {
+			return 0, err
+		}
+		if err := w.file.Close(); err != nil {
+			return 0, err
+		}
+		w.dirty = false
+		if err := w.startSegment(w.segments[len(w.segments)-1] + 1); err != nil {
+			w.file = nil
+			return 0, err
+		}
+	}
+	if _, err := w.file.Write(frame); err != nil {
+		return 0, err
+	}
+	w.size += int64(len(frame))
+	if w.opts.Fsync == FsyncAlways {
+		if err := w.file.Sync(); err != nil {
+			return 0, err
+		}
+	} else {
+		w.dirty = true
+	}
+	return w.segments[len(w.segments)-1], nil
+}
+
+// removeSegments deletes segments from the front of the log for as long as unused
+// reports true for them. The active segment is never deleted.
+func (w *wal) removeSegments(unused func(seq uint64) bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.segments) > 1 && unused(w.segments[0]) {
+		if err := os.Remove(segmentPath(w.dir, w.segments[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		w.segments = w.segments[1:]
+	}
+	return nil
+}
+
+// syncLoop syncs appended records every sync interval until the log is closed.
+func (w *wal) syncLoop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.sync()
+		}
+	}
+}
+
+// sync flushes the active segment to disk if records were appended since the last sync.
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil || !w.dirty {
+		return nil
+	}
+	w.dirty = false
+	return w.file.Sync()
+}
+
+// close syncs and closes the log. Appending afterwards returns ErrClosed.
+func (w *wal) close() error {
+	w.mu.Lock()
+	if w.file == nil {
+		w.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	err := w.file.Sync()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.file = nil
+	w.mu.Unlock()
+	<-w.done
+	return err
+}
@@ -0,0 +1,175 @@
+// wal_test.go - Tests for the segmented write-ahead log.
+
+package mq
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// reopenWAL closes w and opens the log in dir again, returning the records replayed.
+func reopenWAL(t *testing.T, w *wal, dir string, opts WALOptions) (*wal, []string, walReplay) {
+	t.Helper()
+	if w != nil {
+		if err := w.close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var records []string
+	w, stats, err := openWAL(dir, opts, func(_ uint64, record []byte) { records = append(records, string(record)) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, records, stats
+}
+
+func TestWALReplaysRecordsAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	opts := WALOptions{Fsync: FsyncAlways, SegmentSize: 64}
+	w, records, _ := reopenWAL(t, nil, dir, opts)
+	if len(records) != 0 {
+		t.Fatalf("new log replayed %q", records)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := w.append([]byte(fmt.Sprintf("record-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(w.segments) < 2 {
+		t.Fatalf("log did not roll over: segments = %v", w.segments)
+	}
+	w, records, stats := reopenWAL(t, w, dir, opts)
+	defer w.close()
+	if len(records) != 10 || records[0] != "record-0" || records[9] != "record-9" {
+		t.Fatalf("replayed %q", records)
+	}
+	if stats.damaged != 0 {
+		t.Fatalf("damaged = %d, want 0", stats.damaged)
+	}
+	if _, err := w.append([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWALRecoversFromTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	opts := WALOptions{Fsync: FsyncNever}
+	w, _, _ := reopenWAL(t, nil, dir, opts)
+	for _, r := range []string{"one", "two"} {
+		if _, err := w.append([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last := segmentPath(dir, w.segments[len(w.segments)-1])
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	intact, err := os.Stat(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash in the middle of a write leaves a frame header promising more bytes than follow
+	f, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{100, 0, 0, 0, 1, 2, 3, 4, 't', 'h'}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	w, records, stats := reopenWAL(t, nil, dir, opts)
+	if len(records) != 2 || records[1] != "two" {
+		t.Fatalf("replayed %q", records)
+	}
+	if stats.damaged != 1 {
+		t.Fatalf("damaged = %d, want 1", stats.damaged)
+	}
+	if fi, err := os.Stat(last); err != nil || fi.Size() != intact.Size() {
+		t.Fatalf("torn tail not truncated: size %d, want %d (%v)", fi.Size(), intact.Size(), err)
+	}
+
+	// The log stays usable and the torn record is gone for good
+	if _, err := w.append([]byte("three")); err != nil {
+		t.Fatal(err)
+	}
+	w, records, stats = reopenWAL(t, w, dir, opts)
+	defer w.close()
+	if len(records) != 3 || records[2] != "three" || stats.damaged != 0 {
+		t.Fatalf("replayed %q with %d damaged segments", records, stats.damaged)
+	}
+}
+
+func TestWALRecoversFromTruncatedSegment(t *testing.T) {
+	dir := t.TempDir()
+	opts := WALOptions{Fsync: FsyncAlways}
+	w, _, _ := reopenWAL(t, nil, dir, opts)
+	for _, r := range []string{"first", "second", "third"} {
+		if _, err := w.append([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last := segmentPath(dir, w.segments[len(w.segments)-1])
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lose the last three bytes of "third"
+	if err := os.Truncate(last, fi.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	w, records, stats := reopenWAL(t, nil, dir, opts)
+	defer w.close()
+	if len(records) != 2 || records[0] != "first" || records[1] != "second" {
+		t.Fatalf("replayed %q", records)
+	}
+	if stats.damaged != 1 {
+		t.Fatalf("damaged = %d, want 1", stats.damaged)
+	}
+}
+
+func TestWALSkipsCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	opts := WALOptions{Fsync: FsyncAlways}
+	w, _, _ := reopenWAL(t, nil, dir, opts)
+	for _, r := range []string{"good", "flipped"} {
+		if _, err := w.append([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last := segmentPath(dir, w.segments[len(w.segments)-1])
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(last, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, records, stats := reopenWAL(t, nil, dir, opts)
+	defer w.close()
+	if len(records) != 1 || records[0] != "good" || stats.damaged != 1 {
+		t.Fatalf("replayed %q with %d damaged segments", records, stats.damaged)
+	}
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	for name, want := range map[string]FsyncPolicy{"": FsyncInterval, "always": FsyncAlways, "interval": FsyncInterval, "never": FsyncNever} {
+		if got, err := ParseFsyncPolicy(name); err != nil || got != want {
+			t.Errorf("ParseFsyncPolicy(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFsyncPolicy("sometimes"); err != ErrInvalidFsyncPolicy {
+		t.Errorf("ParseFsyncPolicy(sometimes) = %v, want ErrInvalidFsyncPolicy", err)
+	}
+}
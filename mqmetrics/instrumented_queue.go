@@ -1,53 +1,138 @@
-// instrumented_queue.go - Provides InstrumentedQueue, a wrapper for MessageQueue that records metrics.
+// instrumented_queue.go - Provides InstrumentedQueue, a wrapper for mq.Queue that records metrics.
 //
-// This file defines InstrumentedQueue, which wraps a MessageQueue and updates
+// This file defines InstrumentedQueue, which wraps a queue and updates
 // metrics on each enqueue and dequeue operation. It is used to monitor queue
-// activity and performance in real time.
+// activity and performance in real time, typically one per named queue.
 
 package mqmetrics
 
 import (
-	"quickpulse/mq" // MessageQueue implementation
+	"context"       // For blocking queue operations
+	"io"            // For closing metrics collectors
+	"quickpulse/mq" // Queue interface
 	"time"          // For measuring operation latency
 )
 
-// InstrumentedQueue wraps a MessageQueue and updates metrics on each operation.
+// InstrumentedQueue wraps a queue and updates metrics on each operation.
 type InstrumentedQueue struct {
-	Queue   *mq.MessageQueue   // Underlying message queue
+	Queue   mq.Queue           // Underlying message queue
 	Metrics MetricsCollector   // Metrics collector for recording queue stats
 }
 
 // NewInstrumentedQueue creates a new InstrumentedQueue with the given queue and metrics collector.
-func NewInstrumentedQueue(q *mq.MessageQueue, m MetricsCollector) *InstrumentedQueue {
+func NewInstrumentedQueue(q mq.Queue, m MetricsCollector) *InstrumentedQueue {
 	return &InstrumentedQueue{
 		Queue:   q,
 		Metrics: m,
 	}
 }
 
+// refresh updates the queue depth and, if the queue reports them, the queue bytes.
+func (iq *InstrumentedQueue) refresh() {
+	iq.Metrics.SetQueueDepth(int64(iq.Queue.Len()))
+	if s, ok := iq.Queue.(mq.Sizer); ok {
+		iq.Metrics.SetQueueBytes(int64(s.Bytes()))
+	}
+}
+
 // Enqueue adds a message to the queue and updates metrics for enqueue count, queue depth, and latency.
-func (iq *InstrumentedQueue) Enqueue(msg []byte) error {
+func (iq *InstrumentedQueue) Enqueue(msg *mq.Message) error {
 	start := time.Now()
 	err := iq.Queue.Enqueue(msg)
 	if err == nil {
 		iq.Metrics.IncEnqueue() // Increment enqueue counter
-		iq.Metrics.SetQueueDepth(int64(iq.Queue.Len())) // Update queue depth metric
+		iq.refresh() // Update queue depth and bytes metrics
 		iq.Metrics.ObserveEnqueueLatency(time.Since(start)) // Record enqueue latency
 	}
 	return err
 }
 
 // Dequeue removes a message from the queue and updates metrics for dequeue count and queue depth.
-func (iq *InstrumentedQueue) Dequeue() ([]byte, error) {
+func (iq *InstrumentedQueue) Dequeue() (*mq.Message, error) {
 	msg, err := iq.Queue.Dequeue()
 	if err == nil {
 		iq.Metrics.IncDequeue() // Increment dequeue counter
-		iq.Metrics.SetQueueDepth(int64(iq.Queue.Len())) // Update queue depth metric
+		iq.refresh() // Update queue depth and bytes metrics
+	}
+	return msg, err
+}
+
+// EnqueueContext adds a message to the queue, waiting for space until ctx is done,
+// and updates the same metrics as Enqueue. Latency includes the time spent waiting.
+func (iq *InstrumentedQueue) EnqueueContext(ctx context.Context, msg *mq.Message) error {
+	start := time.Now()
+	err := iq.Queue.EnqueueContext(ctx, msg)
+	if err == nil {
+		iq.Metrics.IncEnqueue()
+		iq.refresh()
+		iq.Metrics.ObserveEnqueueLatency(time.Since(start))
+	}
+	return err
+}
+
+// DequeueContext removes a message from the queue, waiting for one until ctx is done,
+// and updates the same metrics as Dequeue.
+func (iq *InstrumentedQueue) DequeueContext(ctx context.Context) (*mq.Message, error) {
+	msg, err := iq.Queue.DequeueContext(ctx)
+	if err == nil {
+		iq.Metrics.IncDequeue()
+		iq.refresh()
 	}
 	return msg, err
 }
 
+// ObserveDeadLetter counts a message moved from this queue to its dead-letter queue.
+// It implements mq.DeadLetterObserver.
+func (iq *InstrumentedQueue) ObserveDeadLetter(reason string) {
+	iq.Metrics.IncDeadLettered(reason)
+}
+
+// ObserveScheduled records the number of delayed messages waiting to become ready.
+// It implements mq.ScheduleObserver.
+func (iq *InstrumentedQueue) ObserveScheduled(n int) {
+	iq.Metrics.SetScheduled(int64(n))
+}
+
+// ObserveExpired counts a message discarded because it expired and refreshes the
+// queue depth and bytes. It implements mq.ExpiryObserver.
+func (iq *InstrumentedQueue) ObserveExpired() {
+	iq.Metrics.IncExpired()
+	iq.refresh()
+}
+
+// ObserveDropped counts a message discarded by the queue's overflow policy and
+// refreshes the queue depth and bytes. It implements mq.DropObserver.
+func (iq *InstrumentedQueue) ObserveDropped(reason string) {
+	iq.Metrics.IncDropped(reason)
+	iq.refresh()
+}
+
+// ObserveDequeued counts a message taken by a partition consumer and refreshes the
+// queue depth and bytes. It implements mq.DequeueObserver.
+func (iq *InstrumentedQueue) ObserveDequeued() {
+	iq.Metrics.IncDequeue()
+	iq.refresh()
+}
+
 // Len returns the current number of messages in the queue.
 func (iq *InstrumentedQueue) Len() uint64 {
 	return iq.Queue.Len()
-}
\ No newline at end of file
+}
+
+// Bytes returns the payload bytes currently in the queue, or zero if the queue does
+// not report them. It implements mq.Sizer.
+func (iq *InstrumentedQueue) Bytes() uint64 {
+	if s, ok := iq.Queue.(mq.Sizer); ok {
+		return s.Bytes()
+	}
+	return 0
+}
+
+// Close releases the metrics collector if it holds resources (such as per-queue
+// Prometheus series). It is called by mq.Registry when the queue is deleted.
+func (iq *InstrumentedQueue) Close() error {
+	if c, ok := iq.Metrics.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -2,7 +2,8 @@
 //
 // This file defines the MetricsCollector interface for queue metrics and
 // implements DefaultMetrics, which uses atomic counters to track enqueue/dequeue
-// operations, queue depth, and throughput.
+// operations, dead-lettered, expired and dropped messages, queue depth and bytes,
+// scheduled messages, and throughput.
 
 package mqmetrics
 
@@ -19,7 +20,12 @@ type MetricsCollector interface {
 	GetThroughput() (enqueuePerSec, dequeuePerSec int64) // Get enqueue/dequeue throughput per second
 	GetQueueDepth() int64                     // Get the current queue depth
 	SetQueueDepth(depth int64)                // Set the current queue depth
+	SetQueueBytes(bytes int64)                // Set the payload bytes currently in the queue
 	ObserveEnqueueLatency(d time.Duration)    // Observe enqueue latency (optional)
+	IncDeadLettered(reason string)            // Count a message moved to the dead-letter queue
+	SetScheduled(n int64)                     // Set the number of delayed messages not yet ready
+	IncExpired()                              // Count a message discarded because it expired
+	IncDropped(reason string)                 // Count a message discarded by the overflow policy
 }
 
 // DefaultMetrics implements MetricsCollector with atomic counters for thread safety.
@@ -29,6 +35,11 @@ type DefaultMetrics struct {
 	lastEnqueue    int64 // Enqueue count at last throughput update
 	lastDequeue    int64 // Dequeue count at last throughput update
 	queueDepth     int64 // Current queue depth
+	queueBytes     int64 // Current payload bytes in the queue
+	deadLettered   int64 // Total number of dead-lettered messages
+	scheduled      int64 // Current number of scheduled messages
+	expired        int64 // Total number of expired messages
+	dropped        int64 // Total number of messages dropped on overflow
 }
 
 // NewDefaultMetrics creates a new DefaultMetrics instance and starts the throughput updater goroutine.
@@ -58,6 +69,16 @@ func (m *DefaultMetrics) GetQueueDepth() int64 {
 	return atomic.LoadInt64(&m.queueDepth)
 }
 
+// SetQueueBytes atomically sets the payload bytes currently in the queue.
+func (m *DefaultMetrics) SetQueueBytes(bytes int64) {
+	atomic.StoreInt64(&m.queueBytes, bytes)
+}
+
+// GetQueueBytes atomically retrieves the payload bytes currently in the queue.
+func (m *DefaultMetrics) GetQueueBytes() int64 {
+	return atomic.LoadInt64(&m.queueBytes)
+}
+
 // GetThroughput returns the number of enqueues and dequeues per second since the last update.
 func (m *DefaultMetrics) GetThroughput() (int64, int64) {
 	enqueue := atomic.LoadInt64(&m.enqueueCount)
@@ -79,4 +100,43 @@ func (m *DefaultMetrics) runThroughputUpdater() {
 }
 
 // ObserveEnqueueLatency is a no-op for DefaultMetrics, but can be implemented in other collectors.
-func (m *DefaultMetrics) ObserveEnqueueLatency(d time.Duration) {}
\ No newline at end of file
+func (m *DefaultMetrics) ObserveEnqueueLatency(d time.Duration) {}
+// IncDeadLettered atomically increments the dead-lettered counter. The reason is ignored.
+func (m *DefaultMetrics) IncDeadLettered(reason string) {
+	atomic.AddInt64(&m.deadLettered, 1)
+}
+
+// GetDeadLettered atomically retrieves the number of dead-lettered messages.
+func (m *DefaultMetrics) GetDeadLettered() int64 {
+	return atomic.LoadInt64(&m.deadLettered)
+}
+
+// SetScheduled atomically sets the number of scheduled messages.
+func (m *DefaultMetrics) SetScheduled(n int64) {
+	atomic.StoreInt64(&m.scheduled, n)
+}
+
+// GetScheduled atomically retrieves the number of scheduled messages.
+func (m *DefaultMetrics) GetScheduled() int64 {
+	return atomic.LoadInt64(&m.scheduled)
+}
+
+// IncExpired atomically increments the expired counter.
+func (m *DefaultMetrics) IncExpired() {
+	atomic.AddInt64(&m.expired, 1)
+}
+
+// GetExpired atomically retrieves the number of expired messages.
+func (m *DefaultMetrics) GetExpired() int64 {
+	return atomic.LoadInt64(&m.expired)
+}
+
+// IncDropped atomically increments the dropped counter. The reason is ignored.
+func (m *DefaultMetrics) IncDropped(reason string) {
+	atomic.AddInt64(&m.dropped, 1)
+}
+
+// GetDropped atomically retrieves the number of messages dropped on overflow.
+func (m *DefaultMetrics) GetDropped() int64 {
+	return atomic.LoadInt64(&m.dropped)
+}
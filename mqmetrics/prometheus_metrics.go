@@ -1,75 +1,184 @@
 // prometheus_metrics.go - Prometheus-based metrics collection for the message queue.
 //
 // This file defines PrometheusMetrics, which implements the MetricsCollector interface
-// and exposes queue metrics (enqueue/dequeue counts, queue depth, throughput, latency)
-// to Prometheus for monitoring and alerting.
+// and exposes queue metrics (enqueue/dequeue counts, dead-lettered, expired and dropped messages,
+// queue depth and bytes, scheduled messages, throughput, latency)
+// to Prometheus for monitoring and alerting. Every metric carries a "queue" label,
+// so each named queue gets its own PrometheusMetrics sharing one set of metric vectors.
+// PrometheusLogMetrics likewise exports the consumer group lag of one log, and
+// PrometheusWsMetrics counts the WebSocket clients the server refuses,
+// PrometheusRateLimitMetrics the messages refused by rate limits, and
+// PrometheusMemoryMetrics reports how much of the memory budget is in use.
 
 package mqmetrics
 
 import (
+	"sync"        // For registering the metric vectors once
 	"sync/atomic" // For atomic operations on counters
 	"github.com/prometheus/client_golang/prometheus" // Prometheus client library
+	"quickpulse/ratelimit" // For rate limit keys
 	"time"        // For time-based throughput calculations
 )
 
-// PrometheusMetrics collects and exposes queue metrics to Prometheus.
+// prometheusVecs holds the labelled metric families shared by all queues.
+type prometheusVecs struct {
+	enqueueCounter    *prometheus.CounterVec
+	dequeueCounter    *prometheus.CounterVec
+	queueDepth        *prometheus.GaugeVec
+	queueBytes        *prometheus.GaugeVec
+	enqueueThroughput *prometheus.GaugeVec
+	dequeueThroughput *prometheus.GaugeVec
+	enqueueLatency    *prometheus.HistogramVec
+	deadLettered      *prometheus.CounterVec // Labelled by queue and reason
+	scheduled         *prometheus.GaugeVec
+	expired           *prometheus.CounterVec
+	dropped           *prometheus.CounterVec // Labelled by queue and reason
+	groupLag          *prometheus.GaugeVec   // Labelled by log and group
+	wsRejected        *prometheus.CounterVec // Labelled by reason
+	throttled         *prometheus.CounterVec // Labelled by key and limit
+	budgetUsed        prometheus.Gauge       // Not labelled: there is one budget
+}
+
+var (
+	vecsOnce sync.Once
+	vecs     *prometheusVecs
+)
+
+// sharedVecs creates and registers the metric vectors on first use.
+func sharedVecs() *prometheusVecs {
+	vecsOnce.Do(func() {
+		labels := []string{"queue"}
+		vecs = &prometheusVecs{
+			enqueueCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_enqueue_total",
+				Help: "Total number of enqueued messages",
+			}, labels),
+			dequeueCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_dequeue_total",
+				Help: "Total number of dequeued messages",
+			}, labels),
+			queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_queue_depth",
+				Help: "Current queue depth",
+			}, labels),
+			queueBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_queue_bytes",
+				Help: "Payload bytes currently in the queue",
+			}, labels),
+			enqueueThroughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_enqueue_throughput",
+				Help: "Enqueue throughput (messages per second)",
+			}, labels),
+			dequeueThroughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_dequeue_throughput",
+				Help: "Dequeue throughput (messages per second)",
+			}, labels),
+			enqueueLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "unnamedmq_enqueue_latency_seconds",
+				Help:    "Histogram of enqueue latencies in seconds",
+				Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16), // 100us to ~3s
+			}, labels),
+			deadLettered: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_dead_lettered_total",
+				Help: "Total number of messages moved to a dead-letter queue, by the failure of their last delivery",
+			}, []string{"queue", "reason"}),
+			scheduled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_scheduled_messages",
+				Help: "Number of delayed messages scheduled but not yet ready",
+			}, labels),
+			expired: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_expired_total",
+				Help: "Total number of messages discarded because they outlived their TTL or the queue's max age",
+			}, labels),
+			dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_dropped_total",
+				Help: "Total number of messages discarded by the queue's overflow policy, by policy",
+			}, []string{"queue", "reason"}),
+			groupLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "unnamedmq_consumer_group_lag",
+				Help: "Messages between a consumer group's committed offset and the end of the log",
+			}, []string{"log", "group"}),
+			wsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_ws_rejected_total",
+				Help: "Total number of WebSocket clients refused by the handshake policy, by reason",
+			}, []string{"reason"}),
+			throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "unnamedmq_throttled_total",
+				Help: "Total number of messages refused by rate limits, by the key of the rule and the limit exceeded",
+			}, []string{"key", "limit"}),
+			budgetUsed: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "unnamedmq_memory_budget_used_bytes",
+				Help: "Payload bytes held by all queues against the memory budget",
+			}),
+		}
+		// Register all metric families with Prometheus
+		prometheus.MustRegister(
+			vecs.enqueueCounter, vecs.dequeueCounter, vecs.queueDepth, vecs.queueBytes,
+			vecs.enqueueThroughput, vecs.dequeueThroughput, vecs.enqueueLatency,
+			vecs.deadLettered, vecs.scheduled, vecs.expired, vecs.dropped,
+			vecs.groupLag, vecs.wsRejected, vecs.throttled, vecs.budgetUsed,
+		)
+	})
+	return vecs
+}
+
+// PrometheusMetrics collects and exposes the metrics of one named queue to Prometheus.
 type PrometheusMetrics struct {
-	EnqueueCounter    prometheus.Counter // Total number of enqueued messages
-	DequeueCounter    prometheus.Counter // Total number of dequeued messages
-	QueueDepth        prometheus.Gauge   // Current queue depth
-	EnqueueThroughput prometheus.Gauge   // Enqueue throughput (messages/sec)
-	DequeueThroughput prometheus.Gauge   // Dequeue throughput (messages/sec)
-	EnqueueLatency    prometheus.Histogram // Histogram of enqueue latencies
+	EnqueueCounter    prometheus.Counter     // Total number of enqueued messages
+	DequeueCounter    prometheus.Counter     // Total number of dequeued messages
+	QueueDepth        prometheus.Gauge       // Current queue depth
+	QueueBytes        prometheus.Gauge       // Current payload bytes in the queue
+	EnqueueThroughput prometheus.Gauge       // Enqueue throughput (messages/sec)
+	DequeueThroughput prometheus.Gauge       // Dequeue throughput (messages/sec)
+	EnqueueLatency    prometheus.Observer    // Histogram of enqueue latencies
+	DeadLettered      *prometheus.CounterVec // Dead-lettered messages of this queue, by reason
+	Scheduled         prometheus.Gauge       // Delayed messages not yet ready
+	ExpiredCounter    prometheus.Counter     // Total number of expired messages
+	Dropped           *prometheus.CounterVec // Messages of this queue discarded on overflow, by reason
 
-	enqueueCount     int64 // Internal counter for enqueues (for throughput)
-	dequeueCount     int64 // Internal counter for dequeues (for throughput)
-	lastEnqueueCount int64 // Last recorded enqueue count (for throughput)
-	lastDequeueCount int64 // Last recorded dequeue count (for throughput)
+	queue            string        // Value of the "queue" label
+	stop             chan struct{} // Closed by Close to stop the throughput updater
+	stopOnce         sync.Once     // Makes Close idempotent
+	enqueueCount     int64         // Internal counter for enqueues (for throughput)
+	dequeueCount     int64         // Internal counter for dequeues (for throughput)
+	lastEnqueueCount int64         // Last recorded enqueue count (for throughput)
+	lastDequeueCount int64         // Last recorded dequeue count (for throughput)
 }
 
-// NewPrometheusMetrics creates and registers Prometheus metrics, and starts the throughput updater goroutine.
-func NewPrometheusMetrics() *PrometheusMetrics {
+// NewPrometheusMetrics creates the Prometheus metrics for the named queue, registering
+// the shared metric vectors on first use, and starts the throughput updater goroutine.
+func NewPrometheusMetrics(queue string) *PrometheusMetrics {
+	v := sharedVecs()
 	m := &PrometheusMetrics{
-		EnqueueCounter: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "unnamedmq_enqueue_total",
-			Help: "Total number of enqueued messages",
-		}),
-		DequeueCounter: prometheus.NewCounter(prometheus.CounterOpts{
-			Name: "unnamedmq_dequeue_total",
-			Help: "Total number of dequeued messages",
-		}),
-		QueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "unnamedmq_queue_depth",
-			Help: "Current queue depth",
-		}),
-		EnqueueThroughput: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "unnamedmq_enqueue_throughput",
-			Help: "Enqueue throughput (messages per second)",
-		}),
-		DequeueThroughput: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "unnamedmq_dequeue_throughput",
-			Help: "Dequeue throughput (messages per second)",
-		}),
-		EnqueueLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
-			Name:    "unnamedmq_enqueue_latency_seconds",
-			Help:    "Histogram of enqueue latencies in seconds",
-			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16), // 100us to ~3s
-		}),
+		EnqueueCounter:    v.enqueueCounter.WithLabelValues(queue),
+		DequeueCounter:    v.dequeueCounter.WithLabelValues(queue),
+		QueueDepth:        v.queueDepth.WithLabelValues(queue),
+		QueueBytes:        v.queueBytes.WithLabelValues(queue),
+		EnqueueThroughput: v.enqueueThroughput.WithLabelValues(queue),
+		DequeueThroughput: v.dequeueThroughput.WithLabelValues(queue),
+		EnqueueLatency:    v.enqueueLatency.WithLabelValues(queue),
+		DeadLettered:      v.deadLettered.MustCurryWith(prometheus.Labels{"queue": queue}),
+		Scheduled:         v.scheduled.WithLabelValues(queue),
+		ExpiredCounter:    v.expired.WithLabelValues(queue),
+		Dropped:           v.dropped.MustCurryWith(prometheus.Labels{"queue": queue}),
+		queue:             queue,
+		stop:              make(chan struct{}),
 	}
-	// Register all metrics with Prometheus
-	prometheus.MustRegister(
-		m.EnqueueCounter, m.DequeueCounter, m.QueueDepth,
-		m.EnqueueThroughput, m.DequeueThroughput, m.EnqueueLatency,
-	)
 	// Start a goroutine to update throughput metrics every second
 	go m.runThroughputUpdater()
 	return m
 }
 
-// runThroughputUpdater updates the enqueue/dequeue throughput metrics every second.
+// runThroughputUpdater updates the enqueue/dequeue throughput metrics every second until Close.
 func (m *PrometheusMetrics) runThroughputUpdater() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	for {
-		time.Sleep(time.Second)
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
 		enqueue := atomic.LoadInt64(&m.enqueueCount)
 		dequeue := atomic.LoadInt64(&m.dequeueCount)
 		m.EnqueueThroughput.Set(float64(enqueue - m.lastEnqueueCount))
@@ -79,6 +188,26 @@ func (m *PrometheusMetrics) runThroughputUpdater() {
 	}
 }
 
+// Close stops the throughput updater and removes this queue's series from Prometheus.
+func (m *PrometheusMetrics) Close() error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		v := sharedVecs()
+		v.enqueueCounter.DeleteLabelValues(m.queue)
+		v.dequeueCounter.DeleteLabelValues(m.queue)
+		v.queueDepth.DeleteLabelValues(m.queue)
+		v.queueBytes.DeleteLabelValues(m.queue)
+		v.enqueueThroughput.DeleteLabelValues(m.queue)
+		v.dequeueThroughput.DeleteLabelValues(m.queue)
+		v.enqueueLatency.DeleteLabelValues(m.queue)
+		v.deadLettered.DeletePartialMatch(prometheus.Labels{"queue": m.queue})
+		v.scheduled.DeleteLabelValues(m.queue)
+		v.expired.DeleteLabelValues(m.queue)
+		v.dropped.DeletePartialMatch(prometheus.Labels{"queue": m.queue})
+	})
+	return nil
+}
+
 // IncEnqueue increments the enqueue counter and updates the internal count.
 func (m *PrometheusMetrics) IncEnqueue() {
 	m.EnqueueCounter.Inc()
@@ -96,8 +225,14 @@ func (m *PrometheusMetrics) SetQueueDepth(depth int64) {
 	m.QueueDepth.Set(float64(depth))
 }
 
+// SetQueueBytes sets the queue bytes gauge.
+func (m *PrometheusMetrics) SetQueueBytes(bytes int64) {
+	m.QueueBytes.Set(float64(bytes))
+}
+
 // The following are no-ops for PrometheusMetrics, but required for interface compatibility.
 func (m *PrometheusMetrics) GetThroughput() (int64, int64) { return 0, 0 }
+
 /*
 GetQueueDepth returns the current queue depth as required by the MetricsCollector interface.
 For PrometheusMetrics, this is a no-op because Prometheus scrapes the value directly from the gauge.
@@ -106,7 +241,108 @@ func (m *PrometheusMetrics) GetQueueDepth() int64 {
 	return 0
 }
 
+// IncDeadLettered counts a message moved to the dead-letter queue after a failed delivery for reason.
+func (m *PrometheusMetrics) IncDeadLettered(reason string) {
+	m.DeadLettered.WithLabelValues(reason).Inc()
+}
+
+// SetScheduled sets the scheduled messages gauge.
+func (m *PrometheusMetrics) SetScheduled(n int64) {
+	m.Scheduled.Set(float64(n))
+}
+
+// IncExpired increments the expired messages counter.
+func (m *PrometheusMetrics) IncExpired() {
+	m.ExpiredCounter.Inc()
+}
+
+// IncDropped counts a message discarded by the overflow policy reason.
+func (m *PrometheusMetrics) IncDropped(reason string) {
+	m.Dropped.WithLabelValues(reason).Inc()
+}
+
 // ObserveEnqueueLatency records the enqueue latency in seconds in the histogram.
 func (m *PrometheusMetrics) ObserveEnqueueLatency(d time.Duration) {
 	m.EnqueueLatency.Observe(d.Seconds())
-}
\ No newline at end of file
+}
+
+// PrometheusLogMetrics exposes the consumer group lag of one log to Prometheus.
+// It implements mq.LogObserver.
+type PrometheusLogMetrics struct {
+	GroupLag *prometheus.GaugeVec // Lag of each consumer group of this log, by group
+
+	log string // Value of the "log" label
+}
+
+// NewPrometheusLogMetrics creates the Prometheus metrics for the named log,
+// registering the shared metric vectors on first use.
+func NewPrometheusLogMetrics(log string) *PrometheusLogMetrics {
+	v := sharedVecs()
+	return &PrometheusLogMetrics{
+		GroupLag: v.groupLag.MustCurryWith(prometheus.Labels{"log": log}),
+		log:      log,
+	}
+}
+
+// ObserveLag sets the lag gauge of a consumer group.
+func (m *PrometheusLogMetrics) ObserveLag(group string, lag uint64) {
+	m.GroupLag.WithLabelValues(group).Set(float64(lag))
+}
+
+// Close removes the log's series from the shared metric vectors. It is called by
+// mq.Registry when the log is deleted.
+func (m *PrometheusLogMetrics) Close() error {
+	sharedVecs().groupLag.DeletePartialMatch(prometheus.Labels{"log": m.log})
+	return nil
+}
+
+// PrometheusWsMetrics counts the WebSocket clients refused by the handshake
+// policy. It implements server.WsObserver.
+type PrometheusWsMetrics struct {
+	Rejected *prometheus.CounterVec // Refused clients, by reason
+}
+
+// NewPrometheusWsMetrics creates the WebSocket metrics, registering the shared
+// metric vectors on first use.
+func NewPrometheusWsMetrics() *PrometheusWsMetrics {
+	return &PrometheusWsMetrics{Rejected: sharedVecs().wsRejected}
+}
+
+// ObserveRejected counts a client refused for reason.
+func (m *PrometheusWsMetrics) ObserveRejected(reason string) {
+	m.Rejected.WithLabelValues(reason).Inc()
+}
+
+// PrometheusRateLimitMetrics counts the messages refused by rate limits. It
+// implements ratelimit.Observer.
+type PrometheusRateLimitMetrics struct {
+	Throttled *prometheus.CounterVec // Refused messages, by key and limit
+}
+
+// NewPrometheusRateLimitMetrics creates the rate limit metrics, registering the
+// shared metric vectors on first use.
+func NewPrometheusRateLimitMetrics() *PrometheusRateLimitMetrics {
+	return &PrometheusRateLimitMetrics{Throttled: sharedVecs().throttled}
+}
+
+// ObserveThrottled counts a message refused by a rule keyed by key for exceeding limit.
+func (m *PrometheusRateLimitMetrics) ObserveThrottled(key ratelimit.Key, limit string) {
+	m.Throttled.WithLabelValues(string(key), limit).Inc()
+}
+
+// PrometheusMemoryMetrics reports how much of the memory budget is in use. It
+// implements mq.BudgetObserver.
+type PrometheusMemoryMetrics struct {
+	BudgetUsed prometheus.Gauge // Payload bytes held against the budget
+}
+
+// NewPrometheusMemoryMetrics creates the memory budget metrics, registering the
+// shared metric vectors on first use.
+func NewPrometheusMemoryMetrics() *PrometheusMemoryMetrics {
+	return &PrometheusMemoryMetrics{BudgetUsed: sharedVecs().budgetUsed}
+}
+
+// ObserveBudgetUsed sets the budget gauge to the bytes in use.
+func (m *PrometheusMemoryMetrics) ObserveBudgetUsed(bytes int64) {
+	m.BudgetUsed.Set(float64(bytes))
+}
@@ -0,0 +1,53 @@
+// credential.go - Credentials for the performance test clients.
+//
+// This file holds the credential the test clients present to a server with
+// authentication enabled: an API key, an HMAC-signed token or a JWT. After
+// UseCredential the gRPC tests send it as bearer metadata on every call and
+// stream, and the WebSocket test sends it in the handshake's Authorization header.
+
+package perfclient
+
+import (
+	"context"  // For per-RPC credentials
+	"net/http" // For the WebSocket handshake header
+
+	"google.golang.org/grpc" // gRPC dial options
+)
+
+// clientCredential is the credential the tests present; empty presents none.
+var clientCredential string
+
+// UseCredential makes every test authenticate with credential. An empty
+// credential restores unauthenticated connections.
+func UseCredential(credential string) {
+	clientCredential = credential
+}
+
+// bearer sends a credential as "authorization: Bearer" metadata.
+type bearer string
+
+func (b bearer) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+// RequireTransportSecurity allows plaintext, so the tests also run against servers without TLS.
+func (bearer) RequireTransportSecurity() bool {
+	return false
+}
+
+// dialOptions returns the gRPC dial options for the TLS settings and credential in use.
+func dialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{grpcCredentials()}
+	if clientCredential != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearer(clientCredential)))
+	}
+	return opts
+}
+
+// wsHeader returns the WebSocket handshake header carrying the credential, if any.
+func wsHeader() http.Header {
+	if clientCredential == "" {
+		return nil
+	}
+	return http.Header{"Authorization": {"Bearer " + clientCredential}}
+}
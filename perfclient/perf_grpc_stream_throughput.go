@@ -47,7 +47,7 @@ func RunGRPCStreamPerfTest(concurrency, inflight int, totalMessages int64, testD
 	// Worker function for each stream
 	worker := func(id int) {
 		defer wg.Done()
-		conn, err := grpc.Dial(grpcAddress, grpc.WithInsecure())
+		conn, err := grpc.Dial(grpcAddress, dialOptions()...)
 		if err != nil {
 			log.Fatalf("Failed to connect: %v", err)
 		}
@@ -95,7 +95,7 @@ func RunGRPCStreamPerfTest(concurrency, inflight int, totalMessages int64, testD
 					atomic.AddInt64(&errors, 1)
 					return
 				}
-				if resp.Error != "" {
+				if resp.Status != nil {
 					atomic.AddInt64(&errors, 1)
 				} else {
 					atomic.AddInt64(&received, 1)
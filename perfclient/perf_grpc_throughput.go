@@ -43,7 +43,7 @@ func RunGRPCPerfTest(concurrency, inflight int) {
 	conns := make([]*grpc.ClientConn, concurrency)
 	clients := make([]pb.MessageQueueClient, concurrency)
 	for i := 0; i < concurrency; i++ {
-		conn, err := grpc.Dial(gRPCAddress, grpc.WithInsecure())
+		conn, err := grpc.Dial(gRPCAddress, dialOptions()...)
 		if err != nil {
 			log.Fatalf("Failed to connect: %v", err)
 		}
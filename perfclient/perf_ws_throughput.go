@@ -32,7 +32,8 @@ func RunWSPerfTest(_concurrency, _inflight int) {
 	endTime := start.Add(time.Duration(testDurationSec) * time.Second)
 
 	// Establish a single WebSocket connection
-	conn, _, err := websocket.DefaultDialer.Dial(WSAddress, nil)
+	dialer, url := wsDial(WSAddress)
+	conn, _, err := dialer.Dial(url, wsHeader())
 	if err != nil {
 		log.Printf("Failed to connect: %v", err)
 		return
@@ -0,0 +1,47 @@
+// tls.go - TLS settings for the performance test clients.
+//
+// This file holds the TLS config the test clients dial with. By default they
+// connect in plaintext; after UseTLS the gRPC tests use TLS transport credentials
+// and the WebSocket test connects to the wss:// form of its address.
+
+package perfclient
+
+import (
+	"crypto/tls" // For the client TLS config
+	"strings"    // For switching the WebSocket address to wss://
+
+	"github.com/gorilla/websocket"                // WebSocket client
+	"google.golang.org/grpc"                      // gRPC dial options
+	"google.golang.org/grpc/credentials"          // TLS transport credentials
+	"google.golang.org/grpc/credentials/insecure" // Plaintext transport credentials
+)
+
+// clientTLS is the TLS config the tests dial with; nil dials plaintext.
+var clientTLS *tls.Config
+
+// UseTLS makes every test connect over TLS with cfg, for instance one built by
+// certs.ClientConfig. A nil cfg restores plaintext connections.
+func UseTLS(cfg *tls.Config) {
+	clientTLS = cfg
+}
+
+// grpcCredentials returns the dial option selecting TLS or plaintext.
+func grpcCredentials() grpc.DialOption {
+	if clientTLS == nil {
+		return grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(clientTLS))
+}
+
+// wsDial returns the dialer and URL for the WebSocket address url.
+func wsDial(url string) (*websocket.Dialer, string) {
+	if clientTLS == nil {
+		return websocket.DefaultDialer, url
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = clientTLS
+	if rest, ok := strings.CutPrefix(url, "ws://"); ok {
+		url = "wss://" + rest
+	}
+	return &dialer, url
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.2
+// 	protoc-gen-go v1.36.6
 // 	protoc        v5.29.3
 // source: messagequeue.proto
 
@@ -9,8 +9,10 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -20,13 +22,89 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Versions of how failed requests are reported (see MessageQueue).
+type ApiVersion int32
+
+const (
+	// No version requested, which selects API_VERSION_1.
+	ApiVersion_API_VERSION_UNSPECIFIED ApiVersion = 0
+	// Failures in the deprecated success and error fields, with an OK status.
+	ApiVersion_API_VERSION_1 ApiVersion = 1
+	// Failures as gRPC status codes with error details.
+	ApiVersion_API_VERSION_2 ApiVersion = 2
+)
+
+// Enum value maps for ApiVersion.
+var (
+	ApiVersion_name = map[int32]string{
+		0: "API_VERSION_UNSPECIFIED",
+		1: "API_VERSION_1",
+		2: "API_VERSION_2",
+	}
+	ApiVersion_value = map[string]int32{
+		"API_VERSION_UNSPECIFIED": 0,
+		"API_VERSION_1":           1,
+		"API_VERSION_2":           2,
+	}
+)
+
+func (x ApiVersion) Enum() *ApiVersion {
+	p := new(ApiVersion)
+	*p = x
+	return p
+}
+
+func (x ApiVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ApiVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_messagequeue_proto_enumTypes[0].Descriptor()
+}
+
+func (ApiVersion) Type() protoreflect.EnumType {
+	return &file_messagequeue_proto_enumTypes[0]
+}
+
+func (x ApiVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ApiVersion.Descriptor instead.
+func (ApiVersion) EnumDescriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{0}
+}
+
 // Request to produce a message (binary payload).
 type ProduceRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Payload []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Name of the target queue (empty selects the default queue).
+	Queue string `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Producer-supplied headers carried with the message.
+	Headers map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// MIME type of the payload (optional).
+	ContentType string `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Deliver the message only after this delay (set at most one of delay_ms and deliver_at).
+	DelayMs int64 `protobuf:"varint,5,opt,name=delay_ms,json=delayMs,proto3" json:"delay_ms,omitempty"`
+	// Deliver the message only at or after this time.
+	DeliverAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=deliver_at,json=deliverAt,proto3" json:"deliver_at,omitempty"`
+	// Discard the message if it has not been consumed this long after it was produced (0 for no TTL).
+	TtlMs int64 `protobuf:"varint,7,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	// Priority level on priority queues (0 is the lowest; values above the top level are clamped).
+	Priority uint32 `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Publish to every subscription of this topic instead of producing to a queue.
+	Topic string `protobuf:"bytes,9,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Append to this log instead of producing to a queue (delays are not supported).
+	Log string `protobuf:"bytes,10,opt,name=log,proto3" json:"log,omitempty"`
+	// Partition key: on partitioned queues all messages with the same key are delivered in order
+	// through one partition (empty spreads messages over the partitions).
+	Key string `protobuf:"bytes,11,opt,name=key,proto3" json:"key,omitempty"`
+	// Publish only to these subscriptions of topic (empty publishes to all of them), so a retry
+	// after a partial failure can skip the subscriptions that already took the message.
+	Subscriptions []string `protobuf:"bytes,12,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ProduceRequest) Reset() {
@@ -66,14 +144,99 @@ func (x *ProduceRequest) GetPayload() []byte {
 	return nil
 }
 
+func (x *ProduceRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *ProduceRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *ProduceRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ProduceRequest) GetDelayMs() int64 {
+	if x != nil {
+		return x.DelayMs
+	}
+	return 0
+}
+
+func (x *ProduceRequest) GetDeliverAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliverAt
+	}
+	return nil
+}
+
+func (x *ProduceRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *ProduceRequest) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *ProduceRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ProduceRequest) GetLog() string {
+	if x != nil {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *ProduceRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ProduceRequest) GetSubscriptions() []string {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
 // Response for produce (acknowledgement).
 type ProduceResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Server-assigned ID of the enqueued message.
+	MessageId string `protobuf:"bytes,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Number of subscriptions the message was delivered to, for messages produced to a topic.
+	Deliveries uint32 `protobuf:"varint,4,opt,name=deliveries,proto3" json:"deliveries,omitempty"`
+	// Offset of the message, for messages appended to a log.
+	Offset uint64 `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Subscriptions the message was delivered to, for messages produced to a topic.
+	DeliveredSubscriptions []string `protobuf:"bytes,6,rep,name=delivered_subscriptions,json=deliveredSubscriptions,proto3" json:"delivered_subscriptions,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
 }
 
 func (x *ProduceResponse) Reset() {
@@ -113,6 +276,7 @@ func (x *ProduceResponse) GetSuccess() bool {
 	return false
 }
 
+// Deprecated: Marked as deprecated in messagequeue.proto.
 func (x *ProduceResponse) GetError() string {
 	if x != nil {
 		return x.Error
@@ -120,11 +284,48 @@ func (x *ProduceResponse) GetError() string {
 	return ""
 }
 
+func (x *ProduceResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ProduceResponse) GetDeliveries() uint32 {
+	if x != nil {
+		return x.Deliveries
+	}
+	return 0
+}
+
+func (x *ProduceResponse) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ProduceResponse) GetDeliveredSubscriptions() []string {
+	if x != nil {
+		return x.DeliveredSubscriptions
+	}
+	return nil
+}
+
 // Request to consume a message.
 type ConsumeRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How long to wait for a message if the queue is empty (0 returns immediately).
+	WaitTimeoutMs int64 `protobuf:"varint,1,opt,name=wait_timeout_ms,json=waitTimeoutMs,proto3" json:"wait_timeout_ms,omitempty"`
+	// Name of the queue to consume from (empty selects the default queue).
+	Queue string `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Lease the message instead of removing it: it is redelivered unless acked
+	// before the visibility timeout expires.
+	Lease bool `protobuf:"varint,3,opt,name=lease,proto3" json:"lease,omitempty"`
+	// Visibility timeout for a leased message (0 selects the queue's default).
+	VisibilityTimeoutMs int64 `protobuf:"varint,4,opt,name=visibility_timeout_ms,json=visibilityTimeoutMs,proto3" json:"visibility_timeout_ms,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *ConsumeRequest) Reset() {
@@ -157,14 +358,46 @@ func (*ConsumeRequest) Descriptor() ([]byte, []int) {
 	return file_messagequeue_proto_rawDescGZIP(), []int{2}
 }
 
+func (x *ConsumeRequest) GetWaitTimeoutMs() int64 {
+	if x != nil {
+		return x.WaitTimeoutMs
+	}
+	return 0
+}
+
+func (x *ConsumeRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *ConsumeRequest) GetLease() bool {
+	if x != nil {
+		return x.Lease
+	}
+	return false
+}
+
+func (x *ConsumeRequest) GetVisibilityTimeoutMs() int64 {
+	if x != nil {
+		return x.VisibilityTimeoutMs
+	}
+	return 0
+}
+
 // Response for consume (binary payload).
 type ConsumeResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Payload []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Metadata of the consumed message.
+	Envelope *Envelope `protobuf:"bytes,3,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	// When the lease expires, for leased messages.
+	LeaseDeadline *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=lease_deadline,json=leaseDeadline,proto3" json:"lease_deadline,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ConsumeResponse) Reset() {
@@ -204,6 +437,7 @@ func (x *ConsumeResponse) GetPayload() []byte {
 	return nil
 }
 
+// Deprecated: Marked as deprecated in messagequeue.proto.
 func (x *ConsumeResponse) GetError() string {
 	if x != nil {
 		return x.Error
@@ -211,29 +445,44 @@ func (x *ConsumeResponse) GetError() string {
 	return ""
 }
 
-type StreamMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *ConsumeResponse) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
 
-	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
-	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+func (x *ConsumeResponse) GetLeaseDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LeaseDeadline
+	}
+	return nil
 }
 
-func (x *StreamMessage) Reset() {
-	*x = StreamMessage{}
+// Request to acknowledge a leased message.
+type AckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the queue the message was consumed from (empty selects the default queue).
+	Queue         string `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	MessageId     string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckRequest) Reset() {
+	*x = AckRequest{}
 	mi := &file_messagequeue_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamMessage) String() string {
+func (x *AckRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamMessage) ProtoMessage() {}
+func (*AckRequest) ProtoMessage() {}
 
-func (x *StreamMessage) ProtoReflect() protoreflect.Message {
+func (x *AckRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_messagequeue_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -245,98 +494,3510 @@ func (x *StreamMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamMessage.ProtoReflect.Descriptor instead.
-func (*StreamMessage) Descriptor() ([]byte, []int) {
+// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
+func (*AckRequest) Descriptor() ([]byte, []int) {
 	return file_messagequeue_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *StreamMessage) GetPayload() []byte {
+func (x *AckRequest) GetQueue() string {
 	if x != nil {
-		return x.Payload
+		return x.Queue
 	}
-	return nil
+	return ""
 }
 
-func (x *StreamMessage) GetError() string {
+func (x *AckRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// Response for ack.
+type AckResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResponse) Reset() {
+	*x = AckResponse{}
+	mi := &file_messagequeue_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResponse) ProtoMessage() {}
+
+func (x *AckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
+func (*AckResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AckResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *AckResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-var File_messagequeue_proto protoreflect.FileDescriptor
+// Request to reject a leased message so that it is redelivered.
+type NackRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the queue the message was consumed from (empty selects the default queue).
+	Queue         string `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	MessageId     string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackRequest) Reset() {
+	*x = NackRequest{}
+	mi := &file_messagequeue_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackRequest) ProtoMessage() {}
+
+func (x *NackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackRequest.ProtoReflect.Descriptor instead.
+func (*NackRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NackRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *NackRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// Response for nack.
+type NackResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackResponse) Reset() {
+	*x = NackResponse{}
+	mi := &file_messagequeue_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-var file_messagequeue_proto_rawDesc = []byte{
-	0x0a, 0x12, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65,
-	0x75, 0x65, 0x22, 0x2a, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x41,
-	0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65,
-	0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x22, 0x10, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x22, 0x41, 0x0a, 0x0f, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
-	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x3f, 0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f,
-	0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
-	0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x32, 0xee, 0x01, 0x0a, 0x0c, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x51, 0x75, 0x65, 0x75, 0x65, 0x12, 0x46, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x64,
-	0x75, 0x63, 0x65, 0x12, 0x1c, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65,
-	0x75, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65,
-	0x2e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x46, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x12, 0x1c, 0x2e, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75,
-	0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0e, 0x53, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x1b, 0x2e, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x1b, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x71, 0x75, 0x65, 0x75, 0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x18, 0x5a, 0x16, 0x71, 0x75, 0x69, 0x63,
-	0x6b, 0x70, 0x75, 0x6c, 0x73, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *NackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackResponse) ProtoMessage() {}
+
+func (x *NackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackResponse.ProtoReflect.Descriptor instead.
+func (*NackResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NackResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *NackResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type StreamMessage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Payload []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Deprecated: use status.
+	//
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Name of the queue this message is produced to and consumed from (empty selects the default queue).
+	Queue string `protobuf:"bytes,3,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Producer-supplied headers for the message being produced.
+	Headers map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// MIME type of the payload being produced (optional).
+	ContentType string `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Metadata of the consumed message (set on server replies).
+	Envelope *Envelope `protobuf:"bytes,6,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	// Priority level of the message being produced, on priority queues.
+	Priority uint32 `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Partition key of the message being produced, on partitioned queues.
+	Key string `protobuf:"bytes,8,opt,name=key,proto3" json:"key,omitempty"`
+	// Why the request failed, on server replies to requests that did (a stream
+	// carries on after them).
+	Status        *ErrorStatus `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
+func (x *StreamMessage) Reset() {
+	*x = StreamMessage{}
+	mi := &file_messagequeue_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMessage) ProtoMessage() {}
+
+func (x *StreamMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMessage.ProtoReflect.Descriptor instead.
+func (*StreamMessage) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamMessage) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *StreamMessage) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StreamMessage) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *StreamMessage) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *StreamMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *StreamMessage) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
+
+func (x *StreamMessage) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *StreamMessage) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StreamMessage) GetStatus() *ErrorStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// A failed stream request, described like the gRPC status of a failed RPC.
+type ErrorStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// gRPC status code (google.rpc.Code), such as 8 for RESOURCE_EXHAUSTED.
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Human-readable description, which may change between versions.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Stable reason for the failure, as in google.rpc.ErrorInfo (such as QUEUE_FULL).
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// How long to wait before retrying, as in google.rpc.RetryInfo (0 if retrying will not help).
+	RetryAfterMs  int64 `protobuf:"varint,4,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorStatus) Reset() {
+	*x = ErrorStatus{}
+	mi := &file_messagequeue_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorStatus) ProtoMessage() {}
+
+func (x *ErrorStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorStatus.ProtoReflect.Descriptor instead.
+func (*ErrorStatus) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ErrorStatus) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ErrorStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ErrorStatus) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+// Metadata carried with every message through the queue.
+type Envelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Server-assigned unique message ID.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Producer-supplied headers.
+	Headers map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// MIME type of the payload, if the producer supplied one.
+	ContentType string `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Time the server accepted the message.
+	EnqueuedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=enqueued_at,json=enqueuedAt,proto3" json:"enqueued_at,omitempty"`
+	// Which delivery of the message this is (1 for the first delivery).
+	DeliveryAttempt uint32 `protobuf:"varint,5,opt,name=delivery_attempt,json=deliveryAttempt,proto3" json:"delivery_attempt,omitempty"`
+	// When the message expires, if the producer set a TTL.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Priority level the producer gave the message.
+	Priority uint32 `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Partition key the producer gave the message.
+	Key           string `protobuf:"bytes,8,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_messagequeue_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Envelope) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Envelope) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Envelope) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Envelope) GetEnqueuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EnqueuedAt
+	}
+	return nil
+}
+
+func (x *Envelope) GetDeliveryAttempt() uint32 {
+	if x != nil {
+		return x.DeliveryAttempt
+	}
+	return 0
+}
+
+func (x *Envelope) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Envelope) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Envelope) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// Request to create a named queue.
+type CreateQueueRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Maximum number of messages the queue can hold.
+	Capacity uint64 `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Default lease duration for leased consumes (0 selects the server default).
+	VisibilityTimeoutMs int64 `protobuf:"varint,3,opt,name=visibility_timeout_ms,json=visibilityTimeoutMs,proto3" json:"visibility_timeout_ms,omitempty"`
+	// Failed deliveries (nacks or expired leases) before a message is dead-lettered (0 disables).
+	MaxDeliveryAttempts uint32 `protobuf:"varint,4,opt,name=max_delivery_attempts,json=maxDeliveryAttempts,proto3" json:"max_delivery_attempts,omitempty"`
+	// Name of the dead-letter queue (empty selects "<name>.dlq"); created if it does not exist.
+	DeadLetterQueue string `protobuf:"bytes,5,opt,name=dead_letter_queue,json=deadLetterQueue,proto3" json:"dead_letter_queue,omitempty"`
+	// Discard messages older than this (0 disables).
+	MaxAgeMs int64 `protobuf:"varint,6,opt,name=max_age_ms,json=maxAgeMs,proto3" json:"max_age_ms,omitempty"`
+	// What a produce to the full queue does: "reject" (default), "block", "drop_oldest" or "drop_newest".
+	OverflowPolicy string `protobuf:"bytes,7,opt,name=overflow_policy,json=overflowPolicy,proto3" json:"overflow_policy,omitempty"`
+	// How long a produce waits for space under the "block" policy (0 selects the server default).
+	BlockTimeoutMs int64 `protobuf:"varint,8,opt,name=block_timeout_ms,json=blockTimeoutMs,proto3" json:"block_timeout_ms,omitempty"`
+	// Number of priority levels (0 or 1 for a plain FIFO queue).
+	PriorityLevels uint32 `protobuf:"varint,9,opt,name=priority_levels,json=priorityLevels,proto3" json:"priority_levels,omitempty"`
+	// How a priority queue picks the next level: "strict" (default) or "weighted".
+	PriorityMode string `protobuf:"bytes,10,opt,name=priority_mode,json=priorityMode,proto3" json:"priority_mode,omitempty"`
+	// Number of keyed partitions (0 or 1 for an unpartitioned queue; cannot be combined with priority levels).
+	Partitions uint32 `protobuf:"varint,11,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	// Journal the queue to disk so its messages survive a restart (requires a data directory on the server;
+	// cannot be combined with partitions or a drop overflow policy).
+	Durable bool `protobuf:"varint,12,opt,name=durable,proto3" json:"durable,omitempty"`
+	// Maximum payload bytes the queue can hold (0 is unlimited). A produce beyond it is handled by the
+	// overflow policy, like one to a full queue.
+	MaxBytes      uint64 `protobuf:"varint,13,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateQueueRequest) Reset() {
+	*x = CreateQueueRequest{}
+	mi := &file_messagequeue_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateQueueRequest) ProtoMessage() {}
+
+func (x *CreateQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateQueueRequest.ProtoReflect.Descriptor instead.
+func (*CreateQueueRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateQueueRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateQueueRequest) GetCapacity() uint64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetVisibilityTimeoutMs() int64 {
+	if x != nil {
+		return x.VisibilityTimeoutMs
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetMaxDeliveryAttempts() uint32 {
+	if x != nil {
+		return x.MaxDeliveryAttempts
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetDeadLetterQueue() string {
+	if x != nil {
+		return x.DeadLetterQueue
+	}
+	return ""
+}
+
+func (x *CreateQueueRequest) GetMaxAgeMs() int64 {
+	if x != nil {
+		return x.MaxAgeMs
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetOverflowPolicy() string {
+	if x != nil {
+		return x.OverflowPolicy
+	}
+	return ""
+}
+
+func (x *CreateQueueRequest) GetBlockTimeoutMs() int64 {
+	if x != nil {
+		return x.BlockTimeoutMs
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetPriorityLevels() uint32 {
+	if x != nil {
+		return x.PriorityLevels
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetPriorityMode() string {
+	if x != nil {
+		return x.PriorityMode
+	}
+	return ""
+}
+
+func (x *CreateQueueRequest) GetPartitions() uint32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return 0
+}
+
+func (x *CreateQueueRequest) GetDurable() bool {
+	if x != nil {
+		return x.Durable
+	}
+	return false
+}
+
+func (x *CreateQueueRequest) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+// Response for queue creation.
+type CreateQueueResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateQueueResponse) Reset() {
+	*x = CreateQueueResponse{}
+	mi := &file_messagequeue_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateQueueResponse) ProtoMessage() {}
+
+func (x *CreateQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateQueueResponse.ProtoReflect.Descriptor instead.
+func (*CreateQueueResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateQueueResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *CreateQueueResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to delete a named queue.
+type DeleteQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQueueRequest) Reset() {
+	*x = DeleteQueueRequest{}
+	mi := &file_messagequeue_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQueueRequest) ProtoMessage() {}
+
+func (x *DeleteQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQueueRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQueueRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteQueueRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response for queue deletion.
+type DeleteQueueResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQueueResponse) Reset() {
+	*x = DeleteQueueResponse{}
+	mi := &file_messagequeue_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQueueResponse) ProtoMessage() {}
+
+func (x *DeleteQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQueueResponse.ProtoReflect.Descriptor instead.
+func (*DeleteQueueResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteQueueResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *DeleteQueueResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to list all named queues.
+type ListQueuesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQueuesRequest) Reset() {
+	*x = ListQueuesRequest{}
+	mi := &file_messagequeue_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQueuesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQueuesRequest) ProtoMessage() {}
+
+func (x *ListQueuesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQueuesRequest.ProtoReflect.Descriptor instead.
+func (*ListQueuesRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{15}
+}
+
+// Summary of a named queue.
+type QueueInfo struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Capacity uint64                 `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Number of messages in the queue when it was listed.
+	Length uint64 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	// Number of leased messages awaiting ack when it was listed.
+	InFlight uint64 `protobuf:"varint,4,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	// Failed deliveries before a message is dead-lettered (0 if disabled).
+	MaxDeliveryAttempts uint32 `protobuf:"varint,5,opt,name=max_delivery_attempts,json=maxDeliveryAttempts,proto3" json:"max_delivery_attempts,omitempty"`
+	// Name of the linked dead-letter queue, if any.
+	DeadLetterQueue string `protobuf:"bytes,6,opt,name=dead_letter_queue,json=deadLetterQueue,proto3" json:"dead_letter_queue,omitempty"`
+	// Number of delayed messages not yet ready when it was listed.
+	Scheduled uint64 `protobuf:"varint,7,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	// Messages older than this are discarded (0 if unlimited).
+	MaxAgeMs int64 `protobuf:"varint,8,opt,name=max_age_ms,json=maxAgeMs,proto3" json:"max_age_ms,omitempty"`
+	// What a produce to the full queue does.
+	OverflowPolicy string `protobuf:"bytes,9,opt,name=overflow_policy,json=overflowPolicy,proto3" json:"overflow_policy,omitempty"`
+	// Number of priority levels (0 for a FIFO queue).
+	PriorityLevels uint32 `protobuf:"varint,10,opt,name=priority_levels,json=priorityLevels,proto3" json:"priority_levels,omitempty"`
+	// How a priority queue picks the next level.
+	PriorityMode string `protobuf:"bytes,11,opt,name=priority_mode,json=priorityMode,proto3" json:"priority_mode,omitempty"`
+	// Topic the queue subscribes to, if it backs a subscription.
+	Topic string `protobuf:"bytes,12,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Number of keyed partitions (0 for an unpartitioned queue).
+	Partitions uint32 `protobuf:"varint,13,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	// Partitions owned by each connected consumer of a partitioned queue, in join order.
+	Assignments []*PartitionAssignment `protobuf:"bytes,14,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	// Whether the queue is journaled to disk.
+	Durable bool `protobuf:"varint,15,opt,name=durable,proto3" json:"durable,omitempty"`
+	// Maximum payload bytes the queue can hold (0 if unlimited).
+	MaxBytes uint64 `protobuf:"varint,16,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// Payload bytes in the queue when it was listed.
+	Bytes         uint64 `protobuf:"varint,17,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueInfo) Reset() {
+	*x = QueueInfo{}
+	mi := &file_messagequeue_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueInfo) ProtoMessage() {}
+
+func (x *QueueInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueInfo.ProtoReflect.Descriptor instead.
+func (*QueueInfo) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *QueueInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetCapacity() uint64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetInFlight() uint64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetMaxDeliveryAttempts() uint32 {
+	if x != nil {
+		return x.MaxDeliveryAttempts
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetDeadLetterQueue() string {
+	if x != nil {
+		return x.DeadLetterQueue
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetScheduled() uint64 {
+	if x != nil {
+		return x.Scheduled
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetMaxAgeMs() int64 {
+	if x != nil {
+		return x.MaxAgeMs
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetOverflowPolicy() string {
+	if x != nil {
+		return x.OverflowPolicy
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetPriorityLevels() uint32 {
+	if x != nil {
+		return x.PriorityLevels
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetPriorityMode() string {
+	if x != nil {
+		return x.PriorityMode
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetPartitions() uint32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetAssignments() []*PartitionAssignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+func (x *QueueInfo) GetDurable() bool {
+	if x != nil {
+		return x.Durable
+	}
+	return false
+}
+
+func (x *QueueInfo) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+// Partitions of a partitioned queue owned by one consumer.
+type PartitionAssignment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the consumer.
+	Consumer      string   `protobuf:"bytes,1,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	Partitions    []uint32 `protobuf:"varint,2,rep,packed,name=partitions,proto3" json:"partitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartitionAssignment) Reset() {
+	*x = PartitionAssignment{}
+	mi := &file_messagequeue_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartitionAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionAssignment) ProtoMessage() {}
+
+func (x *PartitionAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionAssignment.ProtoReflect.Descriptor instead.
+func (*PartitionAssignment) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PartitionAssignment) GetConsumer() string {
+	if x != nil {
+		return x.Consumer
+	}
+	return ""
+}
+
+func (x *PartitionAssignment) GetPartitions() []uint32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+// Response listing all named queues.
+type ListQueuesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queues        []*QueueInfo           `protobuf:"bytes,1,rep,name=queues,proto3" json:"queues,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQueuesResponse) Reset() {
+	*x = ListQueuesResponse{}
+	mi := &file_messagequeue_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQueuesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQueuesResponse) ProtoMessage() {}
+
+func (x *ListQueuesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQueuesResponse.ProtoReflect.Descriptor instead.
+func (*ListQueuesResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListQueuesResponse) GetQueues() []*QueueInfo {
+	if x != nil {
+		return x.Queues
+	}
+	return nil
+}
+
+// One failed delivery of a message.
+type DeliveryAttempt struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	DeliveredAt *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	// Why the delivery failed ("nack" or "lease_expired").
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryAttempt) Reset() {
+	*x = DeliveryAttempt{}
+	mi := &file_messagequeue_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryAttempt) ProtoMessage() {}
+
+func (x *DeliveryAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryAttempt.ProtoReflect.Descriptor instead.
+func (*DeliveryAttempt) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeliveryAttempt) GetDeliveredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return nil
+}
+
+func (x *DeliveryAttempt) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// A message in a dead-letter queue.
+type DeadLetter struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Payload  []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	Envelope *Envelope              `protobuf:"bytes,2,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	// Queue the message was dead-lettered from.
+	SourceQueue string `protobuf:"bytes,3,opt,name=source_queue,json=sourceQueue,proto3" json:"source_queue,omitempty"`
+	// Why the message was dead-lettered.
+	Reason         string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	DeadLetteredAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=dead_lettered_at,json=deadLetteredAt,proto3" json:"dead_lettered_at,omitempty"`
+	// Failed deliveries of the message, oldest first.
+	Attempts      []*DeliveryAttempt `protobuf:"bytes,6,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	mi := &file_messagequeue_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeadLetter) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetSourceQueue() string {
+	if x != nil {
+		return x.SourceQueue
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetDeadLetteredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeadLetteredAt
+	}
+	return nil
+}
+
+func (x *DeadLetter) GetAttempts() []*DeliveryAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+// Request to inspect a queue's dead-letter queue.
+type InspectDeadLettersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the queue whose dead-letter queue to inspect.
+	Queue string `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Maximum number of messages to return, oldest first (0 returns all).
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectDeadLettersRequest) Reset() {
+	*x = InspectDeadLettersRequest{}
+	mi := &file_messagequeue_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectDeadLettersRequest) ProtoMessage() {}
+
+func (x *InspectDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*InspectDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *InspectDeadLettersRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *InspectDeadLettersRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Response listing dead-lettered messages.
+type InspectDeadLettersResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Messages []*DeadLetter          `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectDeadLettersResponse) Reset() {
+	*x = InspectDeadLettersResponse{}
+	mi := &file_messagequeue_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectDeadLettersResponse) ProtoMessage() {}
+
+func (x *InspectDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*InspectDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *InspectDeadLettersResponse) GetMessages() []*DeadLetter {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *InspectDeadLettersResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to requeue messages from a queue's dead-letter queue.
+type RequeueDeadLettersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the queue whose dead-letter queue to requeue from.
+	Queue string `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	// Maximum number of messages to move, oldest first (0 moves all).
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequeueDeadLettersRequest) Reset() {
+	*x = RequeueDeadLettersRequest{}
+	mi := &file_messagequeue_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequeueDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequeueDeadLettersRequest) ProtoMessage() {}
+
+func (x *RequeueDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequeueDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*RequeueDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RequeueDeadLettersRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+func (x *RequeueDeadLettersRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Response for requeueing dead letters.
+type RequeueDeadLettersResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Number of messages moved back.
+	Requeued      uint64 `protobuf:"varint,3,opt,name=requeued,proto3" json:"requeued,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequeueDeadLettersResponse) Reset() {
+	*x = RequeueDeadLettersResponse{}
+	mi := &file_messagequeue_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequeueDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequeueDeadLettersResponse) ProtoMessage() {}
+
+func (x *RequeueDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequeueDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*RequeueDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RequeueDeadLettersResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *RequeueDeadLettersResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RequeueDeadLettersResponse) GetRequeued() uint64 {
+	if x != nil {
+		return x.Requeued
+	}
+	return 0
+}
+
+// Request to purge a queue's dead-letter queue.
+type PurgeDeadLettersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the queue whose dead-letter queue to purge.
+	Queue         string `protobuf:"bytes,1,opt,name=queue,proto3" json:"queue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeDeadLettersRequest) Reset() {
+	*x = PurgeDeadLettersRequest{}
+	mi := &file_messagequeue_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLettersRequest) ProtoMessage() {}
+
+func (x *PurgeDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PurgeDeadLettersRequest) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+// Response for purging dead letters.
+type PurgeDeadLettersResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Number of messages discarded.
+	Purged        uint64 `protobuf:"varint,3,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeDeadLettersResponse) Reset() {
+	*x = PurgeDeadLettersResponse{}
+	mi := &file_messagequeue_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLettersResponse) ProtoMessage() {}
+
+func (x *PurgeDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PurgeDeadLettersResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *PurgeDeadLettersResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PurgeDeadLettersResponse) GetPurged() uint64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+// Request to subscribe to a topic.
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Topic string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Name of the subscription; consumers sharing it compete for its messages.
+	Subscription string `protobuf:"bytes,2,opt,name=subscription,proto3" json:"subscription,omitempty"`
+	// Maximum number of messages the subscription queue can hold (0 selects the server default).
+	Capacity uint64 `protobuf:"varint,3,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Default lease duration for leased consumes (0 selects the server default).
+	VisibilityTimeoutMs int64 `protobuf:"varint,4,opt,name=visibility_timeout_ms,json=visibilityTimeoutMs,proto3" json:"visibility_timeout_ms,omitempty"`
+	// Failed deliveries before a message is dead-lettered (0 disables).
+	MaxDeliveryAttempts uint32 `protobuf:"varint,5,opt,name=max_delivery_attempts,json=maxDeliveryAttempts,proto3" json:"max_delivery_attempts,omitempty"`
+	// Discard messages older than this (0 disables).
+	MaxAgeMs      int64 `protobuf:"varint,6,opt,name=max_age_ms,json=maxAgeMs,proto3" json:"max_age_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_messagequeue_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SubscribeRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetSubscription() string {
+	if x != nil {
+		return x.Subscription
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetCapacity() uint64 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *SubscribeRequest) GetVisibilityTimeoutMs() int64 {
+	if x != nil {
+		return x.VisibilityTimeoutMs
+	}
+	return 0
+}
+
+func (x *SubscribeRequest) GetMaxDeliveryAttempts() uint32 {
+	if x != nil {
+		return x.MaxDeliveryAttempts
+	}
+	return 0
+}
+
+func (x *SubscribeRequest) GetMaxAgeMs() int64 {
+	if x != nil {
+		return x.MaxAgeMs
+	}
+	return 0
+}
+
+// Response for subscribing.
+type SubscribeResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Name of the queue to consume the subscription from.
+	Queue         string `protobuf:"bytes,3,opt,name=queue,proto3" json:"queue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	mi := &file_messagequeue_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SubscribeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *SubscribeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+// Request to unsubscribe from a topic.
+type UnsubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Subscription  string                 `protobuf:"bytes,2,opt,name=subscription,proto3" json:"subscription,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeRequest) Reset() {
+	*x = UnsubscribeRequest{}
+	mi := &file_messagequeue_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeRequest) ProtoMessage() {}
+
+func (x *UnsubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UnsubscribeRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *UnsubscribeRequest) GetSubscription() string {
+	if x != nil {
+		return x.Subscription
+	}
+	return ""
+}
+
+// Response for unsubscribing.
+type UnsubscribeResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeResponse) Reset() {
+	*x = UnsubscribeResponse{}
+	mi := &file_messagequeue_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeResponse) ProtoMessage() {}
+
+func (x *UnsubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UnsubscribeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *UnsubscribeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to list topics.
+type ListTopicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTopicsRequest) Reset() {
+	*x = ListTopicsRequest{}
+	mi := &file_messagequeue_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTopicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTopicsRequest) ProtoMessage() {}
+
+func (x *ListTopicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTopicsRequest.ProtoReflect.Descriptor instead.
+func (*ListTopicsRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{31}
+}
+
+// Summary of one topic.
+type TopicInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Names of the topic's subscriptions.
+	Subscriptions []string `protobuf:"bytes,2,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicInfo) Reset() {
+	*x = TopicInfo{}
+	mi := &file_messagequeue_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicInfo) ProtoMessage() {}
+
+func (x *TopicInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicInfo.ProtoReflect.Descriptor instead.
+func (*TopicInfo) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TopicInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopicInfo) GetSubscriptions() []string {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+// Response listing all topics.
+type ListTopicsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []*TopicInfo           `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTopicsResponse) Reset() {
+	*x = ListTopicsResponse{}
+	mi := &file_messagequeue_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTopicsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTopicsResponse) ProtoMessage() {}
+
+func (x *ListTopicsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTopicsResponse.ProtoReflect.Descriptor instead.
+func (*ListTopicsResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListTopicsResponse) GetTopics() []*TopicInfo {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+// Request to create a log.
+type CreateLogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Messages per segment (0 selects the server default).
+	SegmentSize uint32 `protobuf:"varint,2,opt,name=segment_size,json=segmentSize,proto3" json:"segment_size,omitempty"`
+	// Minimum number of most recent messages retained (0 selects the server default).
+	Retention     uint64 `protobuf:"varint,3,opt,name=retention,proto3" json:"retention,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogRequest) Reset() {
+	*x = CreateLogRequest{}
+	mi := &file_messagequeue_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogRequest) ProtoMessage() {}
+
+func (x *CreateLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogRequest.ProtoReflect.Descriptor instead.
+func (*CreateLogRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CreateLogRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateLogRequest) GetSegmentSize() uint32 {
+	if x != nil {
+		return x.SegmentSize
+	}
+	return 0
+}
+
+func (x *CreateLogRequest) GetRetention() uint64 {
+	if x != nil {
+		return x.Retention
+	}
+	return 0
+}
+
+// Response for log creation.
+type CreateLogResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLogResponse) Reset() {
+	*x = CreateLogResponse{}
+	mi := &file_messagequeue_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLogResponse) ProtoMessage() {}
+
+func (x *CreateLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLogResponse.ProtoReflect.Descriptor instead.
+func (*CreateLogResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CreateLogResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *CreateLogResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to delete a log.
+type DeleteLogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLogRequest) Reset() {
+	*x = DeleteLogRequest{}
+	mi := &file_messagequeue_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogRequest) ProtoMessage() {}
+
+func (x *DeleteLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLogRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeleteLogRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Response for log deletion.
+type DeleteLogResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLogResponse) Reset() {
+	*x = DeleteLogResponse{}
+	mi := &file_messagequeue_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLogResponse) ProtoMessage() {}
+
+func (x *DeleteLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLogResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLogResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DeleteLogResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *DeleteLogResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to list logs.
+type ListLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLogsRequest) Reset() {
+	*x = ListLogsRequest{}
+	mi := &file_messagequeue_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLogsRequest) ProtoMessage() {}
+
+func (x *ListLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLogsRequest.ProtoReflect.Descriptor instead.
+func (*ListLogsRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{38}
+}
+
+// Summary of one consumer group of a log.
+type ConsumerGroupInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The group has processed every message before this offset.
+	CommittedOffset uint64 `protobuf:"varint,2,opt,name=committed_offset,json=committedOffset,proto3" json:"committed_offset,omitempty"`
+	// Offset of the next message handed to a member of the group.
+	Position uint64 `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	// Messages between the committed offset and the end of the log.
+	Lag           uint64 `protobuf:"varint,4,opt,name=lag,proto3" json:"lag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumerGroupInfo) Reset() {
+	*x = ConsumerGroupInfo{}
+	mi := &file_messagequeue_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumerGroupInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumerGroupInfo) ProtoMessage() {}
+
+func (x *ConsumerGroupInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumerGroupInfo.ProtoReflect.Descriptor instead.
+func (*ConsumerGroupInfo) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ConsumerGroupInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ConsumerGroupInfo) GetCommittedOffset() uint64 {
+	if x != nil {
+		return x.CommittedOffset
+	}
+	return 0
+}
+
+func (x *ConsumerGroupInfo) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *ConsumerGroupInfo) GetLag() uint64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+// Summary of one log.
+type LogInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Offset of the oldest retained message.
+	StartOffset uint64 `protobuf:"varint,2,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`
+	// Offset the next appended message will get.
+	EndOffset     uint64               `protobuf:"varint,3,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`
+	Groups        []*ConsumerGroupInfo `protobuf:"bytes,4,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogInfo) Reset() {
+	*x = LogInfo{}
+	mi := &file_messagequeue_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogInfo) ProtoMessage() {}
+
+func (x *LogInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogInfo.ProtoReflect.Descriptor instead.
+func (*LogInfo) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *LogInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LogInfo) GetStartOffset() uint64 {
+	if x != nil {
+		return x.StartOffset
+	}
+	return 0
+}
+
+func (x *LogInfo) GetEndOffset() uint64 {
+	if x != nil {
+		return x.EndOffset
+	}
+	return 0
+}
+
+func (x *LogInfo) GetGroups() []*ConsumerGroupInfo {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// Response listing all logs.
+type ListLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Logs          []*LogInfo             `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLogsResponse) Reset() {
+	*x = ListLogsResponse{}
+	mi := &file_messagequeue_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLogsResponse) ProtoMessage() {}
+
+func (x *ListLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLogsResponse.ProtoReflect.Descriptor instead.
+func (*ListLogsResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListLogsResponse) GetLogs() []*LogInfo {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+// Request to read messages of a log for a consumer group.
+type FetchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Log   string                 `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	// Consumer group; members of the same group share its messages.
+	Group string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// Maximum number of messages to return (0 returns one; the server returns at most 1000).
+	MaxMessages uint32 `protobuf:"varint,3,opt,name=max_messages,json=maxMessages,proto3" json:"max_messages,omitempty"`
+	// Wait up to this long for a message if the group has read everything (0 returns at once).
+	WaitTimeoutMs int64 `protobuf:"varint,4,opt,name=wait_timeout_ms,json=waitTimeoutMs,proto3" json:"wait_timeout_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchRequest) Reset() {
+	*x = FetchRequest{}
+	mi := &file_messagequeue_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchRequest) ProtoMessage() {}
+
+func (x *FetchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchRequest.ProtoReflect.Descriptor instead.
+func (*FetchRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *FetchRequest) GetLog() string {
+	if x != nil {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetMaxMessages() uint32 {
+	if x != nil {
+		return x.MaxMessages
+	}
+	return 0
+}
+
+func (x *FetchRequest) GetWaitTimeoutMs() int64 {
+	if x != nil {
+		return x.WaitTimeoutMs
+	}
+	return 0
+}
+
+// A message read from a log.
+type LogRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        uint64                 `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Envelope      *Envelope              `protobuf:"bytes,3,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRecord) Reset() {
+	*x = LogRecord{}
+	mi := &file_messagequeue_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRecord) ProtoMessage() {}
+
+func (x *LogRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRecord.ProtoReflect.Descriptor instead.
+func (*LogRecord) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *LogRecord) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *LogRecord) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *LogRecord) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
+
+// Response with the messages read.
+type FetchResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Records []*LogRecord           `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchResponse) Reset() {
+	*x = FetchResponse{}
+	mi := &file_messagequeue_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchResponse) ProtoMessage() {}
+
+func (x *FetchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchResponse.ProtoReflect.Descriptor instead.
+func (*FetchResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *FetchResponse) GetRecords() []*LogRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *FetchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to commit a consumer group's offset.
+type CommitOffsetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Log   string                 `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	Group string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	// Offset of the next message to process: everything before it is done.
+	Offset        uint64 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitOffsetRequest) Reset() {
+	*x = CommitOffsetRequest{}
+	mi := &file_messagequeue_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitOffsetRequest) ProtoMessage() {}
+
+func (x *CommitOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitOffsetRequest.ProtoReflect.Descriptor instead.
+func (*CommitOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *CommitOffsetRequest) GetLog() string {
+	if x != nil {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *CommitOffsetRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *CommitOffsetRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// Response for committing an offset.
+type CommitOffsetResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitOffsetResponse) Reset() {
+	*x = CommitOffsetResponse{}
+	mi := &file_messagequeue_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitOffsetResponse) ProtoMessage() {}
+
+func (x *CommitOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitOffsetResponse.ProtoReflect.Descriptor instead.
+func (*CommitOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *CommitOffsetResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *CommitOffsetResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request to move a consumer group back to its committed offset.
+type RewindGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Log           string                 `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	Group         string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewindGroupRequest) Reset() {
+	*x = RewindGroupRequest{}
+	mi := &file_messagequeue_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewindGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindGroupRequest) ProtoMessage() {}
+
+func (x *RewindGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindGroupRequest.ProtoReflect.Descriptor instead.
+func (*RewindGroupRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *RewindGroupRequest) GetLog() string {
+	if x != nil {
+		return x.Log
+	}
+	return ""
+}
+
+func (x *RewindGroupRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+// Response for rewinding a consumer group.
+type RewindGroupResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Offset of the next message the group fetches.
+	Position      uint64 `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RewindGroupResponse) Reset() {
+	*x = RewindGroupResponse{}
+	mi := &file_messagequeue_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RewindGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RewindGroupResponse) ProtoMessage() {}
+
+func (x *RewindGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RewindGroupResponse.ProtoReflect.Descriptor instead.
+func (*RewindGroupResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *RewindGroupResponse) GetPosition() uint64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+// Request to snapshot every queue to the snapshot file configured on the server.
+type SnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_messagequeue_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{49}
+}
+
+// Response for taking a snapshot.
+type SnapshotResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Number of queues written.
+	Queues uint32 `protobuf:"varint,3,opt,name=queues,proto3" json:"queues,omitempty"`
+	// Number of messages written, delayed ones included.
+	Messages      uint64 `protobuf:"varint,4,opt,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	mi := &file_messagequeue_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotResponse) ProtoMessage() {}
+
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SnapshotResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *SnapshotResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SnapshotResponse) GetQueues() uint32 {
+	if x != nil {
+		return x.Queues
+	}
+	return 0
+}
+
+func (x *SnapshotResponse) GetMessages() uint64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+// Request to restore the snapshot file configured on the server.
+type RestoreRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_messagequeue_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{51}
+}
+
+// Response for restoring a snapshot.
+type RestoreResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Deprecated: Marked as deprecated in messagequeue.proto.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Number of queues restored or recreated.
+	Queues uint32 `protobuf:"varint,3,opt,name=queues,proto3" json:"queues,omitempty"`
+	// Number of messages put back.
+	Messages      uint64 `protobuf:"varint,4,opt,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
+	mi := &file_messagequeue_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse) ProtoMessage() {}
+
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messagequeue_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_messagequeue_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RestoreResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Deprecated: Marked as deprecated in messagequeue.proto.
+func (x *RestoreResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RestoreResponse) GetQueues() uint32 {
+	if x != nil {
+		return x.Queues
+	}
+	return 0
+}
+
+func (x *RestoreResponse) GetMessages() uint64 {
+	if x != nil {
+		return x.Messages
+	}
+	return 0
+}
+
+var File_messagequeue_proto protoreflect.FileDescriptor
+
+const file_messagequeue_proto_rawDesc = "" +
+	"\n" +
+	"\x12messagequeue.proto\x12\fmessagequeue\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcd\x03\n" +
+	"\x0eProduceRequest\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x12\x14\n" +
+	"\x05queue\x18\x02 \x01(\tR\x05queue\x12C\n" +
+	"\aheaders\x18\x03 \x03(\v2).messagequeue.ProduceRequest.HeadersEntryR\aheaders\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x19\n" +
+	"\bdelay_ms\x18\x05 \x01(\x03R\adelayMs\x129\n" +
+	"\n" +
+	"deliver_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tdeliverAt\x12\x15\n" +
+	"\x06ttl_ms\x18\a \x01(\x03R\x05ttlMs\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\rR\bpriority\x12\x14\n" +
+	"\x05topic\x18\t \x01(\tR\x05topic\x12\x10\n" +
+	"\x03log\x18\n" +
+	" \x01(\tR\x03log\x12\x10\n" +
+	"\x03key\x18\v \x01(\tR\x03key\x12$\n" +
+	"\rsubscriptions\x18\f \x03(\tR\rsubscriptions\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd5\x01\n" +
+	"\x0fProduceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x03 \x01(\tR\tmessageId\x12\x1e\n" +
+	"\n" +
+	"deliveries\x18\x04 \x01(\rR\n" +
+	"deliveries\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x04R\x06offset\x127\n" +
+	"\x17delivered_subscriptions\x18\x06 \x03(\tR\x16deliveredSubscriptions\"\x98\x01\n" +
+	"\x0eConsumeRequest\x12&\n" +
+	"\x0fwait_timeout_ms\x18\x01 \x01(\x03R\rwaitTimeoutMs\x12\x14\n" +
+	"\x05queue\x18\x02 \x01(\tR\x05queue\x12\x14\n" +
+	"\x05lease\x18\x03 \x01(\bR\x05lease\x122\n" +
+	"\x15visibility_timeout_ms\x18\x04 \x01(\x03R\x13visibilityTimeoutMs\"\xbc\x01\n" +
+	"\x0fConsumeResponse\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x122\n" +
+	"\benvelope\x18\x03 \x01(\v2\x16.messagequeue.EnvelopeR\benvelope\x12A\n" +
+	"\x0elease_deadline\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rleaseDeadline\"A\n" +
+	"\n" +
+	"AckRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\"A\n" +
+	"\vAckResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"B\n" +
+	"\vNackRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\"B\n" +
+	"\fNackResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"\x91\x03\n" +
+	"\rStreamMessage\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x14\n" +
+	"\x05queue\x18\x03 \x01(\tR\x05queue\x12B\n" +
+	"\aheaders\x18\x04 \x03(\v2(.messagequeue.StreamMessage.HeadersEntryR\aheaders\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x122\n" +
+	"\benvelope\x18\x06 \x01(\v2\x16.messagequeue.EnvelopeR\benvelope\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\rR\bpriority\x12\x10\n" +
+	"\x03key\x18\b \x01(\tR\x03key\x121\n" +
+	"\x06status\x18\t \x01(\v2\x19.messagequeue.ErrorStatusR\x06status\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"y\n" +
+	"\vErrorStatus\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12$\n" +
+	"\x0eretry_after_ms\x18\x04 \x01(\x03R\fretryAfterMs\"\x89\x03\n" +
+	"\bEnvelope\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12=\n" +
+	"\aheaders\x18\x02 \x03(\v2#.messagequeue.Envelope.HeadersEntryR\aheaders\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12;\n" +
+	"\venqueued_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"enqueuedAt\x12)\n" +
+	"\x10delivery_attempt\x18\x05 \x01(\rR\x0fdeliveryAttempt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\rR\bpriority\x12\x10\n" +
+	"\x03key\x18\b \x01(\tR\x03key\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xee\x03\n" +
+	"\x12CreateQueueRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bcapacity\x18\x02 \x01(\x04R\bcapacity\x122\n" +
+	"\x15visibility_timeout_ms\x18\x03 \x01(\x03R\x13visibilityTimeoutMs\x122\n" +
+	"\x15max_delivery_attempts\x18\x04 \x01(\rR\x13maxDeliveryAttempts\x12*\n" +
+	"\x11dead_letter_queue\x18\x05 \x01(\tR\x0fdeadLetterQueue\x12\x1c\n" +
+	"\n" +
+	"max_age_ms\x18\x06 \x01(\x03R\bmaxAgeMs\x12'\n" +
+	"\x0foverflow_policy\x18\a \x01(\tR\x0eoverflowPolicy\x12(\n" +
+	"\x10block_timeout_ms\x18\b \x01(\x03R\x0eblockTimeoutMs\x12'\n" +
+	"\x0fpriority_levels\x18\t \x01(\rR\x0epriorityLevels\x12#\n" +
+	"\rpriority_mode\x18\n" +
+	" \x01(\tR\fpriorityMode\x12\x1e\n" +
+	"\n" +
+	"partitions\x18\v \x01(\rR\n" +
+	"partitions\x12\x18\n" +
+	"\adurable\x18\f \x01(\bR\adurable\x12\x1b\n" +
+	"\tmax_bytes\x18\r \x01(\x04R\bmaxBytes\"I\n" +
+	"\x13CreateQueueResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"(\n" +
+	"\x12DeleteQueueRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"I\n" +
+	"\x13DeleteQueueResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"\x13\n" +
+	"\x11ListQueuesRequest\"\xcb\x04\n" +
+	"\tQueueInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bcapacity\x18\x02 \x01(\x04R\bcapacity\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\x12\x1b\n" +
+	"\tin_flight\x18\x04 \x01(\x04R\binFlight\x122\n" +
+	"\x15max_delivery_attempts\x18\x05 \x01(\rR\x13maxDeliveryAttempts\x12*\n" +
+	"\x11dead_letter_queue\x18\x06 \x01(\tR\x0fdeadLetterQueue\x12\x1c\n" +
+	"\tscheduled\x18\a \x01(\x04R\tscheduled\x12\x1c\n" +
+	"\n" +
+	"max_age_ms\x18\b \x01(\x03R\bmaxAgeMs\x12'\n" +
+	"\x0foverflow_policy\x18\t \x01(\tR\x0eoverflowPolicy\x12'\n" +
+	"\x0fpriority_levels\x18\n" +
+	" \x01(\rR\x0epriorityLevels\x12#\n" +
+	"\rpriority_mode\x18\v \x01(\tR\fpriorityMode\x12\x14\n" +
+	"\x05topic\x18\f \x01(\tR\x05topic\x12\x1e\n" +
+	"\n" +
+	"partitions\x18\r \x01(\rR\n" +
+	"partitions\x12C\n" +
+	"\vassignments\x18\x0e \x03(\v2!.messagequeue.PartitionAssignmentR\vassignments\x12\x18\n" +
+	"\adurable\x18\x0f \x01(\bR\adurable\x12\x1b\n" +
+	"\tmax_bytes\x18\x10 \x01(\x04R\bmaxBytes\x12\x14\n" +
+	"\x05bytes\x18\x11 \x01(\x04R\x05bytes\"Q\n" +
+	"\x13PartitionAssignment\x12\x1a\n" +
+	"\bconsumer\x18\x01 \x01(\tR\bconsumer\x12\x1e\n" +
+	"\n" +
+	"partitions\x18\x02 \x03(\rR\n" +
+	"partitions\"E\n" +
+	"\x12ListQueuesResponse\x12/\n" +
+	"\x06queues\x18\x01 \x03(\v2\x17.messagequeue.QueueInfoR\x06queues\"h\n" +
+	"\x0fDeliveryAttempt\x12=\n" +
+	"\fdelivered_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vdeliveredAt\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x96\x02\n" +
+	"\n" +
+	"DeadLetter\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x122\n" +
+	"\benvelope\x18\x02 \x01(\v2\x16.messagequeue.EnvelopeR\benvelope\x12!\n" +
+	"\fsource_queue\x18\x03 \x01(\tR\vsourceQueue\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12D\n" +
+	"\x10dead_lettered_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0edeadLetteredAt\x129\n" +
+	"\battempts\x18\x06 \x03(\v2\x1d.messagequeue.DeliveryAttemptR\battempts\"G\n" +
+	"\x19InspectDeadLettersRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"l\n" +
+	"\x1aInspectDeadLettersResponse\x124\n" +
+	"\bmessages\x18\x01 \x03(\v2\x18.messagequeue.DeadLetterR\bmessages\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"G\n" +
+	"\x19RequeueDeadLettersRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"l\n" +
+	"\x1aRequeueDeadLettersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x1a\n" +
+	"\brequeued\x18\x03 \x01(\x04R\brequeued\"/\n" +
+	"\x17PurgeDeadLettersRequest\x12\x14\n" +
+	"\x05queue\x18\x01 \x01(\tR\x05queue\"f\n" +
+	"\x18PurgeDeadLettersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x16\n" +
+	"\x06purged\x18\x03 \x01(\x04R\x06purged\"\xee\x01\n" +
+	"\x10SubscribeRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\"\n" +
+	"\fsubscription\x18\x02 \x01(\tR\fsubscription\x12\x1a\n" +
+	"\bcapacity\x18\x03 \x01(\x04R\bcapacity\x122\n" +
+	"\x15visibility_timeout_ms\x18\x04 \x01(\x03R\x13visibilityTimeoutMs\x122\n" +
+	"\x15max_delivery_attempts\x18\x05 \x01(\rR\x13maxDeliveryAttempts\x12\x1c\n" +
+	"\n" +
+	"max_age_ms\x18\x06 \x01(\x03R\bmaxAgeMs\"]\n" +
+	"\x11SubscribeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x14\n" +
+	"\x05queue\x18\x03 \x01(\tR\x05queue\"N\n" +
+	"\x12UnsubscribeRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\"\n" +
+	"\fsubscription\x18\x02 \x01(\tR\fsubscription\"I\n" +
+	"\x13UnsubscribeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"\x13\n" +
+	"\x11ListTopicsRequest\"E\n" +
+	"\tTopicInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12$\n" +
+	"\rsubscriptions\x18\x02 \x03(\tR\rsubscriptions\"E\n" +
+	"\x12ListTopicsResponse\x12/\n" +
+	"\x06topics\x18\x01 \x03(\v2\x17.messagequeue.TopicInfoR\x06topics\"g\n" +
+	"\x10CreateLogRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fsegment_size\x18\x02 \x01(\rR\vsegmentSize\x12\x1c\n" +
+	"\tretention\x18\x03 \x01(\x04R\tretention\"G\n" +
+	"\x11CreateLogResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"&\n" +
+	"\x10DeleteLogRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"G\n" +
+	"\x11DeleteLogResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"\x11\n" +
+	"\x0fListLogsRequest\"\x80\x01\n" +
+	"\x11ConsumerGroupInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12)\n" +
+	"\x10committed_offset\x18\x02 \x01(\x04R\x0fcommittedOffset\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\x04R\bposition\x12\x10\n" +
+	"\x03lag\x18\x04 \x01(\x04R\x03lag\"\x98\x01\n" +
+	"\aLogInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fstart_offset\x18\x02 \x01(\x04R\vstartOffset\x12\x1d\n" +
+	"\n" +
+	"end_offset\x18\x03 \x01(\x04R\tendOffset\x127\n" +
+	"\x06groups\x18\x04 \x03(\v2\x1f.messagequeue.ConsumerGroupInfoR\x06groups\"=\n" +
+	"\x10ListLogsResponse\x12)\n" +
+	"\x04logs\x18\x01 \x03(\v2\x15.messagequeue.LogInfoR\x04logs\"\x81\x01\n" +
+	"\fFetchRequest\x12\x10\n" +
+	"\x03log\x18\x01 \x01(\tR\x03log\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12!\n" +
+	"\fmax_messages\x18\x03 \x01(\rR\vmaxMessages\x12&\n" +
+	"\x0fwait_timeout_ms\x18\x04 \x01(\x03R\rwaitTimeoutMs\"q\n" +
+	"\tLogRecord\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x04R\x06offset\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\x122\n" +
+	"\benvelope\x18\x03 \x01(\v2\x16.messagequeue.EnvelopeR\benvelope\"\\\n" +
+	"\rFetchResponse\x121\n" +
+	"\arecords\x18\x01 \x03(\v2\x17.messagequeue.LogRecordR\arecords\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"U\n" +
+	"\x13CommitOffsetRequest\x12\x10\n" +
+	"\x03log\x18\x01 \x01(\tR\x03log\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x04R\x06offset\"J\n" +
+	"\x14CommitOffsetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\"<\n" +
+	"\x12RewindGroupRequest\x12\x10\n" +
+	"\x03log\x18\x01 \x01(\tR\x03log\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\"1\n" +
+	"\x13RewindGroupResponse\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x04R\bposition\"\x11\n" +
+	"\x0fSnapshotRequest\"z\n" +
+	"\x10SnapshotResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x16\n" +
+	"\x06queues\x18\x03 \x01(\rR\x06queues\x12\x1a\n" +
+	"\bmessages\x18\x04 \x01(\x04R\bmessages\"\x10\n" +
+	"\x0eRestoreRequest\"y\n" +
+	"\x0fRestoreResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\x05error\x18\x02 \x01(\tB\x02\x18\x01R\x05error\x12\x16\n" +
+	"\x06queues\x18\x03 \x01(\rR\x06queues\x12\x1a\n" +
+	"\bmessages\x18\x04 \x01(\x04R\bmessages*O\n" +
+	"\n" +
+	"ApiVersion\x12\x1b\n" +
+	"\x17API_VERSION_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rAPI_VERSION_1\x10\x01\x12\x11\n" +
+	"\rAPI_VERSION_2\x10\x022\xf1\r\n" +
+	"\fMessageQueue\x12F\n" +
+	"\aProduce\x12\x1c.messagequeue.ProduceRequest\x1a\x1d.messagequeue.ProduceResponse\x12F\n" +
+	"\aConsume\x12\x1c.messagequeue.ConsumeRequest\x1a\x1d.messagequeue.ConsumeResponse\x12:\n" +
+	"\x03Ack\x12\x18.messagequeue.AckRequest\x1a\x19.messagequeue.AckResponse\x12=\n" +
+	"\x04Nack\x12\x19.messagequeue.NackRequest\x1a\x1a.messagequeue.NackResponse\x12N\n" +
+	"\x0eStreamMessages\x12\x1b.messagequeue.StreamMessage\x1a\x1b.messagequeue.StreamMessage(\x010\x01\x12R\n" +
+	"\vCreateQueue\x12 .messagequeue.CreateQueueRequest\x1a!.messagequeue.CreateQueueResponse\x12R\n" +
+	"\vDeleteQueue\x12 .messagequeue.DeleteQueueRequest\x1a!.messagequeue.DeleteQueueResponse\x12O\n" +
+	"\n" +
+	"ListQueues\x12\x1f.messagequeue.ListQueuesRequest\x1a .messagequeue.ListQueuesResponse\x12g\n" +
+	"\x12InspectDeadLetters\x12'.messagequeue.InspectDeadLettersRequest\x1a(.messagequeue.InspectDeadLettersResponse\x12g\n" +
+	"\x12RequeueDeadLetters\x12'.messagequeue.RequeueDeadLettersRequest\x1a(.messagequeue.RequeueDeadLettersResponse\x12a\n" +
+	"\x10PurgeDeadLetters\x12%.messagequeue.PurgeDeadLettersRequest\x1a&.messagequeue.PurgeDeadLettersResponse\x12L\n" +
+	"\tSubscribe\x12\x1e.messagequeue.SubscribeRequest\x1a\x1f.messagequeue.SubscribeResponse\x12R\n" +
+	"\vUnsubscribe\x12 .messagequeue.UnsubscribeRequest\x1a!.messagequeue.UnsubscribeResponse\x12O\n" +
+	"\n" +
+	"ListTopics\x12\x1f.messagequeue.ListTopicsRequest\x1a .messagequeue.ListTopicsResponse\x12L\n" +
+	"\tCreateLog\x12\x1e.messagequeue.CreateLogRequest\x1a\x1f.messagequeue.CreateLogResponse\x12L\n" +
+	"\tDeleteLog\x12\x1e.messagequeue.DeleteLogRequest\x1a\x1f.messagequeue.DeleteLogResponse\x12I\n" +
+	"\bListLogs\x12\x1d.messagequeue.ListLogsRequest\x1a\x1e.messagequeue.ListLogsResponse\x12@\n" +
+	"\x05Fetch\x12\x1a.messagequeue.FetchRequest\x1a\x1b.messagequeue.FetchResponse\x12U\n" +
+	"\fCommitOffset\x12!.messagequeue.CommitOffsetRequest\x1a\".messagequeue.CommitOffsetResponse\x12R\n" +
+	"\vRewindGroup\x12 .messagequeue.RewindGroupRequest\x1a!.messagequeue.RewindGroupResponse\x12I\n" +
+	"\bSnapshot\x12\x1d.messagequeue.SnapshotRequest\x1a\x1e.messagequeue.SnapshotResponse\x12F\n" +
+	"\aRestore\x12\x1c.messagequeue.RestoreRequest\x1a\x1d.messagequeue.RestoreResponseB\x18Z\x16quickpulse/proto;protob\x06proto3"
+
 var (
 	file_messagequeue_proto_rawDescOnce sync.Once
-	file_messagequeue_proto_rawDescData = file_messagequeue_proto_rawDesc
+	file_messagequeue_proto_rawDescData []byte
 )
 
 func file_messagequeue_proto_rawDescGZIP() []byte {
 	file_messagequeue_proto_rawDescOnce.Do(func() {
-		file_messagequeue_proto_rawDescData = protoimpl.X.CompressGZIP(file_messagequeue_proto_rawDescData)
+		file_messagequeue_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_messagequeue_proto_rawDesc), len(file_messagequeue_proto_rawDesc)))
 	})
 	return file_messagequeue_proto_rawDescData
 }
 
-var file_messagequeue_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_messagequeue_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_messagequeue_proto_msgTypes = make([]protoimpl.MessageInfo, 56)
 var file_messagequeue_proto_goTypes = []any{
-	(*ProduceRequest)(nil),  // 0: messagequeue.ProduceRequest
-	(*ProduceResponse)(nil), // 1: messagequeue.ProduceResponse
-	(*ConsumeRequest)(nil),  // 2: messagequeue.ConsumeRequest
-	(*ConsumeResponse)(nil), // 3: messagequeue.ConsumeResponse
-	(*StreamMessage)(nil),   // 4: messagequeue.StreamMessage
+	(ApiVersion)(0),                    // 0: messagequeue.ApiVersion
+	(*ProduceRequest)(nil),             // 1: messagequeue.ProduceRequest
+	(*ProduceResponse)(nil),            // 2: messagequeue.ProduceResponse
+	(*ConsumeRequest)(nil),             // 3: messagequeue.ConsumeRequest
+	(*ConsumeResponse)(nil),            // 4: messagequeue.ConsumeResponse
+	(*AckRequest)(nil),                 // 5: messagequeue.AckRequest
+	(*AckResponse)(nil),                // 6: messagequeue.AckResponse
+	(*NackRequest)(nil),                // 7: messagequeue.NackRequest
+	(*NackResponse)(nil),               // 8: messagequeue.NackResponse
+	(*StreamMessage)(nil),              // 9: messagequeue.StreamMessage
+	(*ErrorStatus)(nil),                // 10: messagequeue.ErrorStatus
+	(*Envelope)(nil),                   // 11: messagequeue.Envelope
+	(*CreateQueueRequest)(nil),         // 12: messagequeue.CreateQueueRequest
+	(*CreateQueueResponse)(nil),        // 13: messagequeue.CreateQueueResponse
+	(*DeleteQueueRequest)(nil),         // 14: messagequeue.DeleteQueueRequest
+	(*DeleteQueueResponse)(nil),        // 15: messagequeue.DeleteQueueResponse
+	(*ListQueuesRequest)(nil),          // 16: messagequeue.ListQueuesRequest
+	(*QueueInfo)(nil),                  // 17: messagequeue.QueueInfo
+	(*PartitionAssignment)(nil),        // 18: messagequeue.PartitionAssignment
+	(*ListQueuesResponse)(nil),         // 19: messagequeue.ListQueuesResponse
+	(*DeliveryAttempt)(nil),            // 20: messagequeue.DeliveryAttempt
+	(*DeadLetter)(nil),                 // 21: messagequeue.DeadLetter
+	(*InspectDeadLettersRequest)(nil),  // 22: messagequeue.InspectDeadLettersRequest
+	(*InspectDeadLettersResponse)(nil), // 23: messagequeue.InspectDeadLettersResponse
+	(*RequeueDeadLettersRequest)(nil),  // 24: messagequeue.RequeueDeadLettersRequest
+	(*RequeueDeadLettersResponse)(nil), // 25: messagequeue.RequeueDeadLettersResponse
+	(*PurgeDeadLettersRequest)(nil),    // 26: messagequeue.PurgeDeadLettersRequest
+	(*PurgeDeadLettersResponse)(nil),   // 27: messagequeue.PurgeDeadLettersResponse
+	(*SubscribeRequest)(nil),           // 28: messagequeue.SubscribeRequest
+	(*SubscribeResponse)(nil),          // 29: messagequeue.SubscribeResponse
+	(*UnsubscribeRequest)(nil),         // 30: messagequeue.UnsubscribeRequest
+	(*UnsubscribeResponse)(nil),        // 31: messagequeue.UnsubscribeResponse
+	(*ListTopicsRequest)(nil),          // 32: messagequeue.ListTopicsRequest
+	(*TopicInfo)(nil),                  // 33: messagequeue.TopicInfo
+	(*ListTopicsResponse)(nil),         // 34: messagequeue.ListTopicsResponse
+	(*CreateLogRequest)(nil),           // 35: messagequeue.CreateLogRequest
+	(*CreateLogResponse)(nil),          // 36: messagequeue.CreateLogResponse
+	(*DeleteLogRequest)(nil),           // 37: messagequeue.DeleteLogRequest
+	(*DeleteLogResponse)(nil),          // 38: messagequeue.DeleteLogResponse
+	(*ListLogsRequest)(nil),            // 39: messagequeue.ListLogsRequest
+	(*ConsumerGroupInfo)(nil),          // 40: messagequeue.ConsumerGroupInfo
+	(*LogInfo)(nil),                    // 41: messagequeue.LogInfo
+	(*ListLogsResponse)(nil),           // 42: messagequeue.ListLogsResponse
+	(*FetchRequest)(nil),               // 43: messagequeue.FetchRequest
+	(*LogRecord)(nil),                  // 44: messagequeue.LogRecord
+	(*FetchResponse)(nil),              // 45: messagequeue.FetchResponse
+	(*CommitOffsetRequest)(nil),        // 46: messagequeue.CommitOffsetRequest
+	(*CommitOffsetResponse)(nil),       // 47: messagequeue.CommitOffsetResponse
+	(*RewindGroupRequest)(nil),         // 48: messagequeue.RewindGroupRequest
+	(*RewindGroupResponse)(nil),        // 49: messagequeue.RewindGroupResponse
+	(*SnapshotRequest)(nil),            // 50: messagequeue.SnapshotRequest
+	(*SnapshotResponse)(nil),           // 51: messagequeue.SnapshotResponse
+	(*RestoreRequest)(nil),             // 52: messagequeue.RestoreRequest
+	(*RestoreResponse)(nil),            // 53: messagequeue.RestoreResponse
+	nil,                                // 54: messagequeue.ProduceRequest.HeadersEntry
+	nil,                                // 55: messagequeue.StreamMessage.HeadersEntry
+	nil,                                // 56: messagequeue.Envelope.HeadersEntry
+	(*timestamppb.Timestamp)(nil),      // 57: google.protobuf.Timestamp
 }
 var file_messagequeue_proto_depIdxs = []int32{
-	0, // 0: messagequeue.MessageQueue.Produce:input_type -> messagequeue.ProduceRequest
-	2, // 1: messagequeue.MessageQueue.Consume:input_type -> messagequeue.ConsumeRequest
-	4, // 2: messagequeue.MessageQueue.StreamMessages:input_type -> messagequeue.StreamMessage
-	1, // 3: messagequeue.MessageQueue.Produce:output_type -> messagequeue.ProduceResponse
-	3, // 4: messagequeue.MessageQueue.Consume:output_type -> messagequeue.ConsumeResponse
-	4, // 5: messagequeue.MessageQueue.StreamMessages:output_type -> messagequeue.StreamMessage
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	54, // 0: messagequeue.ProduceRequest.headers:type_name -> messagequeue.ProduceRequest.HeadersEntry
+	57, // 1: messagequeue.ProduceRequest.deliver_at:type_name -> google.protobuf.Timestamp
+	11, // 2: messagequeue.ConsumeResponse.envelope:type_name -> messagequeue.Envelope
+	57, // 3: messagequeue.ConsumeResponse.lease_deadline:type_name -> google.protobuf.Timestamp
+	55, // 4: messagequeue.StreamMessage.headers:type_name -> messagequeue.StreamMessage.HeadersEntry
+	11, // 5: messagequeue.StreamMessage.envelope:type_name -> messagequeue.Envelope
+	10, // 6: messagequeue.StreamMessage.status:type_name -> messagequeue.ErrorStatus
+	56, // 7: messagequeue.Envelope.headers:type_name -> messagequeue.Envelope.HeadersEntry
+	57, // 8: messagequeue.Envelope.enqueued_at:type_name -> google.protobuf.Timestamp
+	57, // 9: messagequeue.Envelope.expires_at:type_name -> google.protobuf.Timestamp
+	18, // 10: messagequeue.QueueInfo.assignments:type_name -> messagequeue.PartitionAssignment
+	17, // 11: messagequeue.ListQueuesResponse.queues:type_name -> messagequeue.QueueInfo
+	57, // 12: messagequeue.DeliveryAttempt.delivered_at:type_name -> google.protobuf.Timestamp
+	11, // 13: messagequeue.DeadLetter.envelope:type_name -> messagequeue.Envelope
+	57, // 14: messagequeue.DeadLetter.dead_lettered_at:type_name -> google.protobuf.Timestamp
+	20, // 15: messagequeue.DeadLetter.attempts:type_name -> messagequeue.DeliveryAttempt
+	21, // 16: messagequeue.InspectDeadLettersResponse.messages:type_name -> messagequeue.DeadLetter
+	33, // 17: messagequeue.ListTopicsResponse.topics:type_name -> messagequeue.TopicInfo
+	40, // 18: messagequeue.LogInfo.groups:type_name -> messagequeue.ConsumerGroupInfo
+	41, // 19: messagequeue.ListLogsResponse.logs:type_name -> messagequeue.LogInfo
+	11, // 20: messagequeue.LogRecord.envelope:type_name -> messagequeue.Envelope
+	44, // 21: messagequeue.FetchResponse.records:type_name -> messagequeue.LogRecord
+	1,  // 22: messagequeue.MessageQueue.Produce:input_type -> messagequeue.ProduceRequest
+	3,  // 23: messagequeue.MessageQueue.Consume:input_type -> messagequeue.ConsumeRequest
+	5,  // 24: messagequeue.MessageQueue.Ack:input_type -> messagequeue.AckRequest
+	7,  // 25: messagequeue.MessageQueue.Nack:input_type -> messagequeue.NackRequest
+	9,  // 26: messagequeue.MessageQueue.StreamMessages:input_type -> messagequeue.StreamMessage
+	12, // 27: messagequeue.MessageQueue.CreateQueue:input_type -> messagequeue.CreateQueueRequest
+	14, // 28: messagequeue.MessageQueue.DeleteQueue:input_type -> messagequeue.DeleteQueueRequest
+	16, // 29: messagequeue.MessageQueue.ListQueues:input_type -> messagequeue.ListQueuesRequest
+	22, // 30: messagequeue.MessageQueue.InspectDeadLetters:input_type -> messagequeue.InspectDeadLettersRequest
+	24, // 31: messagequeue.MessageQueue.RequeueDeadLetters:input_type -> messagequeue.RequeueDeadLettersRequest
+	26, // 32: messagequeue.MessageQueue.PurgeDeadLetters:input_type -> messagequeue.PurgeDeadLettersRequest
+	28, // 33: messagequeue.MessageQueue.Subscribe:input_type -> messagequeue.SubscribeRequest
+	30, // 34: messagequeue.MessageQueue.Unsubscribe:input_type -> messagequeue.UnsubscribeRequest
+	32, // 35: messagequeue.MessageQueue.ListTopics:input_type -> messagequeue.ListTopicsRequest
+	35, // 36: messagequeue.MessageQueue.CreateLog:input_type -> messagequeue.CreateLogRequest
+	37, // 37: messagequeue.MessageQueue.DeleteLog:input_type -> messagequeue.DeleteLogRequest
+	39, // 38: messagequeue.MessageQueue.ListLogs:input_type -> messagequeue.ListLogsRequest
+	43, // 39: messagequeue.MessageQueue.Fetch:input_type -> messagequeue.FetchRequest
+	46, // 40: messagequeue.MessageQueue.CommitOffset:input_type -> messagequeue.CommitOffsetRequest
+	48, // 41: messagequeue.MessageQueue.RewindGroup:input_type -> messagequeue.RewindGroupRequest
+	50, // 42: messagequeue.MessageQueue.Snapshot:input_type -> messagequeue.SnapshotRequest
+	52, // 43: messagequeue.MessageQueue.Restore:input_type -> messagequeue.RestoreRequest
+	2,  // 44: messagequeue.MessageQueue.Produce:output_type -> messagequeue.ProduceResponse
+	4,  // 45: messagequeue.MessageQueue.Consume:output_type -> messagequeue.ConsumeResponse
+	6,  // 46: messagequeue.MessageQueue.Ack:output_type -> messagequeue.AckResponse
+	8,  // 47: messagequeue.MessageQueue.Nack:output_type -> messagequeue.NackResponse
+	9,  // 48: messagequeue.MessageQueue.StreamMessages:output_type -> messagequeue.StreamMessage
+	13, // 49: messagequeue.MessageQueue.CreateQueue:output_type -> messagequeue.CreateQueueResponse
+	15, // 50: messagequeue.MessageQueue.DeleteQueue:output_type -> messagequeue.DeleteQueueResponse
+	19, // 51: messagequeue.MessageQueue.ListQueues:output_type -> messagequeue.ListQueuesResponse
+	23, // 52: messagequeue.MessageQueue.InspectDeadLetters:output_type -> messagequeue.InspectDeadLettersResponse
+	25, // 53: messagequeue.MessageQueue.RequeueDeadLetters:output_type -> messagequeue.RequeueDeadLettersResponse
+	27, // 54: messagequeue.MessageQueue.PurgeDeadLetters:output_type -> messagequeue.PurgeDeadLettersResponse
+	29, // 55: messagequeue.MessageQueue.Subscribe:output_type -> messagequeue.SubscribeResponse
+	31, // 56: messagequeue.MessageQueue.Unsubscribe:output_type -> messagequeue.UnsubscribeResponse
+	34, // 57: messagequeue.MessageQueue.ListTopics:output_type -> messagequeue.ListTopicsResponse
+	36, // 58: messagequeue.MessageQueue.CreateLog:output_type -> messagequeue.CreateLogResponse
+	38, // 59: messagequeue.MessageQueue.DeleteLog:output_type -> messagequeue.DeleteLogResponse
+	42, // 60: messagequeue.MessageQueue.ListLogs:output_type -> messagequeue.ListLogsResponse
+	45, // 61: messagequeue.MessageQueue.Fetch:output_type -> messagequeue.FetchResponse
+	47, // 62: messagequeue.MessageQueue.CommitOffset:output_type -> messagequeue.CommitOffsetResponse
+	49, // 63: messagequeue.MessageQueue.RewindGroup:output_type -> messagequeue.RewindGroupResponse
+	51, // 64: messagequeue.MessageQueue.Snapshot:output_type -> messagequeue.SnapshotResponse
+	53, // 65: messagequeue.MessageQueue.Restore:output_type -> messagequeue.RestoreResponse
+	44, // [44:66] is the sub-list for method output_type
+	22, // [22:44] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_messagequeue_proto_init() }
@@ -348,18 +4009,18 @@ func file_messagequeue_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_messagequeue_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   5,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_messagequeue_proto_rawDesc), len(file_messagequeue_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   56,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_messagequeue_proto_goTypes,
 		DependencyIndexes: file_messagequeue_proto_depIdxs,
+		EnumInfos:         file_messagequeue_proto_enumTypes,
 		MessageInfos:      file_messagequeue_proto_msgTypes,
 	}.Build()
 	File_messagequeue_proto = out.File
-	file_messagequeue_proto_rawDesc = nil
 	file_messagequeue_proto_goTypes = nil
 	file_messagequeue_proto_depIdxs = nil
 }
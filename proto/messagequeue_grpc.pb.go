@@ -19,9 +19,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MessageQueue_Produce_FullMethodName        = "/messagequeue.MessageQueue/Produce"
-	MessageQueue_Consume_FullMethodName        = "/messagequeue.MessageQueue/Consume"
-	MessageQueue_StreamMessages_FullMethodName = "/messagequeue.MessageQueue/StreamMessages"
+	MessageQueue_Produce_FullMethodName            = "/messagequeue.MessageQueue/Produce"
+	MessageQueue_Consume_FullMethodName            = "/messagequeue.MessageQueue/Consume"
+	MessageQueue_Ack_FullMethodName                = "/messagequeue.MessageQueue/Ack"
+	MessageQueue_Nack_FullMethodName               = "/messagequeue.MessageQueue/Nack"
+	MessageQueue_StreamMessages_FullMethodName     = "/messagequeue.MessageQueue/StreamMessages"
+	MessageQueue_CreateQueue_FullMethodName        = "/messagequeue.MessageQueue/CreateQueue"
+	MessageQueue_DeleteQueue_FullMethodName        = "/messagequeue.MessageQueue/DeleteQueue"
+	MessageQueue_ListQueues_FullMethodName         = "/messagequeue.MessageQueue/ListQueues"
+	MessageQueue_InspectDeadLetters_FullMethodName = "/messagequeue.MessageQueue/InspectDeadLetters"
+	MessageQueue_RequeueDeadLetters_FullMethodName = "/messagequeue.MessageQueue/RequeueDeadLetters"
+	MessageQueue_PurgeDeadLetters_FullMethodName   = "/messagequeue.MessageQueue/PurgeDeadLetters"
+	MessageQueue_Subscribe_FullMethodName          = "/messagequeue.MessageQueue/Subscribe"
+	MessageQueue_Unsubscribe_FullMethodName        = "/messagequeue.MessageQueue/Unsubscribe"
+	MessageQueue_ListTopics_FullMethodName         = "/messagequeue.MessageQueue/ListTopics"
+	MessageQueue_CreateLog_FullMethodName          = "/messagequeue.MessageQueue/CreateLog"
+	MessageQueue_DeleteLog_FullMethodName          = "/messagequeue.MessageQueue/DeleteLog"
+	MessageQueue_ListLogs_FullMethodName           = "/messagequeue.MessageQueue/ListLogs"
+	MessageQueue_Fetch_FullMethodName              = "/messagequeue.MessageQueue/Fetch"
+	MessageQueue_CommitOffset_FullMethodName       = "/messagequeue.MessageQueue/CommitOffset"
+	MessageQueue_RewindGroup_FullMethodName        = "/messagequeue.MessageQueue/RewindGroup"
+	MessageQueue_Snapshot_FullMethodName           = "/messagequeue.MessageQueue/Snapshot"
+	MessageQueue_Restore_FullMethodName            = "/messagequeue.MessageQueue/Restore"
 )
 
 // MessageQueueClient is the client API for MessageQueue service.
@@ -29,13 +48,70 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
 // The MessageQueue service definition.
+//
+// Errors are reported according to the API version (ApiVersion) a client asks
+// for in the "quickpulse-api-version" request metadata of each call, and the
+// server names the version it used in the response header of the same name.
+//
+// Version 2 ("quickpulse-api-version: 2"): an RPC that fails returns a gRPC status
+// instead of a response. Its code tells clients what went wrong, such as
+// RESOURCE_EXHAUSTED for a full queue, NOT_FOUND for an empty queue or an unknown
+// name, and UNAVAILABLE while the server shuts down. It carries a
+// google.rpc.ErrorInfo whose reason (such as QUEUE_FULL) never changes, and a
+// google.rpc.RetryInfo when the same request may succeed later.
+//
+// Version 1, used when the metadata is missing: an RPC that fails returns an OK
+// status with a response whose success field is false and whose error field
+// describes the failure, as before versions existed. Rate limits and canceled
+// calls still fail with a status. The error fields are deprecated and will be
+// removed, their numbers reserved, together with version 1.
 type MessageQueueClient interface {
 	// Produce a message to the queue.
 	Produce(ctx context.Context, in *ProduceRequest, opts ...grpc.CallOption) (*ProduceResponse, error)
-	// Consume a message from the queue.
+	// Consume a message from the queue. Partitioned queues are only consumed by
+	// members (StreamMessages), so Consume fails with FAILED_PRECONDITION on them.
 	Consume(ctx context.Context, in *ConsumeRequest, opts ...grpc.CallOption) (*ConsumeResponse, error)
+	// Acknowledge a leased message, deleting it.
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error)
+	// Reject a leased message, redelivering it.
+	Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error)
 	// Bidirectional streaming for messages.
 	StreamMessages(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamMessage, StreamMessage], error)
+	// Create a new named queue.
+	CreateQueue(ctx context.Context, in *CreateQueueRequest, opts ...grpc.CallOption) (*CreateQueueResponse, error)
+	// Delete a named queue and discard its messages.
+	DeleteQueue(ctx context.Context, in *DeleteQueueRequest, opts ...grpc.CallOption) (*DeleteQueueResponse, error)
+	// List all named queues.
+	ListQueues(ctx context.Context, in *ListQueuesRequest, opts ...grpc.CallOption) (*ListQueuesResponse, error)
+	// List messages in a queue's dead-letter queue without removing them.
+	InspectDeadLetters(ctx context.Context, in *InspectDeadLettersRequest, opts ...grpc.CallOption) (*InspectDeadLettersResponse, error)
+	// Move messages from a queue's dead-letter queue back to the queues they came from.
+	RequeueDeadLetters(ctx context.Context, in *RequeueDeadLettersRequest, opts ...grpc.CallOption) (*RequeueDeadLettersResponse, error)
+	// Discard all messages in a queue's dead-letter queue.
+	PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error)
+	// Attach a subscription to a topic; messages produced to the topic are copied to it.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error)
+	// Detach a subscription from its topic and discard its messages.
+	Unsubscribe(ctx context.Context, in *UnsubscribeRequest, opts ...grpc.CallOption) (*UnsubscribeResponse, error)
+	// List all topics and their subscriptions.
+	ListTopics(ctx context.Context, in *ListTopicsRequest, opts ...grpc.CallOption) (*ListTopicsResponse, error)
+	// Create a new log, an append-only stream read by consumer groups.
+	CreateLog(ctx context.Context, in *CreateLogRequest, opts ...grpc.CallOption) (*CreateLogResponse, error)
+	// Delete a log with its messages and consumer groups.
+	DeleteLog(ctx context.Context, in *DeleteLogRequest, opts ...grpc.CallOption) (*DeleteLogResponse, error)
+	// List all logs with their consumer groups.
+	ListLogs(ctx context.Context, in *ListLogsRequest, opts ...grpc.CallOption) (*ListLogsResponse, error)
+	// Read the next messages of a log for a consumer group.
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	// Commit the offset up to which a consumer group has processed a log.
+	CommitOffset(ctx context.Context, in *CommitOffsetRequest, opts ...grpc.CallOption) (*CommitOffsetResponse, error)
+	// Move a consumer group back to its committed offset, so messages fetched but not committed
+	// are fetched again; members call it when they (re)connect.
+	RewindGroup(ctx context.Context, in *RewindGroupRequest, opts ...grpc.CallOption) (*RewindGroupResponse, error)
+	// Write every queue with its messages to the server's snapshot file.
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error)
+	// Load the server's snapshot file, recreating its queues and messages.
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
 }
 
 type messageQueueClient struct {
@@ -66,6 +142,26 @@ func (c *messageQueueClient) Consume(ctx context.Context, in *ConsumeRequest, op
 	return out, nil
 }
 
+func (c *messageQueueClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AckResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Ack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NackResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Nack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *messageQueueClient) StreamMessages(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamMessage, StreamMessage], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &MessageQueue_ServiceDesc.Streams[0], MessageQueue_StreamMessages_FullMethodName, cOpts...)
@@ -79,18 +175,245 @@ func (c *messageQueueClient) StreamMessages(ctx context.Context, opts ...grpc.Ca
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MessageQueue_StreamMessagesClient = grpc.BidiStreamingClient[StreamMessage, StreamMessage]
 
+func (c *messageQueueClient) CreateQueue(ctx context.Context, in *CreateQueueRequest, opts ...grpc.CallOption) (*CreateQueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateQueueResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_CreateQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) DeleteQueue(ctx context.Context, in *DeleteQueueRequest, opts ...grpc.CallOption) (*DeleteQueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQueueResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_DeleteQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) ListQueues(ctx context.Context, in *ListQueuesRequest, opts ...grpc.CallOption) (*ListQueuesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQueuesResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_ListQueues_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) InspectDeadLetters(ctx context.Context, in *InspectDeadLettersRequest, opts ...grpc.CallOption) (*InspectDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectDeadLettersResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_InspectDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) RequeueDeadLetters(ctx context.Context, in *RequeueDeadLettersRequest, opts ...grpc.CallOption) (*RequeueDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequeueDeadLettersResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_RequeueDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeDeadLettersResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_PurgeDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscribeResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Subscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Unsubscribe(ctx context.Context, in *UnsubscribeRequest, opts ...grpc.CallOption) (*UnsubscribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribeResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Unsubscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) ListTopics(ctx context.Context, in *ListTopicsRequest, opts ...grpc.CallOption) (*ListTopicsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTopicsResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_ListTopics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) CreateLog(ctx context.Context, in *CreateLogRequest, opts ...grpc.CallOption) (*CreateLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLogResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_CreateLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) DeleteLog(ctx context.Context, in *DeleteLogRequest, opts ...grpc.CallOption) (*DeleteLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLogResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_DeleteLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) ListLogs(ctx context.Context, in *ListLogsRequest, opts ...grpc.CallOption) (*ListLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLogsResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_ListLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Fetch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) CommitOffset(ctx context.Context, in *CommitOffsetRequest, opts ...grpc.CallOption) (*CommitOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitOffsetResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_CommitOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) RewindGroup(ctx context.Context, in *RewindGroupRequest, opts ...grpc.CallOption) (*RewindGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RewindGroupResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_RewindGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Snapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageQueueClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, MessageQueue_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageQueueServer is the server API for MessageQueue service.
 // All implementations must embed UnimplementedMessageQueueServer
 // for forward compatibility.
 //
 // The MessageQueue service definition.
+//
+// Errors are reported according to the API version (ApiVersion) a client asks
+// for in the "quickpulse-api-version" request metadata of each call, and the
+// server names the version it used in the response header of the same name.
+//
+// Version 2 ("quickpulse-api-version: 2"): an RPC that fails returns a gRPC status
+// instead of a response. Its code tells clients what went wrong, such as
+// RESOURCE_EXHAUSTED for a full queue, NOT_FOUND for an empty queue or an unknown
+// name, and UNAVAILABLE while the server shuts down. It carries a
+// google.rpc.ErrorInfo whose reason (such as QUEUE_FULL) never changes, and a
+// google.rpc.RetryInfo when the same request may succeed later.
+//
+// Version 1, used when the metadata is missing: an RPC that fails returns an OK
+// status with a response whose success field is false and whose error field
+// describes the failure, as before versions existed. Rate limits and canceled
+// calls still fail with a status. The error fields are deprecated and will be
+// removed, their numbers reserved, together with version 1.
 type MessageQueueServer interface {
 	// Produce a message to the queue.
 	Produce(context.Context, *ProduceRequest) (*ProduceResponse, error)
-	// Consume a message from the queue.
+	// Consume a message from the queue. Partitioned queues are only consumed by
+	// members (StreamMessages), so Consume fails with FAILED_PRECONDITION on them.
 	Consume(context.Context, *ConsumeRequest) (*ConsumeResponse, error)
+	// Acknowledge a leased message, deleting it.
+	Ack(context.Context, *AckRequest) (*AckResponse, error)
+	// Reject a leased message, redelivering it.
+	Nack(context.Context, *NackRequest) (*NackResponse, error)
 	// Bidirectional streaming for messages.
 	StreamMessages(grpc.BidiStreamingServer[StreamMessage, StreamMessage]) error
+	// Create a new named queue.
+	CreateQueue(context.Context, *CreateQueueRequest) (*CreateQueueResponse, error)
+	// Delete a named queue and discard its messages.
+	DeleteQueue(context.Context, *DeleteQueueRequest) (*DeleteQueueResponse, error)
+	// List all named queues.
+	ListQueues(context.Context, *ListQueuesRequest) (*ListQueuesResponse, error)
+	// List messages in a queue's dead-letter queue without removing them.
+	InspectDeadLetters(context.Context, *InspectDeadLettersRequest) (*InspectDeadLettersResponse, error)
+	// Move messages from a queue's dead-letter queue back to the queues they came from.
+	RequeueDeadLetters(context.Context, *RequeueDeadLettersRequest) (*RequeueDeadLettersResponse, error)
+	// Discard all messages in a queue's dead-letter queue.
+	PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersResponse, error)
+	// Attach a subscription to a topic; messages produced to the topic are copied to it.
+	Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error)
+	// Detach a subscription from its topic and discard its messages.
+	Unsubscribe(context.Context, *UnsubscribeRequest) (*UnsubscribeResponse, error)
+	// List all topics and their subscriptions.
+	ListTopics(context.Context, *ListTopicsRequest) (*ListTopicsResponse, error)
+	// Create a new log, an append-only stream read by consumer groups.
+	CreateLog(context.Context, *CreateLogRequest) (*CreateLogResponse, error)
+	// Delete a log with its messages and consumer groups.
+	DeleteLog(context.Context, *DeleteLogRequest) (*DeleteLogResponse, error)
+	// List all logs with their consumer groups.
+	ListLogs(context.Context, *ListLogsRequest) (*ListLogsResponse, error)
+	// Read the next messages of a log for a consumer group.
+	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	// Commit the offset up to which a consumer group has processed a log.
+	CommitOffset(context.Context, *CommitOffsetRequest) (*CommitOffsetResponse, error)
+	// Move a consumer group back to its committed offset, so messages fetched but not committed
+	// are fetched again; members call it when they (re)connect.
+	RewindGroup(context.Context, *RewindGroupRequest) (*RewindGroupResponse, error)
+	// Write every queue with its messages to the server's snapshot file.
+	Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error)
+	// Load the server's snapshot file, recreating its queues and messages.
+	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
 	mustEmbedUnimplementedMessageQueueServer()
 }
 
@@ -107,9 +430,66 @@ func (UnimplementedMessageQueueServer) Produce(context.Context, *ProduceRequest)
 func (UnimplementedMessageQueueServer) Consume(context.Context, *ConsumeRequest) (*ConsumeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Consume not implemented")
 }
+func (UnimplementedMessageQueueServer) Ack(context.Context, *AckRequest) (*AckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedMessageQueueServer) Nack(context.Context, *NackRequest) (*NackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Nack not implemented")
+}
 func (UnimplementedMessageQueueServer) StreamMessages(grpc.BidiStreamingServer[StreamMessage, StreamMessage]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamMessages not implemented")
 }
+func (UnimplementedMessageQueueServer) CreateQueue(context.Context, *CreateQueueRequest) (*CreateQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQueue not implemented")
+}
+func (UnimplementedMessageQueueServer) DeleteQueue(context.Context, *DeleteQueueRequest) (*DeleteQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQueue not implemented")
+}
+func (UnimplementedMessageQueueServer) ListQueues(context.Context, *ListQueuesRequest) (*ListQueuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQueues not implemented")
+}
+func (UnimplementedMessageQueueServer) InspectDeadLetters(context.Context, *InspectDeadLettersRequest) (*InspectDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectDeadLetters not implemented")
+}
+func (UnimplementedMessageQueueServer) RequeueDeadLetters(context.Context, *RequeueDeadLettersRequest) (*RequeueDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueDeadLetters not implemented")
+}
+func (UnimplementedMessageQueueServer) PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeadLetters not implemented")
+}
+func (UnimplementedMessageQueueServer) Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedMessageQueueServer) Unsubscribe(context.Context, *UnsubscribeRequest) (*UnsubscribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unsubscribe not implemented")
+}
+func (UnimplementedMessageQueueServer) ListTopics(context.Context, *ListTopicsRequest) (*ListTopicsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTopics not implemented")
+}
+func (UnimplementedMessageQueueServer) CreateLog(context.Context, *CreateLogRequest) (*CreateLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateLog not implemented")
+}
+func (UnimplementedMessageQueueServer) DeleteLog(context.Context, *DeleteLogRequest) (*DeleteLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLog not implemented")
+}
+func (UnimplementedMessageQueueServer) ListLogs(context.Context, *ListLogsRequest) (*ListLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLogs not implemented")
+}
+func (UnimplementedMessageQueueServer) Fetch(context.Context, *FetchRequest) (*FetchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fetch not implemented")
+}
+func (UnimplementedMessageQueueServer) CommitOffset(context.Context, *CommitOffsetRequest) (*CommitOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitOffset not implemented")
+}
+func (UnimplementedMessageQueueServer) RewindGroup(context.Context, *RewindGroupRequest) (*RewindGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RewindGroup not implemented")
+}
+func (UnimplementedMessageQueueServer) Snapshot(context.Context, *SnapshotRequest) (*SnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (UnimplementedMessageQueueServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
 func (UnimplementedMessageQueueServer) mustEmbedUnimplementedMessageQueueServer() {}
 func (UnimplementedMessageQueueServer) testEmbeddedByValue()                      {}
 
@@ -167,6 +547,42 @@ func _MessageQueue_Consume_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageQueue_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Nack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Nack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Nack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Nack(ctx, req.(*NackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MessageQueue_StreamMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(MessageQueueServer).StreamMessages(&grpc.GenericServerStream[StreamMessage, StreamMessage]{ServerStream: stream})
 }
@@ -174,6 +590,312 @@ func _MessageQueue_StreamMessages_Handler(srv interface{}, stream grpc.ServerStr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type MessageQueue_StreamMessagesServer = grpc.BidiStreamingServer[StreamMessage, StreamMessage]
 
+func _MessageQueue_CreateQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).CreateQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_CreateQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).CreateQueue(ctx, req.(*CreateQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_DeleteQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).DeleteQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_DeleteQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).DeleteQueue(ctx, req.(*DeleteQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_ListQueues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQueuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).ListQueues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_ListQueues_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).ListQueues(ctx, req.(*ListQueuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_InspectDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).InspectDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_InspectDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).InspectDeadLetters(ctx, req.(*InspectDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_RequeueDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).RequeueDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_RequeueDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).RequeueDeadLetters(ctx, req.(*RequeueDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_PurgeDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).PurgeDeadLetters(ctx, req.(*PurgeDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Subscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Subscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Subscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Subscribe(ctx, req.(*SubscribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Unsubscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Unsubscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Unsubscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Unsubscribe(ctx, req.(*UnsubscribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_ListTopics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTopicsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).ListTopics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_ListTopics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).ListTopics(ctx, req.(*ListTopicsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_CreateLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).CreateLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_CreateLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).CreateLog(ctx, req.(*CreateLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_DeleteLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).DeleteLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_DeleteLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).DeleteLog(ctx, req.(*DeleteLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_ListLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).ListLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_ListLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).ListLogs(ctx, req.(*ListLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Fetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_CommitOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).CommitOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_CommitOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).CommitOffset(ctx, req.(*CommitOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_RewindGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RewindGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).RewindGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_RewindGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).RewindGroup(ctx, req.(*RewindGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Snapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageQueue_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageQueueServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageQueue_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageQueueServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MessageQueue_ServiceDesc is the grpc.ServiceDesc for MessageQueue service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -189,6 +911,82 @@ var MessageQueue_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Consume",
 			Handler:    _MessageQueue_Consume_Handler,
 		},
+		{
+			MethodName: "Ack",
+			Handler:    _MessageQueue_Ack_Handler,
+		},
+		{
+			MethodName: "Nack",
+			Handler:    _MessageQueue_Nack_Handler,
+		},
+		{
+			MethodName: "CreateQueue",
+			Handler:    _MessageQueue_CreateQueue_Handler,
+		},
+		{
+			MethodName: "DeleteQueue",
+			Handler:    _MessageQueue_DeleteQueue_Handler,
+		},
+		{
+			MethodName: "ListQueues",
+			Handler:    _MessageQueue_ListQueues_Handler,
+		},
+		{
+			MethodName: "InspectDeadLetters",
+			Handler:    _MessageQueue_InspectDeadLetters_Handler,
+		},
+		{
+			MethodName: "RequeueDeadLetters",
+			Handler:    _MessageQueue_RequeueDeadLetters_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _MessageQueue_PurgeDeadLetters_Handler,
+		},
+		{
+			MethodName: "Subscribe",
+			Handler:    _MessageQueue_Subscribe_Handler,
+		},
+		{
+			MethodName: "Unsubscribe",
+			Handler:    _MessageQueue_Unsubscribe_Handler,
+		},
+		{
+			MethodName: "ListTopics",
+			Handler:    _MessageQueue_ListTopics_Handler,
+		},
+		{
+			MethodName: "CreateLog",
+			Handler:    _MessageQueue_CreateLog_Handler,
+		},
+		{
+			MethodName: "DeleteLog",
+			Handler:    _MessageQueue_DeleteLog_Handler,
+		},
+		{
+			MethodName: "ListLogs",
+			Handler:    _MessageQueue_ListLogs_Handler,
+		},
+		{
+			MethodName: "Fetch",
+			Handler:    _MessageQueue_Fetch_Handler,
+		},
+		{
+			MethodName: "CommitOffset",
+			Handler:    _MessageQueue_CommitOffset_Handler,
+		},
+		{
+			MethodName: "RewindGroup",
+			Handler:    _MessageQueue_RewindGroup_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _MessageQueue_Snapshot_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _MessageQueue_Restore_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
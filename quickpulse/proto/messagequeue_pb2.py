@@ -1,291 +1,33 @@
 # -*- coding: utf-8 -*-
 # Generated by the protocol buffer compiler.  DO NOT EDIT!
+# NO CHECKED-IN PROTOBUF GENCODE
 # source: messagequeue.proto
+# Protobuf Python Version: 5.29.3
 """Generated protocol buffer code."""
 from google.protobuf import descriptor as _descriptor
-from google.protobuf import message as _message
-from google.protobuf import reflection as _reflection
+from google.protobuf import descriptor_pool as _descriptor_pool
+from google.protobuf import runtime_version as _runtime_version
 from google.protobuf import symbol_database as _symbol_database
+from google.protobuf.internal import builder as _builder
+_runtime_version.ValidateProtobufRuntimeVersion(
+    _runtime_version.Domain.PUBLIC,
+    5,
+    29,
+    3,
+    '',
+    'messagequeue.proto'
+)
 # @@protoc_insertion_point(imports)
 
 _sym_db = _symbol_database.Default()
 
 
+from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
 
 
-DESCRIPTOR = _descriptor.FileDescriptor(
-  name='messagequeue.proto',
-  package='messagequeue',
-  syntax='proto3',
-  serialized_options=b'Z\026quickpulse/proto;proto',
-  create_key=_descriptor._internal_create_key,
-  serialized_pb=b'\n\x12messagequeue.proto\x12\x0cmessagequeue\"!\n\x0eProduceRequest\x12\x0f\n\x07payload\x18\x01 \x01(\x0c\"1\n\x0fProduceResponse\x12\x0f\n\x07success\x18\x01 \x01(\x08\x12\r\n\x05\x65rror\x18\x02 \x01(\t\"\x10\n\x0e\x43onsumeRequest\"1\n\x0f\x43onsumeResponse\x12\x0f\n\x07payload\x18\x01 \x01(\x0c\x12\r\n\x05\x65rror\x18\x02 \x01(\t\"/\n\rStreamMessage\x12\x0f\n\x07payload\x18\x01 \x01(\x0c\x12\r\n\x05\x65rror\x18\x02 \x01(\t2\xee\x01\n\x0cMessageQueue\x12\x46\n\x07Produce\x12\x1c.messagequeue.ProduceRequest\x1a\x1d.messagequeue.ProduceResponse\x12\x46\n\x07\x43onsume\x12\x1c.messagequeue.ConsumeRequest\x1a\x1d.messagequeue.ConsumeResponse\x12N\n\x0eStreamMessages\x12\x1b.messagequeue.StreamMessage\x1a\x1b.messagequeue.StreamMessage(\x01\x30\x01\x42\x18Z\x16quickpulse/proto;protob\x06proto3'
-)
-
-
-
-
-_PRODUCEREQUEST = _descriptor.Descriptor(
-  name='ProduceRequest',
-  full_name='messagequeue.ProduceRequest',
-  filename=None,
-  file=DESCRIPTOR,
-  containing_type=None,
-  create_key=_descriptor._internal_create_key,
-  fields=[
-    _descriptor.FieldDescriptor(
-      name='payload', full_name='messagequeue.ProduceRequest.payload', index=0,
-      number=1, type=12, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"",
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-  ],
-  extensions=[
-  ],
-  nested_types=[],
-  enum_types=[
-  ],
-  serialized_options=None,
-  is_extendable=False,
-  syntax='proto3',
-  extension_ranges=[],
-  oneofs=[
-  ],
-  serialized_start=36,
-  serialized_end=69,
-)
-
-
-_PRODUCERESPONSE = _descriptor.Descriptor(
-  name='ProduceResponse',
-  full_name='messagequeue.ProduceResponse',
-  filename=None,
-  file=DESCRIPTOR,
-  containing_type=None,
-  create_key=_descriptor._internal_create_key,
-  fields=[
-    _descriptor.FieldDescriptor(
-      name='success', full_name='messagequeue.ProduceResponse.success', index=0,
-      number=1, type=8, cpp_type=7, label=1,
-      has_default_value=False, default_value=False,
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-    _descriptor.FieldDescriptor(
-      name='error', full_name='messagequeue.ProduceResponse.error', index=1,
-      number=2, type=9, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"".decode('utf-8'),
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-  ],
-  extensions=[
-  ],
-  nested_types=[],
-  enum_types=[
-  ],
-  serialized_options=None,
-  is_extendable=False,
-  syntax='proto3',
-  extension_ranges=[],
-  oneofs=[
-  ],
-  serialized_start=71,
-  serialized_end=120,
-)
-
-
-_CONSUMEREQUEST = _descriptor.Descriptor(
-  name='ConsumeRequest',
-  full_name='messagequeue.ConsumeRequest',
-  filename=None,
-  file=DESCRIPTOR,
-  containing_type=None,
-  create_key=_descriptor._internal_create_key,
-  fields=[
-  ],
-  extensions=[
-  ],
-  nested_types=[],
-  enum_types=[
-  ],
-  serialized_options=None,
-  is_extendable=False,
-  syntax='proto3',
-  extension_ranges=[],
-  oneofs=[
-  ],
-  serialized_start=122,
-  serialized_end=138,
-)
-
-
-_CONSUMERESPONSE = _descriptor.Descriptor(
-  name='ConsumeResponse',
-  full_name='messagequeue.ConsumeResponse',
-  filename=None,
-  file=DESCRIPTOR,
-  containing_type=None,
-  create_key=_descriptor._internal_create_key,
-  fields=[
-    _descriptor.FieldDescriptor(
-      name='payload', full_name='messagequeue.ConsumeResponse.payload', index=0,
-      number=1, type=12, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"",
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-    _descriptor.FieldDescriptor(
-      name='error', full_name='messagequeue.ConsumeResponse.error', index=1,
-      number=2, type=9, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"".decode('utf-8'),
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-  ],
-  extensions=[
-  ],
-  nested_types=[],
-  enum_types=[
-  ],
-  serialized_options=None,
-  is_extendable=False,
-  syntax='proto3',
-  extension_ranges=[],
-  oneofs=[
-  ],
-  serialized_start=140,
-  serialized_end=189,
-)
-
-
-_STREAMMESSAGE = _descriptor.Descriptor(
-  name='StreamMessage',
-  full_name='messagequeue.StreamMessage',
-  filename=None,
-  file=DESCRIPTOR,
-  containing_type=None,
-  create_key=_descriptor._internal_create_key,
-  fields=[
-    _descriptor.FieldDescriptor(
-      name='payload', full_name='messagequeue.StreamMessage.payload', index=0,
-      number=1, type=12, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"",
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-    _descriptor.FieldDescriptor(
-      name='error', full_name='messagequeue.StreamMessage.error', index=1,
-      number=2, type=9, cpp_type=9, label=1,
-      has_default_value=False, default_value=b"".decode('utf-8'),
-      message_type=None, enum_type=None, containing_type=None,
-      is_extension=False, extension_scope=None,
-      serialized_options=None, file=DESCRIPTOR,  create_key=_descriptor._internal_create_key),
-  ],
-  extensions=[
-  ],
-  nested_types=[],
-  enum_types=[
-  ],
-  serialized_options=None,
-  is_extendable=False,
-  syntax='proto3',
-  extension_ranges=[],
-  oneofs=[
-  ],
-  serialized_start=191,
-  serialized_end=238,
-)
-
-DESCRIPTOR.message_types_by_name['ProduceRequest'] = _PRODUCEREQUEST
-DESCRIPTOR.message_types_by_name['ProduceResponse'] = _PRODUCERESPONSE
-DESCRIPTOR.message_types_by_name['ConsumeRequest'] = _CONSUMEREQUEST
-DESCRIPTOR.message_types_by_name['ConsumeResponse'] = _CONSUMERESPONSE
-DESCRIPTOR.message_types_by_name['StreamMessage'] = _STREAMMESSAGE
-_sym_db.RegisterFileDescriptor(DESCRIPTOR)
-
-ProduceRequest = _reflection.GeneratedProtocolMessageType('ProduceRequest', (_message.Message,), {
-  'DESCRIPTOR' : _PRODUCEREQUEST,
-  '__module__' : 'messagequeue_pb2'
-  # @@protoc_insertion_point(class_scope:messagequeue.ProduceRequest)
-  })
-_sym_db.RegisterMessage(ProduceRequest)
-
-ProduceResponse = _reflection.GeneratedProtocolMessageType('ProduceResponse', (_message.Message,), {
-  'DESCRIPTOR' : _PRODUCERESPONSE,
-  '__module__' : 'messagequeue_pb2'
-  # @@protoc_insertion_point(class_scope:messagequeue.ProduceResponse)
-  })
-_sym_db.RegisterMessage(ProduceResponse)
-
-ConsumeRequest = _reflection.GeneratedProtocolMessageType('ConsumeRequest', (_message.Message,), {
-  'DESCRIPTOR' : _CONSUMEREQUEST,
-  '__module__' : 'messagequeue_pb2'
-  # @@protoc_insertion_point(class_scope:messagequeue.ConsumeRequest)
-  })
-_sym_db.RegisterMessage(ConsumeRequest)
-
-ConsumeResponse = _reflection.GeneratedProtocolMessageType('ConsumeResponse', (_message.Message,), {
-  'DESCRIPTOR' : _CONSUMERESPONSE,
-  '__module__' : 'messagequeue_pb2'
-  # @@protoc_insertion_point(class_scope:messagequeue.ConsumeResponse)
-  })
-_sym_db.RegisterMessage(ConsumeResponse)
-
-StreamMessage = _reflection.GeneratedProtocolMessageType('StreamMessage', (_message.Message,), {
-  'DESCRIPTOR' : _STREAMMESSAGE,
-  '__module__' : 'messagequeue_pb2'
-  # @@protoc_insertion_point(class_scope:messagequeue.StreamMessage)
-  })
-_sym_db.RegisterMessage(StreamMessage)
-
-
-DESCRIPTOR._options = None
-
-_MESSAGEQUEUE = _descriptor.ServiceDescriptor(
-  name='MessageQueue',
-  full_name='messagequeue.MessageQueue',
-  file=DESCRIPTOR,
-  index=0,
-  serialized_options=None,
-  create_key=_descriptor._internal_create_key,
-  serialized_start=241,
-  serialized_end=479,
-  methods=[
-  _descriptor.MethodDescriptor(
-    name='Produce',
-    full_name='messagequeue.MessageQueue.Produce',
-    index=0,
-    containing_service=None,
-    input_type=_PRODUCEREQUEST,
-    output_type=_PRODUCERESPONSE,
-    serialized_options=None,
-    create_key=_descriptor._internal_create_key,
-  ),
-  _descriptor.MethodDescriptor(
-    name='Consume',
-    full_name='messagequeue.MessageQueue.Consume',
-    index=1,
-    containing_service=None,
-    input_type=_CONSUMEREQUEST,
-    output_type=_CONSUMERESPONSE,
-    serialized_options=None,
-    create_key=_descriptor._internal_create_key,
-  ),
-  _descriptor.MethodDescriptor(
-    name='StreamMessages',
-    full_name='messagequeue.MessageQueue.StreamMessages',
-    index=2,
-    containing_service=None,
-    input_type=_STREAMMESSAGE,
-    output_type=_STREAMMESSAGE,
-    serialized_options=None,
-    create_key=_descriptor._internal_create_key,
-  ),
-])
-_sym_db.RegisterServiceDescriptor(_MESSAGEQUEUE)
-
-DESCRIPTOR.services_by_name['MessageQueue'] = _MESSAGEQUEUE
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\x0a\x12messagequeue.proto\x12\x0cmessagequeue\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcd\x03\x0a\x0eProduceRequest\x12\x18\x0a\x07payload\x18\x01 \x01(\x0cR\x07payload\x12\x14\x0a\x05queue\x18\x02 \x01(\x09R\x05queue\x12C\x0a\x07headers\x18\x03 \x03(\x0b2).messagequeue.ProduceRequest.HeadersEntryR\x07headers\x12!\x0a\x0ccontent_type\x18\x04 \x01(\x09R\x0bcontentType\x12\x19\x0a\x08delay_ms\x18\x05 \x01(\x03R\x07delayMs\x129\x0a\x0adeliver_at\x18\x06 \x01(\x0b2\x1a.google.protobuf.TimestampR\x09deliverAt\x12\x15\x0a\x06ttl_ms\x18\x07 \x01(\x03R\x05ttlMs\x12\x1a\x0a\x08priority\x18\x08 \x01(\x0dR\x08priority\x12\x14\x0a\x05topic\x18\x09 \x01(\x09R\x05topic\x12\x10\x0a\x03log\x18\x0a \x01(\x09R\x03log\x12\x10\x0a\x03key\x18\x0b \x01(\x09R\x03key\x12$\x0a\x0dsubscriptions\x18\x0c \x03(\x09R\x0dsubscriptions\x1a:\x0a\x0cHeadersEntry\x12\x10\x0a\x03key\x18\x01 \x01(\x09R\x03key\x12\x14\x0a\x05value\x18\x02 \x01(\x09R\x05value:\x028\x01\"\xd5\x01\x0a\x0fProduceResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x1d\x0a\x0amessage_id\x18\x03 \x01(\x09R\x09messageId\x12\x1e\x0a\x0adeliveries\x18\x04 \x01(\x0dR\x0adeliveries\x12\x16\x0a\x06offset\x18\x05 \x01(\x04R\x06offset\x127\x0a\x17delivered_subscriptions\x18\x06 \x03(\x09R\x16deliveredSubscriptions\"\x98\x01\x0a\x0eConsumeRequest\x12&\x0a\x0fwait_timeout_ms\x18\x01 \x01(\x03R\x0dwaitTimeoutMs\x12\x14\x0a\x05queue\x18\x02 \x01(\x09R\x05queue\x12\x14\x0a\x05lease\x18\x03 \x01(\x08R\x05lease\x122\x0a\x15visibility_timeout_ms\x18\x04 \x01(\x03R\x13visibilityTimeoutMs\"\xbc\x01\x0a\x0fConsumeResponse\x12\x18\x0a\x07payload\x18\x01 \x01(\x0cR\x07payload\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x122\x0a\x08envelope\x18\x03 \x01(\x0b2\x16.messagequeue.EnvelopeR\x08envelope\x12A\x0a\x0elease_deadline\x18\x04 \x01(\x0b2\x1a.google.protobuf.TimestampR\x0dleaseDeadline\"A\x0a\x0aAckRequest\x12\x14\x0a\x05queue\x18\x01 \x01(\x09R\x05queue\x12\x1d\x0a\x0amessage_id\x18\x02 \x01(\x09R\x09messageId\"A\x0a\x0bAckResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"B\x0a\x0bNackRequest\x12\x14\x0a\x05queue\x18\x01 \x01(\x09R\x05queue\x12\x1d\x0a\x0amessage_id\x18\x02 \x01(\x09R\x09messageId\"B\x0a\x0cNackResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"\x91\x03\x0a\x0dStreamMessage\x12\x18\x0a\x07payload\x18\x01 \x01(\x0cR\x07payload\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x14\x0a\x05queue\x18\x03 \x01(\x09R\x05queue\x12B\x0a\x07headers\x18\x04 \x03(\x0b2(.messagequeue.StreamMessage.HeadersEntryR\x07headers\x12!\x0a\x0ccontent_type\x18\x05 \x01(\x09R\x0bcontentType\x122\x0a\x08envelope\x18\x06 \x01(\x0b2\x16.messagequeue.EnvelopeR\x08envelope\x12\x1a\x0a\x08priority\x18\x07 \x01(\x0dR\x08priority\x12\x10\x0a\x03key\x18\x08 \x01(\x09R\x03key\x121\x0a\x06status\x18\x09 \x01(\x0b2\x19.messagequeue.ErrorStatusR\x06status\x1a:\x0a\x0cHeadersEntry\x12\x10\x0a\x03key\x18\x01 \x01(\x09R\x03key\x12\x14\x0a\x05value\x18\x02 \x01(\x09R\x05value:\x028\x01\"y\x0a\x0bErrorStatus\x12\x12\x0a\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\x0a\x07message\x18\x02 \x01(\x09R\x07message\x12\x16\x0a\x06reason\x18\x03 \x01(\x09R\x06reason\x12$\x0a\x0eretry_after_ms\x18\x04 \x01(\x03R\x0cretryAfterMs\"\x89\x03\x0a\x08Envelope\x12\x0e\x0a\x02id\x18\x01 \x01(\x09R\x02id\x12=\x0a\x07headers\x18\x02 \x03(\x0b2#.messagequeue.Envelope.HeadersEntryR\x07headers\x12!\x0a\x0ccontent_type\x18\x03 \x01(\x09R\x0bcontentType\x12;\x0a\x0benqueued_at\x18\x04 \x01(\x0b2\x1a.google.protobuf.TimestampR\x0aenqueuedAt\x12)\x0a\x10delivery_attempt\x18\x05 \x01(\x0dR\x0fdeliveryAttempt\x129\x0a\x0aexpires_at\x18\x06 \x01(\x0b2\x1a.google.protobuf.TimestampR\x09expiresAt\x12\x1a\x0a\x08priority\x18\x07 \x01(\x0dR\x08priority\x12\x10\x0a\x03key\x18\x08 \x01(\x09R\x03key\x1a:\x0a\x0cHeadersEntry\x12\x10\x0a\x03key\x18\x01 \x01(\x09R\x03key\x12\x14\x0a\x05value\x18\x02 \x01(\x09R\x05value:\x028\x01\"\xee\x03\x0a\x12CreateQueueRequest\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12\x1a\x0a\x08capacity\x18\x02 \x01(\x04R\x08capacity\x122\x0a\x15visibility_timeout_ms\x18\x03 \x01(\x03R\x13visibilityTimeoutMs\x122\x0a\x15max_delivery_attempts\x18\x04 \x01(\x0dR\x13maxDeliveryAttempts\x12*\x0a\x11dead_letter_queue\x18\x05 \x01(\x09R\x0fdeadLetterQueue\x12\x1c\x0a\x0amax_age_ms\x18\x06 \x01(\x03R\x08maxAgeMs\x12\'\x0a\x0foverflow_policy\x18\x07 \x01(\x09R\x0eoverflowPolicy\x12(\x0a\x10block_timeout_ms\x18\x08 \x01(\x03R\x0eblockTimeoutMs\x12\'\x0a\x0fpriority_levels\x18\x09 \x01(\x0dR\x0epriorityLevels\x12#\x0a\x0dpriority_mode\x18\x0a \x01(\x09R\x0cpriorityMode\x12\x1e\x0a\x0apartitions\x18\x0b \x01(\x0dR\x0apartitions\x12\x18\x0a\x07durable\x18\x0c \x01(\x08R\x07durable\x12\x1b\x0a\x09max_bytes\x18\x0d \x01(\x04R\x08maxBytes\"I\x0a\x13CreateQueueResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"(\x0a\x12DeleteQueueRequest\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\"I\x0a\x13DeleteQueueResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"\x13\x0a\x11ListQueuesRequest\"\xcb\x04\x0a\x09QueueInfo\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12\x1a\x0a\x08capacity\x18\x02 \x01(\x04R\x08capacity\x12\x16\x0a\x06length\x18\x03 \x01(\x04R\x06length\x12\x1b\x0a\x09in_flight\x18\x04 \x01(\x04R\x08inFlight\x122\x0a\x15max_delivery_attempts\x18\x05 \x01(\x0dR\x13maxDeliveryAttempts\x12*\x0a\x11dead_letter_queue\x18\x06 \x01(\x09R\x0fdeadLetterQueue\x12\x1c\x0a\x09scheduled\x18\x07 \x01(\x04R\x09scheduled\x12\x1c\x0a\x0amax_age_ms\x18\x08 \x01(\x03R\x08maxAgeMs\x12\'\x0a\x0foverflow_policy\x18\x09 \x01(\x09R\x0eoverflowPolicy\x12\'\x0a\x0fpriority_levels\x18\x0a \x01(\x0dR\x0epriorityLevels\x12#\x0a\x0dpriority_mode\x18\x0b \x01(\x09R\x0cpriorityMode\x12\x14\x0a\x05topic\x18\x0c \x01(\x09R\x05topic\x12\x1e\x0a\x0apartitions\x18\x0d \x01(\x0dR\x0apartitions\x12C\x0a\x0bassignments\x18\x0e \x03(\x0b2!.messagequeue.PartitionAssignmentR\x0bassignments\x12\x18\x0a\x07durable\x18\x0f \x01(\x08R\x07durable\x12\x1b\x0a\x09max_bytes\x18\x10 \x01(\x04R\x08maxBytes\x12\x14\x0a\x05bytes\x18\x11 \x01(\x04R\x05bytes\"Q\x0a\x13PartitionAssignment\x12\x1a\x0a\x08consumer\x18\x01 \x01(\x09R\x08consumer\x12\x1e\x0a\x0apartitions\x18\x02 \x03(\x0dR\x0apartitions\"E\x0a\x12ListQueuesResponse\x12/\x0a\x06queues\x18\x01 \x03(\x0b2\x17.messagequeue.QueueInfoR\x06queues\"h\x0a\x0fDeliveryAttempt\x12=\x0a\x0cdelivered_at\x18\x01 \x01(\x0b2\x1a.google.protobuf.TimestampR\x0bdeliveredAt\x12\x16\x0a\x06reason\x18\x02 \x01(\x09R\x06reason\"\x96\x02\x0a\x0aDeadLetter\x12\x18\x0a\x07payload\x18\x01 \x01(\x0cR\x07payload\x122\x0a\x08envelope\x18\x02 \x01(\x0b2\x16.messagequeue.EnvelopeR\x08envelope\x12!\x0a\x0csource_queue\x18\x03 \x01(\x09R\x0bsourceQueue\x12\x16\x0a\x06reason\x18\x04 \x01(\x09R\x06reason\x12D\x0a\x10dead_lettered_at\x18\x05 \x01(\x0b2\x1a.google.protobuf.TimestampR\x0edeadLetteredAt\x129\x0a\x08attempts\x18\x06 \x03(\x0b2\x1d.messagequeue.DeliveryAttemptR\x08attempts\"G\x0a\x19InspectDeadLettersRequest\x12\x14\x0a\x05queue\x18\x01 \x01(\x09R\x05queue\x12\x14\x0a\x05limit\x18\x02 \x01(\x0dR\x05limit\"l\x0a\x1aInspectDeadLettersResponse\x124\x0a\x08messages\x18\x01 \x03(\x0b2\x18.messagequeue.DeadLetterR\x08messages\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"G\x0a\x19RequeueDeadLettersRequest\x12\x14\x0a\x05queue\x18\x01 \x01(\x09R\x05queue\x12\x14\x0a\x05limit\x18\x02 \x01(\x0dR\x05limit\"l\x0a\x1aRequeueDeadLettersResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x1a\x0a\x08requeued\x18\x03 \x01(\x04R\x08requeued\"/\x0a\x17PurgeDeadLettersRequest\x12\x14\x0a\x05queue\x18\x01 \x01(\x09R\x05queue\"f\x0a\x18PurgeDeadLettersResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x16\x0a\x06purged\x18\x03 \x01(\x04R\x06purged\"\xee\x01\x0a\x10SubscribeRequest\x12\x14\x0a\x05topic\x18\x01 \x01(\x09R\x05topic\x12\"\x0a\x0csubscription\x18\x02 \x01(\x09R\x0csubscription\x12\x1a\x0a\x08capacity\x18\x03 \x01(\x04R\x08capacity\x122\x0a\x15visibility_timeout_ms\x18\x04 \x01(\x03R\x13visibilityTimeoutMs\x122\x0a\x15max_delivery_attempts\x18\x05 \x01(\x0dR\x13maxDeliveryAttempts\x12\x1c\x0a\x0amax_age_ms\x18\x06 \x01(\x03R\x08maxAgeMs\"]\x0a\x11SubscribeResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x14\x0a\x05queue\x18\x03 \x01(\x09R\x05queue\"N\x0a\x12UnsubscribeRequest\x12\x14\x0a\x05topic\x18\x01 \x01(\x09R\x05topic\x12\"\x0a\x0csubscription\x18\x02 \x01(\x09R\x0csubscription\"I\x0a\x13UnsubscribeResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"\x13\x0a\x11ListTopicsRequest\"E\x0a\x09TopicInfo\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12$\x0a\x0dsubscriptions\x18\x02 \x03(\x09R\x0dsubscriptions\"E\x0a\x12ListTopicsResponse\x12/\x0a\x06topics\x18\x01 \x03(\x0b2\x17.messagequeue.TopicInfoR\x06topics\"g\x0a\x10CreateLogRequest\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12!\x0a\x0csegment_size\x18\x02 \x01(\x0dR\x0bsegmentSize\x12\x1c\x0a\x09retention\x18\x03 \x01(\x04R\x09retention\"G\x0a\x11CreateLogResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"&\x0a\x10DeleteLogRequest\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\"G\x0a\x11DeleteLogResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"\x11\x0a\x0fListLogsRequest\"\x80\x01\x0a\x11ConsumerGroupInfo\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12)\x0a\x10committed_offset\x18\x02 \x01(\x04R\x0fcommittedOffset\x12\x1a\x0a\x08position\x18\x03 \x01(\x04R\x08position\x12\x10\x0a\x03lag\x18\x04 \x01(\x04R\x03lag\"\x98\x01\x0a\x07LogInfo\x12\x12\x0a\x04name\x18\x01 \x01(\x09R\x04name\x12!\x0a\x0cstart_offset\x18\x02 \x01(\x04R\x0bstartOffset\x12\x1d\x0a\x0aend_offset\x18\x03 \x01(\x04R\x09endOffset\x127\x0a\x06groups\x18\x04 \x03(\x0b2\x1f.messagequeue.ConsumerGroupInfoR\x06groups\"=\x0a\x10ListLogsResponse\x12)\x0a\x04logs\x18\x01 \x03(\x0b2\x15.messagequeue.LogInfoR\x04logs\"\x81\x01\x0a\x0cFetchRequest\x12\x10\x0a\x03log\x18\x01 \x01(\x09R\x03log\x12\x14\x0a\x05group\x18\x02 \x01(\x09R\x05group\x12!\x0a\x0cmax_messages\x18\x03 \x01(\x0dR\x0bmaxMessages\x12&\x0a\x0fwait_timeout_ms\x18\x04 \x01(\x03R\x0dwaitTimeoutMs\"q\x0a\x09LogRecord\x12\x16\x0a\x06offset\x18\x01 \x01(\x04R\x06offset\x12\x18\x0a\x07payload\x18\x02 \x01(\x0cR\x07payload\x122\x0a\x08envelope\x18\x03 \x01(\x0b2\x16.messagequeue.EnvelopeR\x08envelope\"\\\x0a\x0dFetchResponse\x121\x0a\x07records\x18\x01 \x03(\x0b2\x17.messagequeue.LogRecordR\x07records\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"U\x0a\x13CommitOffsetRequest\x12\x10\x0a\x03log\x18\x01 \x01(\x09R\x03log\x12\x14\x0a\x05group\x18\x02 \x01(\x09R\x05group\x12\x16\x0a\x06offset\x18\x03 \x01(\x04R\x06offset\"J\x0a\x14CommitOffsetResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\"<\x0a\x12RewindGroupRequest\x12\x10\x0a\x03log\x18\x01 \x01(\x09R\x03log\x12\x14\x0a\x05group\x18\x02 \x01(\x09R\x05group\"1\x0a\x13RewindGroupResponse\x12\x1a\x0a\x08position\x18\x01 \x01(\x04R\x08position\"\x11\x0a\x0fSnapshotRequest\"z\x0a\x10SnapshotResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x16\x0a\x06queues\x18\x03 \x01(\x0dR\x06queues\x12\x1a\x0a\x08messages\x18\x04 \x01(\x04R\x08messages\"\x10\x0a\x0eRestoreRequest\"y\x0a\x0fRestoreResponse\x12\x18\x0a\x07success\x18\x01 \x01(\x08R\x07success\x12\x18\x0a\x05error\x18\x02 \x01(\x09B\x02\x18\x01R\x05error\x12\x16\x0a\x06queues\x18\x03 \x01(\x0dR\x06queues\x12\x1a\x0a\x08messages\x18\x04 \x01(\x04R\x08messages*O\x0a\x0aApiVersion\x12\x1b\x0a\x17API_VERSION_UNSPECIFIED\x10\x00\x12\x11\x0a\x0dAPI_VERSION_1\x10\x01\x12\x11\x0a\x0dAPI_VERSION_2\x10\x022\xf1\x0d\x0a\x0cMessageQueue\x12F\x0a\x07Produce\x12\x1c.messagequeue.ProduceRequest\x1a\x1d.messagequeue.ProduceResponse\x12F\x0a\x07Consume\x12\x1c.messagequeue.ConsumeRequest\x1a\x1d.messagequeue.ConsumeResponse\x12:\x0a\x03Ack\x12\x18.messagequeue.AckRequest\x1a\x19.messagequeue.AckResponse\x12=\x0a\x04Nack\x12\x19.messagequeue.NackRequest\x1a\x1a.messagequeue.NackResponse\x12N\x0a\x0eStreamMessages\x12\x1b.messagequeue.StreamMessage\x1a\x1b.messagequeue.StreamMessage(\x010\x01\x12R\x0a\x0bCreateQueue\x12 .messagequeue.CreateQueueRequest\x1a!.messagequeue.CreateQueueResponse\x12R\x0a\x0bDeleteQueue\x12 .messagequeue.DeleteQueueRequest\x1a!.messagequeue.DeleteQueueResponse\x12O\x0a\x0aListQueues\x12\x1f.messagequeue.ListQueuesRequest\x1a .messagequeue.ListQueuesResponse\x12g\x0a\x12InspectDeadLetters\x12\'.messagequeue.InspectDeadLettersRequest\x1a(.messagequeue.InspectDeadLettersResponse\x12g\x0a\x12RequeueDeadLetters\x12\'.messagequeue.RequeueDeadLettersRequest\x1a(.messagequeue.RequeueDeadLettersResponse\x12a\x0a\x10PurgeDeadLetters\x12%.messagequeue.PurgeDeadLettersRequest\x1a&.messagequeue.PurgeDeadLettersResponse\x12L\x0a\x09Subscribe\x12\x1e.messagequeue.SubscribeRequest\x1a\x1f.messagequeue.SubscribeResponse\x12R\x0a\x0bUnsubscribe\x12 .messagequeue.UnsubscribeRequest\x1a!.messagequeue.UnsubscribeResponse\x12O\x0a\x0aListTopics\x12\x1f.messagequeue.ListTopicsRequest\x1a .messagequeue.ListTopicsResponse\x12L\x0a\x09CreateLog\x12\x1e.messagequeue.CreateLogRequest\x1a\x1f.messagequeue.CreateLogResponse\x12L\x0a\x09DeleteLog\x12\x1e.messagequeue.DeleteLogRequest\x1a\x1f.messagequeue.DeleteLogResponse\x12I\x0a\x08ListLogs\x12\x1d.messagequeue.ListLogsRequest\x1a\x1e.messagequeue.ListLogsResponse\x12@\x0a\x05Fetch\x12\x1a.messagequeue.FetchRequest\x1a\x1b.messagequeue.FetchResponse\x12U\x0a\x0cCommitOffset\x12!.messagequeue.CommitOffsetRequest\x1a\".messagequeue.CommitOffsetResponse\x12R\x0a\x0bRewindGroup\x12 .messagequeue.RewindGroupRequest\x1a!.messagequeue.RewindGroupResponse\x12I\x0a\x08Snapshot\x12\x1d.messagequeue.SnapshotRequest\x1a\x1e.messagequeue.SnapshotResponse\x12F\x0a\x07Restore\x12\x1c.messagequeue.RestoreRequest\x1a\x1d.messagequeue.RestoreResponseB\x18Z\x16quickpulse/proto;protob\x06proto3')
 
+_globals = globals()
+_builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
+_builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'messagequeue_pb2', _globals)
 # @@protoc_insertion_point(module_scope)
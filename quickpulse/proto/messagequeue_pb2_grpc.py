@@ -25,11 +25,106 @@ class MessageQueueStub(object):
                 request_serializer=messagequeue__pb2.ConsumeRequest.SerializeToString,
                 response_deserializer=messagequeue__pb2.ConsumeResponse.FromString,
                 )
+        self.Ack = channel.unary_unary(
+                '/messagequeue.MessageQueue/Ack',
+                request_serializer=messagequeue__pb2.AckRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.AckResponse.FromString,
+                )
+        self.Nack = channel.unary_unary(
+                '/messagequeue.MessageQueue/Nack',
+                request_serializer=messagequeue__pb2.NackRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.NackResponse.FromString,
+                )
         self.StreamMessages = channel.stream_stream(
                 '/messagequeue.MessageQueue/StreamMessages',
                 request_serializer=messagequeue__pb2.StreamMessage.SerializeToString,
                 response_deserializer=messagequeue__pb2.StreamMessage.FromString,
                 )
+        self.CreateQueue = channel.unary_unary(
+                '/messagequeue.MessageQueue/CreateQueue',
+                request_serializer=messagequeue__pb2.CreateQueueRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.CreateQueueResponse.FromString,
+                )
+        self.DeleteQueue = channel.unary_unary(
+                '/messagequeue.MessageQueue/DeleteQueue',
+                request_serializer=messagequeue__pb2.DeleteQueueRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.DeleteQueueResponse.FromString,
+                )
+        self.ListQueues = channel.unary_unary(
+                '/messagequeue.MessageQueue/ListQueues',
+                request_serializer=messagequeue__pb2.ListQueuesRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.ListQueuesResponse.FromString,
+                )
+        self.InspectDeadLetters = channel.unary_unary(
+                '/messagequeue.MessageQueue/InspectDeadLetters',
+                request_serializer=messagequeue__pb2.InspectDeadLettersRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.InspectDeadLettersResponse.FromString,
+                )
+        self.RequeueDeadLetters = channel.unary_unary(
+                '/messagequeue.MessageQueue/RequeueDeadLetters',
+                request_serializer=messagequeue__pb2.RequeueDeadLettersRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.RequeueDeadLettersResponse.FromString,
+                )
+        self.PurgeDeadLetters = channel.unary_unary(
+                '/messagequeue.MessageQueue/PurgeDeadLetters',
+                request_serializer=messagequeue__pb2.PurgeDeadLettersRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.PurgeDeadLettersResponse.FromString,
+                )
+        self.Subscribe = channel.unary_unary(
+                '/messagequeue.MessageQueue/Subscribe',
+                request_serializer=messagequeue__pb2.SubscribeRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.SubscribeResponse.FromString,
+                )
+        self.Unsubscribe = channel.unary_unary(
+                '/messagequeue.MessageQueue/Unsubscribe',
+                request_serializer=messagequeue__pb2.UnsubscribeRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.UnsubscribeResponse.FromString,
+                )
+        self.ListTopics = channel.unary_unary(
+                '/messagequeue.MessageQueue/ListTopics',
+                request_serializer=messagequeue__pb2.ListTopicsRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.ListTopicsResponse.FromString,
+                )
+        self.CreateLog = channel.unary_unary(
+                '/messagequeue.MessageQueue/CreateLog',
+                request_serializer=messagequeue__pb2.CreateLogRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.CreateLogResponse.FromString,
+                )
+        self.DeleteLog = channel.unary_unary(
+                '/messagequeue.MessageQueue/DeleteLog',
+                request_serializer=messagequeue__pb2.DeleteLogRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.DeleteLogResponse.FromString,
+                )
+        self.ListLogs = channel.unary_unary(
+                '/messagequeue.MessageQueue/ListLogs',
+                request_serializer=messagequeue__pb2.ListLogsRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.ListLogsResponse.FromString,
+                )
+        self.Fetch = channel.unary_unary(
+                '/messagequeue.MessageQueue/Fetch',
+                request_serializer=messagequeue__pb2.FetchRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.FetchResponse.FromString,
+                )
+        self.CommitOffset = channel.unary_unary(
+                '/messagequeue.MessageQueue/CommitOffset',
+                request_serializer=messagequeue__pb2.CommitOffsetRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.CommitOffsetResponse.FromString,
+                )
+        self.Snapshot = channel.unary_unary(
+                '/messagequeue.MessageQueue/Snapshot',
+                request_serializer=messagequeue__pb2.SnapshotRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.SnapshotResponse.FromString,
+                )
+        self.Restore = channel.unary_unary(
+                '/messagequeue.MessageQueue/Restore',
+                request_serializer=messagequeue__pb2.RestoreRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.RestoreResponse.FromString,
+                )
+        self.RewindGroup = channel.unary_unary(
+                '/messagequeue.MessageQueue/RewindGroup',
+                request_serializer=messagequeue__pb2.RewindGroupRequest.SerializeToString,
+                response_deserializer=messagequeue__pb2.RewindGroupResponse.FromString,
+                )
 
 
 class MessageQueueServicer(object):
@@ -50,6 +145,20 @@ class MessageQueueServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def Ack(self, request, context):
+        """Acknowledge a leased message, deleting it.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Nack(self, request, context):
+        """Reject a leased message, redelivering it.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def StreamMessages(self, request_iterator, context):
         """Bidirectional streaming for messages.
         """
@@ -57,6 +166,125 @@ class MessageQueueServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def CreateQueue(self, request, context):
+        """Create a new named queue.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteQueue(self, request, context):
+        """Delete a named queue and discard its messages.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListQueues(self, request, context):
+        """List all named queues.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def InspectDeadLetters(self, request, context):
+        """List messages in a queue's dead-letter queue without removing them.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RequeueDeadLetters(self, request, context):
+        """Move messages from a queue's dead-letter queue back to the queues they came from.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def PurgeDeadLetters(self, request, context):
+        """Discard all messages in a queue's dead-letter queue.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Subscribe(self, request, context):
+        """Attach a subscription to a topic; messages produced to the topic are copied to it.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Unsubscribe(self, request, context):
+        """Detach a subscription from its topic and discard its messages.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListTopics(self, request, context):
+        """List all topics and their subscriptions.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateLog(self, request, context):
+        """Create a new log, an append-only stream read by consumer groups.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteLog(self, request, context):
+        """Delete a log with its messages and consumer groups.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListLogs(self, request, context):
+        """List all logs with their consumer groups.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Fetch(self, request, context):
+        """Read the next messages of a log for a consumer group.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CommitOffset(self, request, context):
+        """Commit the offset up to which a consumer group has processed a log.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Snapshot(self, request, context):
+        """Write every queue with its messages to the server's snapshot file.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Restore(self, request, context):
+        """Load the server's snapshot file, recreating its queues and messages.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RewindGroup(self, request, context):
+        """Move a consumer group back to its committed offset, so messages fetched but not committed are fetched again; members call it when they (re)connect.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_MessageQueueServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -70,11 +298,106 @@ def add_MessageQueueServicer_to_server(servicer, server):
                     request_deserializer=messagequeue__pb2.ConsumeRequest.FromString,
                     response_serializer=messagequeue__pb2.ConsumeResponse.SerializeToString,
             ),
+            'Ack': grpc.unary_unary_rpc_method_handler(
+                    servicer.Ack,
+                    request_deserializer=messagequeue__pb2.AckRequest.FromString,
+                    response_serializer=messagequeue__pb2.AckResponse.SerializeToString,
+            ),
+            'Nack': grpc.unary_unary_rpc_method_handler(
+                    servicer.Nack,
+                    request_deserializer=messagequeue__pb2.NackRequest.FromString,
+                    response_serializer=messagequeue__pb2.NackResponse.SerializeToString,
+            ),
             'StreamMessages': grpc.stream_stream_rpc_method_handler(
                     servicer.StreamMessages,
                     request_deserializer=messagequeue__pb2.StreamMessage.FromString,
                     response_serializer=messagequeue__pb2.StreamMessage.SerializeToString,
             ),
+            'CreateQueue': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateQueue,
+                    request_deserializer=messagequeue__pb2.CreateQueueRequest.FromString,
+                    response_serializer=messagequeue__pb2.CreateQueueResponse.SerializeToString,
+            ),
+            'DeleteQueue': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteQueue,
+                    request_deserializer=messagequeue__pb2.DeleteQueueRequest.FromString,
+                    response_serializer=messagequeue__pb2.DeleteQueueResponse.SerializeToString,
+            ),
+            'ListQueues': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListQueues,
+                    request_deserializer=messagequeue__pb2.ListQueuesRequest.FromString,
+                    response_serializer=messagequeue__pb2.ListQueuesResponse.SerializeToString,
+            ),
+            'InspectDeadLetters': grpc.unary_unary_rpc_method_handler(
+                    servicer.InspectDeadLetters,
+                    request_deserializer=messagequeue__pb2.InspectDeadLettersRequest.FromString,
+                    response_serializer=messagequeue__pb2.InspectDeadLettersResponse.SerializeToString,
+            ),
+            'RequeueDeadLetters': grpc.unary_unary_rpc_method_handler(
+                    servicer.RequeueDeadLetters,
+                    request_deserializer=messagequeue__pb2.RequeueDeadLettersRequest.FromString,
+                    response_serializer=messagequeue__pb2.RequeueDeadLettersResponse.SerializeToString,
+            ),
+            'PurgeDeadLetters': grpc.unary_unary_rpc_method_handler(
+                    servicer.PurgeDeadLetters,
+                    request_deserializer=messagequeue__pb2.PurgeDeadLettersRequest.FromString,
+                    response_serializer=messagequeue__pb2.PurgeDeadLettersResponse.SerializeToString,
+            ),
+            'Subscribe': grpc.unary_unary_rpc_method_handler(
+                    servicer.Subscribe,
+                    request_deserializer=messagequeue__pb2.SubscribeRequest.FromString,
+                    response_serializer=messagequeue__pb2.SubscribeResponse.SerializeToString,
+            ),
+            'Unsubscribe': grpc.unary_unary_rpc_method_handler(
+                    servicer.Unsubscribe,
+                    request_deserializer=messagequeue__pb2.UnsubscribeRequest.FromString,
+                    response_serializer=messagequeue__pb2.UnsubscribeResponse.SerializeToString,
+            ),
+            'ListTopics': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListTopics,
+                    request_deserializer=messagequeue__pb2.ListTopicsRequest.FromString,
+                    response_serializer=messagequeue__pb2.ListTopicsResponse.SerializeToString,
+            ),
+            'CreateLog': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateLog,
+                    request_deserializer=messagequeue__pb2.CreateLogRequest.FromString,
+                    response_serializer=messagequeue__pb2.CreateLogResponse.SerializeToString,
+            ),
+            'DeleteLog': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteLog,
+                    request_deserializer=messagequeue__pb2.DeleteLogRequest.FromString,
+                    response_serializer=messagequeue__pb2.DeleteLogResponse.SerializeToString,
+            ),
+            'ListLogs': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListLogs,
+                    request_deserializer=messagequeue__pb2.ListLogsRequest.FromString,
+                    response_serializer=messagequeue__pb2.ListLogsResponse.SerializeToString,
+            ),
+            'Fetch': grpc.unary_unary_rpc_method_handler(
+                    servicer.Fetch,
+                    request_deserializer=messagequeue__pb2.FetchRequest.FromString,
+                    response_serializer=messagequeue__pb2.FetchResponse.SerializeToString,
+            ),
+            'CommitOffset': grpc.unary_unary_rpc_method_handler(
+                    servicer.CommitOffset,
+                    request_deserializer=messagequeue__pb2.CommitOffsetRequest.FromString,
+                    response_serializer=messagequeue__pb2.CommitOffsetResponse.SerializeToString,
+            ),
+            'Snapshot': grpc.unary_unary_rpc_method_handler(
+                    servicer.Snapshot,
+                    request_deserializer=messagequeue__pb2.SnapshotRequest.FromString,
+                    response_serializer=messagequeue__pb2.SnapshotResponse.SerializeToString,
+            ),
+            'Restore': grpc.unary_unary_rpc_method_handler(
+                    servicer.Restore,
+                    request_deserializer=messagequeue__pb2.RestoreRequest.FromString,
+                    response_serializer=messagequeue__pb2.RestoreResponse.SerializeToString,
+            ),
+            'RewindGroup': grpc.unary_unary_rpc_method_handler(
+                    servicer.RewindGroup,
+                    request_deserializer=messagequeue__pb2.RewindGroupRequest.FromString,
+                    response_serializer=messagequeue__pb2.RewindGroupResponse.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'messagequeue.MessageQueue', rpc_method_handlers)
@@ -120,6 +443,40 @@ class MessageQueue(object):
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
 
+    @staticmethod
+    def Ack(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Ack',
+            messagequeue__pb2.AckRequest.SerializeToString,
+            messagequeue__pb2.AckResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Nack(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Nack',
+            messagequeue__pb2.NackRequest.SerializeToString,
+            messagequeue__pb2.NackResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
     @staticmethod
     def StreamMessages(request_iterator,
             target,
@@ -136,3 +493,292 @@ class MessageQueue(object):
             messagequeue__pb2.StreamMessage.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CreateQueue(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/CreateQueue',
+            messagequeue__pb2.CreateQueueRequest.SerializeToString,
+            messagequeue__pb2.CreateQueueResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteQueue(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/DeleteQueue',
+            messagequeue__pb2.DeleteQueueRequest.SerializeToString,
+            messagequeue__pb2.DeleteQueueResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListQueues(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/ListQueues',
+            messagequeue__pb2.ListQueuesRequest.SerializeToString,
+            messagequeue__pb2.ListQueuesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def InspectDeadLetters(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/InspectDeadLetters',
+            messagequeue__pb2.InspectDeadLettersRequest.SerializeToString,
+            messagequeue__pb2.InspectDeadLettersResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RequeueDeadLetters(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/RequeueDeadLetters',
+            messagequeue__pb2.RequeueDeadLettersRequest.SerializeToString,
+            messagequeue__pb2.RequeueDeadLettersResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def PurgeDeadLetters(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/PurgeDeadLetters',
+            messagequeue__pb2.PurgeDeadLettersRequest.SerializeToString,
+            messagequeue__pb2.PurgeDeadLettersResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Subscribe(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Subscribe',
+            messagequeue__pb2.SubscribeRequest.SerializeToString,
+            messagequeue__pb2.SubscribeResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Unsubscribe(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Unsubscribe',
+            messagequeue__pb2.UnsubscribeRequest.SerializeToString,
+            messagequeue__pb2.UnsubscribeResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListTopics(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/ListTopics',
+            messagequeue__pb2.ListTopicsRequest.SerializeToString,
+            messagequeue__pb2.ListTopicsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CreateLog(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/CreateLog',
+            messagequeue__pb2.CreateLogRequest.SerializeToString,
+            messagequeue__pb2.CreateLogResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteLog(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/DeleteLog',
+            messagequeue__pb2.DeleteLogRequest.SerializeToString,
+            messagequeue__pb2.DeleteLogResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListLogs(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/ListLogs',
+            messagequeue__pb2.ListLogsRequest.SerializeToString,
+            messagequeue__pb2.ListLogsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Fetch(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Fetch',
+            messagequeue__pb2.FetchRequest.SerializeToString,
+            messagequeue__pb2.FetchResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CommitOffset(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/CommitOffset',
+            messagequeue__pb2.CommitOffsetRequest.SerializeToString,
+            messagequeue__pb2.CommitOffsetResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Snapshot(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Snapshot',
+            messagequeue__pb2.SnapshotRequest.SerializeToString,
+            messagequeue__pb2.SnapshotResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def Restore(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/Restore',
+            messagequeue__pb2.RestoreRequest.SerializeToString,
+            messagequeue__pb2.RestoreResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RewindGroup(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/messagequeue.MessageQueue/RewindGroup',
+            messagequeue__pb2.RewindGroupRequest.SerializeToString,
+            messagequeue__pb2.RewindGroupResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
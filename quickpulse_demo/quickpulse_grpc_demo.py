@@ -18,17 +18,26 @@ from quickpulse.proto import messagequeue_pb2_grpc
 def main():
     channel = grpc.insecure_channel('localhost:50051')
     stub = messagequeue_pb2_grpc.MessageQueueStub(channel)
+    # Ask for API version 2, which reports failures as gRPC status codes
+    version = (('quickpulse-api-version', str(messagequeue_pb2.API_VERSION_2)),)
 
-    # Produce a message
+    # Produce a message; failures raise grpc.RpcError with a status code such as
+    # RESOURCE_EXHAUSTED for a full queue
     message = b'Hello from Python gRPC client!'
     produce_req = messagequeue_pb2.ProduceRequest(payload=message)
-    produce_resp = stub.Produce(produce_req)
-    print("Produce response:", produce_resp.success, produce_resp.error)
+    try:
+        produce_resp = stub.Produce(produce_req, metadata=version)
+        print("Produce response:", produce_resp.message_id)
+    except grpc.RpcError as e:
+        print("Produce failed:", e.code(), e.details())
 
-    # Consume a message
+    # Consume a message; an empty queue fails with NOT_FOUND
     consume_req = messagequeue_pb2.ConsumeRequest()
-    consume_resp = stub.Consume(consume_req)
-    print("Consume response:", consume_resp.payload.decode('utf-8', errors='replace'), consume_resp.error)
+    try:
+        consume_resp = stub.Consume(consume_req, metadata=version)
+        print("Consume response:", consume_resp.payload.decode('utf-8', errors='replace'))
+    except grpc.RpcError as e:
+        print("Consume failed:", e.code(), e.details())
 
 if __name__ == '__main__':
     main()
\ No newline at end of file
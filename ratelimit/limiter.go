@@ -0,0 +1,329 @@
+// limiter.go - Token-bucket rate limits for producers.
+//
+// This file defines Limiter, which keeps producers within the rates its rules
+// allow. Each rule limits messages per second, bytes per second or both, for
+// every distinct value of its key: the client's IP address, the authenticated
+// subject (the subject of an API key, or a token's "sub"), or the queue, topic or
+// log produced to. A rule can be narrowed to the key values matching a pattern.
+// Every value gets its own token bucket, which starts full, holds up to a burst
+// of tokens and refills at the rule's rate; a message takes one message token and
+// one byte token per payload byte from every bucket that applies, or is refused
+// if any of them runs short, with how long the client should wait before trying
+// again. A message larger than a bucket's byte burst can never be allowed, so it
+// is refused as too large, with no time to wait. Buckets left idle long enough to
+// refill are dropped, so the number of clients does not grow the limiter without
+// bound. The rules can be replaced while the limiter is in use, as on a
+// configuration reload; the new rules start with full buckets.
+
+package ratelimit
+
+import (
+	"errors"      // For rule errors
+	"math"        // For rounding retry times
+	"path"        // For matching key values
+	"strconv"     // For describing limits
+	"sync"        // For the buckets
+	"sync/atomic" // For the sweep time and the rule set
+	"time"        // For refilling the buckets
+)
+
+// Key selects what a rule limits each bucket to.
+type Key string
+
+// Keys.
+const (
+	KeyClient  Key = "client"  // Client IP address
+	KeySubject Key = "subject" // Authenticated subject; unauthenticated requests are not limited by these rules
+	KeyQueue   Key = "queue"   // Queue, topic or log produced to
+)
+
+// Limits a request can exceed, as reported in Error and to Observer.
+const (
+	LimitMessages = "messages"
+	LimitBytes    = "bytes"
+)
+
+// sweepInterval is how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+// Errors returned by Allow, New and SetRules.
+var (
+	ErrRateLimited  = errors.New("rate limit exceeded")
+	ErrOverBurst    = errors.New("message is larger than the rate limit allows at once")
+	ErrInvalidKey   = errors.New("invalid rate limit key: use client, subject or queue")
+	ErrNoRate       = errors.New("rate limits need messages_per_second or bytes_per_second")
+	ErrInvalidRate  = errors.New("rates and bursts must not be negative")
+	ErrInvalidMatch = errors.New("invalid match pattern")
+)
+
+// Rule limits the producers sharing a key value.
+type Rule struct {
+	Key               Key     // What each bucket is kept for
+	Match             string  // Pattern (path.Match syntax) the key value must match; empty matches every value
+	MessagesPerSecond float64 // Zero leaves the message rate unlimited
+	BytesPerSecond    float64 // Zero leaves the byte rate unlimited
+	MessageBurst      float64 // Messages a full bucket holds; zero selects one second's worth (at least one)
+	ByteBurst         float64 // Bytes a full bucket holds; zero selects one second's worth
+}
+
+// Validate checks the rule's key, pattern, rates and bursts.
+func (r Rule) Validate() error {
+	switch r.Key {
+	case KeyClient, KeySubject, KeyQueue:
+	default:
+		return ErrInvalidKey
+	}
+	if _, err := path.Match(r.Match, ""); err != nil {
+		return ErrInvalidMatch
+	}
+	if r.MessagesPerSecond < 0 || r.BytesPerSecond < 0 || r.MessageBurst < 0 || r.ByteBurst < 0 {
+		return ErrInvalidRate
+	}
+	if r.MessagesPerSecond == 0 && r.BytesPerSecond == 0 {
+		return ErrNoRate
+	}
+	return nil
+}
+
+// withBursts returns the rule with its default bursts filled in.
+func (r Rule) withBursts() Rule {
+	if r.MessageBurst == 0 {
+		r.MessageBurst = max(r.MessagesPerSecond, 1)
+	}
+	if r.ByteBurst == 0 {
+		r.ByteBurst = r.BytesPerSecond
+	}
+	return r
+}
+
+// Request describes a message about to be produced.
+type Request struct {
+	Client  string // Client IP address
+	Subject string // Authenticated subject, or empty
+	Queue   string // Queue, topic or log name
+	Bytes   int    // Payload size
+}
+
+// value returns the request's value of key.
+func (req Request) value(key Key) string {
+	switch key {
+	case KeyClient:
+		return req.Client
+	case KeySubject:
+		return req.Subject
+	}
+	return req.Queue
+}
+
+// Error describes a refused request. It wraps ErrRateLimited, or ErrOverBurst if
+// the message is larger than the rule's byte burst.
+type Error struct {
+	Key        Key           // Key of the rule that refused it
+	Value      string        // The request's value of the key
+	Limit      string        // LimitMessages or LimitBytes
+	Rate       float64       // The rule's rate of that limit, per second
+	RetryAfter time.Duration // How long until the bucket refills enough for the request; zero if it never will
+	Burst      float64       // The rule's byte burst, if the message is larger; zero otherwise
+}
+
+func (e *Error) Error() string {
+	if e.Burst > 0 {
+		return ErrOverBurst.Error() + ": " + strconv.FormatFloat(e.Burst, 'g', -1, 64) + " bytes per " + string(e.Key) + " " + e.Value
+	}
+	return ErrRateLimited.Error() + ": " + strconv.FormatFloat(e.Rate, 'g', -1, 64) + " " + e.Limit + "/s per " + string(e.Key) + " " + e.Value
+}
+
+func (e *Error) Unwrap() error {
+	if e.Burst > 0 {
+		return ErrOverBurst
+	}
+	return ErrRateLimited
+}
+
+// Observer is implemented by metrics collectors that count refused requests. It
+// is called once per refusal, with the key of the rule and the limit exceeded.
+type Observer interface {
+	ObserveThrottled(key Key, limit string)
+}
+
+// Limiter applies rate limit rules to produced messages. It is safe for concurrent use.
+type Limiter struct {
+	set       atomic.Pointer[ruleSet] // Rules in effect
+	observer  Observer                // Notified of refusals, or nil
+	now       func() time.Time        // Clock for refilling buckets
+	lastSweep atomic.Int64            // Unix nanoseconds of the last sweep
+}
+
+// ruleSet holds a list of rules with the buckets kept under them, which are
+// replaced together.
+type ruleSet struct {
+	rules   []Rule   // With default bursts filled in
+	buckets sync.Map // *bucket by bucketKey
+}
+
+// bucketKey identifies the bucket of one key value under one rule.
+type bucketKey struct {
+	rule  int
+	value string
+}
+
+// bucket holds the tokens of one key value.
+type bucket struct {
+	mu       sync.Mutex
+	messages float64   // Message tokens
+	bytes    float64   // Byte tokens
+	last     time.Time // When the tokens were last refilled
+}
+
+// New creates a Limiter applying rules, which are checked first. A Limiter
+// without rules allows every request. observer may be nil.
+func New(rules []Rule, observer Observer) (*Limiter, error) {
+	l := &Limiter{observer: observer, now: time.Now}
+	if err := l.SetRules(rules); err != nil {
+		return nil, err
+	}
+	l.lastSweep.Store(l.now().UnixNano())
+	return l, nil
+}
+
+// SetRules replaces the rules in effect, once all of them are valid. Requests
+// already being checked finish under the old rules; the new ones start with full
+// buckets.
+func (l *Limiter) SetRules(rules []Rule) error {
+	set := &ruleSet{}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		set.rules = append(set.rules, rule.withBursts())
+	}
+	l.set.Store(set)
+	return nil
+}
+
+// Allow takes the tokens for req from every bucket that applies to it, and
+// returns an *Error, taking nothing, if any of them has too few or could never
+// hold enough.
+func (l *Limiter) Allow(req Request) error {
+	now := l.now()
+	l.sweep(now)
+	set := l.set.Load()
+	type taken struct {
+		b    *bucket
+		rule Rule
+	}
+	var took []taken
+	for i, rule := range set.rules {
+		value := req.value(rule.Key)
+		if value == "" {
+			continue
+		}
+		if rule.Match != "" {
+			if ok, _ := path.Match(rule.Match, value); !ok {
+				continue
+			}
+		}
+		var rlErr *Error
+		b := set.bucket(i, rule, value, now)
+		if rule.BytesPerSecond > 0 && float64(req.Bytes) > rule.ByteBurst {
+			rlErr = &Error{Key: rule.Key, Value: value, Limit: LimitBytes, Rate: rule.BytesPerSecond, Burst: rule.ByteBurst}
+		} else if limit, wait := b.take(rule, float64(req.Bytes), now); limit != "" {
+			rate := rule.MessagesPerSecond
+			if limit == LimitBytes {
+				rate = rule.BytesPerSecond
+			}
+			rlErr = &Error{Key: rule.Key, Value: value, Limit: limit, Rate: rate, RetryAfter: wait}
+		}
+		if rlErr != nil {
+			for _, t := range took {
+				t.b.refund(t.rule, float64(req.Bytes))
+			}
+			if l.observer != nil {
+				l.observer.ObserveThrottled(rule.Key, rlErr.Limit)
+			}
+			return rlErr
+		}
+		took = append(took, taken{b, rule})
+	}
+	return nil
+}
+
+// bucket returns the bucket of value under rule i, creating a full one if needed.
+func (set *ruleSet) bucket(i int, rule Rule, value string, now time.Time) *bucket {
+	key := bucketKey{i, value}
+	if b, ok := set.buckets.Load(key); ok {
+		return b.(*bucket)
+	}
+	b, _ := set.buckets.LoadOrStore(key, &bucket{messages: rule.MessageBurst, bytes: rule.ByteBurst, last: now})
+	return b.(*bucket)
+}
+
+// refill adds the tokens earned since the last refill. The caller holds b.mu.
+func (b *bucket) refill(rule Rule, now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.messages = min(rule.MessageBurst, b.messages+elapsed*rule.MessagesPerSecond)
+	b.bytes = min(rule.ByteBurst, b.bytes+elapsed*rule.BytesPerSecond)
+	b.last = now
+}
+
+// take removes the tokens for one message of size bytes, returning the limit that
+// is exceeded instead if there are too few, with how long refilling them takes.
+func (b *bucket) take(rule Rule, size float64, now time.Time) (string, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(rule, now)
+	if rule.MessagesPerSecond > 0 && b.messages < 1 {
+		return LimitMessages, refillTime(1-b.messages, rule.MessagesPerSecond)
+	}
+	if rule.BytesPerSecond > 0 && b.bytes < size {
+		return LimitBytes, refillTime(size-b.bytes, rule.BytesPerSecond)
+	}
+	if rule.MessagesPerSecond > 0 {
+		b.messages--
+	}
+	if rule.BytesPerSecond > 0 {
+		b.bytes -= size
+	}
+	return "", 0
+}
+
+// refillTime returns how long a bucket refilling at rate per second takes to earn tokens.
+func refillTime(tokens, rate float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / rate * float64(time.Second)))
+}
+
+// refund returns the tokens taken for a message that another bucket refused.
+func (b *bucket) refund(rule Rule, size float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rule.MessagesPerSecond > 0 {
+		b.messages = min(rule.MessageBurst, b.messages+1)
+	}
+	if rule.BytesPerSecond > 0 {
+		b.bytes = min(rule.ByteBurst, b.bytes+size)
+	}
+}
+
+// sweep drops the buckets that have refilled completely, at most once per sweepInterval.
+func (l *Limiter) sweep(now time.Time) {
+	last := l.lastSweep.Load()
+	if now.UnixNano()-last < int64(sweepInterval) || !l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	set := l.set.Load()
+	set.buckets.Range(func(k, v any) bool {
+		rule, b := set.rules[k.(bucketKey).rule], v.(*bucket)
+		b.mu.Lock()
+		b.refill(rule, now)
+		full := b.messages >= rule.MessageBurst && b.bytes >= rule.ByteBurst
+		b.mu.Unlock()
+		if full {
+			// A full bucket is the same as a new one
+			set.buckets.CompareAndDelete(k, v)
+		}
+		return true
+	})
+}
@@ -0,0 +1,182 @@
+// limiter_test.go - Tests for token-bucket rate limits.
+
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingObserver records refusals by key and limit.
+type countingObserver map[string]int
+
+func (o countingObserver) ObserveThrottled(key Key, limit string) {
+	o[string(key)+"/"+limit]++
+}
+
+// newTestLimiter returns a limiter on a clock the test advances.
+func newTestLimiter(t *testing.T, rules ...Rule) (*Limiter, *time.Time, countingObserver) {
+	t.Helper()
+	obs := countingObserver{}
+	l, err := New(rules, obs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	l.now = func() time.Time { return now }
+	l.lastSweep.Store(now.UnixNano())
+	return l, &now, obs
+}
+
+func TestMessageRate(t *testing.T) {
+	l, now, obs := newTestLimiter(t, Rule{Key: KeyClient, MessagesPerSecond: 10, MessageBurst: 5})
+	req := Request{Client: "10.0.0.1", Queue: "orders", Bytes: 100}
+	for i := 0; i < 5; i++ {
+		if err := l.Allow(req); err != nil {
+			t.Fatalf("message %d within the burst: %v", i, err)
+		}
+	}
+	err := l.Allow(req)
+	var rlErr *Error
+	if !errors.As(err, &rlErr) || !errors.Is(err, ErrRateLimited) || rlErr.Limit != LimitMessages || rlErr.Value != "10.0.0.1" || rlErr.RetryAfter != 100*time.Millisecond {
+		t.Fatalf("Allow beyond the burst = %v", err)
+	}
+	// Another client has its own bucket
+	if err := l.Allow(Request{Client: "10.0.0.2"}); err != nil {
+		t.Fatalf("other client: %v", err)
+	}
+	// 10 messages per second refill one token every 100ms
+	*now = now.Add(100 * time.Millisecond)
+	if err := l.Allow(req); err != nil {
+		t.Fatalf("after refilling: %v", err)
+	}
+	if err := l.Allow(req); err == nil {
+		t.Fatal("second message after one token refilled was allowed")
+	}
+	if obs["client/messages"] != 2 {
+		t.Fatalf("observed %v", obs)
+	}
+}
+
+func TestByteRateAndMatch(t *testing.T) {
+	l, now, obs := newTestLimiter(t,
+		Rule{Key: KeyQueue, Match: "audit.*", BytesPerSecond: 1000},
+		Rule{Key: KeySubject, MessagesPerSecond: 3},
+	)
+	big := Request{Subject: "ingest", Queue: "audit.eu", Bytes: 600}
+	if err := l.Allow(big); err != nil {
+		t.Fatal(err)
+	}
+	var rlErr *Error
+	// 200 more bytes at 1000 bytes per second take 200ms
+	if err := l.Allow(big); !errors.As(err, &rlErr) || rlErr.Limit != LimitBytes || rlErr.Key != KeyQueue || rlErr.RetryAfter != 200*time.Millisecond {
+		t.Fatalf("Allow over the byte rate = %v", err)
+	}
+	// Queues the pattern does not match are not limited by bytes
+	if err := l.Allow(Request{Subject: "ingest", Queue: "orders", Bytes: 5000}); err != nil {
+		t.Fatal(err)
+	}
+	// The refused message took no subject token: two messages were allowed, so one is left
+	if err := l.Allow(Request{Subject: "ingest", Queue: "orders"}); err != nil {
+		t.Fatalf("refused message used up a token: %v", err)
+	}
+	if err := l.Allow(Request{Subject: "ingest", Queue: "orders"}); err == nil {
+		t.Fatal("subject rate not applied")
+	}
+	// Unauthenticated requests are not limited by subject rules
+	if err := l.Allow(Request{Queue: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if obs["queue/bytes"] != 1 || obs["subject/messages"] != 1 {
+		t.Fatalf("observed %v", obs)
+	}
+
+	// Idle buckets refill and are dropped by the sweep
+	*now = now.Add(2 * sweepInterval)
+	l.Allow(Request{Queue: "orders"})
+	n := 0
+	l.set.Load().buckets.Range(func(any, any) bool { n++; return true })
+	if n != 0 {
+		t.Fatalf("%d buckets left after the sweep", n)
+	}
+}
+
+func TestLargerThanBurst(t *testing.T) {
+	l, _, obs := newTestLimiter(t,
+		Rule{Key: KeyClient, MessagesPerSecond: 10},
+		Rule{Key: KeyQueue, BytesPerSecond: 1000},
+	)
+	// 1001 bytes never fit a bucket holding 1000, so waiting would not help
+	err := l.Allow(Request{Client: "10.0.0.1", Queue: "orders", Bytes: 1001})
+	var rlErr *Error
+	if !errors.As(err, &rlErr) || !errors.Is(err, ErrOverBurst) || errors.Is(err, ErrRateLimited) || rlErr.RetryAfter != 0 || rlErr.Burst != 1000 {
+		t.Fatalf("Allow over the byte burst = %v", err)
+	}
+	if obs["queue/bytes"] != 1 {
+		t.Fatalf("observed %v", obs)
+	}
+	// The refused message took no client token: the whole message burst is left
+	for i := 0; i < 10; i++ {
+		if err := l.Allow(Request{Client: "10.0.0.1", Queue: "orders", Bytes: 100}); err != nil {
+			t.Fatalf("message %d within the burst: %v", i, err)
+		}
+	}
+	if err := l.Allow(Request{Client: "10.0.0.1", Queue: "audit"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("message beyond the burst = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestSetRules(t *testing.T) {
+	l, _, _ := newTestLimiter(t, Rule{Key: KeyClient, MessagesPerSecond: 1})
+	req := Request{Client: "10.0.0.1"}
+	if err := l.Allow(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Allow(req); err == nil {
+		t.Fatal("second message within a second was allowed")
+	}
+	// Invalid rules leave the old ones in effect
+	if err := l.SetRules([]Rule{{Key: "region", MessagesPerSecond: 1}}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("SetRules(invalid) = %v", err)
+	}
+	if err := l.Allow(req); err == nil {
+		t.Fatal("invalid rules replaced the old ones")
+	}
+	// New rules take effect at once, with full buckets
+	if err := l.SetRules([]Rule{{Key: KeyClient, MessagesPerSecond: 1, MessageBurst: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := l.Allow(req); err != nil {
+			t.Fatalf("message %d under the new rules: %v", i, err)
+		}
+	}
+	// No rules allow everything
+	if err := l.SetRules(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Allow(req); err != nil {
+		t.Fatalf("without rules: %v", err)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rule Rule
+		want error
+	}{
+		"valid":         {Rule{Key: KeyQueue, Match: "orders*", MessagesPerSecond: 1}, nil},
+		"unknown key":   {Rule{Key: "tenant", MessagesPerSecond: 1}, ErrInvalidKey},
+		"bad pattern":   {Rule{Key: KeyQueue, Match: "[", MessagesPerSecond: 1}, ErrInvalidMatch},
+		"no rate":       {Rule{Key: KeyClient}, ErrNoRate},
+		"negative rate": {Rule{Key: KeyClient, BytesPerSecond: -1}, ErrInvalidRate},
+	} {
+		if err := tc.rule.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Validate() = %v, want %v", name, err, tc.want)
+		}
+	}
+	if _, err := New([]Rule{{Key: KeyClient}}, nil); !errors.Is(err, ErrNoRate) {
+		t.Fatalf("New with an invalid rule = %v", err)
+	}
+}
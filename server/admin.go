@@ -0,0 +1,119 @@
+// admin.go - Snapshot and configuration administration over gRPC and HTTP.
+//
+// This file implements taking and restoring snapshots of every queue for the gRPC
+// Snapshot and Restore RPCs, and AdminServer, which offers the same operations as
+// HTTP endpoints next to the Prometheus metrics. Both act on the snapshot file
+// configured on the registry; clients cannot choose the path. AdminServer also
+// reloads the server's configuration, like SIGHUP does.
+
+package server
+
+import (
+	"encoding/json" // For JSON responses
+	"errors"        // For matching snapshot errors
+	"net/http"      // For HTTP handlers
+	"strconv"       // For error metadata
+
+	"quickpulse/config" // Configuration reloads
+	"quickpulse/mq"     // Queue registry
+	"quickpulse/proto"  // gRPC protobuf definitions
+)
+
+// snapshotQueues writes a snapshot of every queue as requested.
+func snapshotQueues(queues *mq.Registry) (*proto.SnapshotResponse, error) {
+	info, err := queues.SaveSnapshot()
+	if err != nil {
+		return nil, grpcError(err, nil)
+	}
+	return &proto.SnapshotResponse{Success: true, Queues: uint32(info.Queues), Messages: uint64(info.Messages)}, nil
+}
+
+// restoreQueues restores the snapshot file as requested. If it fails part way, the
+// error reports how many queues and messages were restored.
+func restoreQueues(queues *mq.Registry) (*proto.RestoreResponse, error) {
+	info, err := queues.LoadSnapshot()
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queues": strconv.Itoa(info.Queues), "messages": strconv.Itoa(info.Messages)})
+	}
+	return &proto.RestoreResponse{Success: true, Queues: uint32(info.Queues), Messages: uint64(info.Messages)}, nil
+}
+
+// AdminServer provides HTTP endpoints for administering the queues.
+type AdminServer struct {
+	Queues   *mq.Registry     // Registry of named queues
+	Reloader *config.Reloader // Reloads the configuration; nil disables reloads
+}
+
+// NewAdminServer creates a new AdminServer backed by the given queue registry,
+// reloading the configuration with reloader, which may be nil.
+func NewAdminServer(queues *mq.Registry, reloader *config.Reloader) *AdminServer {
+	return &AdminServer{Queues: queues, Reloader: reloader}
+}
+
+// snapshotResult is the JSON body of a successful snapshot or restore.
+type snapshotResult struct {
+	Queues   int `json:"queues"`
+	Messages int `json:"messages"`
+}
+
+// SnapshotHandler handles POST requests that write a snapshot of every queue to
+// the snapshot file, replying with the number of queues and messages written.
+func (s *AdminServer) SnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, err := s.Queues.SaveSnapshot()
+	writeSnapshotResult(w, info, err)
+}
+
+// RestoreHandler handles POST requests that restore the snapshot file, replying
+// with the number of queues and messages restored.
+func (s *AdminServer) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, err := s.Queues.LoadSnapshot()
+	writeSnapshotResult(w, info, err)
+}
+
+// writeSnapshotResult replies with the outcome of a snapshot or restore.
+func writeSnapshotResult(w http.ResponseWriter, info mq.SnapshotInfo, err error) {
+	switch {
+	case errors.Is(err, mq.ErrSnapshotsDisabled):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, mq.ErrQueueNotEmpty):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body, _ := json.Marshal(snapshotResult{Queues: info.Queues, Messages: info.Messages})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// ReloadHandler handles POST requests that reload the configuration, replying with
+// a report of the changes applied and rejected. A configuration that cannot be
+// loaded or is invalid is reported with status 400 and changes nothing.
+func (s *AdminServer) ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Reloader == nil {
+		http.Error(w, "configuration reloads are disabled", http.StatusNotFound)
+		return
+	}
+	report, err := s.Reloader.Reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, _ := json.Marshal(report)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
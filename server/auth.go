@@ -0,0 +1,279 @@
+// auth.go - Enforcing authentication and per-queue permissions.
+//
+// This file applies an auth.Authenticator to every transport. gRPC clients send
+// their credential as "authorization: Bearer <credential>" or "x-api-key"
+// metadata; the unary interceptor checks each request, and the stream
+// interceptor authenticates the stream when it opens and checks every message
+// it receives. WebSocket and admin HTTP clients send the same headers, or
+// ?access_token= for browsers, which cannot set headers on a WebSocket; the
+// handshake is refused before the upgrade. A request needs a permission on the
+// queue, topic or log it names: produce to send messages, consume to receive and
+// settle them, and admin to create, delete or administer, which includes
+// attaching and removing topic subscriptions. A subscription queue
+// ("topic:subscription") is guarded by its topic on every transport. Listing
+// queues, topics and logs only needs a valid credential.
+
+package server
+
+import (
+	"context"  // For the stream context
+	"net/http" // For WebSocket handshake middleware
+	"strings"  // For parsing bearer credentials and subscription queue names
+
+	"quickpulse/auth"  // Authentication and access rules
+	"quickpulse/mq"    // For subscription queue names
+	"quickpulse/proto" // gRPC protobuf definitions
+
+	"google.golang.org/grpc"          // For interceptors
+	"google.golang.org/grpc/codes"    // For authentication status codes
+	"google.golang.org/grpc/metadata" // For reading credentials
+	"google.golang.org/grpc/status"   // For authentication errors
+)
+
+// AllResources names every queue, topic and log at once. Only rules granting a
+// permission on "*" match it, so operations on the whole registry, such as
+// snapshots and config reloads, require admin on "*".
+const AllResources = "*"
+
+// bearerCredential strips the "Bearer " scheme from an Authorization value.
+func bearerCredential(value string) string {
+	if len(value) > len("Bearer ") && strings.EqualFold(value[:len("Bearer ")], "Bearer ") {
+		return value[len("Bearer "):]
+	}
+	return ""
+}
+
+// grpcCredential returns the credential in the request metadata of ctx, if any.
+func grpcCredential(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		return bearerCredential(values[0])
+	}
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// httpCredential returns the credential of an HTTP request, if any.
+func httpCredential(r *http.Request) string {
+	if value := r.Header.Get("Authorization"); value != "" {
+		return bearerCredential(value)
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("access_token")
+}
+
+// Permission sets of the gRPC requests.
+var (
+	needProduce        = []auth.Permission{auth.Produce}
+	needConsume        = []auth.Permission{auth.Consume}
+	needAdmin          = []auth.Permission{auth.Admin}
+	needProduceConsume = []auth.Permission{auth.Produce, auth.Consume}
+	needConsumeAdmin   = []auth.Permission{auth.Consume, auth.Admin}
+)
+
+// queueResource names the resource that guards the queue called name: the topic
+// for a subscription queue ("topic:subscription"), so that a subscription is
+// consumed with the same permission over gRPC and WebSocket, and otherwise the
+// queue itself, or the default queue if name is empty.
+func queueResource(name string) string {
+	if topic, _, ok := strings.Cut(name, mq.SubscriptionSeparator); ok {
+		return topic
+	}
+	return queueName(name)
+}
+
+// access returns the permissions a gRPC request needs and the resource it needs
+// them on. It returns no permissions for requests any authenticated client may make.
+func access(req any) (perms []auth.Permission, resource string) {
+	switch req := req.(type) {
+	case *proto.ProduceRequest:
+		switch {
+		case req.Topic != "":
+			return needProduce, req.Topic
+		case req.Log != "":
+			return needProduce, req.Log
+		}
+		return needProduce, queueResource(req.Queue)
+	case *proto.StreamMessage:
+		// Every stream message is answered with a message from the queue; one with a payload also produces
+		if req.Payload != nil {
+			return needProduceConsume, queueResource(req.Queue)
+		}
+		return needConsume, queueResource(req.Queue)
+	case *proto.ConsumeRequest:
+		return needConsume, queueResource(req.Queue)
+	case *proto.AckRequest:
+		return needConsume, queueResource(req.Queue)
+	case *proto.NackRequest:
+		return needConsume, queueResource(req.Queue)
+	case *proto.SubscribeRequest:
+		// Subscriptions outlive the caller and take a copy of every message, so
+		// creating and removing them is administration
+		return needAdmin, req.Topic
+	case *proto.UnsubscribeRequest:
+		return needAdmin, req.Topic
+	case *proto.FetchRequest:
+		return needConsume, req.Log
+	case *proto.CommitOffsetRequest:
+		return needConsume, req.Log
+	case *proto.RewindGroupRequest:
+		return needConsume, req.Log
+	case *proto.CreateQueueRequest:
+		return needAdmin, req.Name
+	case *proto.DeleteQueueRequest:
+		return needAdmin, queueResource(req.Name)
+	case *proto.InspectDeadLettersRequest:
+		return needAdmin, queueResource(req.Queue)
+	case *proto.RequeueDeadLettersRequest:
+		return needAdmin, queueResource(req.Queue)
+	case *proto.PurgeDeadLettersRequest:
+		return needAdmin, queueResource(req.Queue)
+	case *proto.CreateLogRequest:
+		return needAdmin, req.Name
+	case *proto.DeleteLogRequest:
+		return needAdmin, req.Name
+	case *proto.SnapshotRequest, *proto.RestoreRequest:
+		return needAdmin, AllResources
+	}
+	return nil, ""
+}
+
+// denied describes a refused request.
+func denied(p *auth.Principal, perm auth.Permission, resource string) string {
+	return "permission denied: " + p.Subject + " may not " + string(perm) + " " + resource
+}
+
+// authorize checks that p may make the gRPC request req.
+func authorize(a *auth.Authenticator, p *auth.Principal, req any) error {
+	perms, resource := access(req)
+	for _, perm := range perms {
+		if !a.Allowed(p, perm, resource) {
+			return status.Error(codes.PermissionDenied, denied(p, perm, resource))
+		}
+	}
+	return nil
+}
+
+// authenticate returns the principal behind the credential in ctx, or an Unauthenticated status.
+func authenticate(a *auth.Authenticator, ctx context.Context) (*auth.Principal, error) {
+	p, err := a.Authenticate(grpcCredential(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return p, nil
+}
+
+// UnaryAuthInterceptor authenticates every unary RPC with a and checks that the
+// caller may make it. The principal is available to handlers through auth.FromContext.
+func UnaryAuthInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, err := authenticate(a, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorize(a, p, req); err != nil {
+			return nil, err
+		}
+		return handler(auth.NewContext(ctx, p), req)
+	}
+}
+
+// StreamAuthInterceptor authenticates every stream with a when it opens, and
+// checks that the caller may send each message it receives; a message that is
+// not allowed ends the stream with PermissionDenied.
+func StreamAuthInterceptor(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		p, err := authenticate(a, ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, authn: a, principal: p, ctx: auth.NewContext(ss.Context(), p)})
+	}
+}
+
+// authStream checks every message received on an authenticated stream.
+type authStream struct {
+	grpc.ServerStream
+	authn     *auth.Authenticator // Checks the messages
+	principal *auth.Principal     // Caller that opened the stream
+	ctx       context.Context     // Stream context carrying the principal
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return authorize(s.authn, s.principal, m)
+}
+
+// QueueResource names the queue of a WebSocket request: the {queue} path segment,
+// or the default queue. Subscription queues are named by their topic.
+func QueueResource(r *http.Request) string {
+	return queueResource(r.PathValue("queue"))
+}
+
+// TopicResource names the topic of a WebSocket request from its {topic} path segment.
+func TopicResource(r *http.Request) string {
+	return r.PathValue("topic")
+}
+
+// RegistryResource names the whole registry, for admin endpoints that act on every queue.
+func RegistryResource(*http.Request) string {
+	return AllResources
+}
+
+// RequirePermission wraps an HTTP or WebSocket handler so that it only runs for
+// clients that authenticate with a and may perm the resource named by resource.
+// Other clients get 401 Unauthorized or 403 Forbidden before any WebSocket
+// upgrade. A nil a returns next unchanged.
+func RequirePermission(a *auth.Authenticator, perm auth.Permission, resource func(*http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	perms := []auth.Permission{perm}
+	return requirePermissions(a, func(*http.Request) []auth.Permission { return perms }, resource, next)
+}
+
+// RequireSubscribePermission wraps a WebSocket topic subscribe handler like
+// RequirePermission. It needs consume on the topic, and admin as well when the
+// client names a durable subscription, which the connection may create and remove.
+func RequireSubscribePermission(a *auth.Authenticator, next http.HandlerFunc) http.HandlerFunc {
+	return requirePermissions(a, subscribeAccess, TopicResource, next)
+}
+
+// subscribeAccess returns the permissions a WebSocket topic subscribe needs.
+func subscribeAccess(r *http.Request) []auth.Permission {
+	if r.URL.Query().Get("subscription") != "" {
+		return needConsumeAdmin
+	}
+	return needConsume
+}
+
+// requirePermissions wraps next so that it only runs for clients that
+// authenticate with a and have every permission perms returns on the resource.
+func requirePermissions(a *auth.Authenticator, perms func(*http.Request) []auth.Permission, resource func(*http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	if a == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(httpCredential(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		name := resource(r)
+		for _, perm := range perms(r) {
+			if !a.Allowed(p, perm, name) {
+				http.Error(w, denied(p, perm, name), http.StatusForbidden)
+				return
+			}
+		}
+		next(w, r.WithContext(auth.NewContext(r.Context(), p)))
+	}
+}
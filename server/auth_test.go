@@ -0,0 +1,131 @@
+// auth_test.go - Tests for the permissions requests need.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"quickpulse/auth"
+	"quickpulse/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTestAuthenticator returns an authenticator with a consume-only key and an admin key.
+func newTestAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	a, err := auth.New(auth.Options{
+		APIKeys: map[string]string{"k-reader": "reader", "k-ops": "ops"},
+		Rules: []auth.Rule{
+			{Subject: "reader", Resources: []string{"*"}, Permissions: []auth.Permission{auth.Consume}},
+			{Subject: "ops", Resources: []string{"*"}, Permissions: []auth.Permission{auth.Consume, auth.Admin}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestSubscriptionsNeedAdmin(t *testing.T) {
+	interceptor := UnaryAuthInterceptor(newTestAuthenticator(t))
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	call := func(key string, req any) codes.Code {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{}, handler)
+		return status.Code(err)
+	}
+	for _, req := range []any{
+		&proto.SubscribeRequest{Topic: "events", Subscription: "billing"},
+		&proto.UnsubscribeRequest{Topic: "events", Subscription: "billing"},
+	} {
+		if code := call("k-reader", req); code != codes.PermissionDenied {
+			t.Errorf("%T with a consume-only key = %v, want PermissionDenied", req, code)
+		}
+		if code := call("k-ops", req); code != codes.OK {
+			t.Errorf("%T with an admin key = %v, want OK", req, code)
+		}
+	}
+	// Consuming from a subscription still only needs consume
+	if code := call("k-reader", &proto.ConsumeRequest{Queue: "events:billing"}); code != codes.OK {
+		t.Errorf("Consume with a consume-only key = %v, want OK", code)
+	}
+
+	// Over WebSocket, only named subscriptions need admin
+	subscribe := RequireSubscribePermission(newTestAuthenticator(t), func(http.ResponseWriter, *http.Request) {})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws/topics/{topic}/subscribe", subscribe)
+	for _, tc := range []struct {
+		key, query string
+		want       int
+	}{
+		{"k-reader", "", http.StatusOK},
+		{"k-reader", "?subscription=billing", http.StatusForbidden},
+		{"k-ops", "?subscription=billing", http.StatusOK},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/ws/topics/events/subscribe"+tc.query, nil)
+		r.Header.Set("X-API-Key", tc.key)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("subscribe%s with %s = %d, want %d", tc.query, tc.key, w.Code, tc.want)
+		}
+	}
+}
+
+func TestSubscriptionQueuesUseTopicPermission(t *testing.T) {
+	a, err := auth.New(auth.Options{
+		APIKeys: map[string]string{"k-events": "events-reader"},
+		Rules:   []auth.Rule{{Subject: "events-reader", Resources: []string{"events"}, Permissions: []auth.Permission{auth.Consume}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Over gRPC, consume on the topic covers its subscription queues
+	interceptor := UnaryAuthInterceptor(a)
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k-events"))
+	for _, tc := range []struct {
+		req  any
+		want codes.Code
+	}{
+		{&proto.ConsumeRequest{Queue: "events:billing"}, codes.OK},
+		{&proto.AckRequest{Queue: "events:billing"}, codes.OK},
+		{&proto.NackRequest{Queue: "events:billing"}, codes.OK},
+		{&proto.ConsumeRequest{Queue: "orders:billing"}, codes.PermissionDenied},
+		{&proto.ConsumeRequest{Queue: "orders"}, codes.PermissionDenied},
+	} {
+		if _, err := interceptor(ctx, tc.req, &grpc.UnaryServerInfo{}, handler); status.Code(err) != tc.want {
+			t.Errorf("%T %v = %v, want %v", tc.req, tc.req, status.Code(err), tc.want)
+		}
+	}
+
+	// Over WebSocket, consuming the subscription queue and subscribing to the topic agree
+	next := func(http.ResponseWriter, *http.Request) {}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws/consume/{queue}", RequirePermission(a, auth.Consume, QueueResource, next))
+	mux.HandleFunc("/ws/topics/{topic}/subscribe", RequireSubscribePermission(a, next))
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"/ws/consume/events:billing", http.StatusOK},
+		{"/ws/topics/events/subscribe", http.StatusOK},
+		{"/ws/consume/orders:billing", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Header.Set("X-API-Key", "k-events")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s = %d, want %d", tc.path, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// envelope.go - Conversions between mq.Message and the wire formats.
+//
+// This file builds mq.Message values from producer requests, assigning the
+// server-side message ID, and renders a message's envelope (ID, headers,
+// content type, priority, partition key and enqueue timestamp) for gRPC responses
+// and for WebSocket clients that use the JSON message format.
+
+package server
+
+import (
+	"encoding/json" // For the WebSocket JSON message format
+	"net/http"      // For reading the requested WebSocket format
+	"strconv"       // For parsing publish options
+	"time"          // For envelope timestamps
+
+	"quickpulse/mq"    // Message type
+	"quickpulse/proto" // gRPC protobuf definitions
+
+	"google.golang.org/protobuf/types/known/timestamppb" // For envelope timestamps
+)
+
+// newMessage builds a message with a fresh server-assigned ID from producer input.
+// A ttl of zero means the message does not expire on its own.
+func newMessage(payload []byte, headers map[string]string, contentType string, ttl time.Duration) *mq.Message {
+	msg := mq.NewMessage(mq.NewID(), payload)
+	msg.SetHeaders(headers)
+	msg.SetContentType(contentType)
+	msg.SetTTL(ttl)
+	return msg
+}
+
+// envelopeProto returns the gRPC envelope describing msg.
+func envelopeProto(msg *mq.Message) *proto.Envelope {
+	env := &proto.Envelope{
+		Id:              msg.GetID(),
+		Headers:         msg.GetHeaders(),
+		ContentType:     msg.GetContentType(),
+		EnqueuedAt:      timestamppb.New(msg.GetTimestamp()),
+		DeliveryAttempt: uint32(msg.GetDeliveryAttempt()),
+		Priority:        uint32(msg.GetPriority()),
+		Key:             msg.GetKey(),
+	}
+	if exp := msg.GetExpiresAt(); !exp.IsZero() {
+		env.ExpiresAt = timestamppb.New(exp)
+	}
+	return env
+}
+
+// wsFormatJSON is the value of the "format" query parameter that selects JSON frames.
+const wsFormatJSON = "json"
+
+// wantsJSON reports whether a WebSocket client asked for JSON frames (?format=json).
+// Other clients exchange raw payloads, as before envelopes existed.
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == wsFormatJSON
+}
+
+// wsMessage is the JSON form of a message on WebSocket connections using ?format=json.
+// Producers set Payload, Headers, ContentType, optionally Priority, Key and TTLMs and
+// optionally one of DelayMs and DeliverAt; the server fills in ID, Timestamp, ExpiresAt
+// and Attempt on consumed messages, and LeaseDeadline on leased ones. Payload is
+// base64-encoded in JSON.
+type wsMessage struct {
+	ID            string            `json:"id,omitempty"`
+	Payload       []byte            `json:"payload"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	ContentType   string            `json:"content_type,omitempty"`
+	Timestamp     *time.Time        `json:"timestamp,omitempty"`
+	Attempt       int               `json:"attempt,omitempty"`
+	LeaseDeadline *time.Time        `json:"lease_deadline,omitempty"`
+	DelayMs       int64             `json:"delay_ms,omitempty"`
+	DeliverAt     *time.Time        `json:"deliver_at,omitempty"`
+	TTLMs         int64             `json:"ttl_ms,omitempty"`
+	ExpiresAt     *time.Time        `json:"expires_at,omitempty"`
+	Priority      *int              `json:"priority,omitempty"`
+	Key           string            `json:"key,omitempty"`
+}
+
+// wsReply is the JSON reply to each message published with ?format=json and to
+// each ack or nack command of a leasing consumer.
+type wsReply struct {
+	Status string `json:"status"`          // "ok" or "error"
+	ID     string `json:"id,omitempty"`    // ID of the published, acked or nacked message
+	Error  string `json:"error,omitempty"` // Reason on failure
+}
+
+// decodeWsMessage parses a JSON publish frame into a message with a fresh ID and
+// the time it becomes ready (zero for now). defaults supplies the connection-level
+// delay, used if the frame sets neither delay_ms nor deliver_at, and TTL, used if
+// the frame sets no ttl_ms, priority, used if the frame sets no priority, and
+// partition key, used if the frame sets no key.
+func decodeWsMessage(data []byte, defaults wsPublishDefaults) (*mq.Message, time.Time, error) {
+	var in wsMessage
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, time.Time{}, err
+	}
+	var deliverAt time.Time
+	if in.DeliverAt != nil {
+		deliverAt = *in.DeliverAt
+	} else if in.DelayMs == 0 {
+		in.DelayMs = defaults.delayMs
+	}
+	if in.TTLMs == 0 {
+		in.TTLMs = defaults.ttlMs
+	}
+	at, err := deliveryTime(in.DelayMs, deliverAt)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	msg := newMessage(in.Payload, in.Headers, in.ContentType, time.Duration(in.TTLMs)*time.Millisecond)
+	if in.Priority != nil {
+		msg.SetPriority(*in.Priority)
+	} else {
+		msg.SetPriority(defaults.priority)
+	}
+	if in.Key == "" {
+		in.Key = defaults.key
+	}
+	msg.SetKey(in.Key)
+	return msg, at, nil
+}
+
+// Handshake headers that set defaults for a publisher's messages.
+const (
+	wsPriorityHeader = "X-Priority"      // Priority of the messages
+	wsKeyHeader      = "X-Partition-Key" // Partition key of the messages
+)
+
+// wsPublishDefaults holds the per-connection options a publisher sets in the URL
+// query or the handshake headers.
+type wsPublishDefaults struct {
+	delayMs  int64  // ?delay_ms=: delay for messages that set no delay or delivery time
+	ttlMs    int64  // ?ttl_ms=: TTL for messages that set none
+	priority int    // X-Priority header or ?priority=: priority for messages that set none
+	key      string // X-Partition-Key header or ?key=: partition key for messages that set none
+}
+
+// publishDefaults reads the per-connection publish options from the request.
+func publishDefaults(r *http.Request) wsPublishDefaults {
+	q := r.URL.Query()
+	delayMs, _ := strconv.ParseInt(q.Get("delay_ms"), 10, 64)
+	ttlMs, _ := strconv.ParseInt(q.Get("ttl_ms"), 10, 64)
+	priority := r.Header.Get(wsPriorityHeader)
+	if priority == "" {
+		priority = q.Get("priority")
+	}
+	p, _ := strconv.Atoi(priority)
+	key := r.Header.Get(wsKeyHeader)
+	if key == "" {
+		key = q.Get("key")
+	}
+	return wsPublishDefaults{delayMs: delayMs, ttlMs: ttlMs, priority: p, key: key}
+}
+
+// encodeWsMessage renders msg, envelope included, as a JSON frame.
+// leaseDeadline is the zero time for messages that are not leased.
+func encodeWsMessage(msg *mq.Message, leaseDeadline time.Time) ([]byte, error) {
+	ts := msg.GetTimestamp()
+	out := wsMessage{
+		ID:          msg.GetID(),
+		Payload:     msg.GetPayload(),
+		Headers:     msg.GetHeaders(),
+		ContentType: msg.GetContentType(),
+		Timestamp:   &ts,
+		Attempt:     msg.GetDeliveryAttempt(),
+		Key:         msg.GetKey(),
+	}
+	if exp := msg.GetExpiresAt(); !exp.IsZero() {
+		out.ExpiresAt = &exp
+	}
+	if p := msg.GetPriority(); p > 0 {
+		out.Priority = &p
+	}
+	if !leaseDeadline.IsZero() {
+		out.LeaseDeadline = &leaseDeadline
+	}
+	return json.Marshal(out)
+}
@@ -1,60 +1,276 @@
 // grpc_server.go - gRPC server implementations for the message queue.
 //
 // This file defines GrpcUnaryServer and GrpcStreamServer, which implement the
-// gRPC service for the message queue in unary and streaming modes, respectively.
-// The servers use a shared queue and provide methods for producing and consuming
-// messages via gRPC.
+// gRPC service for the message queue in unary and streaming modes, respectively,
+// and GrpcServer, which combines them to serve both from one gRPC server.
+// The servers address named queues in a shared registry and provide methods for
+// producing and consuming messages via gRPC. Produced messages are subject to the
+// servers' rate limits (throttle.go). While the registry drains for a shutdown,
+// producers are refused and the streaming server can end open streams. Failed
+// requests are reported with gRPC status codes and error details (status.go), or
+// in the deprecated response fields for API version 1 callers (version.go).
 
 package server
 
 import (
 	"context" // For gRPC context
+	"errors"  // For matching context errors
+	"strconv" // For error metadata
+	"strings" // For listing subscriptions in error metadata
+	"sync"    // For closing streams once
+	"time"    // For consume wait timeouts
 
-	"quickpulse/mq"    // Message queue interface
-	"quickpulse/proto" // gRPC protobuf definitions
+	"quickpulse/mq"        // Message queue interface
+	"quickpulse/proto"     // gRPC protobuf definitions
+	"quickpulse/ratelimit" // Rate limits on producers
 
 	"google.golang.org/grpc/codes"  // gRPC error codes
 	"google.golang.org/grpc/status" // gRPC status errors
+
+	"google.golang.org/protobuf/types/known/timestamppb" // For lease deadlines
 )
 
 // GrpcUnaryServer implements the gRPC MessageQueue service in unary mode.
 type GrpcUnaryServer struct {
 	proto.UnimplementedMessageQueueServer // Embeds unimplemented methods for forward compatibility
-	Queue mq.Queue                        // Underlying message queue
+	Queues *mq.Registry                   // Registry of named queues
+	Limits *ratelimit.Limiter             // Rate limits on producers; nil is unlimited
 }
 
 // GrpcStreamServer implements the gRPC MessageQueue service in streaming mode.
 type GrpcStreamServer struct {
 	proto.UnimplementedMessageQueueServer // Embeds unimplemented methods for forward compatibility
-	Queue mq.Queue                        // Underlying message queue
+	Queues *mq.Registry                   // Registry of named queues
+	Limits *ratelimit.Limiter             // Rate limits on producers; nil is unlimited
+
+	closing   chan struct{} // Closed by Shutdown to end open streams
+	closeOnce sync.Once     // Guards closing
+}
+
+// GrpcServer implements the whole gRPC MessageQueue service: the unary RPCs of
+// GrpcUnaryServer together with StreamMessages of GrpcStreamServer, all backed by
+// the same queue registry.
+type GrpcServer struct {
+	*GrpcUnaryServer                   // Unary RPCs
+	stream           *GrpcStreamServer // Bidirectional streaming
+}
+
+// NewGrpcServer creates a new GrpcServer backed by the given queue registry,
+// applying limits (which may be nil) to producers.
+func NewGrpcServer(queues *mq.Registry, limits *ratelimit.Limiter) *GrpcServer {
+	return &GrpcServer{GrpcUnaryServer: NewGrpcUnaryServer(queues, limits), stream: NewGrpcStreamServer(queues, limits)}
+}
+
+// StreamMessages handles bidirectional streaming like GrpcStreamServer.StreamMessages.
+func (s *GrpcServer) StreamMessages(stream proto.MessageQueue_StreamMessagesServer) error {
+	return s.stream.StreamMessages(stream)
+}
+
+// Shutdown ends every open stream (see GrpcStreamServer.Shutdown).
+func (s *GrpcServer) Shutdown() {
+	s.stream.Shutdown()
+}
+
+// NewGrpcUnaryServer creates a new GrpcUnaryServer backed by the given queue
+// registry, applying limits (which may be nil) to producers.
+func NewGrpcUnaryServer(queues *mq.Registry, limits *ratelimit.Limiter) *GrpcUnaryServer {
+	return &GrpcUnaryServer{Queues: queues, Limits: limits}
 }
 
-// NewGrpcUnaryServer creates a new GrpcUnaryServer with the given queue.
-func NewGrpcUnaryServer(queue mq.Queue) *GrpcUnaryServer {
-	return &GrpcUnaryServer{Queue: queue}
+// NewGrpcStreamServer creates a new GrpcStreamServer backed by the given queue
+// registry, applying limits (which may be nil) to producers.
+func NewGrpcStreamServer(queues *mq.Registry, limits *ratelimit.Limiter) *GrpcStreamServer {
+	return &GrpcStreamServer{Queues: queues, Limits: limits, closing: make(chan struct{})}
 }
 
-// NewGrpcStreamServer creates a new GrpcStreamServer with the given queue.
-func NewGrpcStreamServer(queue mq.Queue) *GrpcStreamServer {
-	return &GrpcStreamServer{Queue: queue}
+// Shutdown ends every open stream once the reply it is sending has gone out; the
+// streams fail with codes.Unavailable so clients know to reconnect elsewhere.
+// Call it before grpc.Server.GracefulStop, which otherwise waits for clients to
+// close their streams.
+func (s *GrpcStreamServer) Shutdown() {
+	s.closeOnce.Do(func() { close(s.closing) })
 }
 
-// Produce handles unary gRPC requests to enqueue a message.
+// Produce handles unary gRPC requests to enqueue a message, to publish it to every
+// subscription of a topic, or to append it to a log. A message with a delay or
+// delivery time is held until it is due. A topic produce can be limited to some of
+// the topic's subscriptions, so that retrying a partial failure is idempotent.
+// Failures are returned as status errors (status.go): a full queue or a message
+// beyond the rate limits fails with codes.ResourceExhausted, for example.
 func (s *GrpcUnaryServer) Produce(ctx context.Context, req *proto.ProduceRequest) (*proto.ProduceResponse, error) {
-	err := s.Queue.Enqueue(req.Payload)
+	targets := 0
+	for _, name := range []string{req.Queue, req.Topic, req.Log} {
+		if name != "" {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return nil, grpcError(errConflictingTarget, nil)
+	}
+	if len(req.Subscriptions) > 0 && req.Topic == "" {
+		return nil, grpcError(errSubscriptionsWithoutTopic, nil)
+	}
+	if !s.Queues.Producing() {
+		return nil, grpcError(mq.ErrDraining, nil)
+	}
+	destination, kind := queueName(req.Queue), "queue"
+	switch {
+	case req.Topic != "":
+		destination, kind = req.Topic, "topic"
+	case req.Log != "":
+		destination, kind = req.Log, "log"
+	}
+	metadata := map[string]string{kind: destination}
+	var queue *mq.NamedQueue
+	if req.Topic == "" && req.Log == "" {
+		var err error
+		if queue, err = lookupQueue(s.Queues, req.Queue); err != nil {
+			return nil, grpcError(err, metadata)
+		}
+	}
+	if err := throttleGrpc(s.Limits, ctx, destination, len(req.Payload)); err != nil {
+		return nil, err
+	}
+	var deliverAt time.Time
+	if req.DeliverAt != nil {
+		deliverAt = req.DeliverAt.AsTime()
+	}
+	at, err := deliveryTime(req.DelayMs, deliverAt)
 	if err != nil {
-		return &proto.ProduceResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err, metadata)
 	}
-	return &proto.ProduceResponse{Success: true}, nil
+	msg := newMessage(req.Payload, req.Headers, req.ContentType, time.Duration(req.TtlMs)*time.Millisecond)
+	msg.SetPriority(int(req.Priority))
+	msg.SetKey(req.Key)
+	if req.Log != "" {
+		l, err := s.Queues.GetLog(req.Log)
+		if err == nil && !at.IsZero() {
+			err = errLogDelay
+		}
+		if err != nil {
+			return nil, grpcError(err, metadata)
+		}
+		return &proto.ProduceResponse{Success: true, MessageId: msg.GetID(), Offset: l.Append(msg)}, nil
+	}
+	if req.Topic != "" {
+		pub, err := s.Queues.Publish(req.Topic, req.Subscriptions, msg, at)
+		if err != nil {
+			// Some subscriptions may have taken the message before one refused it; a retry
+			// naming the failed ones in subscriptions does not duplicate it in the others
+			metadata["deliveries"] = strconv.Itoa(len(pub.Delivered))
+			metadata["delivered_subscriptions"] = strings.Join(pub.Delivered, ",")
+			metadata["failed_subscriptions"] = strings.Join(pub.Failed, ",")
+			return nil, grpcError(err, metadata)
+		}
+		return &proto.ProduceResponse{Success: true, MessageId: msg.GetID(), Deliveries: uint32(len(pub.Delivered)), DeliveredSubscriptions: pub.Delivered}, nil
+	}
+	err = produce(queue, msg, at)
+	if err != nil {
+		return nil, grpcError(err, metadata)
+	}
+	return &proto.ProduceResponse{Success: true, MessageId: msg.GetID()}, nil
 }
 
 // Consume handles unary gRPC requests to dequeue a message.
+// If the request sets a wait timeout, the call parks until a message arrives,
+// the timeout elapses or the client goes away, instead of returning empty at once.
+// If the request asks for a lease, the message stays in flight until it is acked,
+// nacked or its visibility timeout expires. An empty queue fails with codes.NotFound,
+// and a partitioned queue, which only its members consume, with codes.FailedPrecondition.
 func (s *GrpcUnaryServer) Consume(ctx context.Context, req *proto.ConsumeRequest) (*proto.ConsumeResponse, error) {
-	msg, err := s.Queue.Dequeue()
+	metadata := map[string]string{"queue": queueName(req.Queue)}
+	queue, err := lookupQueue(s.Queues, req.Queue)
+	if err != nil {
+		return nil, grpcError(err, metadata)
+	}
+	if queue.Config().Partitions > 1 {
+		return nil, grpcError(errPartitionedConsume, metadata)
+	}
+	visibility := time.Duration(req.VisibilityTimeoutMs) * time.Millisecond
+	var lease *mq.Lease
+	if wait := time.Duration(req.WaitTimeoutMs) * time.Millisecond; wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		lease, err = receive(waitCtx, queue, nil, true, req.Lease, visibility)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			err = mq.ErrEmpty // Our own wait timed out, not the client's deadline
+		}
+	} else {
+		lease, err = receive(ctx, queue, nil, false, req.Lease, visibility)
+	}
+	if err != nil {
+		return nil, grpcError(err, metadata)
+	}
+	resp := &proto.ConsumeResponse{Payload: lease.Message.GetPayload(), Envelope: envelopeProto(lease.Message)}
+	if req.Lease {
+		resp.LeaseDeadline = timestamppb.New(lease.Deadline)
+	}
+	return resp, nil
+}
+
+// receive takes the next message from queue, or from the partitions of member if
+// it is not nil, leasing it if lease is set. If block is set it waits for a message
+// until ctx is done; otherwise it returns mq.ErrEmpty at once when there is none.
+// Unleased messages come back with a zero Deadline.
+func receive(ctx context.Context, queue *mq.NamedQueue, member *mq.PartitionConsumer, block, lease bool, visibility time.Duration) (*mq.Lease, error) {
+	if member != nil {
+		var msg *mq.Message
+		var err error
+		if !block {
+			msg, err = member.Dequeue()
+		} else {
+			msg, err = member.DequeueContext(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if lease {
+			return queue.Leases().Hold(msg, visibility), nil
+		}
+		return &mq.Lease{Message: msg}, nil
+	}
+	if lease {
+		if !block {
+			return queue.Leases().Dequeue(visibility)
+		}
+		return queue.Leases().DequeueContext(ctx, visibility)
+	}
+	var msg *mq.Message
+	var err error
+	if !block {
+		msg, err = queue.Dequeue()
+	} else {
+		msg, err = queue.DequeueContext(ctx)
+	}
 	if err != nil {
-		return &proto.ConsumeResponse{Payload: nil, Error: err.Error()}, nil
+		return nil, err
 	}
-	return &proto.ConsumeResponse{Payload: msg}, nil
+	return &mq.Lease{Message: msg}, nil
+}
+
+// Fetch handles requests to read the next messages of a log for a consumer group.
+func (s *GrpcUnaryServer) Fetch(ctx context.Context, req *proto.FetchRequest) (*proto.FetchResponse, error) {
+	return fetch(ctx, s.Queues, req)
+}
+
+// CommitOffset handles requests to commit a consumer group's offset.
+func (s *GrpcUnaryServer) CommitOffset(ctx context.Context, req *proto.CommitOffsetRequest) (*proto.CommitOffsetResponse, error) {
+	return commitOffset(s.Queues, req)
+}
+
+// RewindGroup handles requests to move a consumer group back to its committed offset.
+func (s *GrpcUnaryServer) RewindGroup(ctx context.Context, req *proto.RewindGroupRequest) (*proto.RewindGroupResponse, error) {
+	return rewindGroup(s.Queues, req)
+}
+
+// Ack handles requests to acknowledge a leased message.
+func (s *GrpcUnaryServer) Ack(ctx context.Context, req *proto.AckRequest) (*proto.AckResponse, error) {
+	return ackMessage(s.Queues, req)
+}
+
+// Nack handles requests to reject a leased message so that it is redelivered.
+func (s *GrpcUnaryServer) Nack(ctx context.Context, req *proto.NackRequest) (*proto.NackResponse, error) {
+	return nackMessage(s.Queues, req)
 }
 
 // StreamMessages is not implemented in unary mode and returns an error.
@@ -62,6 +278,76 @@ func (s *GrpcUnaryServer) StreamMessages(stream proto.MessageQueue_StreamMessage
 	return status.Errorf(codes.Unimplemented, "StreamMessages is not implemented in unary mode")
 }
 
+// CreateQueue handles requests to create a named queue.
+func (s *GrpcUnaryServer) CreateQueue(ctx context.Context, req *proto.CreateQueueRequest) (*proto.CreateQueueResponse, error) {
+	return createQueue(s.Queues, req)
+}
+
+// DeleteQueue handles requests to delete a named queue.
+func (s *GrpcUnaryServer) DeleteQueue(ctx context.Context, req *proto.DeleteQueueRequest) (*proto.DeleteQueueResponse, error) {
+	return deleteQueue(s.Queues, req)
+}
+
+// ListQueues handles requests to list all named queues.
+func (s *GrpcUnaryServer) ListQueues(ctx context.Context, req *proto.ListQueuesRequest) (*proto.ListQueuesResponse, error) {
+	return listQueues(s.Queues), nil
+}
+
+// InspectDeadLetters handles requests to list a queue's dead-letter queue.
+func (s *GrpcUnaryServer) InspectDeadLetters(ctx context.Context, req *proto.InspectDeadLettersRequest) (*proto.InspectDeadLettersResponse, error) {
+	return inspectDeadLetters(s.Queues, req)
+}
+
+// RequeueDeadLetters handles requests to move dead-lettered messages back to their queues.
+func (s *GrpcUnaryServer) RequeueDeadLetters(ctx context.Context, req *proto.RequeueDeadLettersRequest) (*proto.RequeueDeadLettersResponse, error) {
+	return requeueDeadLetters(s.Queues, req)
+}
+
+// PurgeDeadLetters handles requests to discard a queue's dead-lettered messages.
+func (s *GrpcUnaryServer) PurgeDeadLetters(ctx context.Context, req *proto.PurgeDeadLettersRequest) (*proto.PurgeDeadLettersResponse, error) {
+	return purgeDeadLetters(s.Queues, req)
+}
+
+// Subscribe handles requests to attach a subscription to a topic.
+func (s *GrpcUnaryServer) Subscribe(ctx context.Context, req *proto.SubscribeRequest) (*proto.SubscribeResponse, error) {
+	return subscribe(s.Queues, req)
+}
+
+// Unsubscribe handles requests to detach a subscription from its topic.
+func (s *GrpcUnaryServer) Unsubscribe(ctx context.Context, req *proto.UnsubscribeRequest) (*proto.UnsubscribeResponse, error) {
+	return unsubscribe(s.Queues, req)
+}
+
+// ListTopics handles requests to list all topics.
+func (s *GrpcUnaryServer) ListTopics(ctx context.Context, req *proto.ListTopicsRequest) (*proto.ListTopicsResponse, error) {
+	return listTopics(s.Queues), nil
+}
+
+// CreateLog handles requests to create a log.
+func (s *GrpcUnaryServer) CreateLog(ctx context.Context, req *proto.CreateLogRequest) (*proto.CreateLogResponse, error) {
+	return createLog(s.Queues, req)
+}
+
+// DeleteLog handles requests to delete a log.
+func (s *GrpcUnaryServer) DeleteLog(ctx context.Context, req *proto.DeleteLogRequest) (*proto.DeleteLogResponse, error) {
+	return deleteLog(s.Queues, req)
+}
+
+// ListLogs handles requests to list all logs.
+func (s *GrpcUnaryServer) ListLogs(ctx context.Context, req *proto.ListLogsRequest) (*proto.ListLogsResponse, error) {
+	return listLogs(s.Queues), nil
+}
+
+// Snapshot handles requests to write every queue to the snapshot file.
+func (s *GrpcUnaryServer) Snapshot(ctx context.Context, req *proto.SnapshotRequest) (*proto.SnapshotResponse, error) {
+	return snapshotQueues(s.Queues)
+}
+
+// Restore handles requests to load the snapshot file.
+func (s *GrpcUnaryServer) Restore(ctx context.Context, req *proto.RestoreRequest) (*proto.RestoreResponse, error) {
+	return restoreQueues(s.Queues)
+}
+
 // Produce is not implemented in streaming mode and returns an error.
 func (s *GrpcStreamServer) Produce(ctx context.Context, req *proto.ProduceRequest) (*proto.ProduceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "Produce is not implemented in streaming mode")
@@ -73,32 +359,207 @@ func (s *GrpcStreamServer) Consume(ctx context.Context, req *proto.ConsumeReques
 }
 
 // StreamMessages handles bidirectional streaming for producing and consuming messages.
+// On a partitioned queue the stream joins as a member the first time it uses the
+// queue and from then on only receives messages from its assigned partitions.
+// A request that fails gets a reply describing the failure in its status, and the
+// stream carries on; but a message beyond the rate limits ends the stream with
+// codes.ResourceExhausted.
 func (s *GrpcStreamServer) StreamMessages(stream proto.MessageQueue_StreamMessagesServer) error {
+	members := make(map[*mq.NamedQueue]*mq.PartitionConsumer)
+	defer func() {
+		for _, member := range members {
+			member.Leave()
+		}
+	}()
+	// A dedicated reader hands requests to the loop below, so the stream can also
+	// end between replies when the server shuts down.
+	requests := make(chan *proto.StreamMessage)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			// Receive a message from the client
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- in:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
 	for {
-		// Receive a message from the client
-		in, err := stream.Recv()
-		if err != nil {
+		var in *proto.StreamMessage
+		select {
+		case in = <-requests:
+		case err := <-recvErr:
 			// End of stream or error
 			return err
+		case <-s.closing:
+			return status.Error(codes.Unavailable, mq.ErrDraining.Error())
+		}
+		resp := &proto.StreamMessage{Queue: in.Queue}
+		queue, err := lookupQueue(s.Queues, in.Queue)
+		if err != nil {
+			streamError(resp, err)
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			continue
+		}
+		// Enqueue the received payload if present; once the server is draining
+		// producers are refused, but the stream can still consume
+		if in.Payload != nil && !s.Queues.Producing() {
+			streamError(resp, mq.ErrDraining)
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			continue
 		}
-		// Enqueue the received payload if present
 		if in.Payload != nil {
-			_ = s.Queue.Enqueue(in.Payload)
+			// A producer beyond the rate limits ends the stream, the only way to return a status code
+			if err := throttleGrpc(s.Limits, stream.Context(), queue.Name(), len(in.Payload)); err != nil {
+				return err
+			}
+			msg := newMessage(in.Payload, in.Headers, in.ContentType, 0)
+			msg.SetPriority(int(in.Priority))
+			msg.SetKey(in.Key)
+			if err := queue.Enqueue(msg); err != nil {
+				// The reply reports the refused message instead of consuming one
+				streamError(resp, err)
+				if err := stream.Send(resp); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		member := members[queue]
+		if member == nil && queue.Config().Partitions > 1 {
+			member, _ = queue.Join("grpc-" + mq.NewID())
+			members[queue] = member
 		}
 		// Dequeue a message to send back to the client
-		msg, err := s.Queue.Dequeue()
-		resp := &proto.StreamMessage{}
+		var msg *mq.Message
+		if member != nil {
+			msg, err = member.Dequeue()
+		} else {
+			msg, err = queue.Dequeue()
+		}
 		if err != nil {
-			resp.Error = err.Error()
-			resp.Payload = []byte{}
+			streamError(resp, err)
 		} else if msg == nil {
 			resp.Payload = []byte{}
 		} else {
-			resp.Payload = msg
+			resp.Payload = msg.GetPayload()
+			resp.Envelope = envelopeProto(msg)
 		}
 		// Send the response to the client
 		if err := stream.Send(resp); err != nil {
 			return err
 		}
 	}
-}
\ No newline at end of file
+}
+
+// streamError fills in resp as the reply to a stream request that failed with err.
+func streamError(resp *proto.StreamMessage, err error) {
+	resp.Error = err.Error() // Deprecated, still set for clients of API version 1
+	resp.Status = errorStatus(err)
+	resp.Payload = []byte{}
+}
+
+// Fetch handles requests to read the next messages of a log for a consumer group.
+func (s *GrpcStreamServer) Fetch(ctx context.Context, req *proto.FetchRequest) (*proto.FetchResponse, error) {
+	return fetch(ctx, s.Queues, req)
+}
+
+// CommitOffset handles requests to commit a consumer group's offset.
+func (s *GrpcStreamServer) CommitOffset(ctx context.Context, req *proto.CommitOffsetRequest) (*proto.CommitOffsetResponse, error) {
+	return commitOffset(s.Queues, req)
+}
+
+// RewindGroup handles requests to move a consumer group back to its committed offset.
+func (s *GrpcStreamServer) RewindGroup(ctx context.Context, req *proto.RewindGroupRequest) (*proto.RewindGroupResponse, error) {
+	return rewindGroup(s.Queues, req)
+}
+
+// Ack handles requests to acknowledge a leased message.
+func (s *GrpcStreamServer) Ack(ctx context.Context, req *proto.AckRequest) (*proto.AckResponse, error) {
+	return ackMessage(s.Queues, req)
+}
+
+// Nack handles requests to reject a leased message so that it is redelivered.
+func (s *GrpcStreamServer) Nack(ctx context.Context, req *proto.NackRequest) (*proto.NackResponse, error) {
+	return nackMessage(s.Queues, req)
+}
+
+// CreateQueue handles requests to create a named queue.
+func (s *GrpcStreamServer) CreateQueue(ctx context.Context, req *proto.CreateQueueRequest) (*proto.CreateQueueResponse, error) {
+	return createQueue(s.Queues, req)
+}
+
+// DeleteQueue handles requests to delete a named queue.
+func (s *GrpcStreamServer) DeleteQueue(ctx context.Context, req *proto.DeleteQueueRequest) (*proto.DeleteQueueResponse, error) {
+	return deleteQueue(s.Queues, req)
+}
+
+// ListQueues handles requests to list all named queues.
+func (s *GrpcStreamServer) ListQueues(ctx context.Context, req *proto.ListQueuesRequest) (*proto.ListQueuesResponse, error) {
+	return listQueues(s.Queues), nil
+}
+
+// InspectDeadLetters handles requests to list a queue's dead-letter queue.
+func (s *GrpcStreamServer) InspectDeadLetters(ctx context.Context, req *proto.InspectDeadLettersRequest) (*proto.InspectDeadLettersResponse, error) {
+	return inspectDeadLetters(s.Queues, req)
+}
+
+// RequeueDeadLetters handles requests to move dead-lettered messages back to their queues.
+func (s *GrpcStreamServer) RequeueDeadLetters(ctx context.Context, req *proto.RequeueDeadLettersRequest) (*proto.RequeueDeadLettersResponse, error) {
+	return requeueDeadLetters(s.Queues, req)
+}
+
+// PurgeDeadLetters handles requests to discard a queue's dead-lettered messages.
+func (s *GrpcStreamServer) PurgeDeadLetters(ctx context.Context, req *proto.PurgeDeadLettersRequest) (*proto.PurgeDeadLettersResponse, error) {
+	return purgeDeadLetters(s.Queues, req)
+}
+
+// Subscribe handles requests to attach a subscription to a topic.
+func (s *GrpcStreamServer) Subscribe(ctx context.Context, req *proto.SubscribeRequest) (*proto.SubscribeResponse, error) {
+	return subscribe(s.Queues, req)
+}
+
+// Unsubscribe handles requests to detach a subscription from its topic.
+func (s *GrpcStreamServer) Unsubscribe(ctx context.Context, req *proto.UnsubscribeRequest) (*proto.UnsubscribeResponse, error) {
+	return unsubscribe(s.Queues, req)
+}
+
+// ListTopics handles requests to list all topics.
+func (s *GrpcStreamServer) ListTopics(ctx context.Context, req *proto.ListTopicsRequest) (*proto.ListTopicsResponse, error) {
+	return listTopics(s.Queues), nil
+}
+
+// CreateLog handles requests to create a log.
+func (s *GrpcStreamServer) CreateLog(ctx context.Context, req *proto.CreateLogRequest) (*proto.CreateLogResponse, error) {
+	return createLog(s.Queues, req)
+}
+
+// DeleteLog handles requests to delete a log.
+func (s *GrpcStreamServer) DeleteLog(ctx context.Context, req *proto.DeleteLogRequest) (*proto.DeleteLogResponse, error) {
+	return deleteLog(s.Queues, req)
+}
+
+// ListLogs handles requests to list all logs.
+func (s *GrpcStreamServer) ListLogs(ctx context.Context, req *proto.ListLogsRequest) (*proto.ListLogsResponse, error) {
+	return listLogs(s.Queues), nil
+}
+
+// Snapshot handles requests to write every queue to the snapshot file.
+func (s *GrpcStreamServer) Snapshot(ctx context.Context, req *proto.SnapshotRequest) (*proto.SnapshotResponse, error) {
+	return snapshotQueues(s.Queues)
+}
+
+// Restore handles requests to load the snapshot file.
+func (s *GrpcStreamServer) Restore(ctx context.Context, req *proto.RestoreRequest) (*proto.RestoreResponse, error) {
+	return restoreQueues(s.Queues)
+}
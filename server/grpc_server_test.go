@@ -0,0 +1,38 @@
+// grpc_server_test.go - Tests for the gRPC servers.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"quickpulse/mq"
+	"quickpulse/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryConsumeRefusesPartitionedQueue(t *testing.T) {
+	registry := mq.NewRegistry(nil)
+	orders, err := registry.Create("orders", mq.QueueConfig{Capacity: 8, Partitions: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := mq.NewMessage(mq.NewID(), []byte("first"))
+	msg.SetKey("customer-1")
+	if err := orders.Enqueue(msg); err != nil {
+		t.Fatal(err)
+	}
+	s := NewGrpcUnaryServer(registry, nil)
+	// Unary consumers are not members, so they could take several messages of one key at once
+	for _, lease := range []bool{false, true} {
+		_, err := s.Consume(context.Background(), &proto.ConsumeRequest{Queue: "orders", Lease: lease})
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("Consume(lease %v) = %v, want FailedPrecondition", lease, err)
+		}
+	}
+	if orders.Len() != 1 {
+		t.Fatalf("refused Consume took a message: Len() = %d", orders.Len())
+	}
+}
@@ -0,0 +1,108 @@
+// logs.go - Log administration and consumer group reads shared by the gRPC servers.
+//
+// This file implements appending to logs and the log operations exposed over
+// gRPC: creating, deleting and listing logs, fetching messages for a consumer
+// group, committing a group's offset and rewinding a group to it.
+
+package server
+
+import (
+	"context" // For waiting fetches
+	"errors"  // For request validation errors
+	"time"    // For fetch wait timeouts
+
+	"quickpulse/mq"    // Log registry
+	"quickpulse/proto" // gRPC protobuf definitions
+)
+
+// errLogDelay is returned when a producer asks for delayed delivery to a log.
+var errLogDelay = errors.New("delayed delivery is not supported for logs")
+
+// createLog creates a log as requested.
+func createLog(queues *mq.Registry, req *proto.CreateLogRequest) (*proto.CreateLogResponse, error) {
+	cfg := mq.LogConfig{SegmentSize: int(req.SegmentSize), Retention: req.Retention}
+	if _, err := queues.CreateLog(req.Name, cfg); err != nil {
+		return nil, grpcError(err, map[string]string{"log": req.Name})
+	}
+	return &proto.CreateLogResponse{Success: true}, nil
+}
+
+// deleteLog deletes a log as requested.
+func deleteLog(queues *mq.Registry, req *proto.DeleteLogRequest) (*proto.DeleteLogResponse, error) {
+	if err := queues.DeleteLog(req.Name); err != nil {
+		return nil, grpcError(err, map[string]string{"log": req.Name})
+	}
+	return &proto.DeleteLogResponse{Success: true}, nil
+}
+
+// listLogs summarizes every log in the registry with its consumer groups.
+func listLogs(queues *mq.Registry) *proto.ListLogsResponse {
+	infos := queues.Logs()
+	resp := &proto.ListLogsResponse{Logs: make([]*proto.LogInfo, 0, len(infos))}
+	for _, info := range infos {
+		li := &proto.LogInfo{
+			Name:        info.Name,
+			StartOffset: info.Start,
+			EndOffset:   info.End,
+			Groups:      make([]*proto.ConsumerGroupInfo, 0, len(info.Groups)),
+		}
+		for _, g := range info.Groups {
+			li.Groups = append(li.Groups, &proto.ConsumerGroupInfo{
+				Name:            g.Name,
+				CommittedOffset: g.Committed,
+				Position:        g.Position,
+				Lag:             g.Lag,
+			})
+		}
+		resp.Logs = append(resp.Logs, li)
+	}
+	return resp
+}
+
+// fetch reads the next messages of a log for a consumer group. If the request sets
+// a wait timeout and the group has read everything, it waits for new messages until
+// the timeout elapses or ctx is done, and then returns no records.
+func fetch(ctx context.Context, queues *mq.Registry, req *proto.FetchRequest) (*proto.FetchResponse, error) {
+	l, err := queues.GetLog(req.Log)
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"log": req.Log})
+	}
+	var records []mq.Record
+	if wait := time.Duration(req.WaitTimeoutMs) * time.Millisecond; wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, wait)
+		records, _ = l.FetchContext(waitCtx, req.Group, int(req.MaxMessages))
+		cancel()
+	} else {
+		records = l.Fetch(req.Group, int(req.MaxMessages))
+	}
+	resp := &proto.FetchResponse{Records: make([]*proto.LogRecord, 0, len(records))}
+	for _, rec := range records {
+		resp.Records = append(resp.Records, &proto.LogRecord{
+			Offset:   rec.Offset,
+			Payload:  rec.Message.GetPayload(),
+			Envelope: envelopeProto(rec.Message),
+		})
+	}
+	return resp, nil
+}
+
+// commitOffset commits a consumer group's offset as requested.
+func commitOffset(queues *mq.Registry, req *proto.CommitOffsetRequest) (*proto.CommitOffsetResponse, error) {
+	l, err := queues.GetLog(req.Log)
+	if err == nil {
+		err = l.Commit(req.Group, req.Offset)
+	}
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"log": req.Log, "group": req.Group})
+	}
+	return &proto.CommitOffsetResponse{Success: true}, nil
+}
+
+// rewindGroup moves a consumer group back to its committed offset as requested.
+func rewindGroup(queues *mq.Registry, req *proto.RewindGroupRequest) (*proto.RewindGroupResponse, error) {
+	l, err := queues.GetLog(req.Log)
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"log": req.Log, "group": req.Group})
+	}
+	return &proto.RewindGroupResponse{Position: l.Rewind(req.Group)}, nil
+}
@@ -0,0 +1,233 @@
+// queues.go - Queue lookup and administration shared by all servers.
+//
+// This file resolves queue names from client requests against the registry and
+// implements producing with an optional delay, as well as the queue
+// administration, dead-letter administration, topic subscription and
+// acknowledgement operations exposed over gRPC, which report failures as gRPC
+// status errors (status.go).
+
+package server
+
+import (
+	"errors"  // For request validation errors
+	"strconv" // For error metadata
+	"time"    // For visibility timeouts and delivery times
+
+	"quickpulse/mq"    // Queue registry
+	"quickpulse/proto" // gRPC protobuf definitions
+
+	"google.golang.org/protobuf/types/known/timestamppb" // For dead-letter timestamps
+)
+
+// lookupQueue returns the named queue from the registry. An empty name selects
+// mq.DefaultQueueName, so clients that predate named queues keep working.
+func lookupQueue(queues *mq.Registry, name string) (*mq.NamedQueue, error) {
+	return queues.Get(queueName(name))
+}
+
+// queueName maps the empty name used by older clients to mq.DefaultQueueName.
+func queueName(name string) string {
+	if name == "" {
+		return mq.DefaultQueueName
+	}
+	return name
+}
+
+// errConflictingDelay is returned when a producer sets both a delay and a delivery time.
+var errConflictingDelay = errors.New("set at most one of delay_ms and deliver_at")
+
+// errConflictingTarget is returned when a producer names more than one of a queue, a topic and a log.
+var errConflictingTarget = errors.New("set at most one of queue, topic and log")
+
+// errPartitionedConsume is returned when a unary Consume names a partitioned
+// queue. Only members, which hold partitions, may consume from one, or the
+// messages of a key could be processed by several consumers at once.
+var errPartitionedConsume = errors.New("partitioned queues are consumed over StreamMessages or WebSocket, which join as members")
+
+// errSubscriptionsWithoutTopic is returned when a producer names subscriptions without a topic.
+var errSubscriptionsWithoutTopic = errors.New("subscriptions can only be set together with topic")
+
+// deliveryTime resolves a producer's delay or absolute delivery time (either may be
+// zero) to the time the message becomes ready. The zero time means deliver now.
+func deliveryTime(delayMs int64, deliverAt time.Time) (time.Time, error) {
+	switch {
+	case delayMs != 0 && !deliverAt.IsZero():
+		return time.Time{}, errConflictingDelay
+	case delayMs > 0:
+		return time.Now().Add(time.Duration(delayMs) * time.Millisecond), nil
+	default:
+		return deliverAt, nil
+	}
+}
+
+// produce enqueues msg, or holds it in the queue's scheduler until at if at is set.
+func produce(queue *mq.NamedQueue, msg *mq.Message, at time.Time) error {
+	if at.IsZero() {
+		return queue.Enqueue(msg)
+	}
+	return queue.Scheduler().Schedule(msg, at)
+}
+
+// createQueue creates a named queue as requested.
+func createQueue(queues *mq.Registry, req *proto.CreateQueueRequest) (*proto.CreateQueueResponse, error) {
+	cfg := mq.QueueConfig{
+		Capacity:            req.Capacity,
+		MaxBytes:            req.MaxBytes,
+		VisibilityTimeout:   time.Duration(req.VisibilityTimeoutMs) * time.Millisecond,
+		MaxDeliveryAttempts: int(req.MaxDeliveryAttempts),
+		DeadLetterQueue:     req.DeadLetterQueue,
+		MaxAge:              time.Duration(req.MaxAgeMs) * time.Millisecond,
+		Overflow:            mq.OverflowPolicy(req.OverflowPolicy),
+		BlockTimeout:        time.Duration(req.BlockTimeoutMs) * time.Millisecond,
+		PriorityLevels:      int(req.PriorityLevels),
+		PriorityMode:        mq.PriorityMode(req.PriorityMode),
+		Partitions:          int(req.Partitions),
+		Durable:             req.Durable,
+	}
+	if _, err := queues.Create(req.Name, cfg); err != nil {
+		return nil, grpcError(err, map[string]string{"queue": req.Name})
+	}
+	return &proto.CreateQueueResponse{Success: true}, nil
+}
+
+// deleteQueue deletes a named queue as requested.
+func deleteQueue(queues *mq.Registry, req *proto.DeleteQueueRequest) (*proto.DeleteQueueResponse, error) {
+	if err := queues.Delete(req.Name); err != nil {
+		return nil, grpcError(err, map[string]string{"queue": req.Name})
+	}
+	return &proto.DeleteQueueResponse{Success: true}, nil
+}
+
+// listQueues summarizes every queue in the registry.
+func listQueues(queues *mq.Registry) *proto.ListQueuesResponse {
+	infos := queues.List()
+	resp := &proto.ListQueuesResponse{Queues: make([]*proto.QueueInfo, 0, len(infos))}
+	for _, info := range infos {
+		assignments := make([]*proto.PartitionAssignment, 0, len(info.Assignments))
+		for _, a := range info.Assignments {
+			partitions := make([]uint32, len(a.Partitions))
+			for i, p := range a.Partitions {
+				partitions[i] = uint32(p)
+			}
+			assignments = append(assignments, &proto.PartitionAssignment{Consumer: a.Consumer, Partitions: partitions})
+		}
+		resp.Queues = append(resp.Queues, &proto.QueueInfo{
+			Name:                info.Name,
+			Capacity:            info.Capacity,
+			Length:              info.Len,
+			MaxBytes:            info.MaxBytes,
+			Bytes:               info.Bytes,
+			InFlight:            info.InFlight,
+			MaxDeliveryAttempts: uint32(info.MaxDeliveryAttempts),
+			DeadLetterQueue:     info.DeadLetterQueue,
+			Scheduled:           info.Scheduled,
+			MaxAgeMs:            info.MaxAge.Milliseconds(),
+			OverflowPolicy:      string(info.Overflow),
+			PriorityLevels:      uint32(info.PriorityLevels),
+			PriorityMode:        string(info.PriorityMode),
+			Topic:               info.Topic,
+			Partitions:          uint32(info.Partitions),
+			Assignments:         assignments,
+			Durable:             info.Durable,
+		})
+	}
+	return resp
+}
+
+// ackMessage acknowledges a leased message as requested.
+func ackMessage(queues *mq.Registry, req *proto.AckRequest) (*proto.AckResponse, error) {
+	queue, err := lookupQueue(queues, req.Queue)
+	if err == nil {
+		err = queue.Leases().Ack(req.MessageId)
+	}
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queue": queueName(req.Queue), "message_id": req.MessageId})
+	}
+	return &proto.AckResponse{Success: true}, nil
+}
+
+// nackMessage rejects a leased message as requested, redelivering it.
+func nackMessage(queues *mq.Registry, req *proto.NackRequest) (*proto.NackResponse, error) {
+	queue, err := lookupQueue(queues, req.Queue)
+	if err == nil {
+		err = queue.Leases().Nack(req.MessageId)
+	}
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queue": queueName(req.Queue), "message_id": req.MessageId})
+	}
+	return &proto.NackResponse{Success: true}, nil
+}
+
+// inspectDeadLetters lists the dead-letter queue of the requested queue.
+func inspectDeadLetters(queues *mq.Registry, req *proto.InspectDeadLettersRequest) (*proto.InspectDeadLettersResponse, error) {
+	msgs, err := queues.InspectDeadLetters(queueName(req.Queue), int(req.Limit))
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queue": queueName(req.Queue)})
+	}
+	resp := &proto.InspectDeadLettersResponse{Messages: make([]*proto.DeadLetter, 0, len(msgs))}
+	for _, msg := range msgs {
+		dl := &proto.DeadLetter{Payload: msg.GetPayload(), Envelope: envelopeProto(msg)}
+		if info := msg.GetDeadLetter(); info != nil {
+			dl.SourceQueue = info.Queue
+			dl.Reason = info.Reason
+			dl.DeadLetteredAt = timestamppb.New(info.At)
+		}
+		for _, a := range msg.GetAttempts() {
+			dl.Attempts = append(dl.Attempts, &proto.DeliveryAttempt{DeliveredAt: timestamppb.New(a.DeliveredAt), Reason: a.Reason})
+		}
+		resp.Messages = append(resp.Messages, dl)
+	}
+	return resp, nil
+}
+
+// requeueDeadLetters moves messages from the dead-letter queue of the requested
+// queue back. If it fails part way, the error reports how many were moved.
+func requeueDeadLetters(queues *mq.Registry, req *proto.RequeueDeadLettersRequest) (*proto.RequeueDeadLettersResponse, error) {
+	n, err := queues.RequeueDeadLetters(queueName(req.Queue), int(req.Limit))
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queue": queueName(req.Queue), "requeued": strconv.Itoa(n)})
+	}
+	return &proto.RequeueDeadLettersResponse{Success: true, Requeued: uint64(n)}, nil
+}
+
+// purgeDeadLetters empties the dead-letter queue of the requested queue.
+func purgeDeadLetters(queues *mq.Registry, req *proto.PurgeDeadLettersRequest) (*proto.PurgeDeadLettersResponse, error) {
+	n, err := queues.PurgeDeadLetters(queueName(req.Queue))
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"queue": queueName(req.Queue)})
+	}
+	return &proto.PurgeDeadLettersResponse{Success: true, Purged: uint64(n)}, nil
+}
+
+// subscribe attaches a subscription to a topic as requested.
+func subscribe(queues *mq.Registry, req *proto.SubscribeRequest) (*proto.SubscribeResponse, error) {
+	cfg := mq.QueueConfig{
+		Capacity:            req.Capacity,
+		VisibilityTimeout:   time.Duration(req.VisibilityTimeoutMs) * time.Millisecond,
+		MaxDeliveryAttempts: int(req.MaxDeliveryAttempts),
+		MaxAge:              time.Duration(req.MaxAgeMs) * time.Millisecond,
+	}
+	nq, err := queues.Subscribe(req.Topic, req.Subscription, cfg)
+	if err != nil {
+		return nil, grpcError(err, map[string]string{"topic": req.Topic, "subscription": req.Subscription})
+	}
+	return &proto.SubscribeResponse{Success: true, Queue: nq.Name()}, nil
+}
+
+// unsubscribe detaches a subscription from its topic as requested.
+func unsubscribe(queues *mq.Registry, req *proto.UnsubscribeRequest) (*proto.UnsubscribeResponse, error) {
+	if err := queues.Unsubscribe(req.Topic, req.Subscription); err != nil {
+		return nil, grpcError(err, map[string]string{"topic": req.Topic, "subscription": req.Subscription})
+	}
+	return &proto.UnsubscribeResponse{Success: true}, nil
+}
+
+// listTopics summarizes every topic in the registry.
+func listTopics(queues *mq.Registry) *proto.ListTopicsResponse {
+	infos := queues.Topics()
+	resp := &proto.ListTopicsResponse{Topics: make([]*proto.TopicInfo, 0, len(infos))}
+	for _, info := range infos {
+		resp.Topics = append(resp.Topics, &proto.TopicInfo{Name: info.Name, Subscriptions: info.Subscriptions})
+	}
+	return resp
+}
@@ -0,0 +1,141 @@
+// status.go - gRPC status codes and error details for failed requests.
+//
+// This file maps the errors of package mq, of the rate limiter and of the servers
+// themselves to gRPC status codes, so clients can tell a full queue from an
+// unknown one without matching error strings. Every status carries a
+// google.rpc.ErrorInfo with a reason that never changes, and failures that clear
+// up by themselves (a full queue, an exhausted memory budget, a rate limit, a
+// shutdown) also carry a google.rpc.RetryInfo saying how long to wait. Replies on
+// a stream, which carries on after a failed request, describe the failure the
+// same way in an ErrorStatus. These are the errors of API version 2; callers of
+// version 1 get them as responses instead (version.go).
+
+package server
+
+import (
+	"context" // For canceled and timed-out requests
+	"errors"  // For matching errors
+	"time"    // For retry delays
+
+	"quickpulse/mq"        // Queue errors
+	"quickpulse/proto"     // gRPC protobuf definitions
+	"quickpulse/ratelimit" // Rate limit errors
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails" // ErrorInfo, RetryInfo and QuotaFailure
+	"google.golang.org/grpc/codes"                         // gRPC error codes
+	"google.golang.org/grpc/status"                        // gRPC status errors
+
+	"google.golang.org/protobuf/protoadapt"             // For status details
+	"google.golang.org/protobuf/types/known/durationpb" // For retry delays
+)
+
+// errorDomain is the domain of every ErrorInfo the servers send.
+const errorDomain = "quickpulse"
+
+// Retry delays suggested for failures that clear up by themselves.
+const (
+	fullRetryDelay     = 100 * time.Millisecond // Until consumers make room in a queue or the memory budget
+	shutdownRetryDelay = time.Second            // Until the client reconnects to a server that is not shutting down
+)
+
+// failure describes how an error is reported to gRPC clients.
+type failure struct {
+	err    error         // Matched with errors.Is
+	code   codes.Code    // Status code
+	reason string        // ErrorInfo reason
+	retry  time.Duration // RetryInfo delay; zero sends no RetryInfo
+}
+
+// failures lists the errors with their own status codes and reasons.
+var failures = []failure{
+	{mq.ErrFull, codes.ResourceExhausted, "QUEUE_FULL", fullRetryDelay},
+	{mq.ErrMemoryBudget, codes.ResourceExhausted, "MEMORY_BUDGET_EXHAUSTED", fullRetryDelay},
+	{mq.ErrTooLarge, codes.ResourceExhausted, "MESSAGE_TOO_LARGE", 0},
+	{ratelimit.ErrRateLimited, codes.ResourceExhausted, "RATE_LIMITED", 0}, // The delay comes from the error
+	{ratelimit.ErrOverBurst, codes.InvalidArgument, "LARGER_THAN_BURST", 0},
+	{mq.ErrEmpty, codes.NotFound, "QUEUE_EMPTY", 0},
+	{mq.ErrQueueNotFound, codes.NotFound, "QUEUE_NOT_FOUND", 0},
+	{mq.ErrTopicNotFound, codes.NotFound, "TOPIC_NOT_FOUND", 0},
+	{mq.ErrSubscriptionNotFound, codes.NotFound, "SUBSCRIPTION_NOT_FOUND", 0},
+	{mq.ErrLogNotFound, codes.NotFound, "LOG_NOT_FOUND", 0},
+	{mq.ErrLeaseNotFound, codes.NotFound, "LEASE_NOT_FOUND", 0},
+	{mq.ErrQueueExists, codes.AlreadyExists, "QUEUE_EXISTS", 0},
+	{mq.ErrLogExists, codes.AlreadyExists, "LOG_EXISTS", 0},
+	{mq.ErrInvalidOffset, codes.OutOfRange, "INVALID_OFFSET", 0},
+	{mq.ErrInvalidQueueName, codes.InvalidArgument, "INVALID_NAME", 0},
+	{mq.ErrInvalidCapacity, codes.InvalidArgument, "INVALID_CAPACITY", 0},
+	{mq.ErrInvalidDeadLetter, codes.InvalidArgument, "INVALID_DEAD_LETTER_QUEUE", 0},
+	{mq.ErrInvalidOverflowPolicy, codes.InvalidArgument, "INVALID_OVERFLOW_POLICY", 0},
+	{mq.ErrInvalidPriority, codes.InvalidArgument, "INVALID_PRIORITY", 0},
+	{mq.ErrInvalidPartitions, codes.InvalidArgument, "INVALID_PARTITIONS", 0},
+	{mq.ErrInvalidDurability, codes.InvalidArgument, "INVALID_DURABILITY", 0},
+	{errConflictingTarget, codes.InvalidArgument, "CONFLICTING_TARGET", 0},
+	{errConflictingDelay, codes.InvalidArgument, "CONFLICTING_DELAY", 0},
+	{errSubscriptionsWithoutTopic, codes.InvalidArgument, "SUBSCRIPTIONS_WITHOUT_TOPIC", 0},
+	{errLogDelay, codes.InvalidArgument, "LOG_DELAY_UNSUPPORTED", 0},
+	{errAPIVersion, codes.InvalidArgument, "UNSUPPORTED_API_VERSION", 0},
+	{mq.ErrNoDeadLetterQueue, codes.FailedPrecondition, "NO_DEAD_LETTER_QUEUE", 0},
+	{mq.ErrQueueInUse, codes.FailedPrecondition, "QUEUE_IN_USE", 0},
+	{mq.ErrNotPartitioned, codes.FailedPrecondition, "NOT_PARTITIONED", 0},
+	{errPartitionedConsume, codes.FailedPrecondition, "PARTITIONED_QUEUE", 0},
+	{mq.ErrDurabilityDisabled, codes.FailedPrecondition, "DURABILITY_DISABLED", 0},
+	{mq.ErrSnapshotsDisabled, codes.FailedPrecondition, "SNAPSHOTS_DISABLED", 0},
+	{mq.ErrSnapshotVersion, codes.FailedPrecondition, "SNAPSHOT_VERSION_UNSUPPORTED", 0},
+	{mq.ErrQueueNotEmpty, codes.FailedPrecondition, "QUEUE_NOT_EMPTY", 0},
+	{mq.ErrCorruptRecord, codes.DataLoss, "CORRUPT_RECORD", 0},
+	{mq.ErrClosed, codes.Unavailable, "QUEUE_CLOSED", shutdownRetryDelay},
+	{mq.ErrDraining, codes.Unavailable, "SHUTTING_DOWN", shutdownRetryDelay},
+	{context.Canceled, codes.Canceled, "CANCELED", 0},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED", 0},
+}
+
+// describe returns how err is reported. Errors not in failures are internal errors.
+func describe(err error) failure {
+	for _, f := range failures {
+		if errors.Is(err, f.err) {
+			var rlErr *ratelimit.Error
+			if errors.As(err, &rlErr) {
+				f.retry = rlErr.RetryAfter
+			}
+			return f
+		}
+	}
+	return failure{code: codes.Internal, reason: "INTERNAL"}
+}
+
+// grpcError returns the gRPC status error reporting err, with metadata (which may
+// be nil) added to its ErrorInfo. A nil err and errors that already are status
+// errors are returned as they are.
+func grpcError(err error, metadata map[string]string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	f := describe(err)
+	st := status.New(f.code, err.Error())
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: f.reason, Domain: errorDomain, Metadata: metadata}}
+	if f.retry > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(f.retry)})
+	}
+	var rlErr *ratelimit.Error
+	if errors.As(err, &rlErr) {
+		details = append(details, &errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     string(rlErr.Key) + ":" + rlErr.Value,
+			Description: rlErr.Error(),
+		}}})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// errorStatus describes err for a reply on a stream.
+func errorStatus(err error) *proto.ErrorStatus {
+	f := describe(err)
+	return &proto.ErrorStatus{
+		Code:         int32(f.code),
+		Message:      err.Error(),
+		Reason:       f.reason,
+		RetryAfterMs: f.retry.Milliseconds(),
+	}
+}
@@ -0,0 +1,60 @@
+// throttle.go - Applying rate limits to producers.
+//
+// This file checks every produced message against a ratelimit.Limiter before it
+// reaches a queue, topic or log, the same way for every transport. A message is
+// keyed by the client's IP address, the subject the client authenticated as (if
+// any) and the name of its destination. gRPC producers that exceed a limit get
+// ResourceExhausted with the time to wait before retrying (a stream ends with
+// it), or InvalidArgument for a message that no wait would let through;
+// WebSocket producers get an error frame for that message and may go on sending.
+
+package server
+
+import (
+	"context"  // For the caller's address and principal
+	"net"      // For splitting the peer address
+	"net/http" // For WebSocket producers
+
+	"quickpulse/auth"      // For the authenticated subject
+	"quickpulse/ratelimit" // Rate limits
+
+	"google.golang.org/grpc/peer" // For the gRPC client address
+)
+
+// throttle checks a message of size bytes for destination, sent by the client at
+// addr (host:port) in ctx, against limits. A nil limits allows everything.
+func throttle(limits *ratelimit.Limiter, ctx context.Context, addr, destination string, size int) error {
+	if limits == nil {
+		return nil
+	}
+	req := ratelimit.Request{Client: addr, Queue: destination, Bytes: size}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		req.Client = host
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		req.Subject = p.Subject
+	}
+	return limits.Allow(req)
+}
+
+// throttleGrpc checks a message produced over gRPC, returning a ResourceExhausted
+// status with RetryInfo and QuotaFailure details if it exceeds a limit, or an
+// InvalidArgument one without RetryInfo if it is larger than a byte burst.
+func throttleGrpc(limits *ratelimit.Limiter, ctx context.Context, destination string, size int) error {
+	if limits == nil {
+		return nil
+	}
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if err := throttle(limits, ctx, addr, destination, size); err != nil {
+		return grpcError(err, nil)
+	}
+	return nil
+}
+
+// throttleWs checks a message produced over the WebSocket connection opened by r.
+func throttleWs(limits *ratelimit.Limiter, r *http.Request, destination string, size int) error {
+	return throttle(limits, r.Context(), r.RemoteAddr, destination, size)
+}
@@ -0,0 +1,164 @@
+// version.go - Negotiating how failed gRPC requests are reported.
+//
+// This file lets every gRPC call choose the API version (proto.ApiVersion) it is
+// answered with, in the "quickpulse-api-version" request metadata, and names the
+// version used in the response header of the same name. Version 2 reports
+// failures as status errors with error details (status.go). Version 1, which
+// callers that send no version get, reports them as the servers did before
+// versions existed: the response's deprecated error field (and success, where
+// the response has one) describe the failure and the call returns an OK status.
+// The handlers only produce version 2 errors; the unary interceptor turns them
+// into version 1 responses, so the deprecated fields are filled in one place.
+
+package server
+
+import (
+	"context" // For the call context
+	"errors"  // For version errors
+	"strconv" // For parsing versions and counts
+	"strings" // For splitting lists
+
+	"quickpulse/proto" // gRPC protobuf definitions
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails" // For the ErrorInfo of a failure
+	"google.golang.org/grpc"                               // For interceptors
+	"google.golang.org/grpc/metadata"                      // For the version metadata
+	"google.golang.org/grpc/status"                        // For reading status errors
+
+	"google.golang.org/protobuf/reflect/protoreflect"  // For filling in any response
+	"google.golang.org/protobuf/reflect/protoregistry" // For finding response types
+)
+
+// APIVersionKey is the request metadata, and response header, naming the API version of a call.
+const APIVersionKey = "quickpulse-api-version"
+
+// errAPIVersion refuses calls asking for a version this server does not know.
+var errAPIVersion = errors.New("unsupported API version: use 1 or 2")
+
+// v1Statuses lists the reasons that version 1 already reported as status errors.
+var v1Statuses = map[string]bool{
+	"RATE_LIMITED":      true,
+	"LARGER_THAN_BURST": true,
+	"CANCELED":          true,
+	"DEADLINE_EXCEEDED": true,
+}
+
+// apiVersion returns the API version the call in ctx asks for.
+func apiVersion(ctx context.Context) (proto.ApiVersion, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(APIVersionKey)
+	if len(values) == 0 {
+		return proto.ApiVersion_API_VERSION_1, nil
+	}
+	switch n, _ := strconv.Atoi(values[0]); proto.ApiVersion(n) {
+	case proto.ApiVersion_API_VERSION_1, proto.ApiVersion_API_VERSION_2:
+		return proto.ApiVersion(n), nil
+	}
+	return 0, errAPIVersion
+}
+
+// negotiate returns the API version of the call in ctx and sends it in the response header.
+func negotiate(ctx context.Context, setHeader func(metadata.MD) error) (proto.ApiVersion, error) {
+	version, err := apiVersion(ctx)
+	if err != nil {
+		return 0, grpcError(err, nil)
+	}
+	setHeader(metadata.Pairs(APIVersionKey, strconv.Itoa(int(version))))
+	return version, nil
+}
+
+// UnaryVersionInterceptor answers every unary RPC in the API version it asks
+// for, turning failures into version 1 responses for version 1 callers.
+func UnaryVersionInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		version, err := negotiate(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		if err != nil && version == proto.ApiVersion_API_VERSION_1 {
+			if v1, ok := v1Response(info.FullMethod, err); ok {
+				return v1, nil
+			}
+		}
+		return resp, err
+	}
+}
+
+// StreamVersionInterceptor checks the API version every stream asks for. Stream
+// replies describe failures the same way in both versions.
+func StreamVersionInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, err := negotiate(ss.Context(), ss.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// v1Response returns the version 1 response of the method for the status error
+// err: the method's response with error set to the status message, success
+// false, and the counts and lists of the ErrorInfo metadata in the fields of the
+// same name. It returns false for failures version 1 reported as status errors,
+// and for responses without an error field.
+func v1Response(method string, err error) (any, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if ei, ok := detail.(*errdetails.ErrorInfo); ok && ei.Domain == errorDomain {
+			info = ei
+		}
+	}
+	if info == nil || v1Statuses[info.Reason] {
+		return nil, false
+	}
+	// "/messagequeue.MessageQueue/Produce" describes messagequeue.MessageQueue.Produce
+	desc, lookupErr := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(strings.ReplaceAll(strings.TrimPrefix(method, "/"), "/", ".")))
+	md, ok := desc.(protoreflect.MethodDescriptor)
+	if lookupErr != nil || !ok {
+		return nil, false
+	}
+	mt, lookupErr := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if lookupErr != nil {
+		return nil, false
+	}
+	resp := mt.New()
+	fields := resp.Descriptor().Fields()
+	errorField := fields.ByName("error")
+	if errorField == nil {
+		return nil, false
+	}
+	resp.Set(errorField, protoreflect.ValueOfString(st.Message()))
+	for name, value := range info.Metadata {
+		if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+			setV1Field(resp, fd, value)
+		}
+	}
+	return resp.Interface(), true
+}
+
+// setV1Field sets a count or list field of resp from its ErrorInfo metadata value.
+// Other fields are left unset, as their metadata names what the request named.
+func setV1Field(resp protoreflect.Message, fd protoreflect.FieldDescriptor, value string) {
+	switch {
+	case fd.IsList() && fd.Kind() == protoreflect.StringKind:
+		list := resp.Mutable(fd).List()
+		for _, item := range strings.Split(value, ",") {
+			if item != "" {
+				list.Append(protoreflect.ValueOfString(item))
+			}
+		}
+	case fd.IsList():
+	case fd.Kind() == protoreflect.Uint32Kind:
+		if n, err := strconv.ParseUint(value, 10, 32); err == nil {
+			resp.Set(fd, protoreflect.ValueOfUint32(uint32(n)))
+		}
+	case fd.Kind() == protoreflect.Uint64Kind:
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			resp.Set(fd, protoreflect.ValueOfUint64(n))
+		}
+	}
+}
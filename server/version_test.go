@@ -0,0 +1,64 @@
+// version_test.go - Tests for negotiating the API version of gRPC calls.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"quickpulse/mq"
+	"quickpulse/proto"
+	"quickpulse/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// callVersion runs a unary call of method that fails with err through the
+// version interceptor, asking for version (none if empty).
+func callVersion(version, method string, err error) (any, error) {
+	ctx := context.Background()
+	if version != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(APIVersionKey, version))
+	}
+	handler := func(context.Context, any) (any, error) { return nil, err }
+	return UnaryVersionInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+}
+
+func TestAPIVersions(t *testing.T) {
+	full := grpcError(mq.ErrFull, map[string]string{"queue": "orders"})
+
+	// Version 1, the default, reports failures in the response
+	for _, version := range []string{"", "1"} {
+		resp, err := callVersion(version, "/messagequeue.MessageQueue/Produce", full)
+		produced, ok := resp.(*proto.ProduceResponse)
+		if err != nil || !ok || produced.Success || produced.Error != mq.ErrFull.Error() {
+			t.Fatalf("version %q Produce = %v, %v", version, resp, err)
+		}
+	}
+	resp, err := callVersion("", "/messagequeue.MessageQueue/Consume", grpcError(mq.ErrEmpty, nil))
+	if consumed, ok := resp.(*proto.ConsumeResponse); err != nil || !ok || consumed.Error != mq.ErrEmpty.Error() {
+		t.Fatalf("version 1 Consume = %v, %v", resp, err)
+	}
+	// Counts and lists in the error metadata fill the response fields of the same name
+	partial := grpcError(mq.ErrFull, map[string]string{"topic": "events", "deliveries": "2", "delivered_subscriptions": "a,b"})
+	resp, err = callVersion("", "/messagequeue.MessageQueue/Produce", partial)
+	if produced, ok := resp.(*proto.ProduceResponse); err != nil || !ok || produced.Deliveries != 2 || len(produced.DeliveredSubscriptions) != 2 {
+		t.Fatalf("version 1 partial Produce = %v, %v", resp, err)
+	}
+	// Rate limits were status errors in version 1 already
+	limited := grpcError(&ratelimit.Error{Key: ratelimit.KeyClient, Value: "10.0.0.1", Limit: ratelimit.LimitMessages, Rate: 1}, nil)
+	if _, err := callVersion("", "/messagequeue.MessageQueue/Produce", limited); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("version 1 rate limit = %v, want ResourceExhausted", err)
+	}
+
+	// Version 2 reports failures as status errors
+	if resp, err := callVersion("2", "/messagequeue.MessageQueue/Produce", full); resp != nil || status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("version 2 Produce = %v, %v", resp, err)
+	}
+	if _, err := callVersion("3", "/messagequeue.MessageQueue/Produce", nil); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("version 3 = %v, want InvalidArgument", err)
+	}
+}
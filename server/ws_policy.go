@@ -0,0 +1,184 @@
+// ws_policy.go - Origin, subprotocol and size limits for WebSocket clients.
+//
+// This file defines WsOptions, the handshake policy of the WebSocket endpoints.
+// Browsers send an Origin header, which is checked against a list of allowed
+// origins, exact ("https://app.example.com") or with wildcards
+// ("https://*.example.com"); without a list only same-origin pages and clients
+// that send no Origin (non-browser clients) may connect. A client offering
+// subprotocols gets the first of the server's that it offers, and is refused if
+// there is none. Once connected, a frame or message larger than MaxMessageSize
+// ends the connection with close code 1009, and each client IP may hold at most
+// MaxConnectionsPerIP connections. Every refused client is reported to the
+// WsObserver with the reason.
+
+package server
+
+import (
+	"errors"   // For recognizing oversized messages
+	"log/slog" // For logging upgrade errors
+	"net"      // For the client IP
+	"net/http" // For the handshake
+	"path"     // For matching wildcard origins
+	"strings"  // For comparing origins
+	"sync"     // For counting connections per IP
+
+	"github.com/gorilla/websocket" // WebSocket support
+)
+
+// Reasons a WebSocket client is refused, as reported to WsObserver.
+const (
+	RejectOrigin          = "origin"            // Origin not allowed
+	RejectSubprotocol     = "subprotocol"       // None of the offered subprotocols is supported
+	RejectConnectionLimit = "connection_limit"  // The client IP holds MaxConnectionsPerIP connections
+	RejectMessageTooLarge = "message_too_large" // A message exceeded MaxMessageSize
+)
+
+// WsObserver is implemented by metrics collectors that count WebSocket clients
+// refused by the handshake policy. It is called once per refusal.
+type WsObserver interface {
+	ObserveRejected(reason string)
+}
+
+// WsOptions is the handshake policy of the WebSocket endpoints. The zero value
+// allows same-origin browsers and non-browser clients, negotiates no
+// subprotocol and sets no limits.
+type WsOptions struct {
+	AllowedOrigins      []string   // Origins browsers may connect from; "*" allows any, empty only the same origin
+	Subprotocols        []string   // Supported subprotocols, in order of preference
+	MaxMessageSize      int64      // Largest frame or message a client may send; zero is unlimited
+	MaxConnectionsPerIP int        // Open connections per client IP; zero is unlimited
+	ReadBufferSize      int        // Read buffer size; zero selects 4KB
+	WriteBufferSize     int        // Write buffer size; zero selects 4KB
+	Observer            WsObserver // Counts refused clients; nil counts nothing
+}
+
+// wsPolicy applies WsOptions to the connections of a WsServer.
+type wsPolicy struct {
+	opts     WsOptions
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex     // Guards perIP
+	perIP map[string]int // Open connections by client IP
+}
+
+func newWsPolicy(opts WsOptions) *wsPolicy {
+	p := &wsPolicy{opts: opts, perIP: make(map[string]int)}
+	p.upgrader = websocket.Upgrader{
+		ReadBufferSize:  opts.ReadBufferSize,
+		WriteBufferSize: opts.WriteBufferSize,
+		Subprotocols:    opts.Subprotocols,
+		CheckOrigin:     p.checkOrigin,
+	}
+	return p
+}
+
+// reject reports a refused client.
+func (p *wsPolicy) reject(reason string) {
+	if p.opts.Observer != nil {
+		p.opts.Observer.ObserveRejected(reason)
+	}
+}
+
+// checkOrigin allows clients without an Origin header, same-origin pages and the
+// allowed origins.
+func (p *wsPolicy) checkOrigin(r *http.Request) bool {
+	origin := strings.ToLower(r.Header.Get("Origin"))
+	if origin == "" || originAllowed(origin, p.opts.AllowedOrigins) {
+		return true
+	}
+	if len(p.opts.AllowedOrigins) == 0 {
+		if _, host, ok := strings.Cut(origin, "://"); ok && strings.EqualFold(host, r.Host) {
+			return true
+		}
+	}
+	p.reject(RejectOrigin)
+	return false
+}
+
+// originAllowed reports whether origin matches one of patterns, in which "*"
+// stands for any run of characters other than "/".
+func originAllowed(origin string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), origin); ok || pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// upgrade checks the subprotocols and connection limit, upgrades the connection
+// and applies the read limit. On failure it has already answered the request and
+// returns a nil connection; otherwise the caller must call release once the
+// connection is closed.
+func (p *wsPolicy) upgrade(w http.ResponseWriter, r *http.Request) (conn *websocket.Conn, release func()) {
+	if offered := websocket.Subprotocols(r); len(offered) > 0 && len(p.opts.Subprotocols) > 0 && !supported(offered, p.opts.Subprotocols) {
+		p.reject(RejectSubprotocol)
+		http.Error(w, "unsupported subprotocol: use one of "+strings.Join(p.opts.Subprotocols, ", "), http.StatusBadRequest)
+		return nil, nil
+	}
+	ip := clientIP(r)
+	if !p.acquire(ip) {
+		p.reject(RejectConnectionLimit)
+		http.Error(w, "too many connections from "+ip, http.StatusTooManyRequests)
+		return nil, nil
+	}
+	// Upgrade answers the request itself on failure, including a 403 for a refused origin
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Warn("WebSocket upgrade error", "err", err)
+		p.release(ip)
+		return nil, nil
+	}
+	if p.opts.MaxMessageSize > 0 {
+		conn.SetReadLimit(p.opts.MaxMessageSize)
+	}
+	return conn, func() { p.release(ip) }
+}
+
+// readError reports a read that failed because the client sent too large a message.
+func (p *wsPolicy) readError(err error) {
+	if errors.Is(err, websocket.ErrReadLimit) {
+		p.reject(RejectMessageTooLarge)
+	}
+}
+
+// supported reports whether the client offered one of the server's subprotocols.
+func supported(offered, protocols []string) bool {
+	for _, o := range offered {
+		for _, p := range protocols {
+			if o == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP address of the client that sent r.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// acquire counts a new connection from ip, returning false if ip is at its limit.
+func (p *wsPolicy) acquire(ip string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if limit := p.opts.MaxConnectionsPerIP; limit > 0 && p.perIP[ip] >= limit {
+		return false
+	}
+	p.perIP[ip]++
+	return true
+}
+
+// release forgets a connection from ip counted by acquire.
+func (p *wsPolicy) release(ip string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.perIP[ip]--; p.perIP[ip] <= 0 {
+		delete(p.perIP, ip)
+	}
+}
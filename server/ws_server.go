@@ -1,96 +1,468 @@
 // ws_server.go - WebSocket server for publishing and consuming messages.
 //
 // This file defines WsServer, which provides WebSocket endpoints for clients to
-// publish messages to and consume messages from a message queue. It uses the
-// gorilla/websocket package for WebSocket support.
+// publish messages to and consume messages from named queues. The queue is taken
+// from the {queue} path segment (e.g. /ws/publish/orders), falling back to the
+// default queue. Topics have their own endpoints under /ws/topics/{topic}/ for
+// publishing to every subscription and for consuming through a subscription.
+// Clients exchange raw payloads by default, or JSON frames carrying the message
+// envelope when they connect with ?format=json. On shutdown the server sends every
+// open connection a close frame. It uses the gorilla/websocket package for
+// WebSocket support; the handshake policy (origins, subprotocols and limits) is in
+// ws_policy.go.
 
 package server
 
 import (
-	"log"      // For logging errors and events
-	"net/http" // For HTTP server and handlers
+	"context"       // For cancelling blocked consumers when the connection closes
+	"encoding/json" // For JSON publish acknowledgements
+	"log/slog"      // For logging errors and events
+	"net/http"      // For HTTP server and handlers
+	"strconv"       // For parsing query parameters
+	"strings"       // For parsing ack/nack commands
+	"sync"          // For serializing connection writes and tracking connections
+	"time"          // For visibility timeouts
 
 	"github.com/gorilla/websocket" // WebSocket support
 	"quickpulse/mq"                // Message queue interface
+	"quickpulse/ratelimit"         // Rate limits on producers
 )
 
+// closeFrameTimeout bounds how long sending a close frame may take.
+const closeFrameTimeout = time.Second
+
 // WsServer provides WebSocket endpoints for publishing and consuming messages.
 type WsServer struct {
-	Queue mq.Queue // Underlying message queue
+	Queues *mq.Registry       // Registry of named queues
+	Limits *ratelimit.Limiter // Rate limits on producers; nil is unlimited
+
+	policy   *wsPolicy                    // Handshake policy and connection limits
+	mu       sync.Mutex                   // Guards conns and closing
+	conns    map[*websocket.Conn]struct{} // Open connections, for Shutdown
+	closing  bool                         // Set by Shutdown; new connections are closed at once
+	handlers sync.WaitGroup               // Handlers serving a tracked connection
+}
+
+// NewWsServer creates a new WsServer backed by the given queue registry, applying
+// limits (which may be nil) to producers and accepting clients according to opts.
+func NewWsServer(queues *mq.Registry, limits *ratelimit.Limiter, opts WsOptions) *WsServer {
+	return &WsServer{Queues: queues, Limits: limits, policy: newWsPolicy(opts)}
+}
+
+// track registers an upgraded connection so Shutdown can close it. Once the server
+// is shutting down it sends the close frame at once and returns false, and the
+// handler should return; otherwise the handler must call untrack when it is done.
+func (s *WsServer) track(conn *websocket.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		sendClose(conn)
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[*websocket.Conn]struct{})
+	}
+	s.conns[conn] = struct{}{}
+	s.handlers.Add(1)
+	return true
 }
 
-// NewWsServer creates a new WsServer with the given queue.
-func NewWsServer(queue mq.Queue) *WsServer {
-	return &WsServer{Queue: queue}
+// untrack forgets a connection registered by track.
+func (s *WsServer) untrack(conn *websocket.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	s.handlers.Done()
 }
 
-// upgrader is used to upgrade HTTP connections to WebSocket connections.
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true }, // Allow all origins
+// Shutdown sends a close frame (1001 "going away") to every open connection and
+// waits until the clients have answered and the handlers have returned, or until
+// ctx is done, in which case the remaining connections are closed outright and
+// ctx.Err() is returned. Connections accepted afterwards are closed at once. It
+// does not stop the HTTP server, which must be shut down separately.
+func (s *WsServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	for conn := range s.conns {
+		sendClose(conn)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.handlers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
 }
 
+// sendClose tells the client that the server is going away. The client's reply
+// makes the handler's next read fail, which ends the connection.
+func sendClose(conn *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, mq.ErrDraining.Error())
+	// WriteControl may be called concurrently with the handler's writes
+	if err := conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeFrameTimeout)); err != nil {
+		slog.Debug("WebSocket close error", "err", err)
+	}
+}
+
+// queueFor resolves the queue named by the request path, writing an HTTP error
+// (before any WebSocket upgrade) and returning nil if it does not exist.
+func (s *WsServer) queueFor(w http.ResponseWriter, r *http.Request) *mq.NamedQueue {
+	queue, err := lookupQueue(s.Queues, r.PathValue("queue"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil
+	}
+	return queue
+}
+
+// wsTarget delivers a published message, ready at at (zero for now), to a queue or topic.
+type wsTarget func(msg *mq.Message, at time.Time) error
+
 // PublishHandler handles WebSocket connections for publishing messages to the queue.
 // Each message received from the client is enqueued, and an "ok" or "error" response is sent back.
+// With ?format=json each frame is a JSON message (payload, headers, content_type and an
+// optional priority, ttl_ms and delay_ms or deliver_at) and the reply is a JSON
+// acknowledgement carrying the server-assigned message ID. ?delay_ms=, ?ttl_ms= and the
+// X-Priority handshake header (or ?priority=) apply to every message that does not set its own.
+// A message beyond the rate limits is answered with an error and not enqueued.
 func (s *WsServer) PublishHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Println("WebSocket upgrade error:", err)
+	queue := s.queueFor(w, r)
+	if queue == nil {
+		return
+	}
+	s.publish(w, r, queue.Name(), func(msg *mq.Message, at time.Time) error {
+		return produce(queue, msg, at)
+	})
+}
+
+// TopicPublishHandler handles WebSocket connections for publishing messages to every
+// subscription of the {topic} in the path. It works like PublishHandler; publishing to
+// a topic without subscriptions fails with "topic not found".
+func (s *WsServer) TopicPublishHandler(w http.ResponseWriter, r *http.Request) {
+	topic := r.PathValue("topic")
+	s.publish(w, r, topic, func(msg *mq.Message, at time.Time) error {
+		_, err := s.Queues.Publish(topic, nil, msg, at)
+		return err
+	})
+}
+
+// publish serves a publishing connection, handing every message for destination
+// to deliver. Once the registry stops producing, every message is answered with
+// an error, as are messages beyond the rate limits.
+func (s *WsServer) publish(w http.ResponseWriter, r *http.Request, destination string, deliver wsTarget) {
+	defaults := publishDefaults(r)
+	conn, release := s.policy.upgrade(w, r)
+	if conn == nil {
 		return
 	}
+	defer release()
 	defer conn.Close()
+	if !s.track(conn) {
+		return
+	}
+	defer s.untrack(conn)
+	target := func(msg *mq.Message, at time.Time) error {
+		if !s.Queues.Producing() {
+			return mq.ErrDraining
+		}
+		if err := throttleWs(s.Limits, r, destination, len(msg.GetPayload())); err != nil {
+			return err
+		}
+		return deliver(msg, at)
+	}
 
+	jsonFormat := wantsJSON(r)
 	for {
 		// Read a message from the client
-		_, msg, err := conn.ReadMessage()
+		_, data, err := conn.ReadMessage()
 		if err != nil {
-			log.Println("Read error:", err)
+			slog.Debug("WebSocket read error", "err", err)
+			s.policy.readError(err)
 			break
 		}
-		// Enqueue the message
-		err = s.Queue.Enqueue(msg)
-		resp := "ok"
-		if err != nil {
-			resp = "error: " + err.Error()
+		var resp []byte
+		if jsonFormat {
+			resp = publishJSON(target, data, defaults)
+		} else {
+			// Enqueue the raw payload
+			resp = []byte("ok")
+			at, _ := deliveryTime(defaults.delayMs, time.Time{})
+			msg := newMessage(data, nil, "", time.Duration(defaults.ttlMs)*time.Millisecond)
+			msg.SetPriority(defaults.priority)
+			msg.SetKey(defaults.key)
+			if err := target(msg, at); err != nil {
+				resp = []byte("error: " + err.Error())
+			}
 		}
 		// Send response to the client
-		if err := conn.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
-			log.Println("Write error:", err)
+		if err := conn.WriteMessage(websocket.TextMessage, resp); err != nil {
+			slog.Debug("WebSocket write error", "err", err)
 			break
 		}
 	}
 }
 
+// publishJSON enqueues a JSON publish frame and returns the JSON acknowledgement.
+func publishJSON(target wsTarget, data []byte, defaults wsPublishDefaults) []byte {
+	ack := wsReply{Status: "ok"}
+	msg, at, err := decodeWsMessage(data, defaults)
+	if err == nil {
+		err = target(msg, at)
+	}
+	if err != nil {
+		ack = wsReply{Status: "error", Error: err.Error()}
+	} else {
+		ack.ID = msg.GetID()
+	}
+	resp, _ := json.Marshal(ack)
+	return resp
+}
+
 // ConsumeHandler handles WebSocket connections for consuming messages from the queue.
 // The client sends a request (any message) to receive the next message from the queue.
+// If the queue is empty the handler parks until a message arrives or the client disconnects.
+// Messages are sent as raw binary payloads, or as JSON text frames with ?format=json.
+//
+// With ?lease=1 (which requires ?format=json) messages are leased rather than removed:
+// the client settles each one by sending "ack <id>" or "nack <id>", and a message that
+// is not acked within the visibility timeout (?visibility_timeout_ms, or the queue's
+// default) is redelivered. Every ack or nack is answered with a JSON status frame.
+//
+// On a partitioned queue each connection is a member of the queue (named by
+// ?consumer=, or generated) and only receives messages from the partitions
+// assigned to it; partitions are rebalanced as consumers connect and disconnect.
 func (s *WsServer) ConsumeHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	queue := s.queueFor(w, r)
+	if queue == nil {
+		return
+	}
+	s.consume(w, r, func() (*mq.NamedQueue, wsCommand, func(), error) {
+		return queue, nil, nil, nil
+	})
+}
+
+// wsCommand handles a text command sent by a consumer, other than ack and nack.
+// ok is false if frame is not a command it knows; stop ends the connection once
+// the reply has been sent.
+type wsCommand func(frame string) (reply []byte, stop, ok bool)
+
+// wsSource opens the queue a consumer reads from, once its connection has been
+// upgraded. It also returns the commands the connection accepts (nil for none)
+// and a function to call when the connection ends (nil for none).
+type wsSource func() (queue *mq.NamedQueue, command wsCommand, done func(), err error)
+
+// SubscribeHandler handles WebSocket connections for consuming from a subscription
+// to the {topic} in the path. With ?subscription=<name> the connection attaches to
+// that (durable) subscription, creating it if needed, and competes for its messages
+// with other consumers of the same subscription; the subscription outlives the
+// connection until a client sends "unsubscribe". Without it the connection gets a
+// private subscription that is removed when it disconnects. Messages are requested
+// and delivered as with ConsumeHandler, whose options (format, lease) apply. The
+// subscription is only attached once the handshake has been accepted, so a refused
+// client leaves nothing behind.
+func (s *WsServer) SubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	topic := r.PathValue("topic")
+	subscription := r.URL.Query().Get("subscription")
+	ephemeral := subscription == ""
+	if ephemeral {
+		subscription = "ws-" + mq.NewID()
+	}
+	if !mq.ValidQueueName(topic) || !mq.ValidQueueName(subscription) {
+		http.Error(w, mq.ErrInvalidQueueName.Error(), http.StatusBadRequest)
+		return
+	}
+	jsonFormat := wantsJSON(r)
+	s.consume(w, r, func() (*mq.NamedQueue, wsCommand, func(), error) {
+		queue, err := s.Queues.Subscribe(topic, subscription, mq.QueueConfig{})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var done func()
+		if ephemeral {
+			done = func() { s.Queues.Unsubscribe(topic, subscription) }
+		}
+		return queue, func(frame string) ([]byte, bool, bool) {
+			if strings.TrimSpace(frame) != "unsubscribe" {
+				return nil, false, false
+			}
+			err := s.Queues.Unsubscribe(topic, subscription)
+			return commandReply(jsonFormat, "", err), err == nil, true
+		}, done, nil
+	})
+}
+
+// commandReply renders the reply to a consumer command: a JSON status frame for
+// JSON clients, and "ok" or "error: ..." for raw ones.
+func commandReply(jsonFormat bool, id string, err error) []byte {
+	if !jsonFormat {
+		if err != nil {
+			return []byte("error: " + err.Error())
+		}
+		return []byte("ok")
+	}
+	ack := wsReply{Status: "ok", ID: id}
 	if err != nil {
-		log.Println("WebSocket upgrade error:", err)
+		ack = wsReply{Status: "error", ID: id, Error: err.Error()}
+	}
+	reply, _ := json.Marshal(ack)
+	return reply
+}
+
+// consume upgrades a consuming connection and serves it on the queue source
+// opens, which is only called once the handshake has been accepted.
+func (s *WsServer) consume(w http.ResponseWriter, r *http.Request, source wsSource) {
+	jsonFormat := wantsJSON(r)
+	lease := r.URL.Query().Get("lease") == "1"
+	if lease && !jsonFormat {
+		http.Error(w, "lease mode requires format=json", http.StatusBadRequest)
+		return
+	}
+	visibilityMs, _ := strconv.ParseInt(r.URL.Query().Get("visibility_timeout_ms"), 10, 64)
+	visibility := time.Duration(visibilityMs) * time.Millisecond
+	conn, release := s.policy.upgrade(w, r)
+	if conn == nil {
 		return
 	}
+	defer release()
 	defer conn.Close()
+	if !s.track(conn) {
+		return
+	}
+	defer s.untrack(conn)
+	queue, command, done, err := source()
+	if err != nil {
+		// The handshake is over, so the failure is reported as the reason for closing
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+		return
+	}
+	if done != nil {
+		defer done()
+	}
+
+	// On a partitioned queue the connection joins as a member and only receives
+	// messages from the partitions assigned to it while it stays connected.
+	var member *mq.PartitionConsumer
+	if queue.Config().Partitions > 1 {
+		id := r.URL.Query().Get("consumer")
+		if id == "" {
+			id = "ws-" + mq.NewID()
+		}
+		member, _ = queue.Join(id)
+		defer member.Leave()
+	}
+
+	// Acks are answered by the reader while the main loop may be sending a message,
+	// so writes are serialized.
+	var writeMu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteMessage(messageType, data)
+	}
+
+	// A hijacked connection does not cancel r.Context(), so a dedicated reader
+	// cancels ctx when the client goes away and wakes a blocked DequeueContext.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	requests := make(chan struct{})
+	go func() {
+		defer cancel()
+		for {
+			// Wait for client to request a message (could be any message, e.g., "next")
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				slog.Debug("WebSocket read error", "err", err)
+				s.policy.readError(err)
+				return
+			}
+			if lease {
+				if reply, ok := settleCommand(queue.Leases(), string(data)); ok {
+					if err := write(websocket.TextMessage, reply); err != nil {
+						slog.Debug("WebSocket write error", "err", err)
+						return
+					}
+					continue
+				}
+			}
+			if command != nil {
+				if reply, stop, ok := command(string(data)); ok {
+					if err := write(websocket.TextMessage, reply); err != nil {
+						slog.Debug("WebSocket write error", "err", err)
+						return
+					}
+					if stop {
+						return
+					}
+					continue
+				}
+			}
+			select {
+			case requests <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	for {
-		// Wait for client to request a message (could be any message, e.g., "next")
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			log.Println("Read error:", err)
-			break
+		select {
+		case <-requests:
+		case <-ctx.Done():
+			return
 		}
-		// Dequeue the next message from the queue
-		msg, err := s.Queue.Dequeue()
+		// Wait for the next message from the queue
+		next, err := receive(ctx, queue, member, true, lease, visibility)
 		if err != nil {
-			// Send error response if queue is empty
-			if err := conn.WriteMessage(websocket.TextMessage, []byte("error: "+err.Error())); err != nil {
-				log.Println("Write error:", err)
-				break
+			// The client disconnected while we were waiting
+			return
+		}
+		if jsonFormat {
+			// Send the message with its envelope as a JSON text message
+			data, err := encodeWsMessage(next.Message, next.Deadline)
+			if err == nil {
+				err = write(websocket.TextMessage, data)
+			}
+			if err != nil {
+				slog.Debug("WebSocket write error", "err", err)
+				return
 			}
 			continue
 		}
 		// Send the message to the client as a binary WebSocket message
-		if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
-			log.Println("Write error:", err)
-			break
+		if err := write(websocket.BinaryMessage, next.Message.GetPayload()); err != nil {
+			slog.Debug("WebSocket write error", "err", err)
+			return
 		}
 	}
-}
\ No newline at end of file
+}
+
+// settleCommand applies an "ack <id>" or "nack <id>" command from a leasing consumer
+// and returns the JSON reply. ok is false if the frame is not such a command.
+func settleCommand(leases *mq.Leases, frame string) (reply []byte, ok bool) {
+	verb, id, found := strings.Cut(strings.TrimSpace(frame), " ")
+	if !found {
+		return nil, false
+	}
+	var err error
+	switch verb {
+	case "ack":
+		err = leases.Ack(id)
+	case "nack":
+		err = leases.Nack(id)
+	default:
+		return nil, false
+	}
+	return commandReply(true, id, err), true
+}
@@ -0,0 +1,48 @@
+// ws_server_test.go - Tests for the WebSocket server.
+
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quickpulse/mq"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSubscribeRefusedOriginLeavesNoSubscription(t *testing.T) {
+	registry := mq.NewRegistry(nil)
+	s := NewWsServer(registry, nil, WsOptions{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws/topics/{topic}/subscribe", s.SubscribeHandler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/topics/events/subscribe?subscription=billing"
+
+	// A cross-origin page is refused before the subscription is attached
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://elsewhere.example"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross-origin subscribe = %v, %v; want 403", resp, err)
+	}
+	if _, err := registry.Get(mq.SubscriptionQueue("events", "billing")); !errors.Is(err, mq.ErrQueueNotFound) {
+		t.Fatalf("subscription queue after a refused handshake: %v", err)
+	}
+
+	// An accepted connection attaches it, just after the handshake
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for deadline := time.Now().Add(time.Second); len(registry.Topics()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if topics := registry.Topics(); len(topics) != 1 || topics[0].Name != "events" {
+		t.Fatalf("Topics() = %+v", topics)
+	}
+}